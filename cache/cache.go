@@ -0,0 +1,152 @@
+// Package cache — read-through кэш для справочных данных, которые отдаются
+// пользователям (города, предметы, карточка университета и т.п.).
+//
+// Значения кэшируются по ключу из сущности, языка и параметров запроса и помечаются
+// тегами сущностей, от которых зависят. Изменение сущности через админку сбрасывает
+// все ключи с её тегом.
+//
+// В кэш попадают только данные, одинаковые для всех пользователей. Избранное, отзывы
+// пользователя и всё, что зависит от user_id, кэшировать нельзя.
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testhub-spec-uni/metrics"
+	"time"
+)
+
+const (
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+	BackendOff    = "off"
+)
+
+// DefaultTTL — время жизни записи, если в конфиге не задан cache_ttl.
+const DefaultTTL = 10 * time.Minute
+
+// allTag добавляется к каждой записи, чтобы InvalidateAll работал одинаково во всех
+// бэкендах.
+const allTag = "*"
+
+// Backend хранит сериализованные значения и индекс тегов.
+type Backend interface {
+	Get(key string) ([]byte, bool, error)
+	Set(key string, value []byte, ttl time.Duration, tags []string) error
+	InvalidateTags(tags ...string) error
+}
+
+var (
+	mu      sync.RWMutex
+	backend Backend = NewMemoryBackend()
+	ttl             = DefaultTTL
+)
+
+// Configure выбирает бэкенд: memory (по умолчанию), redis — любой сервер с протоколом
+// RESP по адресу addr, off — кэш отключён.
+func Configure(kind, addr string, recordTTL time.Duration) error {
+	var b Backend
+	switch strings.ToLower(kind) {
+	case "", BackendMemory:
+		b = NewMemoryBackend()
+	case BackendRedis:
+		if addr == "" {
+			return fmt.Errorf("cache: redis backend requires an address")
+		}
+		b = NewRESPBackend(addr)
+	case BackendOff:
+		b = nil
+	default:
+		return fmt.Errorf("cache: unknown backend %q", kind)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	backend = b
+	if recordTTL > 0 {
+		ttl = recordTTL
+	} else {
+		ttl = DefaultTTL
+	}
+	return nil
+}
+
+// SetBackend подменяет бэкенд, например в тестах.
+func SetBackend(b Backend) {
+	mu.Lock()
+	backend = b
+	mu.Unlock()
+}
+
+func current() (Backend, time.Duration) {
+	mu.RLock()
+	defer mu.RUnlock()
+	return backend, ttl
+}
+
+// Key собирает ключ из сущности, языка и параметров: "university:ru:12".
+func Key(entity, locale string, params ...interface{}) string {
+	parts := make([]string, 0, len(params)+2)
+	parts = append(parts, entity, locale)
+	for _, p := range params {
+		parts = append(parts, fmt.Sprint(p))
+	}
+	return strings.Join(parts, ":")
+}
+
+// Remember возвращает значение из кэша или вызывает load и сохраняет результат с
+// тегами. Ошибки кэша не ломают запрос: значение просто загружается заново.
+func Remember[T any](key string, tags []string, load func() (T, error)) (T, error) {
+	b, recordTTL := current()
+	if b == nil {
+		return load()
+	}
+
+	if data, ok, err := b.Get(key); err != nil {
+		slog.Warn("Cache get failed", "key", key, "error", err)
+	} else if ok {
+		var value T
+		err := json.Unmarshal(data, &value)
+		if err == nil {
+			metrics.CacheLookup(key, true)
+			return value, nil
+		}
+		slog.Warn("Cache decode failed", "key", key, "error", err)
+	}
+	metrics.CacheLookup(key, false)
+
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		slog.Warn("Cache encode failed", "key", key, "error", err)
+		return value, nil
+	}
+	recordTags := append(append(make([]string, 0, len(tags)+1), tags...), allTag)
+	if err := b.Set(key, data, recordTTL, recordTags); err != nil {
+		slog.Warn("Cache set failed", "key", key, "error", err)
+	}
+	return value, nil
+}
+
+// Invalidate удаляет все записи с любым из тегов.
+func Invalidate(tags ...string) {
+	b, _ := current()
+	if b == nil || len(tags) == 0 {
+		return
+	}
+	if err := b.InvalidateTags(tags...); err != nil {
+		slog.Warn("Cache invalidate failed", "tags", tags, "error", err)
+	}
+}
+
+// InvalidateAll очищает кэш целиком.
+func InvalidateAll() {
+	Invalidate(allTag)
+}
@@ -0,0 +1,154 @@
+package cache
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRESPServer — минимальный сервер RESP с командами, которые использует RESPBackend.
+type fakeRESPServer struct {
+	mu       sync.Mutex
+	values   map[string]string
+	sets     map[string]map[string]bool
+	listener net.Listener
+}
+
+func startFakeRESPServer(t *testing.T) *fakeRESPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &fakeRESPServer{values: map[string]string{}, sets: map[string]map[string]bool{}, listener: listener}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+	return s
+}
+
+func (s *fakeRESPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		reply, err := readRESP(r)
+		if err != nil {
+			return
+		}
+		items, _ := reply.([]interface{})
+		args := make([]string, len(items))
+		for i, item := range items {
+			args[i] = string(item.([]byte))
+		}
+		conn.Write([]byte(s.exec(args)))
+	}
+}
+
+func (s *fakeRESPServer) exec(args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch strings.ToUpper(args[0]) {
+	case "GET":
+		value, ok := s.values[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return "$" + strconv.Itoa(len(value)) + "\r\n" + value + "\r\n"
+	case "SET":
+		s.values[args[1]] = args[2]
+		return "+OK\r\n"
+	case "SADD":
+		if s.sets[args[1]] == nil {
+			s.sets[args[1]] = map[string]bool{}
+		}
+		s.sets[args[1]][args[2]] = true
+		return ":1\r\n"
+	case "PEXPIRE":
+		return ":1\r\n"
+	case "SMEMBERS":
+		reply := "*" + strconv.Itoa(len(s.sets[args[1]])) + "\r\n"
+		for member := range s.sets[args[1]] {
+			reply += "$" + strconv.Itoa(len(member)) + "\r\n" + member + "\r\n"
+		}
+		return reply
+	case "DEL":
+		for _, key := range args[1:] {
+			delete(s.values, key)
+			delete(s.sets, key)
+		}
+		return ":1\r\n"
+	}
+	return "-ERR unknown command\r\n"
+}
+
+func TestRememberAndInvalidate(t *testing.T) {
+	backends := map[string]func(t *testing.T) Backend{
+		"memory": func(t *testing.T) Backend { return NewMemoryBackend() },
+		"resp": func(t *testing.T) Backend {
+			return NewRESPBackend(startFakeRESPServer(t).listener.Addr().String())
+		},
+	}
+
+	for name, newBackend := range backends {
+		t.Run(name, func(t *testing.T) {
+			SetBackend(newBackend(t))
+			defer SetBackend(NewMemoryBackend())
+
+			loads := 0
+			load := func() ([]string, error) {
+				loads++
+				return []string{"Алматы", "Астана"}, nil
+			}
+
+			cityKey := Key("cities", "ru")
+			for i := 0; i < 2; i++ {
+				value, err := Remember(cityKey, []string{"city"}, load)
+				if err != nil || len(value) != 2 || value[0] != "Алматы" {
+					t.Fatalf("unexpected value %v, err %v", value, err)
+				}
+			}
+			if loads != 1 {
+				t.Fatalf("expected one load before invalidation, got %d", loads)
+			}
+
+			Invalidate("subject")
+			Remember(cityKey, []string{"city"}, load)
+			if loads != 1 {
+				t.Fatalf("unrelated tag dropped the key: %d loads", loads)
+			}
+
+			Invalidate("city")
+			Remember(cityKey, []string{"city"}, load)
+			if loads != 2 {
+				t.Fatalf("expected reload after invalidation, got %d loads", loads)
+			}
+
+			InvalidateAll()
+			Remember(cityKey, []string{"city"}, load)
+			if loads != 3 {
+				t.Fatalf("expected reload after InvalidateAll, got %d loads", loads)
+			}
+		})
+	}
+}
+
+func TestMemoryBackendExpires(t *testing.T) {
+	b := NewMemoryBackend()
+	b.Set("k", []byte("v"), time.Millisecond, nil)
+	time.Sleep(5 * time.Millisecond)
+	if _, ok, _ := b.Get("k"); ok {
+		t.Fatal("expired entry returned")
+	}
+}
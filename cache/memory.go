@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// memoryMaxEntries ограничивает размер кэша в памяти; при переполнении сначала
+// удаляются просроченные записи, затем кэш очищается целиком.
+const memoryMaxEntries = 10000
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+	tags      []string
+}
+
+// MemoryBackend хранит записи в памяти процесса.
+type MemoryBackend struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+	tags    map[string]map[string]struct{}
+}
+
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		entries: make(map[string]*memoryEntry),
+		tags:    make(map[string]map[string]struct{}),
+	}
+}
+
+func (m *MemoryBackend) Get(key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if time.Now().After(entry.expiresAt) {
+		m.remove(key)
+		return nil, false, nil
+	}
+	return entry.value, true, nil
+}
+
+func (m *MemoryBackend) Set(key string, value []byte, ttl time.Duration, tags []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.entries[key]; exists {
+		m.remove(key)
+	} else if len(m.entries) >= memoryMaxEntries {
+		m.evict()
+	}
+
+	m.entries[key] = &memoryEntry{value: value, expiresAt: time.Now().Add(ttl), tags: tags}
+	for _, tag := range tags {
+		if m.tags[tag] == nil {
+			m.tags[tag] = make(map[string]struct{})
+		}
+		m.tags[tag][key] = struct{}{}
+	}
+	return nil
+}
+
+func (m *MemoryBackend) InvalidateTags(tags ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, tag := range tags {
+		for key := range m.tags[tag] {
+			m.remove(key)
+		}
+		delete(m.tags, tag)
+	}
+	return nil
+}
+
+// remove удаляет запись и её ключ из индекса тегов. Вызывается под m.mu.
+func (m *MemoryBackend) remove(key string) {
+	entry, ok := m.entries[key]
+	if !ok {
+		return
+	}
+	delete(m.entries, key)
+	for _, tag := range entry.tags {
+		delete(m.tags[tag], key)
+		if len(m.tags[tag]) == 0 {
+			delete(m.tags, tag)
+		}
+	}
+}
+
+func (m *MemoryBackend) evict() {
+	now := time.Now()
+	for key, entry := range m.entries {
+		if now.After(entry.expiresAt) {
+			m.remove(key)
+		}
+	}
+	if len(m.entries) >= memoryMaxEntries {
+		m.entries = make(map[string]*memoryEntry)
+		m.tags = make(map[string]map[string]struct{})
+	}
+}
@@ -0,0 +1,210 @@
+package cache
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	respKeyPrefix = "uni:cache:"
+	respTagPrefix = "uni:cache-tag:"
+	respPoolSize  = 8
+	respTimeout   = 2 * time.Second
+)
+
+// errRESPNil — ответ сервера "нет значения" ($-1).
+var errRESPNil = errors.New("resp: nil reply")
+
+// RESPBackend хранит записи на сервере с протоколом RESP (Redis, KeyDB, Dragonfly).
+// Ключи тега хранятся в множестве, которое удаляется вместе с ключами при сбросе.
+type RESPBackend struct {
+	addr string
+	pool chan *respConn
+}
+
+type respConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func NewRESPBackend(addr string) *RESPBackend {
+	return &RESPBackend{addr: addr, pool: make(chan *respConn, respPoolSize)}
+}
+
+func (r *RESPBackend) Get(key string) ([]byte, bool, error) {
+	reply, err := r.do("GET", respKeyPrefix+key)
+	if err == errRESPNil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, false, fmt.Errorf("resp: unexpected reply %T for GET", reply)
+	}
+	return value, true, nil
+}
+
+func (r *RESPBackend) Set(key string, value []byte, ttl time.Duration, tags []string) error {
+	ms := strconv.FormatInt(ttl.Milliseconds(), 10)
+	if _, err := r.do("SET", respKeyPrefix+key, string(value), "PX", ms); err != nil {
+		return err
+	}
+	// Множество тега живёт дольше записей, чтобы сброс находил все ещё живые ключи.
+	tagTTL := strconv.FormatInt((2 * ttl).Milliseconds(), 10)
+	for _, tag := range tags {
+		if _, err := r.do("SADD", respTagPrefix+tag, key); err != nil {
+			return err
+		}
+		if _, err := r.do("PEXPIRE", respTagPrefix+tag, tagTTL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *RESPBackend) InvalidateTags(tags ...string) error {
+	for _, tag := range tags {
+		reply, err := r.do("SMEMBERS", respTagPrefix+tag)
+		if err != nil {
+			return err
+		}
+		members, _ := reply.([]interface{})
+		args := make([]string, 0, len(members)+2)
+		args = append(args, "DEL", respTagPrefix+tag)
+		for _, m := range members {
+			if key, ok := m.([]byte); ok {
+				args = append(args, respKeyPrefix+string(key))
+			}
+		}
+		if _, err := r.do(args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Do выполняет произвольную команду на пуле соединений бэкенда. Нужна тем, кто
+// делит сервер с кэшем, например ограничителю запросов.
+func (r *RESPBackend) Do(args ...string) (interface{}, error) {
+	return r.do(args...)
+}
+
+// do отправляет команду и читает ответ. Соединение с ошибкой закрывается, а не
+// возвращается в пул.
+func (r *RESPBackend) do(args ...string) (interface{}, error) {
+	c, err := r.conn()
+	if err != nil {
+		return nil, err
+	}
+
+	c.conn.SetDeadline(time.Now().Add(respTimeout))
+	reply, err := c.roundTrip(args)
+	if err != nil && err != errRESPNil {
+		if _, isServerErr := err.(respError); !isServerErr {
+			c.conn.Close()
+			return nil, err
+		}
+	}
+
+	select {
+	case r.pool <- c:
+	default:
+		c.conn.Close()
+	}
+	return reply, err
+}
+
+func (r *RESPBackend) conn() (*respConn, error) {
+	select {
+	case c := <-r.pool:
+		return c, nil
+	default:
+	}
+	conn, err := net.DialTimeout("tcp", r.addr, respTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return &respConn{conn: conn, reader: bufio.NewReader(conn)}, nil
+}
+
+func (c *respConn) roundTrip(args []string) (interface{}, error) {
+	buf := make([]byte, 0, 64)
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, '\r', '\n')
+	for _, arg := range args {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(arg)), 10)
+		buf = append(buf, '\r', '\n')
+		buf = append(buf, arg...)
+		buf = append(buf, '\r', '\n')
+	}
+	if _, err := c.conn.Write(buf); err != nil {
+		return nil, err
+	}
+	return readRESP(c.reader)
+}
+
+// respError — ошибка, которую вернул сервер ("-ERR ...").
+type respError string
+
+func (e respError) Error() string { return "resp: " + string(e) }
+
+func readRESP(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("resp: malformed line %q", line)
+	}
+	kind, payload := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, respError(payload)
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, errRESPNil
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return data[:n], nil
+	case '*':
+		n, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, errRESPNil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			item, err := readRESP(r)
+			if err != nil && err != errRESPNil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("resp: unknown reply type %q", kind)
+	}
+}
@@ -0,0 +1,430 @@
+// Package config собирает настройки сервиса в одну типизированную структуру.
+//
+// Каждое поле описывается тегом config:"ключ". Значение берётся, по возрастанию
+// приоритета: из default:"..." в теге, из conf/app.conf, из переменной окружения
+// с именем ключа в верхнем регистре (db_host → DB_HOST) и из флага -ключ.
+// Поля с тегом secret:"true" не выводятся ни в логи, ни в config print.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testhub-spec-uni/cache"
+	"testhub-spec-uni/dialect"
+	"testhub-spec-uni/logging"
+	"testhub-spec-uni/ratelimit"
+	"testhub-spec-uni/tracing"
+	"time"
+)
+
+// Config — все настройки сервиса, которые не относятся к самому Beego.
+type Config struct {
+	Server  Server
+	Log     Log
+	Tracing Tracing
+	DB      Database
+	Cache   Cache
+	Limits  RateLimit
+	Storage Storage
+	Auth    Auth
+	CORS    CORS
+	I18n    I18n
+}
+
+type Server struct {
+	// ShutdownTimeout — сколько ждать завершения начатых запросов после SIGTERM.
+	ShutdownTimeout time.Duration `config:"shutdown_timeout" default:"30s"`
+	// ReadinessTimeout — ограничение на каждую проверку /readyz.
+	ReadinessTimeout time.Duration `config:"readiness_timeout" default:"3s"`
+}
+
+type Log struct {
+	// Level — минимальный уровень записей: debug, info, warn или error.
+	Level string `config:"log_level" default:"info"`
+}
+
+type Tracing struct {
+	// Exporter — куда отправлять трассы: none, stdout или otlp.
+	Exporter string `config:"trace_exporter" default:"none"`
+	// Endpoint — адрес OTLP/HTTP-коллектора для otlp, например http://localhost:4318.
+	// Пустой адрес берётся из OTEL_EXPORTER_OTLP_ENDPOINT.
+	Endpoint string `config:"trace_endpoint"`
+}
+
+type Database struct {
+	Driver   string `config:"db_driver" default:"postgres"`
+	Host     string `config:"db_host"`
+	Port     int    `config:"db_port" default:"5432"`
+	User     string `config:"db_user"`
+	Password string `config:"db_password" secret:"true"`
+	Name     string `config:"db_name"`
+	// Schema — search_path Postgres: схема сервиса и схема accounts с пользователями.
+	Schema string `config:"db_schema" default:"uni_spec,accounts"`
+	// Path и AutoMigrate используются только для SQLite.
+	Path        string `config:"db_path" default:"uni.db"`
+	AutoMigrate bool   `config:"db_auto_migrate" default:"true"`
+}
+
+type Cache struct {
+	Backend string        `config:"cache_backend" default:"memory"`
+	Addr    string        `config:"cache_addr"`
+	TTL     time.Duration `config:"cache_ttl" default:"10m"`
+}
+
+// RateLimit — ограничение частоты запросов по пользователю или IP. Лимиты
+// групп маршрутов задаются как "60/m" (s, m или h) или off.
+type RateLimit struct {
+	Backend string `config:"ratelimit_backend" default:"memory"`
+	Addr    string `config:"ratelimit_addr"`
+	// TrustProxy — брать IP клиента из X-Forwarded-For; только за своим прокси.
+	TrustProxy  bool   `config:"ratelimit_trust_proxy" default:"false"`
+	Search      string `config:"ratelimit_search" default:"60/m"`
+	Favorites   string `config:"ratelimit_favorites" default:"30/m"`
+	AdminWrites string `config:"ratelimit_admin_writes" default:"120/m"`
+}
+
+// Storage — S3-совместимое хранилище изображений университетов.
+type Storage struct {
+	Endpoint  string `config:"storage_endpoint" default:"https://chi-sextans.object.pscloud.io"`
+	Region    string `config:"storage_region" default:"us-east-1"`
+	Bucket    string `config:"bucket"`
+	AccessKey string `config:"aws_access_key" secret:"true"`
+	SecretKey string `config:"aws_secret_key" secret:"true"`
+}
+
+// Auth — сервис аккаунтов, который проверяет токен и возвращает пользователя.
+type Auth struct {
+	URL                string        `config:"auth_url" default:"https://api-dev.testhub.kz/accounts/api/v1/me"`
+	Timeout            time.Duration `config:"auth_timeout" default:"10s"`
+	InsecureSkipVerify bool          `config:"auth_insecure_skip_verify" default:"false"` // включается явно для dev-стенда с самоподписанным сертификатом
+}
+
+type CORS struct {
+	AllowOrigins []string `config:"cors_origins" default:"http://localhost:3000,https://admin-course.testhub.kz,https://ent.testhub.kz,https://console.ps.kz,https://api-dev.testhub.kz,https://dev-front.testhub.kz"`
+}
+
+type I18n struct {
+	// Fallback — цепочка языков через запятую; пустая строка — цепочка по умолчанию.
+	Fallback string `config:"i18n_fallback"`
+}
+
+// Source возвращает значение ключа и признак того, что оно задано.
+type Source func(key string) (string, bool)
+
+const redacted = "******"
+
+var current = defaults()
+
+// Get возвращает загруженную конфигурацию; до Load — значения по умолчанию.
+func Get() *Config {
+	return current
+}
+
+// Set заменяет текущую конфигурацию.
+func Set(c *Config) {
+	current = c
+}
+
+// field — поле конфигурации, найденное по тегу config.
+type field struct {
+	key    string
+	def    string
+	secret bool
+	value  reflect.Value
+}
+
+func fields(c *Config) []field {
+	var result []field
+	sections := reflect.ValueOf(c).Elem()
+	for i := 0; i < sections.NumField(); i++ {
+		section := sections.Field(i)
+		for j := 0; j < section.NumField(); j++ {
+			tag := section.Type().Field(j)
+			key := tag.Tag.Get("config")
+			if key == "" {
+				continue
+			}
+			result = append(result, field{
+				key:    key,
+				def:    tag.Tag.Get("default"),
+				secret: tag.Tag.Get("secret") == "true",
+				value:  section.Field(j),
+			})
+		}
+	}
+	return result
+}
+
+func defaults() *Config {
+	c := &Config{}
+	for _, f := range fields(c) {
+		if f.def == "" {
+			continue
+		}
+		if err := set(f.value, f.def); err != nil {
+			panic(fmt.Sprintf("config: bad default for %s: %v", f.key, err))
+		}
+	}
+	return c
+}
+
+// Loaded — результат Load: конфигурация, откуда взято каждое значение и
+// аргументы командной строки, оставшиеся после флагов.
+type Loaded struct {
+	*Config
+	Sources map[string]string
+	Args    []string
+}
+
+// Load собирает конфигурацию из файла, окружения и флагов args и проверяет её.
+// Все найденные ошибки возвращаются вместе, по одной на строку.
+func Load(file, env Source, args []string) (*Loaded, error) {
+	c := defaults()
+	loaded := &Loaded{Config: c, Sources: map[string]string{}}
+	for _, f := range fields(c) {
+		if f.def != "" {
+			loaded.Sources[f.key] = "default"
+		}
+	}
+
+	flags := flag.NewFlagSet("main", flag.ContinueOnError)
+	flagValues := map[string]*string{}
+	for _, f := range fields(c) {
+		flagValues[f.key] = flags.String(f.key, "", "overrides "+f.key)
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	loaded.Args = flags.Args()
+	flagSet := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) { flagSet[f.Name] = true })
+
+	var errs []error
+	for _, f := range fields(c) {
+		var layers []layer
+		if value, ok := file(f.key); ok {
+			layers = append(layers, layer{"file", value})
+		}
+		envKey := strings.ToUpper(f.key)
+		if value, ok := env(envKey); ok {
+			layers = append(layers, layer{"env " + envKey, value})
+		}
+		if flagSet[f.key] {
+			layers = append(layers, layer{"flag -" + f.key, *flagValues[f.key]})
+		}
+		for _, l := range layers {
+			if err := set(f.value, l.value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %v (from %s)", f.key, err, l.source))
+				continue
+			}
+			loaded.Sources[f.key] = l.source
+		}
+	}
+
+	errs = append(errs, c.Validate()...)
+	if len(errs) > 0 {
+		return loaded, errors.Join(errs...)
+	}
+	return loaded, nil
+}
+
+// layer — значение ключа из одного источника.
+type layer struct {
+	source string
+	value  string
+}
+
+func set(v reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+	switch v.Interface().(type) {
+	case string:
+		v.SetString(raw)
+	case int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		v.SetInt(int64(n))
+	case bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		v.SetBool(b)
+	case time.Duration:
+		// Целое число — секунды, как в прежнем cache_ttl.
+		if seconds, err := strconv.Atoi(raw); err == nil {
+			v.SetInt(int64(time.Duration(seconds) * time.Second))
+			return nil
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		v.SetInt(int64(d))
+	case []string:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// Validate проверяет согласованность настроек.
+func (c *Config) Validate() []error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.Server.ShutdownTimeout <= 0 {
+		fail("shutdown_timeout must be positive")
+	}
+	if c.Server.ReadinessTimeout <= 0 {
+		fail("readiness_timeout must be positive")
+	}
+
+	if _, err := logging.ParseLevel(c.Log.Level); err != nil {
+		fail("log_level %v, got %q", err, c.Log.Level)
+	}
+
+	switch c.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP:
+	default:
+		fail("trace_exporter must be none, stdout or otlp, got %q", c.Tracing.Exporter)
+	}
+	if c.Tracing.Endpoint != "" {
+		if err := checkURL(c.Tracing.Endpoint); err != nil {
+			fail("trace_endpoint: %v", err)
+		}
+	}
+
+	switch c.DB.Driver {
+	case dialect.Postgres:
+		for _, required := range [][2]string{{"db_host", c.DB.Host}, {"db_user", c.DB.User}, {"db_name", c.DB.Name}} {
+			if required[1] == "" {
+				fail("%s is required for db_driver %s", required[0], c.DB.Driver)
+			}
+		}
+		if c.DB.Port <= 0 || c.DB.Port > 65535 {
+			fail("db_port must be between 1 and 65535, got %d", c.DB.Port)
+		}
+	case dialect.SQLite:
+		if c.DB.Path == "" {
+			fail("db_path is required for db_driver %s", c.DB.Driver)
+		}
+	default:
+		fail("db_driver must be %s or %s, got %q", dialect.Postgres, dialect.SQLite, c.DB.Driver)
+	}
+
+	switch c.Cache.Backend {
+	case cache.BackendMemory, cache.BackendOff:
+	case cache.BackendRedis:
+		if c.Cache.Addr == "" {
+			fail("cache_addr is required for cache_backend redis")
+		}
+	default:
+		fail("cache_backend must be memory, redis or off, got %q", c.Cache.Backend)
+	}
+	if c.Cache.TTL <= 0 {
+		fail("cache_ttl must be positive")
+	}
+
+	switch c.Limits.Backend {
+	case ratelimit.BackendMemory, ratelimit.BackendOff:
+	case ratelimit.BackendRedis:
+		if c.Limits.Addr == "" {
+			fail("ratelimit_addr is required for ratelimit_backend redis")
+		}
+	default:
+		fail("ratelimit_backend must be memory, redis or off, got %q", c.Limits.Backend)
+	}
+	for _, limit := range [][2]string{
+		{"ratelimit_search", c.Limits.Search},
+		{"ratelimit_favorites", c.Limits.Favorites},
+		{"ratelimit_admin_writes", c.Limits.AdminWrites},
+	} {
+		if _, err := ratelimit.ParseLimit(limit[1]); err != nil {
+			fail("%s: %v", limit[0], err)
+		}
+	}
+
+	if err := checkURL(c.Auth.URL); err != nil {
+		fail("auth_url: %v", err)
+	}
+	if c.Auth.Timeout <= 0 {
+		fail("auth_timeout must be positive")
+	}
+
+	if err := checkURL(c.Storage.Endpoint); err != nil {
+		fail("storage_endpoint: %v", err)
+	}
+	if (c.Storage.AccessKey == "") != (c.Storage.SecretKey == "") {
+		fail("aws_access_key and aws_secret_key must be set together")
+	}
+
+	for _, origin := range c.CORS.AllowOrigins {
+		if origin == "*" {
+			continue
+		}
+		if err := checkURL(origin); err != nil {
+			fail("cors_origins: %v", err)
+		}
+	}
+	return errs
+}
+
+func checkURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q is not an absolute http(s) URL", raw)
+	}
+	return nil
+}
+
+// String — настройки в одну строку для лога, секреты скрыты.
+func (c *Config) String() string {
+	var parts []string
+	for _, f := range fields(c) {
+		parts = append(parts, f.key+"="+display(f))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Print выводит настройки по одной на строку с источником значения; секреты скрыты.
+func (l *Loaded) Print(w io.Writer) {
+	for _, f := range fields(l.Config) {
+		source := l.Sources[f.key]
+		if source == "" {
+			source = "unset"
+		}
+		fmt.Fprintf(w, "%s = %s\t# %s\n", f.key, display(f), source)
+	}
+}
+
+func display(f field) string {
+	if f.secret {
+		if f.value.String() == "" {
+			return ""
+		}
+		return redacted
+	}
+	switch value := f.value.Interface().(type) {
+	case []string:
+		return strings.Join(value, ",")
+	default:
+		return fmt.Sprint(value)
+	}
+}
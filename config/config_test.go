@@ -0,0 +1,90 @@
+package config
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mapSource(values map[string]string) Source {
+	return func(key string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := mapSource(map[string]string{
+		"db_host": "file-host", "db_user": "uni", "db_name": "uni", "db_password": "from-file",
+		"db_port": "5433", "cache_ttl": "60",
+	})
+	env := mapSource(map[string]string{"DB_HOST": "env-host", "DB_PASSWORD": "from-env"})
+
+	loaded, err := Load(file, env, []string{"-db_host=flag-host", "migrate", "up"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.DB.Host != "flag-host" || loaded.Sources["db_host"] != "flag -db_host" {
+		t.Errorf("flag did not win: %q from %q", loaded.DB.Host, loaded.Sources["db_host"])
+	}
+	if loaded.DB.Password != "from-env" || loaded.Sources["db_password"] != "env DB_PASSWORD" {
+		t.Errorf("env did not override file: %q", loaded.DB.Password)
+	}
+	if loaded.DB.Port != 5433 || loaded.Cache.TTL != time.Minute || loaded.DB.Schema != "uni_spec,accounts" {
+		t.Errorf("unexpected values: port %d, ttl %s, schema %q", loaded.DB.Port, loaded.Cache.TTL, loaded.DB.Schema)
+	}
+	if loaded.Auth.InsecureSkipVerify {
+		t.Error("TLS verification of the auth service is disabled by default")
+	}
+	if strings.Join(loaded.Args, " ") != "migrate up" {
+		t.Errorf("unexpected args %v", loaded.Args)
+	}
+}
+
+func TestLoadReportsAllErrors(t *testing.T) {
+	env := mapSource(map[string]string{
+		"DB_PORT": "abc", "CACHE_BACKEND": "redis", "LOG_LEVEL": "loud", "AUTH_URL": "accounts/me", "AWS_ACCESS_KEY": "key",
+		"RATELIMIT_SEARCH": "60/day",
+	})
+	_, err := Load(mapSource(nil), env, nil)
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+	for _, want := range []string{
+		`db_port: invalid integer "abc" (from env DB_PORT)`,
+		"db_host is required",
+		`log_level must be debug, info, warn or error, got "loud"`,
+		"cache_addr is required",
+		`ratelimit_search: invalid rate limit "60/day"`,
+		"auth_url:",
+		"aws_access_key and aws_secret_key must be set together",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestSecretsAreRedacted(t *testing.T) {
+	env := mapSource(map[string]string{
+		"DB_DRIVER": "sqlite3", "DB_PASSWORD": "hunter2", "AWS_ACCESS_KEY": "AKIA", "AWS_SECRET_KEY": "s3cr3t",
+	})
+	loaded, err := Load(mapSource(nil), env, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	loaded.Print(&out)
+	for _, text := range []string{out.String(), loaded.Config.String()} {
+		for _, secret := range []string{"hunter2", "AKIA", "s3cr3t"} {
+			if strings.Contains(text, secret) {
+				t.Errorf("secret %q leaked:\n%s", secret, text)
+			}
+		}
+	}
+	if !strings.Contains(out.String(), "db_password = ******\t# env DB_PASSWORD") {
+		t.Errorf("unexpected print output:\n%s", out.String())
+	}
+}
@@ -0,0 +1,351 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"testhub-spec-uni/models"
+	"time"
+
+	beego "github.com/beego/beego/v2/server/web"
+	"github.com/go-playground/validator/v10"
+)
+
+// AdmissionEventController обрабатывает запросы для работы с календарём приёмной кампании.
+type AdmissionEventController struct {
+	beego.Controller
+}
+
+// Create добавляет событие в календарь университета.
+// @Title Create
+// @Description Создание события приёмной кампании (приём документов, итоги гранта, творческий экзамен, день открытых дверей).
+// @Param   UniversityId   formData int    true  "ID университета"
+// @Param   SpecialityId   formData int    false "ID специальности"
+// @Param   EventType      formData string true  "document_submission, grant_results, creative_exam или open_day"
+// @Param   TitleRu        formData string true  "Название на русском языке"
+// @Param   TitleKz        formData string true  "Название на казахском языке"
+// @Param   DescriptionRu  formData string false "Описание на русском языке"
+// @Param   DescriptionKz  formData string false "Описание на казахском языке"
+// @Param   StartDate      formData string true  "Дата начала, YYYY-MM-DD или RFC3339"
+// @Param   EndDate        formData string false "Дата окончания, YYYY-MM-DD или RFC3339"
+// @Success 200 {object} map[string]int64 "ID созданного события"
+// @Failure 400 {object} map[string]string "Error message"
+// @router / [post]
+func (c *AdmissionEventController) Create() {
+	var form models.AddAdmissionEventResponse
+	if err := c.ParseForm(&form); err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid form data")
+		return
+	}
+
+	if err := validator.New().Struct(&form); err != nil {
+		errMap := make(map[string]string)
+		for _, err := range err.(validator.ValidationErrors) {
+			errMap[err.Field()] = fmt.Sprintf("Validation failed on the '%s' tag", err.Tag())
+		}
+		c.Ctx.Output.SetStatus(http.StatusBadRequest)
+		c.Data["json"] = errMap
+		c.ServeJSON()
+		return
+	}
+
+	startDate, allDay, err := models.ParseEventDate(form.StartDate)
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, err.Error())
+		return
+	}
+	var endDate time.Time
+	if form.EndDate != "" {
+		if endDate, _, err = models.ParseEventDate(form.EndDate); err != nil {
+			c.CustomAbort(http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	event := models.AdmissionEvent{
+		University:    &models.University{Id: form.UniversityId},
+		EventType:     form.EventType,
+		TitleRu:       form.TitleRu,
+		TitleKz:       form.TitleKz,
+		DescriptionRu: form.DescriptionRu,
+		DescriptionKz: form.DescriptionKz,
+		StartDate:     startDate,
+		EndDate:       endDate,
+		AllDay:        allDay,
+	}
+	if form.SpecialityId != 0 {
+		event.Speciality = &models.Speciality{Id: form.SpecialityId}
+	}
+
+	id, err := models.AddAdmissionEvent(&event)
+	if err != nil {
+		c.Ctx.Output.SetStatus(http.StatusBadRequest)
+		c.Data["json"] = map[string]string{"error": err.Error()}
+	} else {
+		c.Data["json"] = map[string]int64{"id": id}
+	}
+	c.ServeJSON()
+}
+
+// Get возвращает событие по его ID.
+// @Title Get
+// @Description Получение события приёмной кампании по ID.
+// @Param	id		path	int	true	"ID события"
+// @Param	lang	header	string	false	"Язык для получения данных, 'ru' или 'kz'"
+// @Success 200 {object} models.AdmissionEventResponse "Информация о событии"
+// @Failure 404 {string} string "Событие не найдено"
+// @router /:id [get]
+func (c *AdmissionEventController) Get() {
+	id, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid event ID")
+		return
+	}
+
+	language := requestLocale(c.Ctx)
+
+	event, err := models.GetAdmissionEventById(id, language)
+	if err != nil {
+		c.CustomAbort(http.StatusNotFound, err.Error())
+		return
+	}
+
+	c.Data["json"] = event
+	c.ServeJSON()
+}
+
+// GetByUniversity возвращает все события университета.
+// @Title GetByUniversity
+// @Description Получение календаря приёмной кампании университета.
+// @Param	universityId	path	int	true	"ID университета"
+// @Param	lang	header	string	false	"Язык для получения данных, 'ru' или 'kz'"
+// @Success 200 {array} models.AdmissionEventResponse "Список событий"
+// @Failure 400 {string} string "Некорректный ID университета"
+// @router /byuni/:universityId [get]
+func (c *AdmissionEventController) GetByUniversity() {
+	universityId, err := c.GetInt(":universityId")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid university ID")
+		return
+	}
+
+	language := requestLocale(c.Ctx)
+
+	events, err := models.GetAdmissionEventsByUniversity(universityId, language)
+	if err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = events
+	c.ServeJSON()
+}
+
+// Update обновляет событие по его ID.
+// @Title Update
+// @Description Обновление события приёмной кампании. Передаются только изменяемые поля.
+// @Param   id             path     int    true  "ID события"
+// @Param   SpecialityId   formData int    false "ID специальности"
+// @Param   EventType      formData string false "Тип события"
+// @Param   TitleRu        formData string false "Название на русском языке"
+// @Param   TitleKz        formData string false "Название на казахском языке"
+// @Param   DescriptionRu  formData string false "Описание на русском языке"
+// @Param   DescriptionKz  formData string false "Описание на казахском языке"
+// @Param   StartDate      formData string false "Дата начала"
+// @Param   EndDate        formData string false "Дата окончания"
+// @Success 200 {string} string "Update successful"
+// @Failure 400 {string} string "Invalid input"
+// @router /:id [put]
+func (c *AdmissionEventController) Update() {
+	id, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid event ID")
+		return
+	}
+
+	var form models.UpdateAdmissionEventResponse
+	if err := c.ParseForm(&form); err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid form data")
+		return
+	}
+	if err := validator.New().Struct(&form); err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Validation failed: "+err.Error())
+		return
+	}
+
+	event, err := models.GetAdmissionEventByIdForAdmin(id)
+	if err != nil {
+		c.CustomAbort(http.StatusNotFound, "Event not found")
+		return
+	}
+
+	if form.SpecialityId != 0 {
+		event.Speciality = &models.Speciality{Id: form.SpecialityId}
+	}
+	if form.EventType != "" {
+		event.EventType = form.EventType
+	}
+	if form.TitleRu != "" {
+		event.TitleRu = form.TitleRu
+	}
+	if form.TitleKz != "" {
+		event.TitleKz = form.TitleKz
+	}
+	if form.DescriptionRu != "" {
+		event.DescriptionRu = form.DescriptionRu
+	}
+	if form.DescriptionKz != "" {
+		event.DescriptionKz = form.DescriptionKz
+	}
+	if form.StartDate != "" {
+		startDate, allDay, err := models.ParseEventDate(form.StartDate)
+		if err != nil {
+			c.CustomAbort(http.StatusBadRequest, err.Error())
+			return
+		}
+		event.StartDate = startDate
+		event.AllDay = allDay
+	}
+	if form.EndDate != "" {
+		endDate, _, err := models.ParseEventDate(form.EndDate)
+		if err != nil {
+			c.CustomAbort(http.StatusBadRequest, err.Error())
+			return
+		}
+		event.EndDate = endDate
+	}
+
+	if err := models.UpdateAdmissionEvent(event); err != nil {
+		c.CustomAbort(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.Data["json"] = "Update successful"
+	c.ServeJSON()
+}
+
+// Delete удаляет событие по его ID.
+// @Title Delete
+// @Description Удаление события приёмной кампании.
+// @Param	id		path	int	true	"ID события"
+// @Success 200 {string} string "Delete successful"
+// @Failure 400 {string} string "Некорректный ID"
+// @router /:id [delete]
+func (c *AdmissionEventController) Delete() {
+	id, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid event ID")
+		return
+	}
+
+	if err := models.DeleteAdmissionEvent(id); err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = "Delete successful"
+	c.ServeJSON()
+}
+
+// GetUpcoming возвращает ближайшие события по избранным университетам пользователя.
+// @Title GetUpcoming
+// @Description Ближайшие события приёмной кампании по университетам из избранного.
+// @Param	days	query	int	false	"Горизонт в днях (по умолчанию без ограничения)"
+// @Param	limit	query	int	false	"Максимальное количество событий (по умолчанию 50)"
+// @Param	lang	header	string	false	"Язык для получения данных, 'ru' или 'kz'"
+// @Success 200 {array} models.AdmissionEventResponse "Список событий"
+// @Failure 400 {string} string "Invalid user_id"
+// @router /events/upcoming [get]
+func (c *AdmissionEventController) GetUpcoming() {
+	userId, ok := c.Ctx.Input.GetData("user_id").(int)
+	if !ok {
+		c.CustomAbort(http.StatusBadRequest, "Invalid user_id")
+		return
+	}
+
+	limit, err := c.GetInt("limit", 50)
+	if err != nil || limit < 1 {
+		c.CustomAbort(http.StatusBadRequest, "Invalid limit value")
+		return
+	}
+
+	days, err := c.GetInt("days", 0)
+	if err != nil || days < 0 {
+		c.CustomAbort(http.StatusBadRequest, "Invalid days value")
+		return
+	}
+
+	language := requestLocale(c.Ctx)
+
+	now := time.Now()
+	var until time.Time
+	if days > 0 {
+		until = now.AddDate(0, 0, days)
+	}
+
+	events, err := models.GetUpcomingEventsForUser(userId, language, now, until, limit)
+	if err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = events
+	c.ServeJSON()
+}
+
+// GetCalendarFeed возвращает ссылку на персональную .ics-ленту пользователя.
+// @Title GetCalendarFeed
+// @Description Ссылка для подписки на календарь событий избранных университетов.
+// @Success 200 {object} map[string]string "token и url ленты"
+// @Failure 400 {string} string "Invalid user_id"
+// @router /events/feed [get]
+func (c *AdmissionEventController) GetCalendarFeed() {
+	userId, ok := c.Ctx.Input.GetData("user_id").(int)
+	if !ok {
+		c.CustomAbort(http.StatusBadRequest, "Invalid user_id")
+		return
+	}
+
+	token, err := models.GetOrCreateCalendarFeedToken(userId)
+	if err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = map[string]string{
+		"token": token,
+		"url":   "/user/events/calendar/" + token,
+	}
+	c.ServeJSON()
+}
+
+// ServeCalendar отдаёт .ics-ленту по токену без авторизации.
+// @Title ServeCalendar
+// @Description iCalendar-лента событий избранных университетов пользователя.
+// @Param	token	path	string	true	"Токен ленты"
+// @Param	lang	query	string	false	"Язык событий, 'ru' или 'kz'"
+// @Success 200 {string} string "text/calendar"
+// @Failure 404 {string} string "Лента не найдена"
+// @router /calendar/:token [get]
+func (c *AdmissionEventController) ServeCalendar() {
+	token := c.Ctx.Input.Param(":token")
+
+	userId, err := models.GetUserIdByCalendarFeedToken(token)
+	if err != nil {
+		c.CustomAbort(http.StatusNotFound, "Calendar feed not found")
+		return
+	}
+
+	// Календарные приложения не передают заголовок lang, поэтому язык обычно приходит в query.
+	language := requestLocale(c.Ctx)
+
+	// В ленту попадают и недавно прошедшие события, чтобы они не пропадали из календаря сразу.
+	events, err := models.GetUpcomingEventsForUser(userId, language, time.Now().AddDate(0, -1, 0), time.Time{}, 0)
+	if err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Ctx.Output.Header("Content-Type", "text/calendar; charset=utf-8")
+	c.Ctx.Output.Header("Content-Disposition", `inline; filename="testhub-admissions.ics"`)
+	c.Ctx.Output.Body([]byte(models.BuildICalendar("Testhub", events)))
+}
@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"testhub-spec-uni/models"
+
+	beego "github.com/beego/beego/v2/server/web"
+	"github.com/go-playground/validator/v10"
+)
+
+// CampusController обрабатывает запросы для корпусов и филиалов университетов.
+type CampusController struct {
+	beego.Controller
+}
+
+// Create добавляет корпус университета.
+// @Title Create
+// @Description Добавление корпуса или филиала университета с координатами.
+// @Param   UniversityId  formData int     true  "ID университета"
+// @Param   CityId        formData int     false "ID города"
+// @Param   NameRu        formData string  true  "Название на русском языке"
+// @Param   NameKz        formData string  true  "Название на казахском языке"
+// @Param   Address       formData string  false "Адрес"
+// @Param   Latitude      formData float64 true  "Широта"
+// @Param   Longitude     formData float64 true  "Долгота"
+// @Success 200 {object} map[string]int64 "ID созданного корпуса"
+// @Failure 400 {object} map[string]string "Error message"
+// @router / [post]
+func (c *CampusController) Create() {
+	var form models.AddCampusResponse
+	if err := c.ParseForm(&form); err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid form data")
+		return
+	}
+
+	if err := validator.New().Struct(&form); err != nil {
+		errMap := make(map[string]string)
+		for _, err := range err.(validator.ValidationErrors) {
+			errMap[err.Field()] = fmt.Sprintf("Validation failed on the '%s' tag", err.Tag())
+		}
+		c.Ctx.Output.SetStatus(http.StatusBadRequest)
+		c.Data["json"] = errMap
+		c.ServeJSON()
+		return
+	}
+
+	id, err := models.AddCampus(&form)
+	if err != nil {
+		c.Ctx.Output.SetStatus(http.StatusBadRequest)
+		c.Data["json"] = map[string]string{"error": err.Error()}
+	} else {
+		c.Data["json"] = map[string]int64{"id": id}
+	}
+	c.ServeJSON()
+}
+
+// GetByUniversity возвращает корпуса университета.
+// @Title GetByUniversity
+// @Description Получение корпусов университета.
+// @Param	universityId	path	int		true	"ID университета"
+// @Param	lang			header	string	false	"Язык для получения данных, 'ru' или 'kz'"
+// @Success 200 {array} models.CampusResponse
+// @Failure 400 {string} string "Invalid university ID"
+// @router /byuni/:universityId [get]
+func (c *CampusController) GetByUniversity() {
+	universityId, err := c.GetInt(":universityId")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid university ID")
+		return
+	}
+
+	campuses, err := models.GetCampusesByUniversity(universityId, requestLocale(c.Ctx))
+	if err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = campuses
+	c.ServeJSON()
+}
+
+// Update обновляет данные корпуса.
+// @Title Update
+// @Description Обновление корпуса по ID.
+// @Param	id			path		int		true	"ID корпуса"
+// @Param   CityId		formData	int		false	"ID города"
+// @Param   NameRu		formData	string	false	"Название на русском языке"
+// @Param   NameKz		formData	string	false	"Название на казахском языке"
+// @Param   Address		formData	string	false	"Адрес"
+// @Param   Latitude	formData	float64	false	"Широта"
+// @Param   Longitude	formData	float64	false	"Долгота"
+// @Success 200 {string} "Update successful"
+// @Failure 400 {string} string "Invalid input"
+// @router /:id [put]
+func (c *CampusController) Update() {
+	id, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid campus ID")
+		return
+	}
+
+	var form models.UpdateCampusResponse
+	if err := c.ParseForm(&form); err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid form data")
+		return
+	}
+	if err := validator.New().Struct(&form); err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Validation failed: "+err.Error())
+		return
+	}
+
+	if err := models.UpdateCampus(id, &form); err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = "Update successful"
+	c.ServeJSON()
+}
+
+// Delete удаляет корпус.
+// @Title Delete
+// @Description Удаление корпуса по ID.
+// @Param	id	path	int	true	"ID корпуса"
+// @Success 200 {string} "Delete successful"
+// @Failure 400 {string} string "Invalid campus ID"
+// @router /:id [delete]
+func (c *CampusController) Delete() {
+	id, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid campus ID")
+		return
+	}
+
+	if err := models.DeleteCampus(id); err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = "Delete successful"
+	c.ServeJSON()
+}
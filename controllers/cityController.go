@@ -2,7 +2,9 @@ package controllers
 
 import (
 	"encoding/json"
+	"net/http"
 	"testhub-spec-uni/models"
+	"testhub-spec-uni/repository"
 	"time"
 
 	beego "github.com/beego/beego/v2/server/web"
@@ -11,12 +13,23 @@ import (
 type CityResponse struct {
 	Id        int       `json:"Id"`
 	Name      string    `json:"Name"`
+	RegionId  int       `json:"RegionId,omitempty"`
+	Latitude  float64   `json:"Latitude"`
+	Longitude float64   `json:"Longitude"`
 	CreatedAt time.Time `json:"CreatedAt"`
 	UpdatedAt time.Time `json:"UpdatedAt"`
 }
 
 type CityController struct {
 	beego.Controller
+	Cities repository.Cities
+}
+
+func cityRegionId(city *models.City) int {
+	if city.Region == nil {
+		return 0
+	}
+	return city.Region.Id
 }
 
 // Create adds a new city to the database.
@@ -38,7 +51,7 @@ func (c *CityController) Create() {
 		return
 	}
 
-	id, err := models.AddCity(&city)
+	id, err := c.Cities.Create(&city)
 	if err == nil {
 		city.Id = int(id)
 		c.Data["json"] = map[string]int64{"id": id}
@@ -58,25 +71,25 @@ func (c *CityController) Create() {
 // @router /:id [get]
 func (c *CityController) Get() {
 	id, _ := c.GetInt(":id")
-	language := c.Ctx.Input.Header("lang")
-	if language != "ru" && language != "kz" {
-		c.Data["json"] = "Invalid or unsupported language"
-		c.ServeJSON()
-		return
-	}
+	language := requestLocale(c.Ctx)
 
-	city, err := models.GetCityById(id, language)
+	city, err := c.Cities.Get(id, language)
 	if err == nil {
 		response := CityResponse{
 			Id:        city.Id,
 			Name:      city.Name,
+			RegionId:  cityRegionId(city),
+			Latitude:  city.Latitude,
+			Longitude: city.Longitude,
 			CreatedAt: city.CreatedAt,
 			UpdatedAt: city.UpdatedAt,
 		}
 		c.Data["json"] = response
-	} else {
-		c.Data["json"] = err.Error()
+		serveConditionalJSON(&c.Controller)
+		return
 	}
+
+	c.Data["json"] = err.Error()
 	c.ServeJSON()
 }
 
@@ -88,28 +101,28 @@ func (c *CityController) Get() {
 // @Failure 400 {string} string "400 ошибка получения списка или другая ошибка"
 // @router / [get]
 func (c *CityController) GetAll() {
-	language := c.Ctx.Input.Header("lang")
-	if language != "ru" && language != "kz" {
-		c.Data["json"] = "Invalid or unsupported language"
-		c.ServeJSON()
-		return
-	}
+	language := requestLocale(c.Ctx)
 
-	cities, err := models.GetAllCitiesByLanguage(language)
+	cities, err := c.Cities.List(language)
 	if err == nil {
 		var response []CityResponse
 		for _, city := range cities {
 			response = append(response, CityResponse{
 				Id:        city.Id,
 				Name:      city.Name,
+				RegionId:  cityRegionId(city),
+				Latitude:  city.Latitude,
+				Longitude: city.Longitude,
 				CreatedAt: city.CreatedAt,
 				UpdatedAt: city.UpdatedAt,
 			})
 		}
 		c.Data["json"] = response
-	} else {
-		c.Data["json"] = err.Error()
+		serveConditionalJSON(&c.Controller)
+		return
 	}
+
+	c.Data["json"] = err.Error()
 	c.ServeJSON()
 }
 
@@ -126,7 +139,7 @@ func (c *CityController) Update() {
 	var city models.City
 	json.Unmarshal(c.Ctx.Input.RequestBody, &city)
 	city.Id = id
-	err := models.UpdateCity(&city)
+	err := c.Cities.Update(&city)
 	if err == nil {
 		c.Data["json"] = "Update successful"
 	} else {
@@ -145,7 +158,7 @@ func (c *CityController) Update() {
 // @Router /:id [delete]
 func (c *CityController) Delete() {
 	id, _ := c.GetInt(":id")
-	err := models.DeleteCity(id)
+	err := c.Cities.Delete(id)
 	if err == nil {
 		c.Data["json"] = "Delete successful"
 	} else {
@@ -154,6 +167,36 @@ func (c *CityController) Delete() {
 	c.ServeJSON()
 }
 
+// AssignRegion привязывает город к области.
+// @Title AssignRegion
+// @Description Привязка города к области.
+// @Param	cityId		path	int	true	"ID города"
+// @Param	regionId	path	int	true	"ID области"
+// @Success 200 {string} string "Регион успешно назначен"
+// @Failure 400 {string} string "400 некорректный ID"
+// @Failure 404 {string} string "Город или область не найдены"
+// @Failure 500 {string} string "Ошибка базы данных"
+// @router /assignregion/:cityId/:regionId [put]
+func (c *CityController) AssignRegion() {
+	cityId, err := c.GetInt(":cityId")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid city ID")
+		return
+	}
+	regionId, err := c.GetInt(":regionId")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid region ID")
+		return
+	}
+
+	if err := c.Cities.AssignRegion(cityId, regionId); err != nil {
+		c.CustomAbort(errorStatus(err), err.Error())
+		return
+	}
+	c.Data["json"] = "Region assigned successfully"
+	c.ServeJSON()
+}
+
 // GetWithUniversities возвращает информацию о городе вместе с университетами по его ID.
 // @Title GetWithUniversities
 // @Description Получение информации о городе с университетами по ID.
@@ -164,18 +207,16 @@ func (c *CityController) Delete() {
 // @router /info/:id [get]
 func (c *CityController) GetWithUniversities() {
 	id, _ := c.GetInt(":id")
-	language := c.Ctx.Input.Header("lang")
-	if language != "ru" && language != "kz" {
-		c.Data["json"] = "Invalid or unsupported language"
-		c.ServeJSON()
-		return
-	}
+	language := requestLocale(c.Ctx)
 
-	city, err := models.GetCityWithUniversities(id, language)
+	city, err := c.Cities.GetWithUniversities(id, language)
 	if err == nil {
 		response := CityResponse{
 			Id:        city.Id,
 			Name:      city.Name,
+			RegionId:  cityRegionId(city),
+			Latitude:  city.Latitude,
+			Longitude: city.Longitude,
 			CreatedAt: city.CreatedAt,
 			UpdatedAt: city.UpdatedAt,
 		}
@@ -196,14 +237,9 @@ func (c *CityController) GetWithUniversities() {
 // @router /search [get]
 func (c *CityController) SearchCities() {
 	name := c.GetString("name")
-	language := c.Ctx.Input.Header("lang")
-	if language != "ru" && language != "kz" {
-		c.Data["json"] = "Invalid or unsupported language"
-		c.ServeJSON()
-		return
-	}
+	language := requestLocale(c.Ctx)
 
-	cities, err := models.SearchCitiesByName(name, language)
+	cities, err := c.Cities.Search(name, language)
 	if err != nil {
 		c.Data["json"] = err.Error()
 		c.ServeJSON()
@@ -212,19 +248,9 @@ func (c *CityController) SearchCities() {
 
 	var cityResponses []CityResponse
 	for _, city := range cities {
-		var cityName string
-		switch language {
-		case "ru":
-			cityName = city.NameRu
-		case "kz":
-			cityName = city.NameKz
-		default:
-			cityName = city.Name
-		}
-
 		cityResponses = append(cityResponses, CityResponse{
 			Id:        city.Id,
-			Name:      cityName,
+			Name:      city.Name,
 			CreatedAt: city.CreatedAt,
 			UpdatedAt: city.UpdatedAt,
 		})
@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	beego "github.com/beego/beego/v2/server/web"
+)
+
+// serveConditionalJSON отдаёт c.Data["json"] как ServeJSON, добавляя ETag (хэш ответа).
+// Если клиент прислал совпадающий If-None-Match, отвечает 304 без тела.
+//
+// Last-Modified не отправляется: максимум updated_at не меняется при удалении строк
+// и изменении связей many-to-many, а ETag считается по самому ответу.
+func serveConditionalJSON(c *beego.Controller) {
+	body, err := json.Marshal(c.Data["json"])
+	if err != nil {
+		c.ServeJSON()
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	// Слабый ETag: тело может отдаваться сжатым, но смысл ответа тот же.
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+
+	output := c.Ctx.Output
+	output.Header("ETag", etag)
+	// Ответы для авторизованных пользователей уже помечены private в AuthMiddleware.
+	if c.Ctx.ResponseWriter.Header().Get("Cache-Control") == "" {
+		output.Header("Cache-Control", "public, no-cache")
+	}
+
+	if notModified(c.Ctx.Input.Header("If-None-Match"), etag) {
+		c.Ctx.ResponseWriter.WriteHeader(http.StatusNotModified)
+		return
+	}
+	c.ServeJSON()
+}
+
+func notModified(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate != "" && strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"testhub-spec-uni/models"
+	"testhub-spec-uni/repository"
+
+	"github.com/astaxie/beego/orm"
+)
+
+// errorStatus выбирает код ответа для ошибки models или репозитория: 400 для
+// некорректных входных данных, 404 для отсутствующей записи, 500 для остальных.
+func errorStatus(err error) int {
+	var validation *models.ValidationError
+	switch {
+	case errors.As(err, &validation):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrNotFound), errors.Is(err, orm.ErrNoRows):
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
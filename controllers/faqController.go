@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"testhub-spec-uni/models"
+
+	beego "github.com/beego/beego/v2/server/web"
+	"github.com/go-playground/validator/v10"
+)
+
+// FaqController обрабатывает FAQ университетов.
+type FaqController struct {
+	beego.Controller
+}
+
+// Create добавляет запись FAQ.
+// @Title Create
+// @Description Создание записи FAQ для университета.
+// @Param   UniversityId  formData int    true  "ID университета"
+// @Param   QuestionRu    formData string true  "Вопрос на русском языке"
+// @Param   QuestionKz    formData string true  "Вопрос на казахском языке"
+// @Param   AnswerRu      formData string true  "Ответ на русском языке"
+// @Param   AnswerKz      formData string true  "Ответ на казахском языке"
+// @Param   SortOrder     formData int    false "Порядок отображения"
+// @Success 200 {object} map[string]int64 "ID созданной записи"
+// @Failure 400 {object} map[string]string "Error message"
+// @router / [post]
+func (c *FaqController) Create() {
+	var form models.AddFaqEntryResponse
+	if err := c.ParseForm(&form); err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid form data")
+		return
+	}
+	if err := validator.New().Struct(&form); err != nil {
+		errMap := make(map[string]string)
+		for _, err := range err.(validator.ValidationErrors) {
+			errMap[err.Field()] = fmt.Sprintf("Validation failed on the '%s' tag", err.Tag())
+		}
+		c.Ctx.Output.SetStatus(http.StatusBadRequest)
+		c.Data["json"] = errMap
+		c.ServeJSON()
+		return
+	}
+
+	id, err := models.AddFaqEntry(&form)
+	if err != nil {
+		c.Ctx.Output.SetStatus(http.StatusBadRequest)
+		c.Data["json"] = map[string]string{"error": err.Error()}
+	} else {
+		c.Data["json"] = map[string]int64{"id": id}
+	}
+	c.ServeJSON()
+}
+
+// GetByUniversityForAdmin возвращает FAQ университета на обоих языках.
+// @Title GetByUniversityForAdmin
+// @Description Записи FAQ университета.
+// @Param	universityId	path	int	true	"ID университета"
+// @Success 200 {array} models.FaqEntry
+// @Failure 400 {string} string "Invalid university ID"
+// @router /byuni/:universityId [get]
+func (c *FaqController) GetByUniversityForAdmin() {
+	universityId, err := c.GetInt(":universityId")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid university ID")
+		return
+	}
+
+	entries, err := models.GetFaqEntriesForAdmin(universityId)
+	if err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = entries
+	c.ServeJSON()
+}
+
+// Update обновляет запись FAQ.
+// @Title Update
+// @Description Обновление записи FAQ по ID. Пустые поля не изменяются.
+// @Param	id			path		int		true	"ID записи"
+// @Param   QuestionRu	formData	string	false	"Вопрос на русском языке"
+// @Param   QuestionKz	formData	string	false	"Вопрос на казахском языке"
+// @Param   AnswerRu	formData	string	false	"Ответ на русском языке"
+// @Param   AnswerKz	formData	string	false	"Ответ на казахском языке"
+// @Param   SortOrder	formData	int		false	"Порядок отображения"
+// @Success 200 {string} "Update successful"
+// @Failure 400 {string} string "Invalid input"
+// @router /:id [put]
+func (c *FaqController) Update() {
+	id, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid FAQ entry ID")
+		return
+	}
+
+	var form models.UpdateFaqEntryResponse
+	if err := c.ParseForm(&form); err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid form data")
+		return
+	}
+
+	if err := models.UpdateFaqEntry(id, &form); err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = "Update successful"
+	c.ServeJSON()
+}
+
+// Delete удаляет запись FAQ.
+// @Title Delete
+// @Description Удаление записи FAQ по ID.
+// @Param	id	path	int	true	"ID записи"
+// @Success 200 {string} "Delete successful"
+// @Failure 400 {string} string "Invalid FAQ entry ID"
+// @router /:id [delete]
+func (c *FaqController) Delete() {
+	id, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid FAQ entry ID")
+		return
+	}
+
+	if err := models.DeleteFaqEntry(id); err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = "Delete successful"
+	c.ServeJSON()
+}
@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"net/http"
+	"testhub-spec-uni/config"
+	"testhub-spec-uni/health"
+
+	beego "github.com/beego/beego/v2/server/web"
+)
+
+type HealthController struct {
+	beego.Controller
+}
+
+// Healthz сообщает, что процесс жив. Внешние зависимости не проверяются, чтобы
+// сбой базы не приводил к перезапуску контейнера.
+// @Title Healthz
+// @Description Проверка живости процесса.
+// @Success 200 {object} map[string]string {"status": "ok"}
+// @router /healthz [get]
+func (c *HealthController) Healthz() {
+	c.Ctx.Output.Header("Cache-Control", "no-store")
+	c.Data["json"] = map[string]string{"status": health.StatusOK}
+	c.ServeJSON()
+}
+
+// Readyz проверяет базу, хранилище изображений и сервис аккаунтов. Во время
+// остановки отвечает 503, чтобы балансировщик перестал присылать запросы.
+// @Title Readyz
+// @Description Готовность принимать запросы: база, хранилище, сервис авторизации.
+// @Success 200 {object} health.Report
+// @Failure 503 {object} health.Report "Зависимость недоступна или сервис останавливается"
+// @router /readyz [get]
+func (c *HealthController) Readyz() {
+	report := health.Ready(c.Ctx.Request.Context(), config.Get().Server.ReadinessTimeout)
+
+	c.Ctx.Output.Header("Cache-Control", "no-store")
+	if report.Status != health.StatusOK {
+		c.Ctx.Output.SetStatus(http.StatusServiceUnavailable)
+	}
+	c.Data["json"] = report
+	c.ServeJSON()
+}
@@ -0,0 +1,15 @@
+package controllers
+
+import (
+	"testhub-spec-uni/middleware"
+
+	"github.com/beego/beego/v2/server/web/context"
+)
+
+// requestLocale возвращает язык запроса, определённый LocaleMiddleware.
+func requestLocale(ctx *context.Context) string {
+	if locale, ok := ctx.Input.GetData(middleware.LocaleDataKey).(string); ok && locale != "" {
+		return locale
+	}
+	return middleware.ResolveLocale(ctx)
+}
@@ -0,0 +1,241 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testhub-spec-uni/models"
+
+	beego "github.com/beego/beego/v2/server/web"
+	"github.com/go-playground/validator/v10"
+)
+
+// QuotaAllocationController обрабатывает запросы для распределения квот по программам и годам.
+type QuotaAllocationController struct {
+	beego.Controller
+}
+
+// Create добавляет распределение квоты для программы университета на год.
+// @Title Create
+// @Description Создание распределения квоты (квота, университет, специальность, год).
+// @Param   QuotaId       formData int true  "ID квоты"
+// @Param   UniversityId  formData int true  "ID университета"
+// @Param   SpecialityId  formData int true  "ID специальности"
+// @Param   Year          formData int true  "Год приёма"
+// @Param   GrantCount    formData int false "Количество грантов по квоте"
+// @Param   MinScore      formData int false "Проходной балл по квоте"
+// @Param   MaxScore      formData int false "Максимальный балл по квоте"
+// @Success 200 {object} map[string]int64 "ID созданной записи"
+// @Failure 400 {object} map[string]string "Error message"
+// @Failure 500 {object} map[string]string "Ошибка базы данных"
+// @router / [post]
+func (c *QuotaAllocationController) Create() {
+	var form models.AddQuotaAllocationResponse
+	if err := c.ParseForm(&form); err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid form data")
+		return
+	}
+
+	if err := validator.New().Struct(&form); err != nil {
+		errMap := make(map[string]string)
+		for _, err := range err.(validator.ValidationErrors) {
+			errMap[err.Field()] = fmt.Sprintf("Validation failed on the '%s' tag", err.Tag())
+		}
+		c.Ctx.Output.SetStatus(http.StatusBadRequest)
+		c.Data["json"] = errMap
+		c.ServeJSON()
+		return
+	}
+
+	id, err := models.AddQuotaAllocation(&form)
+	if err != nil {
+		c.Ctx.Output.SetStatus(errorStatus(err))
+		c.Data["json"] = map[string]string{"error": err.Error()}
+	} else {
+		c.Data["json"] = map[string]int64{"id": id}
+	}
+	c.ServeJSON()
+}
+
+// Get возвращает распределение квоты по ID.
+// @Title Get
+// @Description Получение распределения квоты по ID.
+// @Param	id		path	int	true	"ID записи"
+// @Param	lang	header	string	false	"Язык для получения данных, 'ru' или 'kz'"
+// @Success 200 {object} models.QuotaAllocationResponse
+// @Failure 404 {string} string "Запись не найдена"
+// @router /:id [get]
+func (c *QuotaAllocationController) Get() {
+	id, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid allocation ID")
+		return
+	}
+
+	allocation, err := models.GetQuotaAllocationById(id, requestLocale(c.Ctx))
+	if err != nil {
+		c.CustomAbort(http.StatusNotFound, err.Error())
+		return
+	}
+
+	c.Data["json"] = allocation
+	c.ServeJSON()
+}
+
+// GetAll возвращает распределения квот с фильтрами.
+// @Title GetAll
+// @Description Получение списка распределений квот с фильтрами по квоте, университету, специальности и году.
+// @Param	quota_id		query	int	false	"ID квоты"
+// @Param	university_id	query	int	false	"ID университета"
+// @Param	speciality_id	query	int	false	"ID специальности"
+// @Param	year			query	int	false	"Год приёма"
+// @Param	lang	header	string	false	"Язык для получения данных, 'ru' или 'kz'"
+// @Success 200 {array} models.QuotaAllocationResponse
+// @Failure 500 {string} string "Internal Server Error"
+// @router / [get]
+func (c *QuotaAllocationController) GetAll() {
+	quotaId, _ := c.GetInt("quota_id")
+	universityId, _ := c.GetInt("university_id")
+	specialityId, _ := c.GetInt("speciality_id")
+	year, _ := c.GetInt("year")
+
+	allocations, err := models.GetQuotaAllocations(quotaId, universityId, specialityId, year, requestLocale(c.Ctx))
+	if err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = allocations
+	c.ServeJSON()
+}
+
+// Update обновляет количество грантов и баллы распределения квоты.
+// @Title Update
+// @Description Обновление распределения квоты по ID.
+// @Param	id			path		int	true	"ID записи"
+// @Param   GrantCount	formData	int	false	"Количество грантов по квоте"
+// @Param   MinScore	formData	int	false	"Проходной балл по квоте"
+// @Param   MaxScore	formData	int	false	"Максимальный балл по квоте"
+// @Success 200 {string} "Update successful"
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Запись не найдена"
+// @Failure 500 {string} string "Ошибка базы данных"
+// @router /:id [put]
+func (c *QuotaAllocationController) Update() {
+	id, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid allocation ID")
+		return
+	}
+
+	var form models.UpdateQuotaAllocationResponse
+	if err := c.ParseForm(&form); err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid form data")
+		return
+	}
+
+	if err := models.UpdateQuotaAllocation(id, &form); err != nil {
+		c.CustomAbort(errorStatus(err), err.Error())
+		return
+	}
+
+	c.Data["json"] = "Update successful"
+	c.ServeJSON()
+}
+
+// Delete удаляет распределение квоты по ID.
+// @Title Delete
+// @Description Удаление распределения квоты по ID.
+// @Param	id	path	int	true	"ID записи"
+// @Success 200 {string} "Delete successful"
+// @Failure 400 {string} string "Invalid allocation ID"
+// @router /:id [delete]
+func (c *QuotaAllocationController) Delete() {
+	id, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid allocation ID")
+		return
+	}
+
+	if err := models.DeleteQuotaAllocation(id); err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = "Delete successful"
+	c.ServeJSON()
+}
+
+// Import загружает распределение квот массивом JSON; существующие записи обновляются.
+// @Title Import
+// @Description Массовый импорт распределения квот. Тело — JSON-массив объектов с полями quota_id, university_id, speciality_id, year, grant_count, min_score, max_score.
+// @Param	body	body	[]models.AddQuotaAllocationResponse	true	"Массив распределений квот"
+// @Success 200 {object} models.QuotaAllocationImportResult
+// @Failure 400 {object} map[string]string "Error message"
+// @Failure 500 {object} map[string]string "Ошибка базы данных"
+// @router /import [post]
+func (c *QuotaAllocationController) Import() {
+	body := c.Ctx.Input.CopyBody(10 * 1024 * 1024)
+
+	var rows []*models.AddQuotaAllocationResponse
+	if err := json.Unmarshal(body, &rows); err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid JSON: "+err.Error())
+		return
+	}
+	if len(rows) == 0 {
+		c.CustomAbort(http.StatusBadRequest, "No rows to import")
+		return
+	}
+
+	validate := validator.New()
+	for i, row := range rows {
+		if err := validate.Struct(row); err != nil {
+			c.Ctx.Output.SetStatus(http.StatusBadRequest)
+			c.Data["json"] = map[string]string{"error": fmt.Sprintf("row %d: %v", i+1, err)}
+			c.ServeJSON()
+			return
+		}
+	}
+
+	result, err := models.ImportQuotaAllocations(rows)
+	if err != nil {
+		c.Ctx.Output.SetStatus(errorStatus(err))
+		c.Data["json"] = map[string]string{"error": err.Error()}
+		c.ServeJSON()
+		return
+	}
+
+	c.Data["json"] = result
+	c.ServeJSON()
+}
+
+// GetBreakdown возвращает статистику программы по годам вместе с разбивкой по квотам.
+// @Title GetBreakdown
+// @Description Статистика баллов программы по годам и распределение квот за каждый год.
+// @Param	universityId	path	int	true	"ID университета"
+// @Param	specialityId	path	int	true	"ID специальности"
+// @Param	lang	header	string	false	"Язык для получения данных, 'ru' или 'kz'"
+// @Success 200 {array} models.PointStatWithQuotasResponse
+// @Failure 400 {string} string "Invalid input"
+// @router /breakdown/:universityId/:specialityId [get]
+func (c *QuotaAllocationController) GetBreakdown() {
+	universityId, err := c.GetInt(":universityId")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid university ID")
+		return
+	}
+	specialityId, err := c.GetInt(":specialityId")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid speciality ID")
+		return
+	}
+
+	breakdown, err := models.GetPointStatsWithQuotas(universityId, specialityId, requestLocale(c.Ctx))
+	if err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = breakdown
+	c.ServeJSON()
+}
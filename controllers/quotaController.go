@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"testhub-spec-uni/models"
+	"testhub-spec-uni/repository"
 	"time"
 
 	beego "github.com/beego/beego/v2/server/web"
@@ -12,6 +13,7 @@ import (
 // QuotaController handles operations for Quota.
 type QuotaController struct {
 	beego.Controller
+	Quotas repository.Quotas
 }
 
 type QuotaResponse struct {
@@ -56,7 +58,7 @@ func (c *QuotaController) Create() {
 		return
 	}
 
-	id, err := models.AddQuota(&quota)
+	id, err := c.Quotas.Create(&quota)
 	if err == nil {
 		c.Data["json"] = map[string]int64{"id": id}
 	} else {
@@ -83,13 +85,9 @@ func (c *QuotaController) Create() {
 // @router /:id [get]
 func (c *QuotaController) Get() {
 	id, _ := c.GetInt(":id")
-	language := c.Ctx.Input.Header("lang")
-	if language != "ru" && language != "kz" {
-		c.CustomAbort(http.StatusBadRequest, "Invalid or unsupported language")
-		return
-	}
+	language := requestLocale(c.Ctx)
 
-	quota, err := models.GetQuotaById(id, language)
+	quota, err := c.Quotas.Get(id, language)
 	if err != nil {
 		c.Data["json"] = err.Error()
 	} else {
@@ -116,13 +114,9 @@ func (c *QuotaController) Get() {
 // @Failure 400 {string} string "400 ошибка получения списка или другая ошибка"
 // @router / [get]
 func (c *QuotaController) GetAll() {
-	language := c.Ctx.Input.Header("lang")
-	if language != "ru" && language != "kz" {
-		c.CustomAbort(http.StatusBadRequest, "Invalid or unsupported language")
-		return
-	}
+	language := requestLocale(c.Ctx)
 
-	quotas, err := models.GetAllQuotas(language)
+	quotas, err := c.Quotas.List(language)
 	if err != nil {
 		c.Data["json"] = err.Error()
 	} else {
@@ -150,11 +144,17 @@ func (c *QuotaController) GetAll() {
 // @Param	id		path	int	true	"ID квоты для обновления информации"
 // @Param	body	body	models.Quota	true	"JSON с обновленными данными о квоте"
 // @Success 200 string "Обновление успешно выполнено"
-// @Failure 400 {string} string "400 некорректный ID, ошибка разбора JSON или другая ошибка"
+// @Failure 400 {string} string "400 некорректный ID, ошибка разбора JSON или недопустимый диапазон баллов"
+// @Failure 404 {string} string "Квота не найдена"
+// @Failure 500 {string} string "Ошибка базы данных"
 // @router /:id [put]
 func (c *QuotaController) Update() {
 	_ = c.Ctx.Input.CopyBody(1024)
-	id, _ := c.GetInt(":id")
+	id, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid quota ID")
+		return
+	}
 	var quota models.Quota
 
 	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &quota); err != nil {
@@ -177,11 +177,11 @@ func (c *QuotaController) Update() {
 		fields = append(fields, field)
 	}
 
-	if err := models.UpdateQuota(&quota, fields...); err == nil {
-		c.Data["json"] = "Update successful"
-	} else {
-		c.Data["json"] = err.Error()
+	if err := c.Quotas.Update(&quota, fields...); err != nil {
+		c.CustomAbort(errorStatus(err), err.Error())
+		return
 	}
+	c.Data["json"] = "Update successful"
 	c.ServeJSON()
 }
 
@@ -194,7 +194,7 @@ func (c *QuotaController) Update() {
 // @router /:id [delete]
 func (c *QuotaController) Delete() {
 	id, _ := c.GetInt(":id")
-	err := models.DeleteQuota(id)
+	err := c.Quotas.Delete(id)
 	if err == nil {
 		c.Data["json"] = "Delete successful"
 	} else {
@@ -206,16 +206,16 @@ func (c *QuotaController) Delete() {
 // AddSpecialityToQuota adds a speciality to a quota by their IDs.
 // @Title AddSpecialityToQuota
 // @Description Добавление специальности к квоте по их ID.
-// @Param	quotaId		path	int	true	"ID квоты"
-// @Param	specialityId	path	int	true	"ID специальности"
+// @Param	quota_id		path	int	true	"ID квоты"
+// @Param	speciality_id	path	int	true	"ID специальности"
 // @Success 200 string "Добавление успешно выполнено"
 // @Failure 400 {string} string "400 некорректный ID или другая ошибка"
-// @router /:quotaId/specialities/:specialityId [post]
+// @router /:quota_id/specialities/:speciality_id [post]
 func (c *QuotaController) AddSpecialityToQuota() {
-	quotaId, _ := c.GetInt(":quotaId")
-	specialityId, _ := c.GetInt(":specialityId")
+	quotaId, _ := c.GetInt(":quota_id")
+	specialityId, _ := c.GetInt(":speciality_id")
 
-	err := models.AddSpecialityToQuota(specialityId, quotaId)
+	err := c.Quotas.AddSpeciality(quotaId, specialityId)
 	if err == nil {
 		c.Data["json"] = "Add speciality to quota successful"
 	} else {
@@ -232,13 +232,9 @@ func (c *QuotaController) AddSpecialityToQuota() {
 // @Failure 400 {string} string "400 ошибка получения списка или другая ошибка"
 // @router all/:id [get]
 func (c *QuotaController) GetQuotaWithSpecialities() {
-	language := c.Ctx.Input.Header("lang")
-	if language != "ru" && language != "kz" {
-		c.CustomAbort(http.StatusBadRequest, "Invalid or unsupported language")
-		return
-	}
+	language := requestLocale(c.Ctx)
 
-	quotas, err := models.GetAllQuotasWithSpecialities(language)
+	quotas, err := c.Quotas.ListWithSpecialities(language)
 	if err != nil {
 		c.Data["json"] = err.Error()
 	} else {
@@ -270,13 +266,9 @@ func (c *QuotaController) GetQuotaWithSpecialities() {
 // @router /with-specialities/:id [get]
 func (c *QuotaController) GetWithSpecialitiesById() {
 	id, _ := c.GetInt(":id")
-	language := c.Ctx.Input.Header("lang")
-	if language != "ru" && language != "kz" {
-		c.CustomAbort(http.StatusBadRequest, "Invalid or unsupported language")
-		return
-	}
+	language := requestLocale(c.Ctx)
 
-	quota, err := models.GetQuotaWithSpecialitiesById(id, language)
+	quota, err := c.Quotas.GetWithSpecialities(id, language)
 	if err != nil {
 		c.Data["json"] = err.Error()
 	} else {
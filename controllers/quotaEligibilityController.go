@@ -0,0 +1,249 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testhub-spec-uni/models"
+
+	beego "github.com/beego/beego/v2/server/web"
+	"github.com/go-playground/validator/v10"
+)
+
+// QuotaEligibilityController обрабатывает анкету для подбора квот и правила квот.
+type QuotaEligibilityController struct {
+	beego.Controller
+}
+
+func (c *QuotaEligibilityController) abortValidation(err error) {
+	errMap := make(map[string]string)
+	for _, err := range err.(validator.ValidationErrors) {
+		errMap[err.Field()] = fmt.Sprintf("Validation failed on the '%s' tag", err.Tag())
+	}
+	c.Ctx.Output.SetStatus(http.StatusBadRequest)
+	c.Data["json"] = errMap
+	c.ServeJSON()
+}
+
+// CreateCriterion добавляет вопрос анкеты.
+// @Title CreateCriterion
+// @Description Создание критерия для правил квот.
+// @Param   Code        formData string true "Код критерия, например rural или disability"
+// @Param   QuestionRu  formData string true "Вопрос на русском языке"
+// @Param   QuestionKz  formData string true "Вопрос на казахском языке"
+// @Success 200 {object} map[string]int64 "ID созданного критерия"
+// @Failure 400 {object} map[string]string "Error message"
+// @router /criteria [post]
+func (c *QuotaEligibilityController) CreateCriterion() {
+	var form models.AddQuotaCriterionResponse
+	if err := c.ParseForm(&form); err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid form data")
+		return
+	}
+	if err := validator.New().Struct(&form); err != nil {
+		c.abortValidation(err)
+		return
+	}
+
+	id, err := models.AddQuotaCriterion(&form)
+	if err != nil {
+		c.Ctx.Output.SetStatus(http.StatusBadRequest)
+		c.Data["json"] = map[string]string{"error": err.Error()}
+	} else {
+		c.Data["json"] = map[string]int64{"id": id}
+	}
+	c.ServeJSON()
+}
+
+// GetAllCriteriaForAdmin возвращает критерии с вопросами на обоих языках.
+// @Title GetAllCriteriaForAdmin
+// @Description Получение всех критериев анкеты.
+// @Success 200 {array} models.QuotaCriterion
+// @Failure 500 {string} string "Internal Server Error"
+// @router /criteria [get]
+func (c *QuotaEligibilityController) GetAllCriteriaForAdmin() {
+	criteria, err := models.GetAllQuotaCriteriaForAdmin()
+	if err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = criteria
+	c.ServeJSON()
+}
+
+// UpdateCriterion обновляет формулировку вопроса.
+// @Title UpdateCriterion
+// @Description Обновление критерия анкеты по ID.
+// @Param	id			path		int		true	"ID критерия"
+// @Param   QuestionRu	formData	string	false	"Вопрос на русском языке"
+// @Param   QuestionKz	formData	string	false	"Вопрос на казахском языке"
+// @Success 200 {string} "Update successful"
+// @Failure 400 {string} string "Invalid input"
+// @router /criteria/:id [put]
+func (c *QuotaEligibilityController) UpdateCriterion() {
+	id, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid criterion ID")
+		return
+	}
+
+	var form models.UpdateQuotaCriterionResponse
+	if err := c.ParseForm(&form); err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid form data")
+		return
+	}
+
+	if err := models.UpdateQuotaCriterion(id, &form); err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = "Update successful"
+	c.ServeJSON()
+}
+
+// DeleteCriterion удаляет критерий и правила, которые на него ссылаются.
+// @Title DeleteCriterion
+// @Description Удаление критерия анкеты по ID.
+// @Param	id	path	int	true	"ID критерия"
+// @Success 200 {string} "Delete successful"
+// @Failure 400 {string} string "Invalid criterion ID"
+// @router /criteria/:id [delete]
+func (c *QuotaEligibilityController) DeleteCriterion() {
+	id, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid criterion ID")
+		return
+	}
+
+	if err := models.DeleteQuotaCriterion(id); err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = "Delete successful"
+	c.ServeJSON()
+}
+
+// CreateRule добавляет условие к квоте.
+// @Title CreateRule
+// @Description Добавление правила квоты. Правила с одинаковым Group объединяются через «И», разные группы — через «ИЛИ».
+// @Param   QuotaId        formData int    true  "ID квоты"
+// @Param   CriterionCode  formData string true  "Код критерия"
+// @Param   Group          formData int    false "Номер группы условий"
+// @Success 200 {object} map[string]int64 "ID созданного правила"
+// @Failure 400 {object} map[string]string "Error message"
+// @router /rules [post]
+func (c *QuotaEligibilityController) CreateRule() {
+	var form models.AddQuotaRuleResponse
+	if err := c.ParseForm(&form); err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid form data")
+		return
+	}
+	if err := validator.New().Struct(&form); err != nil {
+		c.abortValidation(err)
+		return
+	}
+
+	id, err := models.AddQuotaRule(&form)
+	if err != nil {
+		c.Ctx.Output.SetStatus(http.StatusBadRequest)
+		c.Data["json"] = map[string]string{"error": err.Error()}
+	} else {
+		c.Data["json"] = map[string]int64{"id": id}
+	}
+	c.ServeJSON()
+}
+
+// GetRulesByQuota возвращает правила квоты.
+// @Title GetRulesByQuota
+// @Description Получение правил квоты.
+// @Param	quotaId	path	int	true	"ID квоты"
+// @Success 200 {array} models.QuotaRuleResponse
+// @Failure 400 {string} string "Invalid quota ID"
+// @router /rules/byquota/:quotaId [get]
+func (c *QuotaEligibilityController) GetRulesByQuota() {
+	quotaId, err := c.GetInt(":quotaId")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid quota ID")
+		return
+	}
+
+	rules, err := models.GetQuotaRulesByQuota(quotaId)
+	if err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = rules
+	c.ServeJSON()
+}
+
+// DeleteRule удаляет правило квоты.
+// @Title DeleteRule
+// @Description Удаление правила квоты по ID.
+// @Param	id	path	int	true	"ID правила"
+// @Success 200 {string} "Delete successful"
+// @Failure 400 {string} string "Invalid rule ID"
+// @router /rules/:id [delete]
+func (c *QuotaEligibilityController) DeleteRule() {
+	id, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid rule ID")
+		return
+	}
+
+	if err := models.DeleteQuotaRule(id); err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = "Delete successful"
+	c.ServeJSON()
+}
+
+// GetQuestionnaire возвращает вопросы анкеты на выбранном языке.
+// @Title GetQuestionnaire
+// @Description Вопросы анкеты для подбора квот.
+// @Param	lang	header	string	false	"Язык для получения данных, 'ru' или 'kz'"
+// @Success 200 {array} models.QuotaCriterionResponse
+// @Failure 500 {string} string "Internal Server Error"
+// @router /questions [get]
+func (c *QuotaEligibilityController) GetQuestionnaire() {
+	criteria, err := models.GetAllQuotaCriteria(requestLocale(c.Ctx))
+	if err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = criteria
+	c.ServeJSON()
+}
+
+// Check подбирает квоты по ответам анкеты и программы, где квота даёт преимущество.
+// @Title Check
+// @Description Ответы передаются JSON-объектом {"answers": {"rural": true, ...}, "score": 95} или с баллами по блокам в поле "unt". Возвращает подходящие квоты и программы, где проходной балл по квоте ниже общего.
+// @Param	body	body	models.QuotaEligibilityRequest	true	"Ответы на анкету и балл ЕНТ"
+// @Param	lang	header	string	false	"Язык для получения данных, 'ru' или 'kz'"
+// @Success 200 {object} models.QuotaEligibilityResult
+// @Failure 400 {string} string "Invalid input"
+// @router /check [post]
+func (c *QuotaEligibilityController) Check() {
+	body := c.Ctx.Input.CopyBody(64 * 1024)
+
+	var request models.QuotaEligibilityRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid JSON: "+err.Error())
+		return
+	}
+
+	result, err := models.CheckQuotaEligibility(&request, requestLocale(c.Ctx))
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.Data["json"] = result
+	c.ServeJSON()
+}
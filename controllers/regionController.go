@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testhub-spec-uni/models"
+
+	beego "github.com/beego/beego/v2/server/web"
+)
+
+// RegionController обрабатывает запросы для областей.
+type RegionController struct {
+	beego.Controller
+}
+
+// Create добавляет новую область.
+// @Title Create
+// @Description Создание новой области.
+// @Param	body	body	models.Region	true	"JSON с данными об области"
+// @Success 200 {object} map[string]int64 "ID созданной области"
+// @Failure 400 {string} string "400 ошибка разбора JSON или другая ошибка"
+// @router / [post]
+func (c *RegionController) Create() {
+	var region models.Region
+
+	requestBody := c.Ctx.Input.CopyBody(1024)
+	if err := json.Unmarshal(requestBody, &region); err != nil {
+		c.CustomAbort(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := models.AddRegion(&region)
+	if err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = map[string]int64{"id": id}
+	c.ServeJSON()
+}
+
+// Get возвращает область вместе с её городами.
+// @Title Get
+// @Description Получение области с городами по ID.
+// @Param	id		path	int		true	"ID области"
+// @Param	lang	header	string	true	"Язык для получения данных, 'ru' или 'kz'"
+// @Success 200 {object} models.RegionResponse
+// @Failure 404 {string} string "Область не найдена"
+// @router /:id [get]
+func (c *RegionController) Get() {
+	id, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid region ID")
+		return
+	}
+
+	region, err := models.GetRegionWithCities(id, requestLocale(c.Ctx))
+	if err != nil {
+		c.CustomAbort(http.StatusNotFound, err.Error())
+		return
+	}
+
+	c.Data["json"] = region
+	c.ServeJSON()
+}
+
+// GetAll возвращает список областей.
+// @Title GetAll
+// @Description Получение списка всех областей.
+// @Param	lang	header	string	true	"Язык для получения данных, 'ru' или 'kz'"
+// @Success 200 {array} models.RegionResponse
+// @Failure 500 {string} string "Internal Server Error"
+// @router / [get]
+func (c *RegionController) GetAll() {
+	regions, err := models.GetAllRegions(requestLocale(c.Ctx))
+	if err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := make([]*models.RegionResponse, 0, len(regions))
+	for _, region := range regions {
+		response = append(response, &models.RegionResponse{Id: region.Id, Name: region.Name})
+	}
+
+	c.Data["json"] = response
+	c.ServeJSON()
+}
+
+// Update обновляет данные области.
+// @Title Update
+// @Description Обновление области по ID.
+// @Param	id		path	int				true	"ID области"
+// @Param	body	body	models.UpdateRegionRequest	true	"JSON с обновленными данными; пустые поля не меняются"
+// @Success 200 {string} string "Update successful"
+// @Failure 400 {string} string "400 некорректный ID или ошибка разбора JSON"
+// @Failure 404 {string} string "Область не найдена"
+// @router /:id [put]
+func (c *RegionController) Update() {
+	id, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid region ID")
+		return
+	}
+
+	var form models.UpdateRegionRequest
+	if err := json.Unmarshal(c.Ctx.Input.CopyBody(1024), &form); err != nil {
+		c.CustomAbort(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := models.UpdateRegion(id, &form); err != nil {
+		c.CustomAbort(errorStatus(err), err.Error())
+		return
+	}
+
+	c.Data["json"] = "Update successful"
+	c.ServeJSON()
+}
+
+// Delete удаляет область; города остаются без области.
+// @Title Delete
+// @Description Удаление области по ID.
+// @Param	id	path	int	true	"ID области"
+// @Success 200 {string} string "Delete successful"
+// @Failure 400 {string} string "400 некорректный ID"
+// @router /:id [delete]
+func (c *RegionController) Delete() {
+	id, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid region ID")
+		return
+	}
+
+	if err := models.DeleteRegion(id); err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = "Delete successful"
+	c.ServeJSON()
+}
@@ -0,0 +1,269 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"testhub-spec-uni/models"
+
+	beego "github.com/beego/beego/v2/server/web"
+	"github.com/go-playground/validator/v10"
+)
+
+// ReviewController обрабатывает отзывы студентов и их модерацию.
+type ReviewController struct {
+	beego.Controller
+}
+
+// Create добавляет отзыв текущего пользователя. Отзыв уходит на модерацию.
+// @Title Create
+// @Description Создание отзыва об университете. Оценки по критериям от 1 до 5.
+// @Param   UniversityId         formData int    true  "ID университета"
+// @Param   SpecialityId         formData int    false "ID специальности"
+// @Param   TeachingScore        formData int    true  "Качество преподавания"
+// @Param   InfrastructureScore  formData int    true  "Инфраструктура"
+// @Param   StudentLifeScore     formData int    true  "Студенческая жизнь и общежития"
+// @Param   CareerScore          formData int    true  "Карьерные возможности"
+// @Param   ValueScore           formData int    true  "Соотношение цены и качества"
+// @Param   Text                 formData string true  "Текст отзыва"
+// @Success 200 {object} map[string]int64 "ID созданного отзыва"
+// @Failure 400 {object} map[string]string "Error message"
+// @router /reviews [post]
+func (c *ReviewController) Create() {
+	userId, ok := c.Ctx.Input.GetData("user_id").(int)
+	if !ok {
+		c.CustomAbort(http.StatusBadRequest, "Invalid user_id")
+		return
+	}
+
+	var form models.AddReviewResponse
+	if err := c.ParseForm(&form); err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid form data")
+		return
+	}
+	if err := validator.New().Struct(&form); err != nil {
+		errMap := make(map[string]string)
+		for _, err := range err.(validator.ValidationErrors) {
+			errMap[err.Field()] = fmt.Sprintf("Validation failed on the '%s' tag", err.Tag())
+		}
+		c.Ctx.Output.SetStatus(http.StatusBadRequest)
+		c.Data["json"] = errMap
+		c.ServeJSON()
+		return
+	}
+
+	id, err := models.AddReview(userId, &form)
+	if err != nil {
+		c.Ctx.Output.SetStatus(http.StatusBadRequest)
+		c.Data["json"] = map[string]string{"error": err.Error()}
+	} else {
+		c.Data["json"] = map[string]int64{"id": id}
+	}
+	c.ServeJSON()
+}
+
+// GetByUniversity возвращает одобренные отзывы об университете.
+// @Title GetByUniversity
+// @Description Одобренные отзывы об университете, новые первыми.
+// @Param	universityId	path	int	true	"ID университета"
+// @Param	speciality_id	query	int	false	"ID специальности"
+// @Param	page			query	int	false	"Номер страницы"
+// @Param	per_page		query	int	false	"Количество элементов на странице"
+// @Success 200 {object} models.ReviewListResult
+// @Failure 400 {string} string "Invalid university ID"
+// @router /byuni/:universityId [get]
+func (c *ReviewController) GetByUniversity() {
+	universityId, err := c.GetInt(":universityId")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid university ID")
+		return
+	}
+
+	specialityId, _ := c.GetInt("speciality_id", 0)
+	page, err := c.GetInt("page", 1)
+	if err != nil || page < 1 {
+		c.CustomAbort(http.StatusBadRequest, "Invalid page value")
+		return
+	}
+	perPage, err := c.GetInt("per_page", 10)
+	if err != nil || perPage < 1 {
+		c.CustomAbort(http.StatusBadRequest, "Invalid per_page value")
+		return
+	}
+
+	result, err := models.GetApprovedReviewsByUniversity(universityId, specialityId, page, perPage)
+	if err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = result
+	c.ServeJSON()
+}
+
+// GetMine возвращает отзывы текущего пользователя со статусом модерации.
+// @Title GetMine
+// @Description Отзывы текущего пользователя.
+// @Success 200 {array} models.ReviewResponse
+// @Failure 400 {string} string "Invalid user_id"
+// @router /reviews/mine [get]
+func (c *ReviewController) GetMine() {
+	userId, ok := c.Ctx.Input.GetData("user_id").(int)
+	if !ok {
+		c.CustomAbort(http.StatusBadRequest, "Invalid user_id")
+		return
+	}
+
+	reviews, err := models.GetReviewsByUser(userId)
+	if err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = reviews
+	c.ServeJSON()
+}
+
+// GetQueue возвращает очередь модерации.
+// @Title GetQueue
+// @Description Отзывы в статусе pending; отзывы со стоп-словами идут первыми.
+// @Success 200 {array} models.ReviewForModeration
+// @Failure 500 {string} string "Internal Server Error"
+// @router /queue [get]
+func (c *ReviewController) GetQueue() {
+	queue, err := models.GetModerationQueue()
+	if err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = queue
+	c.ServeJSON()
+}
+
+// Approve одобряет отзыв и пересчитывает рейтинг университета.
+// @Title Approve
+// @Description Одобрение отзыва по ID.
+// @Param	id	path	int	true	"ID отзыва"
+// @Success 200 {string} "Review approved"
+// @Failure 400 {string} string "Invalid review ID"
+// @router /:id/approve [put]
+func (c *ReviewController) Approve() {
+	id, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid review ID")
+		return
+	}
+
+	if err := models.ModerateReview(id, true, ""); err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = "Review approved"
+	c.ServeJSON()
+}
+
+// Reject отклоняет отзыв с указанием причины.
+// @Title Reject
+// @Description Отклонение отзыва по ID.
+// @Param	id		path		int		true	"ID отзыва"
+// @Param	Reason	formData	string	false	"Причина отклонения"
+// @Success 200 {string} "Review rejected"
+// @Failure 400 {string} string "Invalid review ID"
+// @router /:id/reject [put]
+func (c *ReviewController) Reject() {
+	id, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid review ID")
+		return
+	}
+
+	if err := models.ModerateReview(id, false, c.GetString("Reason")); err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = "Review rejected"
+	c.ServeJSON()
+}
+
+// Delete удаляет отзыв.
+// @Title Delete
+// @Description Удаление отзыва по ID.
+// @Param	id	path	int	true	"ID отзыва"
+// @Success 200 {string} "Delete successful"
+// @Failure 400 {string} string "Invalid review ID"
+// @router /:id [delete]
+func (c *ReviewController) Delete() {
+	id, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid review ID")
+		return
+	}
+
+	if err := models.DeleteReview(id); err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = "Delete successful"
+	c.ServeJSON()
+}
+
+// GetStopWords возвращает список стоп-слов.
+// @Title GetStopWords
+// @Description Список стоп-слов для проверки отзывов.
+// @Success 200 {array} models.ReviewStopWord
+// @Failure 500 {string} string "Internal Server Error"
+// @router /stopwords [get]
+func (c *ReviewController) GetStopWords() {
+	words, err := models.GetReviewStopWords()
+	if err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = words
+	c.ServeJSON()
+}
+
+// AddStopWord добавляет стоп-слово.
+// @Title AddStopWord
+// @Description Добавление стоп-слова. Слово сравнивается с началом слов отзыва без учёта регистра.
+// @Param	Word	formData	string	true	"Стоп-слово"
+// @Success 200 {object} map[string]int64 "ID созданного слова"
+// @Failure 400 {object} map[string]string "Error message"
+// @router /stopwords [post]
+func (c *ReviewController) AddStopWord() {
+	id, err := models.AddReviewStopWord(c.GetString("Word"))
+	if err != nil {
+		c.Ctx.Output.SetStatus(http.StatusBadRequest)
+		c.Data["json"] = map[string]string{"error": err.Error()}
+	} else {
+		c.Data["json"] = map[string]int64{"id": id}
+	}
+	c.ServeJSON()
+}
+
+// DeleteStopWord удаляет стоп-слово.
+// @Title DeleteStopWord
+// @Description Удаление стоп-слова по ID.
+// @Param	id	path	int	true	"ID стоп-слова"
+// @Success 200 {string} "Delete successful"
+// @Failure 400 {string} string "Invalid stop word ID"
+// @router /stopwords/:id [delete]
+func (c *ReviewController) DeleteStopWord() {
+	id, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid stop word ID")
+		return
+	}
+
+	if err := models.DeleteReviewStopWord(id); err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = "Delete successful"
+	c.ServeJSON()
+}
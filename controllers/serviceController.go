@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strconv"
 	"testhub-spec-uni/models"
+	"testhub-spec-uni/repository"
 
 	beego "github.com/beego/beego/v2/server/web"
 )
@@ -13,6 +14,8 @@ import (
 // ServiceController handles operations related to services
 type ServiceController struct {
 	beego.Controller
+	Services repository.Services
+	Storage  repository.Storage
 }
 
 // @Title GetAllServices
@@ -22,19 +25,15 @@ type ServiceController struct {
 // @Failure 500 Internal server error
 // @router / [get]
 func (c *ServiceController) GetAllServices() {
-	language := c.Ctx.Input.Header("lang")
-	if language != "ru" && language != "kz" {
-		c.CustomAbort(http.StatusBadRequest, "Invalid or unsupported language")
-		return
-	}
+	language := requestLocale(c.Ctx)
 
-	services, err := models.GetAllServices(language)
+	services, err := c.Services.List(language)
 	if err != nil {
 		c.CustomAbort(http.StatusInternalServerError, err.Error())
 	}
 
 	c.Data["json"] = services
-	c.ServeJSON()
+	serveConditionalJSON(&c.Controller)
 }
 
 // @Title GetAllServicesForAdmin
@@ -43,7 +42,7 @@ func (c *ServiceController) GetAllServices() {
 // @Failure 500 Internal server error
 // @router /all [get]
 func (c *ServiceController) GetAllServicesForAdmin() {
-	services, err := models.GetAllServicesForAdmin()
+	services, err := c.Services.ListForAdmin()
 	if err != nil {
 		c.CustomAbort(http.StatusInternalServerError, err.Error())
 	}
@@ -67,20 +66,16 @@ func (c *ServiceController) GetServiceById() {
 		return
 	}
 
-	language := c.Ctx.Input.Header("lang")
-	if language != "ru" && language != "kz" {
-		c.CustomAbort(http.StatusBadRequest, "Invalid or unsupported language")
-		return
-	}
+	language := requestLocale(c.Ctx)
 
-	service, err := models.GetServiceById(id, language)
+	service, err := c.Services.Get(id, language)
 	if err != nil {
 		c.CustomAbort(http.StatusNotFound, err.Error())
 		return
 	}
 
 	c.Data["json"] = service
-	c.ServeJSON()
+	serveConditionalJSON(&c.Controller)
 }
 
 // @Title SearchServices
@@ -97,13 +92,9 @@ func (c *ServiceController) SearchServices() {
 		return
 	}
 
-	language := c.Ctx.Input.Header("lang")
-	if language != "ru" && language != "kz" {
-		c.CustomAbort(http.StatusBadRequest, "Invalid or unsupported language")
-		return
-	}
+	language := requestLocale(c.Ctx)
 
-	services, err := models.SearchServicesByName(prefix, language)
+	services, err := c.Services.Search(prefix, language)
 	if err != nil {
 		c.CustomAbort(http.StatusInternalServerError, err.Error())
 		return
@@ -140,14 +131,14 @@ func (c *ServiceController) AddService() {
 	defer file.Close()
 
 	filePath := fmt.Sprintf("Services/%d/%s", service.Id, header.Filename)
-	imageUrl, err := models.UploadFileToCloud(filePath, file)
+	imageUrl, err := c.Storage.Upload(c.Ctx.Request.Context(), filePath, file)
 	if err != nil {
 		c.CustomAbort(http.StatusInternalServerError, "Failed to upload file")
 		return
 	}
 	service.ImageUrl = imageUrl
 
-	id, err := models.AddService(&service)
+	id, err := c.Services.Create(&service)
 	if err != nil {
 		c.CustomAbort(http.StatusInternalServerError, err.Error())
 		return
@@ -197,7 +188,7 @@ func (c *ServiceController) UpdateService() {
 		fields = append(fields, field)
 	}
 
-	if err := models.UpdateService(&service, fields...); err != nil {
+	if err := c.Services.Update(&service, fields...); err != nil {
 		c.CustomAbort(http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -220,7 +211,7 @@ func (c *ServiceController) DeleteService() {
 		return
 	}
 
-	if err := models.DeleteService(id); err != nil {
+	if err := c.Services.Delete(id); err != nil {
 		c.CustomAbort(http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -231,33 +222,29 @@ func (c *ServiceController) DeleteService() {
 
 // @Title GetServicesByUniversityId
 // @Description Get services by university ID
-// @Param id path int true "University ID"
+// @Param universityId path int true "University ID"
 // @Param lang header string true "Язык для получения данных, 'ru' или 'kz'"
 // @Success 200 {array} models.ServiceResponseForUser
 // @Failure 400 Invalid input
-// @router /university/:id [get]
+// @router /getbyuni/:universityId [get]
 func (c *ServiceController) GetServicesByUniversityId() {
-	universityIdStr := c.Ctx.Input.Param(":id")
+	universityIdStr := c.Ctx.Input.Param(":universityId")
 	universityId, err := strconv.Atoi(universityIdStr)
 	if err != nil {
 		c.CustomAbort(http.StatusBadRequest, "Invalid university ID")
 		return
 	}
 
-	language := c.Ctx.Input.Header("lang")
-	if language != "ru" && language != "kz" {
-		c.CustomAbort(http.StatusBadRequest, "Invalid or unsupported language")
-		return
-	}
+	language := requestLocale(c.Ctx)
 
-	services, err := models.GetServicesByUniversityId(universityId, language)
+	services, err := c.Services.ListByUniversity(universityId, language)
 	if err != nil {
 		c.CustomAbort(http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	c.Data["json"] = services
-	c.ServeJSON()
+	serveConditionalJSON(&c.Controller)
 }
 
 // @Title GetServicesByUniversityIdForAdmin
@@ -274,7 +261,7 @@ func (c *ServiceController) GetServicesByUniversityIdForAdmin() {
 		return
 	}
 
-	services, err := models.GetServicesByUniversityIdForAdmin(universityId)
+	services, err := c.Services.ListByUniversityForAdmin(universityId)
 	if err != nil {
 		c.CustomAbort(http.StatusInternalServerError, err.Error())
 		return
@@ -306,7 +293,7 @@ func (c *ServiceController) AddServiceToUniversity() {
 		return
 	}
 
-	if err := models.AddServiceToUniversity(serviceId, universityId); err != nil {
+	if err := c.Services.AddToUniversity(c.Ctx.Request.Context(), serviceId, universityId); err != nil {
 		c.CustomAbort(http.StatusInternalServerError, err.Error())
 		return
 	}
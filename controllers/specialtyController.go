@@ -3,10 +3,13 @@ package controllers
 import (
 	"fmt"
 	"github.com/go-playground/validator/v10"
-	"log"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
 	"testhub-spec-uni/models"
+	"testhub-spec-uni/repository"
 
 	beego "github.com/beego/beego/v2/server/web"
 )
@@ -14,6 +17,8 @@ import (
 // SpecialityController обрабатывает запросы для работы со специальностями.
 type SpecialityController struct {
 	beego.Controller
+	Specialities repository.Specialities
+	PointStats   repository.PointStats
 }
 
 // Create adds a new speciality to the database.
@@ -56,7 +61,7 @@ func (c *SpecialityController) Create() {
 	}
 
 	// Create speciality
-	if id, err := models.AddSpecialityFromFormData(&data); err == nil {
+	if id, err := c.Specialities.Create(&data); err == nil {
 		c.Data["json"] = map[string]int64{"id": id}
 	} else {
 		c.Data["json"] = err.Error()
@@ -73,14 +78,17 @@ func (c *SpecialityController) Create() {
 // @router /:id [get]
 func (c *SpecialityController) Get() {
 	id, _ := c.GetInt(":id")
-	lang := c.Ctx.Input.Header("lang")
+	lang := requestLocale(c.Ctx)
 
-	if speciality, err := models.GetSpecialityById(id, lang); err == nil {
-		c.Data["json"] = speciality
-	} else {
+	speciality, err := c.Specialities.Get(id, lang)
+	if err != nil {
 		c.Data["json"] = err.Error()
+		c.ServeJSON()
+		return
 	}
-	c.ServeJSON()
+
+	c.Data["json"] = speciality
+	serveConditionalJSON(&c.Controller)
 }
 
 // GetAll возвращает список всех специальностей.
@@ -90,14 +98,17 @@ func (c *SpecialityController) Get() {
 // @Failure 400 ошибка получения списка или другая ошибка
 // @router / [get]
 func (c *SpecialityController) GetAll() {
-	lang := c.Ctx.Input.Header("lang")
+	lang := requestLocale(c.Ctx)
 
-	if specialities, err := models.GetAllSpecialities(lang); err == nil {
-		c.Data["json"] = specialities
-	} else {
+	specialities, err := c.Specialities.List(lang)
+	if err != nil {
 		c.Data["json"] = err.Error()
+		c.ServeJSON()
+		return
 	}
-	c.ServeJSON()
+
+	c.Data["json"] = specialities
+	serveConditionalJSON(&c.Controller)
 }
 
 // Update updates the information of a speciality by its ID.
@@ -126,9 +137,6 @@ func (c *SpecialityController) Update() {
 		return
 	}
 
-	// Log raw form data
-	log.Printf("Raw form data: %+v\n", c.Ctx.Input.RequestBody)
-
 	var data models.UpdateSpecialityResponse
 	if err := c.ParseForm(&data); err != nil {
 		c.CustomAbort(http.StatusBadRequest, "Invalid input: "+err.Error())
@@ -137,10 +145,9 @@ func (c *SpecialityController) Update() {
 
 	data.Id = id
 
-	// Log the parsed form data
-	log.Printf("Parsed form data: %+v\n", data)
+	slog.DebugContext(c.Ctx.Request.Context(), "Updating speciality", "speciality_id", id, "fields", formFields(c.Ctx.Request.Form))
 
-	if err := models.UpdateSpecialityFromFormData(&data); err != nil {
+	if err := c.Specialities.Update(&data); err != nil {
 		c.CustomAbort(http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -149,6 +156,17 @@ func (c *SpecialityController) Update() {
 	c.ServeJSON()
 }
 
+// formFields возвращает отсортированные имена полей формы. В журнал пишутся только
+// они: значения (описания, названия) могут быть большими и не нужны для отладки.
+func formFields(form url.Values) []string {
+	fields := make([]string, 0, len(form))
+	for name := range form {
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
 // Delete удаляет специальность по ее ID.
 // @Title Delete
 // @Description Удаление специальности по ID.
@@ -158,7 +176,7 @@ func (c *SpecialityController) Update() {
 // @router /:id [delete]
 func (c *SpecialityController) Delete() {
 	id, _ := c.GetInt(":id")
-	if err := models.DeleteSpeciality(id); err == nil {
+	if err := c.Specialities.Delete(id); err == nil {
 		c.Data["json"] = "Delete successful"
 	} else {
 		c.Data["json"] = err.Error()
@@ -185,12 +203,12 @@ func (c *SpecialityController) GetByUniversity() {
 		return
 	}
 
-	lang := c.Ctx.Input.Header("lang")
+	lang := requestLocale(c.Ctx)
 	if lang == "" {
 		lang = "ru"
 	}
 
-	specialities, totalCount, err := models.GetSpecialitiesInUniversityForUser(universityId, lang, page, perPage)
+	specialities, totalCount, err := c.Specialities.ListByUniversity(universityId, lang, page, perPage)
 	if err != nil {
 		c.CustomAbort(500, err.Error())
 		return
@@ -207,7 +225,7 @@ func (c *SpecialityController) GetByUniversity() {
 	}
 
 	c.Data["json"] = response
-	c.ServeJSON()
+	serveConditionalJSON(&c.Controller)
 }
 
 // GetByUniversityForAdmin retrieves all specialities associated with a university by its ID.
@@ -224,7 +242,7 @@ func (c *SpecialityController) GetByUniversityForAdmin() {
 		return
 	}
 
-	specialities, err := models.GetSpecialitiesInUniversityForAdmin(universityId)
+	specialities, err := c.Specialities.ListByUniversityForAdmin(universityId)
 	if err != nil {
 		c.CustomAbort(500, err.Error())
 		return
@@ -256,7 +274,7 @@ func (c *SpecialityController) AssociateSpecialityWithSubjectPair() {
 		c.CustomAbort(http.StatusBadRequest, "Invalid subject_pair_id")
 	}
 
-	err = models.AssociateSpecialityWithSubjectPair(specialityId, subjectPairId)
+	err = c.Specialities.SetSubjectPair(specialityId, subjectPairId)
 	if err != nil {
 		c.CustomAbort(http.StatusInternalServerError, err.Error())
 	}
@@ -282,9 +300,9 @@ func (c *SpecialityController) GetSubjectPairsBySpecialityId() {
 		c.CustomAbort(http.StatusBadRequest, "Invalid speciality_id")
 	}
 
-	lang := c.Ctx.Input.Header("lang")
+	lang := requestLocale(c.Ctx)
 
-	subjectPairs, err := models.GetSubjectPairsBySpecialityId(specialityId, lang)
+	subjectPairs, err := c.Specialities.SubjectPairs(specialityId, lang)
 	if err != nil {
 		c.CustomAbort(http.StatusInternalServerError, err.Error())
 	}
@@ -302,7 +320,7 @@ func (c *SpecialityController) GetSubjectPairsBySpecialityId() {
 // @Failure 404 "Speciality not found"
 // @router /bysubjects/:subject1_id/:subject2_id [get]
 func (c *SpecialityController) GetSpecialitiesBySubjectPair() {
-	lang := c.Ctx.Input.Header("lang")
+	lang := requestLocale(c.Ctx)
 
 	subject1IdStr := c.Ctx.Input.Param(":subject1_id")
 	subject2IdStr := c.Ctx.Input.Param(":subject2_id")
@@ -316,7 +334,7 @@ func (c *SpecialityController) GetSpecialitiesBySubjectPair() {
 		c.CustomAbort(http.StatusBadRequest, "Invalid subject2_id")
 	}
 
-	speciality, err := models.GetSpecialitiesBySubjectPair(subject1Id, subject2Id, lang)
+	speciality, err := c.Specialities.ListBySubjects(subject1Id, subject2Id, lang)
 	if err != nil {
 		c.CustomAbort(http.StatusInternalServerError, err.Error())
 	}
@@ -368,9 +386,9 @@ func (c *SpecialityController) AddPointStat() {
 		Speciality:    &models.Speciality{Id: specialityId},
 	}
 
-	fmt.Printf("PointStat before insert: %+v\n", pointStat)
+	slog.DebugContext(c.Ctx.Request.Context(), "Adding point stat", "university_id", universityId, "speciality_id", specialityId, "year", pointStat.Year)
 
-	id, err := models.AddPointStat(universityId, specialityId, &pointStat)
+	id, err := c.PointStats.Create(universityId, specialityId, &pointStat)
 	if err != nil {
 		c.Data["json"] = err.Error()
 	} else {
@@ -400,7 +418,7 @@ func (c *SpecialityController) GetPointStatsByUniversityAndSpeciality() {
 		return
 	}
 
-	pointStats, err := models.GetPointStatsByUniversityAndSpeciality(universityId, specialityId)
+	pointStats, err := c.PointStats.List(universityId, specialityId)
 	if err != nil {
 		c.CustomAbort(500, err.Error())
 		return
@@ -429,7 +447,7 @@ func (c *SpecialityController) GetPointStatById() {
 		return
 	}
 
-	pointStat, err := models.GetPointStatById(pointStatId)
+	pointStat, err := c.PointStats.Get(pointStatId)
 	if err != nil {
 		c.CustomAbort(500, err.Error())
 		return
@@ -468,7 +486,7 @@ func (c *SpecialityController) DeletePointStat() {
 		return
 	}
 
-	if err := models.DeletePointStat(id); err != nil {
+	if err := c.PointStats.Delete(id); err != nil {
 		c.CustomAbort(500, err.Error())
 		return
 	}
@@ -495,8 +513,8 @@ func (c *SpecialityController) UpdatePointStat() {
 		return
 	}
 
-	if err := models.UpdatePointStatById(id, &form); err != nil {
-		c.CustomAbort(500, "Failed to update PointStat: "+err.Error())
+	if err := c.PointStats.Update(id, &form); err != nil {
+		c.CustomAbort(errorStatus(err), "Failed to update PointStat: "+err.Error())
 		return
 	}
 
@@ -544,12 +562,12 @@ func (c *SpecialityController) SearchSpecialities() {
 		params["per_page"] = perPage
 	}
 
-	lang := c.Ctx.Input.Header("lang")
+	lang := requestLocale(c.Ctx)
 	if lang != "" {
 		params["lang"] = lang
 	}
 
-	result, err := models.SearchSpecialities(params, lang)
+	result, err := c.Specialities.Search(c.Ctx.Request.Context(), params, lang)
 	if err != nil {
 		c.CustomAbort(http.StatusInternalServerError, err.Error())
 		return
@@ -567,9 +585,9 @@ func (c *SpecialityController) SearchSpecialities() {
 // @Failure 500 "Failed to retrieve specialities"
 // @router /specialitynames[get]
 func (c *SpecialityController) GetSpecialityNames() {
-	lang := c.Ctx.Input.Header("lang")
+	lang := requestLocale(c.Ctx)
 
-	specialities, err := models.GetSpecialityNames(lang)
+	specialities, err := c.Specialities.Names(lang)
 	if err != nil {
 		c.CustomAbort(http.StatusInternalServerError, "Failed to retrieve specialities")
 		return
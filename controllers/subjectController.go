@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 	"testhub-spec-uni/models"
+	"testhub-spec-uni/repository"
 
 	beego "github.com/beego/beego/v2/server/web"
 )
@@ -12,6 +13,7 @@ import (
 // SubjectController обрабатывает запросы для работы с предметами.
 type SubjectController struct {
 	beego.Controller
+	Subjects repository.Subjects
 }
 
 // Create добавляет новый предмет в базу данных.
@@ -23,14 +25,15 @@ type SubjectController struct {
 // @router / [post]
 func (c *SubjectController) Create() {
 	_ = c.Ctx.Input.CopyBody(1024)
-	var subject models.Subject
+	// Не переданные баллы получают стандартные значения профильного предмета.
+	subject := models.Subject{MaxScore: models.UntProfileMaxScore, Threshold: models.UntProfileThreshold}
 	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &subject); err != nil {
 		c.Data["json"] = err.Error()
 		c.ServeJSON()
 		return
 	}
 
-	if id, err := models.AddSubject(&subject); err == nil {
+	if id, err := c.Subjects.Create(&subject); err == nil {
 		c.Data["json"] = map[string]int64{"id": id}
 	} else {
 		c.Data["json"] = err.Error()
@@ -54,20 +57,16 @@ func (c *SubjectController) Get() {
 		return
 	}
 
-	language := c.Ctx.Input.Header("lang")
-	if language != "ru" && language != "kz" {
-		c.CustomAbort(http.StatusBadRequest, "Invalid or unsupported language")
-		return
-	}
+	language := requestLocale(c.Ctx)
 
-	subject, err := models.GetSubjectById(id, language)
+	subject, err := c.Subjects.Get(id, language)
 	if err != nil {
 		c.CustomAbort(http.StatusNotFound, err.Error())
 		return
 	}
 
 	c.Data["json"] = subject
-	c.ServeJSON()
+	serveConditionalJSON(&c.Controller)
 }
 
 // GetAll возвращает список всех предметов.
@@ -78,28 +77,26 @@ func (c *SubjectController) Get() {
 // @Failure 400 ошибка получения списка или другая ошибка
 // @router / [get]
 func (c *SubjectController) GetAll() {
-	language := c.Ctx.Input.Header("lang")
-	if language != "ru" && language != "kz" {
-		c.CustomAbort(http.StatusBadRequest, "Invalid or unsupported language")
-		return
-	}
+	language := requestLocale(c.Ctx)
 
-	subjects, err := models.GetAllSubjects(language)
+	subjects, err := c.Subjects.List(language)
 	if err != nil {
 		c.CustomAbort(http.StatusInternalServerError, err.Error())
 	}
 
 	c.Data["json"] = subjects
-	c.ServeJSON()
+	serveConditionalJSON(&c.Controller)
 }
 
 // Update обновляет информацию о предмете по его ID.
 // @Title Update
 // @Description Обновление информации о предмете по ID.
 // @Param	id		path	int	true	"ID предмета для обновления информации"
-// @Param	body	body	models.Subject	true	"JSON с обновленными данными о предмете"
+// @Param	body	body	models.UpdateSubjectRequest	true	"JSON с обновленными данными о предмете"
 // @Success 200 string	"Обновление успешно выполнено"
-// @Failure 400 некорректный ID, ошибка разбора JSON или другая ошибка
+// @Failure 400 некорректный ID, ошибка разбора JSON или недопустимые MaxScore и Threshold
+// @Failure 404 предмет не найден
+// @Failure 500 ошибка базы данных
 // @router /:id [put]
 func (c *SubjectController) Update() {
 	idStr := c.Ctx.Input.Param(":id")
@@ -110,16 +107,14 @@ func (c *SubjectController) Update() {
 		return
 	}
 
-	var subject models.Subject
-	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &subject); err != nil {
+	var form models.UpdateSubjectRequest
+	if err := json.Unmarshal(c.Ctx.Input.RequestBody, &form); err != nil {
 		c.CustomAbort(http.StatusBadRequest, "Invalid input: "+err.Error())
 		return
 	}
 
-	subject.Id = id
-
-	if err := models.UpdateSubject(&subject); err != nil {
-		c.CustomAbort(http.StatusInternalServerError, err.Error())
+	if err := c.Subjects.Update(id, &form); err != nil {
+		c.CustomAbort(errorStatus(err), err.Error())
 		return
 	}
 
@@ -142,7 +137,7 @@ func (c *SubjectController) Delete() {
 		return
 	}
 
-	if err := models.DeleteSubject(id); err != nil {
+	if err := c.Subjects.Delete(id); err != nil {
 		c.CustomAbort(http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -166,13 +161,9 @@ func (c *SubjectController) SearchSubjectsByName() {
 		return
 	}
 
-	language := c.Ctx.Input.Header("lang")
-	if language != "ru" && language != "kz" {
-		c.CustomAbort(http.StatusBadRequest, "Invalid or unsupported language")
-		return
-	}
+	language := requestLocale(c.Ctx)
 
-	subjects, err := models.SearchSubjectsByName(name, language)
+	subjects, err := c.Subjects.Search(name, language)
 	if err != nil {
 		c.CustomAbort(http.StatusInternalServerError, err.Error())
 	}
@@ -197,33 +188,14 @@ func (c *SubjectController) GetAllowedSecondSubjects() {
 		return
 	}
 
-	language := c.Ctx.Input.Header("lang")
-	if language != "ru" && language != "kz" {
-		c.CustomAbort(http.StatusBadRequest, "Invalid or unsupported language")
-		return
-	}
+	language := requestLocale(c.Ctx)
 
-	subjects, err := models.GetAllowedSecondSubjects(subject1Id)
+	subjects, err := c.Subjects.AllowedSecond(subject1Id, language)
 	if err != nil {
 		c.CustomAbort(http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	var subjectResponses []*models.SubjectResponse
-	for _, subject := range subjects {
-		name := subject.Name
-		switch language {
-		case "ru":
-			name = subject.NameRu
-		case "kz":
-			name = subject.NameKz
-		}
-		subjectResponses = append(subjectResponses, &models.SubjectResponse{
-			Id:   subject.Id,
-			Name: name,
-		})
-	}
-
-	c.Data["json"] = subjectResponses
+	c.Data["json"] = subjects
 	c.ServeJSON()
 }
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"testhub-spec-uni/models"
+
+	beego "github.com/beego/beego/v2/server/web"
+	"github.com/go-playground/validator/v10"
+)
+
+// TranslationController управляет переводами полей сущностей.
+type TranslationController struct {
+	beego.Controller
+}
+
+// Set сохраняет перевод одного поля.
+// @Title Set
+// @Description Сохранение перевода поля сущности, например названия университета на английском. Перевод kz-latn заменяет автоматическую транслитерацию. Пустое значение удаляет перевод.
+// @Param   EntityType  formData string true  "Тип сущности: city, region, service, subject, quota, university, speciality"
+// @Param   EntityId    formData int    true  "ID сущности"
+// @Param   Field       formData string true  "Поле, например name или description"
+// @Param   Locale      formData string true  "Язык: kz, ru, en, kz-latn"
+// @Param   Value       formData string false "Перевод"
+// @Success 200 {string} "Translation saved"
+// @Failure 400 {object} map[string]string "Error message"
+// @router / [put]
+func (c *TranslationController) Set() {
+	var request models.SetTranslationRequest
+	if err := c.ParseForm(&request); err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid form data")
+		return
+	}
+	if err := validator.New().Struct(&request); err != nil {
+		errMap := make(map[string]string)
+		for _, err := range err.(validator.ValidationErrors) {
+			errMap[err.Field()] = fmt.Sprintf("Validation failed on the '%s' tag", err.Tag())
+		}
+		c.Ctx.Output.SetStatus(http.StatusBadRequest)
+		c.Data["json"] = errMap
+		c.ServeJSON()
+		return
+	}
+
+	if err := models.SetTranslation(&request); err != nil {
+		c.Ctx.Output.SetStatus(http.StatusBadRequest)
+		c.Data["json"] = map[string]string{"error": err.Error()}
+		c.ServeJSON()
+		return
+	}
+
+	c.Data["json"] = "Translation saved"
+	c.ServeJSON()
+}
+
+// Get возвращает все переводы сущности.
+// @Title Get
+// @Description Переводы сущности: поле → язык → значение.
+// @Param	entityType	path	string	true	"Тип сущности"
+// @Param	entityId	path	int		true	"ID сущности"
+// @Success 200 {object} map[string]models.LocalizedText
+// @Failure 400 {string} string "Invalid input"
+// @router /:entityType/:entityId [get]
+func (c *TranslationController) Get() {
+	entityId, err := c.GetInt(":entityId")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid entity ID")
+		return
+	}
+
+	result, err := models.GetTranslations(c.GetString(":entityType"), entityId)
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.Data["json"] = result
+	c.ServeJSON()
+}
+
+// Audit проверяет полноту и качество переводов.
+// @Title Audit
+// @Description Отчёт по переводам: пустые значения, одинаковый текст на ru и kz, латиница в кириллических полях, сильная разница в длине. Результаты сгруппированы по сущностям с процентом заполненности.
+// @Param	entityType	query	string	false	"Тип сущности: university, speciality, city, service, subject, quota. По умолчанию все"
+// @Param	threshold	query	number	false	"Допустимая относительная разница длин, по умолчанию 0.5"
+// @Success 200 {object} models.TranslationAuditResult
+// @Failure 400 {string} string "Invalid input"
+// @router /audit [get]
+func (c *TranslationController) Audit() {
+	threshold, err := c.GetFloat("threshold", models.DefaultLengthMismatchThreshold)
+	if err != nil || threshold <= 0 || threshold > 1 {
+		c.CustomAbort(http.StatusBadRequest, "Invalid threshold")
+		return
+	}
+
+	result, err := models.AuditTranslations(c.GetString("entityType"), threshold)
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.Data["json"] = result
+	c.ServeJSON()
+}
+
+// Migrate переносит значения колонок *Ru/*Kz в таблицу переводов.
+// @Title Migrate
+// @Description Перенос существующих переводов в таблицу translation. Повторный запуск безопасен.
+// @Success 200 {object} models.TranslationMigrationResult
+// @Failure 500 {string} string "Internal Server Error"
+// @router /migrate [post]
+func (c *TranslationController) Migrate() {
+	result, err := models.MigrateLegacyTranslations()
+	if err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = result
+	c.ServeJSON()
+}
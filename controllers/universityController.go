@@ -2,20 +2,25 @@ package controllers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"github.com/astaxie/beego/orm"
 	beego "github.com/beego/beego/v2/server/web"
 	"github.com/go-playground/validator/v10"
-	"log"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
 	"testhub-spec-uni/models"
+	"testhub-spec-uni/repository"
 )
 
 // UniversityController обрабатывает запросы для работы с университетами.
 type UniversityController struct {
 	beego.Controller
+	Universities repository.Universities
+	Services     repository.Services
+	Favorites    repository.Favorites
+	Storage      repository.Storage
 }
 
 // Create
@@ -36,13 +41,14 @@ type UniversityController struct {
 // @Param   AbbreviationKz    formData    string  true  "Аббревиатура на казахском языке"
 // @Param   MainImageUrl      formData    file    true  "Главное изображение"
 // @Param   AddressLink       formData    string  true  "Ссылка на адрес"
+// @Param   Latitude          formData    float64 false "Широта главного корпуса"
+// @Param   Longitude         formData    float64 false "Долгота главного корпуса"
 // @Param   DescriptionRu     formData    string  true  "Описание на русском языке"
 // @Param   DescriptionKz     formData    string  true  "Описание на казахском языке"
 // @Param   Rating            formData    string  true  "Рейтинг"
 // @Param   Gallery           formData    file    true  "Галерея изображений"
 // @Param   CityId            formData    int     true  "ID города"
 // @Param   ServiceIds        formData    string  true  "Список ID сервисов в формате [1,2,3]"
-// @Param   MinScore           formData    int     true  "Минимальный балл для университета"
 // @Success 200 {int64} id "ID созданного университета"
 // @Failure 400 {object} map[string]string "Error message"
 // @Failure 500 {object} map[string]string "Error message"
@@ -98,14 +104,15 @@ func (c *UniversityController) Create() {
 	universityResponse.AbbreviationKz = partialResponse.AbbreviationKz
 	universityResponse.MainImageUrl = partialResponse.MainImageUrl
 	universityResponse.AddressLink = partialResponse.AddressLink
+	universityResponse.Latitude = partialResponse.Latitude
+	universityResponse.Longitude = partialResponse.Longitude
 	universityResponse.DescriptionRu = partialResponse.DescriptionRu
 	universityResponse.DescriptionKz = partialResponse.DescriptionKz
 	universityResponse.Rating = partialResponse.Rating
-	universityResponse.MinScore = partialResponse.MinScore
 	universityResponse.Gallery = partialResponse.Gallery
 	universityResponse.CityId = partialResponse.CityId
 
-	id, err := models.AddUniversity(&universityResponse)
+	id, err := c.Universities.Create(&universityResponse)
 	if err != nil {
 		if validationErrors, ok := err.(validator.ValidationErrors); ok {
 			errors := make(map[string]string)
@@ -133,7 +140,7 @@ func (c *UniversityController) Create() {
 	universityID := strconv.FormatInt(id, 10)
 	mainImagePath := fmt.Sprintf("Universities/%s/%s", universityID, header.Filename)
 
-	uploadedMainImageURL, err := models.UploadFileToCloud(mainImagePath, file)
+	uploadedMainImageURL, err := c.Storage.Upload(c.Ctx.Request.Context(), mainImagePath, file)
 	if err != nil {
 		c.Data["json"] = map[string]string{"error": "Failed to upload main image: " + err.Error()}
 		c.Ctx.Output.SetStatus(500)
@@ -141,7 +148,7 @@ func (c *UniversityController) Create() {
 		return
 	}
 
-	err = models.UpdateUniversityImageURL(id, uploadedMainImageURL)
+	err = c.Universities.SetMainImage(id, uploadedMainImageURL)
 	if err != nil {
 		c.Data["json"] = map[string]string{"error": "Failed to update university main image URL: " + err.Error()}
 		c.Ctx.Output.SetStatus(500)
@@ -163,7 +170,7 @@ func (c *UniversityController) Create() {
 		defer galleryFile.Close()
 
 		galleryFilePath := fmt.Sprintf("Universities/%s/Gallery/%s", universityID, galleryFileHeader.Filename)
-		uploadedGalleryURL, err := models.UploadFileToCloud(galleryFilePath, galleryFile)
+		uploadedGalleryURL, err := c.Storage.Upload(c.Ctx.Request.Context(), galleryFilePath, galleryFile)
 		if err != nil {
 			c.Data["json"] = map[string]string{"error": "Failed to upload gallery image: " + err.Error()}
 			c.Ctx.Output.SetStatus(500)
@@ -174,7 +181,7 @@ func (c *UniversityController) Create() {
 		galleryURLs = append(galleryURLs, uploadedGalleryURL)
 	}
 
-	err = models.AddGalleryImages(id, galleryURLs)
+	err = c.Universities.AddGallery(id, galleryURLs)
 	if err != nil {
 		c.Data["json"] = map[string]string{"error": "Failed to add gallery images: " + err.Error()}
 		c.Ctx.Output.SetStatus(500)
@@ -196,7 +203,7 @@ func (c *UniversityController) Create() {
 // @router /:id [get]
 func (c *UniversityController) GetForAdmin() {
 	id, _ := c.GetInt(":id")
-	university, err := models.GetUniversityByIdForAdmin(id)
+	university, err := c.Universities.GetForAdmin(id)
 	if err == nil {
 		c.Data["json"] = university
 	} else {
@@ -221,15 +228,17 @@ func (c *UniversityController) GetForUser() {
 		return
 	}
 
-	language := c.Ctx.Input.Header("lang")
+	language := requestLocale(c.Ctx)
 
-	university, err := models.GetUniversityByIdForUser(id, language)
-	if err == nil {
-		c.Data["json"] = university
-	} else {
+	university, err := c.Universities.GetForUser(id, language)
+	if err != nil {
 		c.Data["json"] = err.Error()
+		c.ServeJSON()
+		return
 	}
-	c.ServeJSON()
+
+	c.Data["json"] = university
+	serveConditionalJSON(&c.Controller)
 }
 
 // GetAll возвращает список всех университетов.
@@ -241,11 +250,7 @@ func (c *UniversityController) GetForUser() {
 // @Failure 400 ошибка получения списка или другая ошибка
 // @router / [get]
 func (c *UniversityController) GetAll() {
-	language := c.Ctx.Input.Header("lang")
-	if language != "ru" && language != "kz" {
-		c.CustomAbort(http.StatusBadRequest, "Invalid or unsupported language")
-		return
-	}
+	language := requestLocale(c.Ctx)
 
 	page, err := c.GetInt("page", 1)
 	if err != nil || page < 1 {
@@ -259,19 +264,27 @@ func (c *UniversityController) GetAll() {
 		return
 	}
 
-	// Передаем контекст в метод модели
-	universities, totalCount, totalPage, currentPage, err := models.GetAllUniversities(c.Ctx, language, page, perPage)
-	if err == nil {
-		c.Data["json"] = map[string]interface{}{
-			"universities": universities,
-			"total_count":  totalCount,
-			"total_page":   totalPage,
-			"current_page": currentPage,
-		}
-	} else {
+	userId, ok := c.Ctx.Input.GetData("user_id").(int)
+	if !ok {
+		c.Data["json"] = "failed to retrieve user_id from context"
+		c.ServeJSON()
+		return
+	}
+
+	universities, totalCount, totalPage, currentPage, err := c.Universities.List(userId, language, page, perPage)
+	if err != nil {
 		c.Data["json"] = err.Error()
+		c.ServeJSON()
+		return
 	}
-	c.ServeJSON()
+
+	c.Data["json"] = map[string]interface{}{
+		"universities": universities,
+		"total_count":  totalCount,
+		"total_page":   totalPage,
+		"current_page": currentPage,
+	}
+	serveConditionalJSON(&c.Controller)
 }
 
 // GetAllForAdmin возвращает список всех университетов.
@@ -281,7 +294,7 @@ func (c *UniversityController) GetAll() {
 // @Failure 400 ошибка получения списка или другая ошибка
 // @router / [get]
 func (c *UniversityController) GetAllForAdmin() {
-	universities, err := models.GetAllUniversitiesForAdmin()
+	universities, err := c.Universities.ListForAdmin()
 	if err == nil {
 		c.Data["json"] = universities
 	} else {
@@ -367,7 +380,7 @@ func (c *UniversityController) Update() {
 		}
 	}
 
-	university, err := models.GetUniversityByID(universityId)
+	university, err := c.Universities.Get(universityId)
 	if err != nil {
 		c.Data["json"] = map[string]string{"error": "University not found: " + err.Error()}
 		c.Ctx.Output.SetStatus(404)
@@ -417,6 +430,12 @@ func (c *UniversityController) Update() {
 	if partialResponse.AddressLink != "" {
 		university.AddressLink = partialResponse.AddressLink
 	}
+	if partialResponse.Latitude != 0 {
+		university.Latitude = partialResponse.Latitude
+	}
+	if partialResponse.Longitude != 0 {
+		university.Longitude = partialResponse.Longitude
+	}
 	if partialResponse.DescriptionRu != "" {
 		university.DescriptionRu = partialResponse.DescriptionRu
 	}
@@ -426,9 +445,6 @@ func (c *UniversityController) Update() {
 	if partialResponse.Rating != "" {
 		university.Rating = partialResponse.Rating
 	}
-	if partialResponse.MinScore != 0 {
-		university.MinEntryScore = partialResponse.MinScore
-	}
 	if partialResponse.CityId != 0 {
 		university.City.Id = partialResponse.CityId
 	}
@@ -440,7 +456,7 @@ func (c *UniversityController) Update() {
 	if err == nil {
 		defer file.Close()
 		mainImagePath := fmt.Sprintf("Universities/%d/%s", universityId, header.Filename)
-		uploadedMainImageURL, err := models.UploadFileToCloud(mainImagePath, file)
+		uploadedMainImageURL, err := c.Storage.Upload(c.Ctx.Request.Context(), mainImagePath, file)
 		if err != nil {
 			c.Data["json"] = map[string]string{"error": "Failed to upload main image: " + err.Error()}
 			c.Ctx.Output.SetStatus(500)
@@ -465,7 +481,7 @@ func (c *UniversityController) Update() {
 			defer galleryFile.Close()
 
 			galleryFilePath := fmt.Sprintf("Universities/%d/Gallery/%s", universityId, galleryFileHeader.Filename)
-			uploadedGalleryURL, err := models.UploadFileToCloud(galleryFilePath, galleryFile)
+			uploadedGalleryURL, err := c.Storage.Upload(c.Ctx.Request.Context(), galleryFilePath, galleryFile)
 			if err != nil {
 				c.Data["json"] = map[string]string{"error": "Failed to upload gallery image: " + err.Error()}
 				c.Ctx.Output.SetStatus(500)
@@ -475,7 +491,7 @@ func (c *UniversityController) Update() {
 
 			galleryURLs = append(galleryURLs, uploadedGalleryURL)
 		}
-		if err := models.UpdateUniversityGallery(universityId, galleryURLs); err != nil {
+		if err := c.Universities.MergeGallery(universityId, galleryURLs); err != nil {
 			c.Data["json"] = map[string]string{"error": "Failed to update gallery images: " + err.Error()}
 			c.Ctx.Output.SetStatus(500)
 			c.ServeJSON()
@@ -487,7 +503,7 @@ func (c *UniversityController) Update() {
 	if len(serviceIds) > 0 {
 		var services []*models.Service
 		for _, serviceID := range serviceIds {
-			service, err := models.GetServiceByID(serviceID)
+			service, err := c.Services.GetByID(serviceID)
 			if err != nil {
 				c.Data["json"] = map[string]string{"error": "Service not found: " + err.Error()}
 				c.Ctx.Output.SetStatus(404)
@@ -497,7 +513,7 @@ func (c *UniversityController) Update() {
 			services = append(services, service)
 		}
 
-		if err := models.UpdateUniversityServices(universityId, services); err != nil {
+		if err := c.Universities.ReplaceServices(universityId, services); err != nil {
 			c.Data["json"] = map[string]string{"error": "Failed to update university services: " + err.Error()}
 			c.Ctx.Output.SetStatus(500)
 			c.ServeJSON()
@@ -505,7 +521,7 @@ func (c *UniversityController) Update() {
 		}
 	}
 
-	if err := models.UpdateUniversity(university); err != nil {
+	if err := c.Universities.Update(university); err != nil {
 		c.Data["json"] = map[string]string{"error": "Failed to update university: " + err.Error()}
 		c.Ctx.Output.SetStatus(500)
 		c.ServeJSON()
@@ -526,7 +542,7 @@ func (c *UniversityController) Update() {
 // @router /:id [delete]
 func (c *UniversityController) Delete() {
 	id, _ := c.GetInt(":id")
-	err := models.DeleteUniversity(id)
+	err := c.Universities.Delete(c.Ctx.Request.Context(), id)
 	if err == nil {
 		c.Data["json"] = "Delete successful"
 	} else {
@@ -546,7 +562,7 @@ func (c *UniversityController) Delete() {
 func (c *UniversityController) AssignCityToUniversity() {
 	universityId, _ := c.GetInt(":universityId")
 	cityId, _ := c.GetInt(":cityId")
-	err := models.AssignCityToUniversity(universityId, cityId)
+	err := c.Universities.AssignCity(universityId, cityId)
 	if err == nil {
 		c.Data["json"] = "City successfully assigned"
 	} else {
@@ -567,7 +583,7 @@ func (c *UniversityController) AddSpecialityToUniversity() {
 	universityId, _ := c.GetInt(":universityId")
 	specialityId, _ := c.GetInt(":specialityId")
 
-	err := models.AddSpecialityToUniversity(specialityId, universityId)
+	err := c.Universities.AddSpeciality(c.Ctx.Request.Context(), universityId, specialityId)
 	if err == nil {
 		c.Data["json"] = "Speciality added to university successfully"
 	} else {
@@ -594,7 +610,7 @@ func (c *UniversityController) AddSpecialitiesToUniversity() {
 		return
 	}
 
-	err := models.AddSpecialitiesToUniversity(specialityIds, universityId)
+	err := c.Universities.AddSpecialities(c.Ctx.Request.Context(), universityId, specialityIds)
 	if err == nil {
 		c.Data["json"] = "Specialities added to university successfully"
 	} else {
@@ -621,7 +637,7 @@ func (c *UniversityController) AddServicesToUniversity() {
 		return
 	}
 
-	err := models.AddServicesToUniversity(serviceIds, universityId)
+	err := c.Universities.AddServices(c.Ctx.Request.Context(), universityId, serviceIds)
 	if err == nil {
 		c.Data["json"] = "Services added to university successfully"
 	} else {
@@ -636,11 +652,15 @@ func (c *UniversityController) AddServicesToUniversity() {
 // @Param	min_score			query	int		false	"Минимальный балл"
 // @Param	avg_fee				query	int		false	"Средняя цена"
 // @Param	city_id				query	int		false	"ID города"
+// @Param	region_id			query	int		false	"ID области"
+// @Param	lat					query	float64	false	"Широта точки, от которой считается расстояние"
+// @Param	lng					query	float64	false	"Долгота точки, от которой считается расстояние"
+// @Param	radius_km			query	float64	false	"Радиус поиска в километрах (требует lat и lng)"
 // @Param	speciality_ids		query	string	false	"Список специальностей в JSON формате, должны передавать массив с id специальнотей"
 // @Param	service_ids			query	string	false	"Список сервисов в JSON формате, должны передавать массив с id сервисов"
 // @Param	first_subject_id	query	int		false	"ID первого предмета"
 // @Param	second_subject_id	query	int		false	"ID второго предмета"
-// @Param	sort    			query   string  false  "Sort parameter (avg_fee_asc or avg_fee_desc)"
+// @Param	sort    			query   string  false  "Sort parameter (name_asc, name_desc, distance_asc, rating_desc or rating_asc)"
 // @Param  name                query   string  false  "Название университета или его часть"
 // @Param  study_format        query   string  false  "Формат обучения (full_time, part_time, etc.)"
 // @Param  page                query   int     false  "Номер страницы"
@@ -649,13 +669,71 @@ func (c *UniversityController) AddServicesToUniversity() {
 // @Failure 400 {string} string "400 ошибка поиска или другая ошибка"
 // @router /search [get]
 func (c *UniversityController) SearchUniversities() {
-	language := c.Ctx.Input.Header("lang")
-	if language != "ru" && language != "kz" {
-		c.CustomAbort(http.StatusBadRequest, "Invalid or unsupported language")
+	language := requestLocale(c.Ctx)
+
+	params := c.searchParams()
+	slog.DebugContext(c.Ctx.Request.Context(), "University search", "params", params)
+
+	result, err := c.Universities.Search(c.Ctx.Request.Context(), params, language)
+	if err == nil {
+		c.Data["json"] = result
+	} else {
+		c.Data["json"] = err.Error()
+	}
+	c.ServeJSON()
+}
+
+// Map возвращает университеты и их корпуса на карте в формате GeoJSON.
+// @Title Map
+// @Description Университеты и корпуса в виде GeoJSON FeatureCollection. Принимает те же фильтры, что и /search. При zoom меньше 11 близкие точки объединяются в кластеры.
+// @Param	bbox	query	string	false	"Видимая область карты: minLng,minLat,maxLng,maxLat"
+// @Param	zoom	query	int		false	"Уровень масштаба карты (0-22); без параметра кластеризация не выполняется"
+// @Param	lang	header	string	true	"Язык для получения данных, 'ru' или 'kz'"
+// @Success 200 {object} models.GeoJSONFeatureCollection
+// @Failure 400 {string} string "400 ошибка поиска или другая ошибка"
+// @router /map [get]
+func (c *UniversityController) Map() {
+	language := requestLocale(c.Ctx)
+
+	params := c.searchParams()
+
+	var bbox *models.BoundingBox
+	if value := c.GetString("bbox"); value != "" {
+		box, err := models.ParseBoundingBox(value)
+		if err != nil {
+			c.CustomAbort(http.StatusBadRequest, err.Error())
+			return
+		}
+		bbox = box
+	}
+
+	zoom, err := c.GetInt("zoom", -1)
+	if err != nil || zoom > 22 {
+		c.CustomAbort(http.StatusBadRequest, "Invalid zoom")
 		return
 	}
+
+	userId, _ := c.Ctx.Input.GetData("user_id").(int)
+
+	collection, err := c.Universities.Map(c.Ctx.Request.Context(), params, language, userId, bbox, zoom)
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.Data["json"] = collection
+	c.ServeJSON()
+}
+
+// searchParams собирает фильтры поиска университетов из query-параметров.
+// Используется в SearchUniversities и Map, чтобы фильтры совпадали.
+func (c *UniversityController) searchParams() map[string]interface{} {
 	params := make(map[string]interface{})
 	if minScore, err := c.GetInt("min_score"); err == nil {
+		if err := models.ValidateTotalScore("min_score", minScore); err != nil {
+			c.CustomAbort(http.StatusBadRequest, err.Error())
+			return nil
+		}
 		params["min_score"] = minScore
 	}
 	if avgFee, err := c.GetInt("avg_fee"); err == nil {
@@ -664,6 +742,30 @@ func (c *UniversityController) SearchUniversities() {
 	if cityID, err := c.GetInt("city_id"); err == nil {
 		params["city_id"] = cityID
 	}
+	if regionID, err := c.GetInt("region_id"); err == nil {
+		params["region_id"] = regionID
+	}
+	if lat, err := c.GetFloat("lat"); err == nil {
+		params["lat"] = lat
+	}
+	if lng, err := c.GetFloat("lng"); err == nil {
+		params["lng"] = lng
+	}
+	if radius, err := c.GetFloat("radius_km"); err == nil {
+		if radius <= 0 {
+			c.CustomAbort(http.StatusBadRequest, "radius_km must be positive")
+			return nil
+		}
+		params["radius_km"] = radius
+	}
+	if _, hasRadius := params["radius_km"]; hasRadius {
+		_, hasLat := params["lat"]
+		_, hasLng := params["lng"]
+		if !hasLat || !hasLng {
+			c.CustomAbort(http.StatusBadRequest, "radius_km requires lat and lng")
+			return nil
+		}
+	}
 	if specialityIDsStr := c.GetString("speciality_ids"); specialityIDsStr != "" {
 		var specialityIDs []int
 		err := json.Unmarshal([]byte(specialityIDsStr), &specialityIDs)
@@ -677,7 +779,7 @@ func (c *UniversityController) SearchUniversities() {
 		if err == nil {
 			params["service_ids"] = serviceIDs
 		} else {
-			log.Printf("Error unmarshaling service_ids: %v", err)
+			slog.WarnContext(c.Ctx.Request.Context(), "Invalid service_ids", "value", serviceIDsStr, "error", err)
 		}
 	}
 	if firstSubjectID, err := c.GetInt("first_subject_id"); err == nil {
@@ -686,7 +788,7 @@ func (c *UniversityController) SearchUniversities() {
 	if secondSubjectID, err := c.GetInt("second_subject_id"); err == nil {
 		params["second_subject_id"] = secondSubjectID
 	}
-	if sort := c.GetString("sort"); sort == "name_asc" || sort == "name_desc" {
+	if sort := c.GetString("sort"); sort == "name_asc" || sort == "name_desc" || sort == "distance_asc" || sort == "rating_desc" || sort == "rating_asc" {
 		params["sort"] = sort
 	}
 	if name := c.GetString("name"); name != "" {
@@ -709,15 +811,7 @@ func (c *UniversityController) SearchUniversities() {
 		params["term"] = term
 	}
 
-	log.Printf("Received parameters map: %+v", params)
-
-	result, err := models.SearchUniversities(params, language)
-	if err == nil {
-		c.Data["json"] = result
-	} else {
-		c.Data["json"] = err.Error()
-	}
-	c.ServeJSON()
+	return params
 }
 
 // DeleteSpecialityFromUniversity
@@ -728,14 +822,13 @@ func (c *UniversityController) SearchUniversities() {
 // @Success 200 {string} "Success"
 // @Failure 400 {string} "Invalid input"
 // @Failure 404 {string} "Not Found"
-// @router /deletespec/:universityId/:speciality_id [delete]
+// @router /deletespec/:university_id/:speciality_id [delete]
 func (c *UniversityController) DeleteSpecialityFromUniversity() {
 	universityIDStr := c.Ctx.Input.Param(":university_id")
 	specialityIDStr := c.Ctx.Input.Param(":speciality_id")
 
 	universityID, err := strconv.Atoi(universityIDStr)
 	if err != nil {
-		fmt.Println(universityID)
 		c.CustomAbort(http.StatusBadRequest, "Invalid university ID")
 		return
 	}
@@ -746,23 +839,28 @@ func (c *UniversityController) DeleteSpecialityFromUniversity() {
 		return
 	}
 
-	o := orm.NewOrm()
-
-	sql := `DELETE FROM speciality_university WHERE university_id = ? AND speciality_id = ?`
-	res, err := o.Raw(sql, universityID, specialityID).Exec()
-	if err != nil {
+	if err := c.Universities.RemoveSpeciality(c.Ctx.Request.Context(), universityID, specialityID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.CustomAbort(http.StatusNotFound, "No relation found between university and speciality")
+			return
+		}
 		c.CustomAbort(http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	num, err := res.RowsAffected()
-	if err != nil {
-		c.CustomAbort(http.StatusInternalServerError, err.Error())
-		return
-	}
+	c.Data["json"] = "Success"
+	c.ServeJSON()
+}
 
-	if num == 0 {
-		c.CustomAbort(http.StatusNotFound, "No relation found between university and speciality")
+// RefreshStats пересчитывает минимальный балл и стоимость обучения всех университетов по PointStat.
+// @Title RefreshStats
+// @Description Пересчитывает MinEntryScore, AverageFee и диапазоны баллов/стоимости для всех университетов
+// @Success 200 {string} "Success"
+// @Failure 500 {string} "Internal Server Error"
+// @router /refreshstats [put]
+func (c *UniversityController) RefreshStats() {
+	if err := c.Universities.RefreshAllStats(c.Ctx.Request.Context()); err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
 		return
 	}
 
@@ -793,15 +891,11 @@ func (c *UniversityController) DeleteGalleryPhoto() {
 		return
 	}
 
-	o := orm.NewOrm()
-
-	university := &models.University{Id: uniId}
-	if err := o.Read(university); err != nil {
-		c.CustomAbort(404, "University not found")
-		return
-	}
-
-	if err := university.RemoveGalleryPhoto(photoId); err != nil {
+	if err := c.Universities.RemoveGalleryPhoto(uniId, photoId); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			c.CustomAbort(404, "University not found")
+			return
+		}
 		c.CustomAbort(500, err.Error())
 		return
 	}
@@ -818,12 +912,9 @@ func (c *UniversityController) DeleteGalleryPhoto() {
 // @Failure 500 {object} map[string]string "Internal Server Error with an error message."
 // @Router /universities/names [get]
 func (c *UniversityController) GetUniNames() {
-	lang := c.Ctx.Input.Header("lang")
-	if lang == "" {
-		lang = "kz"
-	}
+	lang := requestLocale(c.Ctx)
 
-	unis, err := models.GetUniversityNames(lang)
+	unis, err := c.Universities.Names(lang)
 	if err != nil {
 		c.Ctx.Output.SetStatus(http.StatusInternalServerError)
 		c.Data["json"] = map[string]string{"error": err.Error()}
@@ -857,11 +948,11 @@ func (c *UniversityController) AddFavoriteUniversity() {
 		return
 	}
 
-	err = models.AddFavoriteUniversity(userId, universityId)
+	err = c.Favorites.Add(userId, universityId)
 	if err != nil {
 		c.Ctx.Output.SetStatus(http.StatusInternalServerError)
 		c.Ctx.Output.JSON(map[string]string{"error": "Failed to add favorite university"}, true, true)
-		fmt.Println(err)
+		slog.ErrorContext(c.Ctx.Request.Context(), "Failed to add favorite university", "university_id", universityId, "error", err)
 		return
 	}
 
@@ -877,7 +968,10 @@ func (c *UniversityController) RemoveFavoriteUniversity() {
 		return
 	}
 
-	universityIdStr := c.Ctx.Input.Query("universityId")
+	universityIdStr := c.Ctx.Input.Param(":universityId")
+	if universityIdStr == "" {
+		universityIdStr = c.Ctx.Input.Query("universityId")
+	}
 	if universityIdStr == "" {
 		c.Ctx.Output.SetStatus(http.StatusBadRequest)
 		c.Ctx.Output.JSON(map[string]string{"error": "Missing universityId parameter"}, true, true)
@@ -891,7 +985,7 @@ func (c *UniversityController) RemoveFavoriteUniversity() {
 		return
 	}
 
-	err = models.RemoveFavoriteUniversity(userId, universityId)
+	err = c.Favorites.Remove(userId, universityId)
 	if err != nil {
 		c.Ctx.Output.SetStatus(http.StatusInternalServerError)
 		c.Ctx.Output.JSON(map[string]string{"error": "Failed to remove favorite university"}, true, true)
@@ -903,50 +997,16 @@ func (c *UniversityController) RemoveFavoriteUniversity() {
 }
 
 func (c *UniversityController) ListFavoriteUniversities() {
-	language := c.Ctx.Input.Header("lang")
-	if language != "ru" && language != "kz" {
-		c.CustomAbort(http.StatusBadRequest, "Invalid or unsupported language")
-		return
-	}
+	language := requestLocale(c.Ctx)
 
 	userId := c.Ctx.Input.GetData("user_id").(int)
 
-	universities, err := models.ListFavoriteUniversities(userId)
+	responses, err := c.Favorites.List(userId, language)
 	if err != nil {
 		c.Ctx.Output.SetStatus(http.StatusInternalServerError)
 		c.Ctx.Output.JSON(map[string]string{"error": "Failed to retrieve favorite universities"}, true, true)
-		fmt.Println(err)
-		return
-	}
-
-	var responses []*models.GetAllUniversityResponse
-	for _, university := range universities {
-		switch language {
-		case "ru":
-			university.Name = university.NameRu
-			university.UniversityStatus = university.UniversityStatusRu
-		case "kz":
-			university.Name = university.NameKz
-			university.UniversityStatus = university.UniversityStatusKz
-		default:
-			university.Name = university.NameKz
-			university.UniversityStatus = university.UniversityStatusKz
-		}
-
-		response := &models.GetAllUniversityResponse{
-			Id:               university.Id,
-			Name:             university.Name,
-			ImageUrl:         university.MainImageUrl,
-			Address:          university.Address,
-			UniversityCode:   university.UniversityCode,
-			SpecialityCount:  len(university.Specialities),
-			UniversityStatus: university.UniversityStatus,
-			MinScore:         university.MinEntryScore,
-			Rating:           university.Rating,
-			Favorite:         true,
-		}
-
-		responses = append(responses, response)
+		slog.ErrorContext(c.Ctx.Request.Context(), "Failed to retrieve favorite universities", "error", err)
+		return
 	}
 
 	c.Ctx.Output.SetStatus(http.StatusOK)
@@ -0,0 +1,372 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"testhub-spec-uni/models"
+
+	beego "github.com/beego/beego/v2/server/web"
+	"github.com/go-playground/validator/v10"
+)
+
+// UniversityQAController обрабатывает вопросы и ответы на страницах университетов.
+type UniversityQAController struct {
+	beego.Controller
+}
+
+func (c *UniversityQAController) userId() (int, bool) {
+	userId, ok := c.Ctx.Input.GetData("user_id").(int)
+	if !ok {
+		c.CustomAbort(http.StatusBadRequest, "Invalid user_id")
+	}
+	return userId, ok
+}
+
+func (c *UniversityQAController) parseAndValidate(form interface{}) bool {
+	if err := c.ParseForm(form); err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid form data")
+		return false
+	}
+	if err := validator.New().Struct(form); err != nil {
+		errMap := make(map[string]string)
+		for _, err := range err.(validator.ValidationErrors) {
+			errMap[err.Field()] = fmt.Sprintf("Validation failed on the '%s' tag", err.Tag())
+		}
+		c.Ctx.Output.SetStatus(http.StatusBadRequest)
+		c.Data["json"] = errMap
+		c.ServeJSON()
+		return false
+	}
+	return true
+}
+
+func (c *UniversityQAController) serveCreated(id int64, err error) {
+	if err != nil {
+		c.Ctx.Output.SetStatus(http.StatusBadRequest)
+		c.Data["json"] = map[string]string{"error": err.Error()}
+	} else {
+		c.Data["json"] = map[string]int64{"id": id}
+	}
+	c.ServeJSON()
+}
+
+// GetByUniversity возвращает опубликованные вопросы университета с ответами.
+// @Title GetByUniversity
+// @Description Вопросы и ответы университета, упорядоченные по числу голосов.
+// @Param	universityId	path	int	true	"ID университета"
+// @Success 200 {array} models.UniversityQuestionResponse
+// @Failure 400 {string} string "Invalid university ID"
+// @router /byuni/:universityId [get]
+func (c *UniversityQAController) GetByUniversity() {
+	universityId, err := c.GetInt(":universityId")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid university ID")
+		return
+	}
+
+	questions, err := models.GetUniversityQuestions(universityId)
+	if err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = questions
+	c.ServeJSON()
+}
+
+// AskQuestion добавляет вопрос от текущего пользователя.
+// @Title AskQuestion
+// @Description Вопрос об университете. Вопросы со стоп-словами уходят на модерацию.
+// @Param   UniversityId  formData int    true "ID университета"
+// @Param   Text          formData string true "Текст вопроса"
+// @Success 200 {object} map[string]int64 "ID созданного вопроса"
+// @Failure 400 {object} map[string]string "Error message"
+// @router /qa/questions [post]
+func (c *UniversityQAController) AskQuestion() {
+	userId, ok := c.userId()
+	if !ok {
+		return
+	}
+
+	var form models.AddUniversityQuestionResponse
+	if !c.parseAndValidate(&form) {
+		return
+	}
+
+	c.serveCreated(models.AddUniversityQuestion(userId, &form))
+}
+
+// AnswerAsRepresentative добавляет ответ от представителя университета.
+// @Title AnswerAsRepresentative
+// @Description Ответ на вопрос. Доступен только представителям университета, к которому относится вопрос.
+// @Param	id		path		int		true	"ID вопроса"
+// @Param   Text	formData	string	true	"Текст ответа"
+// @Success 200 {object} map[string]int64 "ID созданного ответа"
+// @Failure 400 {object} map[string]string "Error message"
+// @router /qa/questions/:id/answers [post]
+func (c *UniversityQAController) AnswerAsRepresentative() {
+	c.answer(models.AnswerAuthorRepresentative)
+}
+
+// AnswerAsAdmin добавляет ответ от администратора.
+// @Title AnswerAsAdmin
+// @Description Ответ администратора на вопрос.
+// @Param	id		path		int		true	"ID вопроса"
+// @Param   Text	formData	string	true	"Текст ответа"
+// @Success 200 {object} map[string]int64 "ID созданного ответа"
+// @Failure 400 {object} map[string]string "Error message"
+// @router /questions/:id/answers [post]
+func (c *UniversityQAController) AnswerAsAdmin() {
+	c.answer(models.AnswerAuthorAdmin)
+}
+
+func (c *UniversityQAController) answer(role string) {
+	userId, ok := c.userId()
+	if !ok {
+		return
+	}
+	questionId, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid question ID")
+		return
+	}
+
+	var form models.AddUniversityAnswerResponse
+	if !c.parseAndValidate(&form) {
+		return
+	}
+
+	c.serveCreated(models.AddUniversityAnswer(questionId, userId, role, &form))
+}
+
+// VoteQuestion ставит или снимает голос за вопрос.
+// @Title VoteQuestion
+// @Description Повторный вызов снимает голос.
+// @Param	id	path	int	true	"ID вопроса"
+// @Success 200 {object} map[string]int "Новое число голосов"
+// @Failure 400 {string} string "Invalid question ID"
+// @router /qa/questions/:id/vote [put]
+func (c *UniversityQAController) VoteQuestion() {
+	userId, ok := c.userId()
+	if !ok {
+		return
+	}
+	id, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid question ID")
+		return
+	}
+
+	count, err := models.ToggleQuestionVote(id, userId)
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.Data["json"] = map[string]int{"votes": count}
+	c.ServeJSON()
+}
+
+// VoteAnswer ставит или снимает голос за ответ.
+// @Title VoteAnswer
+// @Description Повторный вызов снимает голос.
+// @Param	id	path	int	true	"ID ответа"
+// @Success 200 {object} map[string]int "Новое число голосов"
+// @Failure 400 {string} string "Invalid answer ID"
+// @router /qa/answers/:id/vote [put]
+func (c *UniversityQAController) VoteAnswer() {
+	userId, ok := c.userId()
+	if !ok {
+		return
+	}
+	id, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid answer ID")
+		return
+	}
+
+	count, err := models.ToggleAnswerVote(id, userId)
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.Data["json"] = map[string]int{"votes": count}
+	c.ServeJSON()
+}
+
+// GetQueue возвращает вопросы и ответы, ожидающие модерации.
+// @Title GetQueue
+// @Description Очередь модерации вопросов и ответов.
+// @Success 200 {object} models.QAModerationQueue
+// @Failure 500 {string} string "Internal Server Error"
+// @router /queue [get]
+func (c *UniversityQAController) GetQueue() {
+	queue, err := models.GetQAModerationQueue()
+	if err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = queue
+	c.ServeJSON()
+}
+
+// SetQuestionStatus публикует или скрывает вопрос.
+// @Title SetQuestionStatus
+// @Description Смена статуса вопроса: published, hidden или pending.
+// @Param	id		path	int		true	"ID вопроса"
+// @Param	status	path	string	true	"Новый статус"
+// @Success 200 {string} "Status updated"
+// @Failure 400 {string} string "Invalid input"
+// @router /questions/:id/status/:status [put]
+func (c *UniversityQAController) SetQuestionStatus() {
+	id, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid question ID")
+		return
+	}
+
+	if err := models.SetQuestionStatus(id, c.GetString(":status")); err != nil {
+		c.CustomAbort(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.Data["json"] = "Status updated"
+	c.ServeJSON()
+}
+
+// SetAnswerStatus публикует или скрывает ответ.
+// @Title SetAnswerStatus
+// @Description Смена статуса ответа: published, hidden или pending.
+// @Param	id		path	int		true	"ID ответа"
+// @Param	status	path	string	true	"Новый статус"
+// @Success 200 {string} "Status updated"
+// @Failure 400 {string} string "Invalid input"
+// @router /answers/:id/status/:status [put]
+func (c *UniversityQAController) SetAnswerStatus() {
+	id, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid answer ID")
+		return
+	}
+
+	if err := models.SetAnswerStatus(id, c.GetString(":status")); err != nil {
+		c.CustomAbort(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.Data["json"] = "Status updated"
+	c.ServeJSON()
+}
+
+// DeleteQuestion удаляет вопрос вместе с ответами.
+// @Title DeleteQuestion
+// @Description Удаление вопроса по ID.
+// @Param	id	path	int	true	"ID вопроса"
+// @Success 200 {string} "Delete successful"
+// @Failure 400 {string} string "Invalid question ID"
+// @router /questions/:id [delete]
+func (c *UniversityQAController) DeleteQuestion() {
+	id, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid question ID")
+		return
+	}
+
+	if err := models.DeleteUniversityQuestion(id); err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = "Delete successful"
+	c.ServeJSON()
+}
+
+// DeleteAnswer удаляет ответ.
+// @Title DeleteAnswer
+// @Description Удаление ответа по ID.
+// @Param	id	path	int	true	"ID ответа"
+// @Success 200 {string} "Delete successful"
+// @Failure 400 {string} string "Invalid answer ID"
+// @router /answers/:id [delete]
+func (c *UniversityQAController) DeleteAnswer() {
+	id, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid answer ID")
+		return
+	}
+
+	if err := models.DeleteUniversityAnswer(id); err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = "Delete successful"
+	c.ServeJSON()
+}
+
+// AddRepresentative назначает пользователя представителем университета.
+// @Title AddRepresentative
+// @Description Назначение представителя университета.
+// @Param   UniversityId  formData int    true  "ID университета"
+// @Param   UserId        formData int    true  "ID пользователя"
+// @Param   Position      formData string false "Должность"
+// @Success 200 {object} map[string]int64 "ID записи"
+// @Failure 400 {object} map[string]string "Error message"
+// @router /representatives [post]
+func (c *UniversityQAController) AddRepresentative() {
+	var form models.AddUniversityRepresentativeResponse
+	if !c.parseAndValidate(&form) {
+		return
+	}
+
+	c.serveCreated(models.AddUniversityRepresentative(&form))
+}
+
+// GetRepresentatives возвращает представителей университета.
+// @Title GetRepresentatives
+// @Description Список представителей университета.
+// @Param	universityId	path	int	true	"ID университета"
+// @Success 200 {array} models.UniversityRepresentative
+// @Failure 400 {string} string "Invalid university ID"
+// @router /representatives/byuni/:universityId [get]
+func (c *UniversityQAController) GetRepresentatives() {
+	universityId, err := c.GetInt(":universityId")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid university ID")
+		return
+	}
+
+	representatives, err := models.GetUniversityRepresentatives(universityId)
+	if err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = representatives
+	c.ServeJSON()
+}
+
+// DeleteRepresentative отзывает права представителя.
+// @Title DeleteRepresentative
+// @Description Удаление представителя по ID записи.
+// @Param	id	path	int	true	"ID записи"
+// @Success 200 {string} "Delete successful"
+// @Failure 400 {string} string "Invalid representative ID"
+// @router /representatives/:id [delete]
+func (c *UniversityQAController) DeleteRepresentative() {
+	id, err := c.GetInt(":id")
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid representative ID")
+		return
+	}
+
+	if err := models.DeleteUniversityRepresentative(id); err != nil {
+		c.CustomAbort(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data["json"] = "Delete successful"
+	c.ServeJSON()
+}
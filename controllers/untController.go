@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testhub-spec-uni/models"
+
+	beego "github.com/beego/beego/v2/server/web"
+)
+
+// UntController обрабатывает запросы, связанные со структурой баллов ЕНТ.
+type UntController struct {
+	beego.Controller
+}
+
+// Evaluate проверяет баллы по блокам ЕНТ и пороговые значения предметов.
+// @Title Evaluate
+// @Description Проверка баллов ЕНТ: история Казахстана (до 20, порог 5), грамотность чтения (до 10, порог 3), математическая грамотность (до 10, порог 3) и два профильных предмета из пары предметов (по умолчанию до 50, порог 5).
+// @Param	body	body	models.UntScore	true	"Баллы по блокам и ID пары профильных предметов"
+// @Success 200 {object} models.UntEvaluation
+// @Failure 400 {string} string "Invalid input"
+// @router /evaluate [post]
+func (c *UntController) Evaluate() {
+	body := c.Ctx.Input.CopyBody(1024)
+
+	var score models.UntScore
+	if err := json.Unmarshal(body, &score); err != nil {
+		c.CustomAbort(http.StatusBadRequest, "Invalid JSON: "+err.Error())
+		return
+	}
+
+	evaluation, err := models.EvaluateUntScore(&score)
+	if err != nil {
+		c.CustomAbort(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.Data["json"] = evaluation
+	c.ServeJSON()
+}
@@ -0,0 +1,60 @@
+// Package dbtest поднимает для тестов временную базу SQLite со всеми миграциями
+// и регистрирует её в ORM как базу по умолчанию. Так тесты репозиториев и HTTP
+// проходят через те же функции models, что и сервис.
+package dbtest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testhub-spec-uni/cache"
+	"testhub-spec-uni/dialect"
+	"testhub-spec-uni/migrations"
+	"testing"
+
+	"github.com/astaxie/beego/orm"
+)
+
+// Run создаёт базу, запускает тесты m и удаляет базу; результат передаётся в
+// os.Exit из TestMain. before выполняется после миграций, до тестов, и
+// заполняет базу общими записями; может быть nil.
+func Run(m *testing.M, before func() error) int {
+	dir, err := os.MkdirTemp("", "dbtest")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer os.RemoveAll(dir)
+
+	if err := open(filepath.Join(dir, "test.db")); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if before != nil {
+		if err := before(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	return m.Run()
+}
+
+func open(path string) error {
+	// Записи меняются в обход HTTP-фильтра, который сбрасывает кэш.
+	cache.SetBackend(nil)
+	if err := dialect.Configure(dialect.SQLite); err != nil {
+		return err
+	}
+	if err := orm.RegisterDriver(dialect.SQLiteDriver, orm.DRSqlite); err != nil {
+		return err
+	}
+	if err := orm.RegisterDataBase("default", dialect.SQLiteDriver, dialect.SQLiteDataSource(path)); err != nil {
+		return err
+	}
+	db, err := orm.GetDB("default")
+	if err != nil {
+		return err
+	}
+	_, err = migrations.Up(db, dialect.Current())
+	return err
+}
@@ -0,0 +1,112 @@
+// Package dialect прячет различия SQL между Postgres и SQLite для запросов,
+// которые пишутся вручную. Запросы через ORM и Raw с плейсхолдерами ? ORM
+// переводит сам; сюда попадает только то, что он не умеет.
+package dialect
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// Postgres — основная база, значение db_driver "postgres".
+	Postgres = "postgres"
+	// SQLite — локальный режим без внешней базы, значение db_driver "sqlite3".
+	SQLite = "sqlite3"
+)
+
+// Dialect — фрагменты SQL, которые по-разному пишутся в разных базах.
+type Dialect interface {
+	// Name — имя диалекта: Postgres или SQLite.
+	Name() string
+	// Placeholder — n-й (с единицы) параметр запроса через database/sql без ORM.
+	Placeholder(n int) string
+	// ILike — условие «column содержит параметр ? без учёта регистра».
+	ILike(column string) string
+	// JSONObject — JSON-объект из пар ключ/выражение: 'id', t.id, ...
+	JSONObject(pairs ...string) string
+	// JSONArrayAgg — агрегат, собирающий value строк группы, для которых выполнено
+	// filter, в JSON-массив; '[]', если таких строк нет.
+	JSONArrayAgg(value, filter string) string
+	// Table — таблица name из схемы schema.
+	Table(schema, name string) string
+	// LockTable — команда, блокирующая таблицу на запись до конца транзакции,
+	// или пустая строка, если база блокирует запись целиком.
+	LockTable(table string) string
+}
+
+var current Dialect = postgres{}
+
+// ForDriver возвращает диалект для значения db_driver.
+func ForDriver(driver string) (Dialect, error) {
+	switch driver {
+	case Postgres, "":
+		return postgres{}, nil
+	case SQLite:
+		return sqlite{}, nil
+	}
+	return nil, fmt.Errorf("unsupported db driver %q", driver)
+}
+
+// Configure выбирает диалект, который возвращает Current.
+func Configure(driver string) error {
+	d, err := ForDriver(driver)
+	if err != nil {
+		return err
+	}
+	current = d
+	return nil
+}
+
+// Current возвращает выбранный диалект; по умолчанию Postgres.
+func Current() Dialect {
+	return current
+}
+
+type postgres struct{}
+
+func (postgres) Name() string { return Postgres }
+
+func (postgres) Placeholder(n int) string { return fmt.Sprintf("$%d", n) }
+
+func (postgres) ILike(column string) string { return column + " ILIKE ?" }
+
+func (postgres) JSONObject(pairs ...string) string {
+	return "json_build_object(" + strings.Join(pairs, ", ") + ")"
+}
+
+func (postgres) JSONArrayAgg(value, filter string) string {
+	return fmt.Sprintf("COALESCE(json_agg(%s) FILTER (WHERE %s), '[]')", value, filter)
+}
+
+func (postgres) Table(schema, name string) string { return schema + `."` + name + `"` }
+
+func (postgres) LockTable(table string) string {
+	return `LOCK TABLE "` + table + `" IN EXCLUSIVE MODE`
+}
+
+type sqlite struct{}
+
+func (sqlite) Name() string { return SQLite }
+
+func (sqlite) Placeholder(int) string { return "?" }
+
+// LOWER в SQLite понимает только ASCII, поэтому используется unicode_lower,
+// которую регистрирует драйвер SQLiteDriver.
+func (sqlite) ILike(column string) string {
+	return "unicode_lower(" + column + ") LIKE unicode_lower(?)"
+}
+
+func (sqlite) JSONObject(pairs ...string) string {
+	return "json_object(" + strings.Join(pairs, ", ") + ")"
+}
+
+// json_group_array по группе без подходящих строк сам возвращает '[]'.
+func (sqlite) JSONArrayAgg(value, filter string) string {
+	return fmt.Sprintf("json_group_array(%s) FILTER (WHERE %s)", value, filter)
+}
+
+// В SQLite нет схем: все таблицы лежат в одном файле.
+func (sqlite) Table(_, name string) string { return `"` + name + `"` }
+
+func (sqlite) LockTable(string) string { return "" }
@@ -0,0 +1,59 @@
+package dialect
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestSQLiteFragments(t *testing.T) {
+	db, err := sql.Open(SQLiteDriver, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(`
+		CREATE TABLE u (id integer PRIMARY KEY, name text);
+		CREATE TABLE s (id integer PRIMARY KEY, u_id integer, year integer);
+		INSERT INTO u VALUES (1, 'Казахский национальный университет'), (2, 'Satbayev University');
+		INSERT INTO s VALUES (1, 1, 2023), (2, 1, 2024);
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	d := sqlite{}
+	var id int
+	if err := db.QueryRow("SELECT id FROM u WHERE "+d.ILike("name"), "%НАЦИОНАЛЬНЫЙ%").Scan(&id); err != nil || id != 1 {
+		t.Fatalf("ILike did not match Cyrillic text case-insensitively: id %d, err %v", id, err)
+	}
+
+	rows, err := db.Query(`SELECT u.id, ` + d.JSONArrayAgg(d.JSONObject("'year'", "s.year"), "s.id IS NOT NULL") + `
+		FROM u LEFT JOIN s ON s.u_id = u.id GROUP BY u.id ORDER BY u.id`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	want := map[int]string{1: `[{"year":2023},{"year":2024}]`, 2: `[]`}
+	for rows.Next() {
+		var statistics string
+		if err := rows.Scan(&id, &statistics); err != nil {
+			t.Fatal(err)
+		}
+		if statistics != want[id] {
+			t.Errorf("university %d: got %s, want %s", id, statistics, want[id])
+		}
+	}
+}
+
+func TestForDriver(t *testing.T) {
+	for driver, name := range map[string]string{"": Postgres, Postgres: Postgres, SQLite: SQLite} {
+		d, err := ForDriver(driver)
+		if err != nil || d.Name() != name {
+			t.Errorf("ForDriver(%q) = %v, %v", driver, d, err)
+		}
+	}
+	if _, err := ForDriver("mysql"); err == nil {
+		t.Error("expected an error for an unsupported driver")
+	}
+}
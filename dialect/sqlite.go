@@ -0,0 +1,26 @@
+package dialect
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// SQLiteDriver — имя драйвера database/sql для SQLite с функциями, которых
+// не хватает встроенному SQLite. Регистрируется в ORM как orm.DRSqlite.
+const SQLiteDriver = "sqlite3_uni"
+
+func init() {
+	sql.Register(SQLiteDriver, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("unicode_lower", strings.ToLower, true)
+		},
+	})
+}
+
+// SQLiteDataSource — строка подключения к файлу базы path. Запись ждёт
+// освобождения блокировки вместо немедленной ошибки database is locked.
+func SQLiteDataSource(path string) string {
+	return "file:" + path + "?_busy_timeout=5000&_journal_mode=WAL&_txlock=immediate"
+}
@@ -0,0 +1,99 @@
+// Package health хранит проверки готовности сервиса и признак остановки.
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Check проверяет одну внешнюю зависимость; ошибка означает, что она недоступна.
+type Check func(ctx context.Context) error
+
+// CheckResult — результат одной проверки.
+type CheckResult struct {
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// Report — ответ /readyz.
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+const (
+	StatusOK           = "ok"
+	StatusFailed       = "failed"
+	StatusUnavailable  = "unavailable"
+	StatusShuttingDown = "shutting_down"
+)
+
+var (
+	mu           sync.RWMutex
+	checks       = map[string]Check{}
+	shuttingDown atomic.Bool
+)
+
+// Register добавляет проверку готовности под именем name.
+func Register(name string, check Check) {
+	mu.Lock()
+	defer mu.Unlock()
+	checks[name] = check
+}
+
+// SetShuttingDown помечает сервис останавливающимся: /readyz начинает отвечать
+// 503, чтобы балансировщик перестал присылать новые запросы.
+func SetShuttingDown() {
+	shuttingDown.Store(true)
+}
+
+// ShuttingDown сообщает, начата ли остановка.
+func ShuttingDown() bool {
+	return shuttingDown.Load()
+}
+
+// Ready выполняет все проверки параллельно, каждую не дольше timeout.
+func Ready(ctx context.Context, timeout time.Duration) Report {
+	if ShuttingDown() {
+		return Report{Status: StatusShuttingDown}
+	}
+
+	mu.RLock()
+	registered := make(map[string]Check, len(checks))
+	for name, check := range checks {
+		registered[name] = check
+	}
+	mu.RUnlock()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(registered))}
+	var wg sync.WaitGroup
+	var resultsMu sync.Mutex
+	for name, check := range registered {
+		wg.Add(1)
+		go func(name string, check Check) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			started := time.Now()
+			err := check(checkCtx)
+			result := CheckResult{Status: StatusOK, DurationMs: time.Since(started).Milliseconds()}
+			if err != nil {
+				result.Status = StatusFailed
+				result.Error = err.Error()
+			}
+
+			resultsMu.Lock()
+			defer resultsMu.Unlock()
+			report.Checks[name] = result
+			if err != nil {
+				report.Status = StatusUnavailable
+			}
+		}(name, check)
+	}
+	wg.Wait()
+	return report
+}
@@ -0,0 +1,36 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReady(t *testing.T) {
+	Register("database", func(ctx context.Context) error { return nil })
+	Register("storage", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	report := Ready(context.Background(), 10*time.Millisecond)
+	if report.Status != StatusUnavailable {
+		t.Fatalf("expected unavailable, got %+v", report)
+	}
+	if report.Checks["database"].Status != StatusOK || report.Checks["storage"].Error != context.DeadlineExceeded.Error() {
+		t.Fatalf("unexpected checks: %+v", report.Checks)
+	}
+
+	Register("storage", func(ctx context.Context) error { return nil })
+	if report := Ready(context.Background(), time.Second); report.Status != StatusOK {
+		t.Fatalf("expected ok, got %+v", report)
+	}
+
+	Register("auth", func(ctx context.Context) error { return errors.New("must not run") })
+	SetShuttingDown()
+	defer shuttingDown.Store(false)
+	if report := Ready(context.Background(), time.Second); report.Status != StatusShuttingDown || report.Checks != nil {
+		t.Fatalf("expected shutting_down without checks, got %+v", report)
+	}
+}
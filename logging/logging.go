@@ -0,0 +1,181 @@
+// Package logging настраивает структурированные JSON-логи сервиса поверх log/slog.
+//
+// Каждый запрос получает идентификатор из заголовка X-Request-ID или новый, если
+// заголовка нет. Записи, сделанные с контекстом запроса (slog.InfoContext и т.п.),
+// содержат request_id и, после авторизации, user_id. Токены, пароли, ключи и
+// персональные данные в значениях заменяются на ******.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testhub-spec-uni/metrics"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader — заголовок с идентификатором запроса во входящем запросе и в ответе.
+const RequestIDHeader = "X-Request-ID"
+
+const redacted = "******"
+
+// sensitiveKeys — части имён атрибутов, значения которых не попадают в лог.
+var sensitiveKeys = []string{
+	"authorization", "token", "password", "secret", "cookie", "access_key",
+	"email", "phone", "iin",
+}
+
+var (
+	bearerPattern = regexp.MustCompile(`(?i)(bearer|token)\s+[^\s"',]+`)
+	emailPattern  = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	requestIDOK   = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+)
+
+// ParseLevel разбирает уровень логирования: debug, info, warn или error.
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return level, errors.New("must be debug, info, warn or error")
+	}
+	return level, nil
+}
+
+// New создаёт JSON-логгер с уровнем level, контекстом запроса и скрытием секретов.
+func New(w io.Writer, level slog.Leveler) *slog.Logger {
+	return slog.New(contextHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redact,
+	})})
+}
+
+// Setup делает New логгером по умолчанию для slog и стандартного пакета log.
+func Setup(w io.Writer, level slog.Level) {
+	slog.SetDefault(New(w, level))
+}
+
+// requestInfo — данные запроса для записей лога. Пользователь становится
+// известен позже, в фильтре авторизации, поэтому он хранится по указателю.
+type requestInfo struct {
+	id     string
+	userID atomic.Int64
+}
+
+type contextKey struct{}
+
+// WithRequestID возвращает контекст, записи с которым содержат request_id id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, &requestInfo{id: id})
+}
+
+// RequestID возвращает идентификатор запроса из контекста или пустую строку.
+func RequestID(ctx context.Context) string {
+	if info, ok := ctx.Value(contextKey{}).(*requestInfo); ok {
+		return info.id
+	}
+	return ""
+}
+
+// SetUserID добавляет пользователя к записям, сделанным с контекстом запроса ctx.
+func SetUserID(ctx context.Context, userID int) {
+	if info, ok := ctx.Value(contextKey{}).(*requestInfo); ok {
+		info.userID.Store(int64(userID))
+	}
+}
+
+// Middleware принимает X-Request-ID клиента или создаёт новый, возвращает его в
+// ответе и пишет по одной записи на запрос с кодом ответа и длительностью.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+		id := r.Header.Get(RequestIDHeader)
+		if !requestIDOK.MatchString(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		ctx := WithRequestID(r.Context(), id)
+
+		recorder := metrics.NewStatusRecorder(w)
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		status := recorder.Status()
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		slog.Log(ctx, level, "request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", status,
+			"duration_ms", time.Since(started).Milliseconds(),
+		)
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
+
+// contextHandler дописывает к записи request_id, user_id и trace_id из контекста.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if info, ok := ctx.Value(contextKey{}).(*requestInfo); ok {
+		r.AddAttrs(slog.String("request_id", info.id))
+		if userID := info.userID.Load(); userID != 0 {
+			r.AddAttrs(slog.Int64("user_id", userID))
+		}
+	}
+	if span := trace.SpanContextFromContext(ctx); span.HasTraceID() {
+		r.AddAttrs(slog.String("trace_id", span.TraceID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// redact скрывает значения атрибутов с чувствительными именами, а в остальных
+// строках и ошибках — токены после Bearer и адреса почты.
+func redact(groups []string, a slog.Attr) slog.Attr {
+	key := strings.ToLower(a.Key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return slog.String(a.Key, redacted)
+		}
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(redactText(a.Value.String()))
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			a.Value = slog.StringValue(redactText(err.Error()))
+		}
+	}
+	return a
+}
+
+func redactText(text string) string {
+	text = bearerPattern.ReplaceAllString(text, "$1 "+redacted)
+	return emailPattern.ReplaceAllString(text, redacted)
+}
@@ -0,0 +1,111 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func records(t *testing.T, out *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var result []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("not a JSON line %q: %v", line, err)
+		}
+		result = append(result, record)
+	}
+	return result
+}
+
+func TestMiddlewareAddsRequestAndUser(t *testing.T) {
+	var out bytes.Buffer
+	defer func(previous *slog.Logger) { slog.SetDefault(previous) }(slog.Default())
+	slog.SetDefault(New(&out, slog.LevelInfo))
+
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		SetUserID(r.Context(), 42)
+		slog.InfoContext(r.Context(), "handled")
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	request := httptest.NewRequest(http.MethodPost, "/api/universities?name=x", nil)
+	request.Header.Set(RequestIDHeader, "abc-123")
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+
+	if got := response.Header().Get(RequestIDHeader); got != "abc-123" {
+		t.Fatalf("expected the client request ID to be echoed, got %q", got)
+	}
+	lines := records(t, &out)
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 records, got %d:\n%s", len(lines), out.String())
+	}
+	for _, record := range lines {
+		if record["request_id"] != "abc-123" || record["user_id"] != float64(42) {
+			t.Errorf("record without request context: %v", record)
+		}
+	}
+	if access := lines[1]; access["msg"] != "request" || access["status"] != float64(http.StatusCreated) || access["path"] != "/api/universities" {
+		t.Errorf("unexpected access record: %v", access)
+	}
+}
+
+func TestMiddlewareReplacesInvalidRequestID(t *testing.T) {
+	var out bytes.Buffer
+	defer func(previous *slog.Logger) { slog.SetDefault(previous) }(slog.Default())
+	slog.SetDefault(New(&out, slog.LevelInfo))
+
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	request.Header.Set(RequestIDHeader, "bad id\nwith newline")
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, request)
+
+	id := response.Header().Get(RequestIDHeader)
+	if len(id) != 32 {
+		t.Fatalf("expected a generated request ID, got %q", id)
+	}
+	if record := records(t, &out)[0]; record["request_id"] != id || record["user_id"] != nil {
+		t.Errorf("unexpected record: %v", record)
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	var out bytes.Buffer
+	logger := New(&out, slog.LevelDebug)
+	logger.Info("auth",
+		"authorization", "Bearer abc.def",
+		"aws_secret_key", "s3cr3t",
+		"user_email", "student@example.kz",
+		"detail", "header Bearer abc.def from student@example.kz",
+		"error", errors.New("token xyz rejected"),
+		"university_id", 7,
+	)
+
+	text := out.String()
+	for _, secret := range []string{"abc.def", "s3cr3t", "student@example.kz", "xyz"} {
+		if strings.Contains(text, secret) {
+			t.Errorf("%q leaked: %s", secret, text)
+		}
+	}
+	record := records(t, &out)[0]
+	if record["detail"] != "header Bearer ****** from ******" || record["university_id"] != float64(7) {
+		t.Errorf("unexpected record: %v", record)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	if level, err := ParseLevel("debug"); err != nil || level != slog.LevelDebug {
+		t.Errorf("debug parsed as %v, %v", level, err)
+	}
+	if _, err := ParseLevel("loud"); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+}
@@ -1,11 +1,28 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"log"
-	"testhub-spec-uni/controllers"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"testhub-spec-uni/cache"
+	"testhub-spec-uni/config"
+	"testhub-spec-uni/dialect"
+	"testhub-spec-uni/health"
+	"testhub-spec-uni/logging"
+	"testhub-spec-uni/metrics"
 	"testhub-spec-uni/middleware"
-	_ "testhub-spec-uni/routers"
+	"testhub-spec-uni/migrations"
+	"testhub-spec-uni/models"
+	"testhub-spec-uni/ratelimit"
+	"testhub-spec-uni/repository"
+	"testhub-spec-uni/routers"
+	"testhub-spec-uni/tracing"
+	"time"
 
 	"github.com/astaxie/beego/orm"
 	"github.com/beego/beego/v2/server/web"
@@ -14,72 +31,217 @@ import (
 	_ "github.com/lib/pq"
 )
 
-func init() {
-	driverName, err := web.AppConfig.String("db_driver")
-	if err != nil {
-		log.Fatalf("Failed to get 'db_driver': %v", err)
-	}
+// appConfigValue читает ключ из conf/app.conf; пустое значение считается незаданным.
+func appConfigValue(key string) (string, bool) {
+	value, err := web.AppConfig.String(key)
+	return value, err == nil && value != ""
+}
 
-	user, err := web.AppConfig.String("db_user")
+// instrumentedDriver регистрирует в ORM обёртку над драйвером base, которая
+// замеряет запросы для /metrics.
+func instrumentedDriver(base string, typ orm.DriverType) string {
+	name, err := metrics.RegisterDriver(base)
 	if err != nil {
-		log.Fatalf("Failed to get 'db_user': %v", err)
+		fatal("Failed to register database driver", "driver", base, "error", err)
 	}
+	orm.RegisterDriver(name, typ)
+	return name
+}
 
-	password, err := web.AppConfig.String("db_password")
-	if err != nil {
-		log.Fatalf("Failed to get 'db_password': %v", err)
-	}
+func registerPostgres(db config.Database) {
+	dataSource := fmt.Sprintf("user=%s password=%s host=%s port=%d dbname=%s sslmode=disable search_path=%s",
+		db.User, db.Password, db.Host, db.Port, db.Name, db.Schema)
 
-	host, err := web.AppConfig.String("db_host")
-	if err != nil {
-		log.Fatalf("Failed to get 'db_host': %v", err)
-	}
+	orm.RegisterDataBase("default", instrumentedDriver(db.Driver, orm.DRPostgres), dataSource)
+}
 
-	port, err := web.AppConfig.String("db_port")
-	if err != nil {
-		log.Fatalf("Failed to get 'db_port': %v", err)
+// registerSQLite подключает файл базы db_path и, если не выключено
+// db_auto_migrate, сразу применяет миграции, чтобы сервис поднимался на пустом
+// файле без отдельного migrate up.
+func registerSQLite(cfg config.Database) {
+	driverName := instrumentedDriver(dialect.SQLiteDriver, orm.DRSqlite)
+	if err := orm.RegisterDataBase("default", driverName, dialect.SQLiteDataSource(cfg.Path)); err != nil {
+		fatal("Failed to open SQLite database", "path", cfg.Path, "error", err)
 	}
 
-	dbName, err := web.AppConfig.String("db_name")
+	if !cfg.AutoMigrate {
+		return
+	}
+	db, err := orm.GetDB("default")
 	if err != nil {
-		log.Fatalf("Failed to get 'db_name': %v", err)
+		fatal("Failed to get database", "error", err)
 	}
+	if _, err := migrations.Up(db, dialect.Current()); err != nil {
+		fatal("Migration failed", "error", err)
+	}
+}
 
-	schema := "uni_spec,accounts"
-	dataSource := fmt.Sprintf("user=%s password=%s host=%s port=%s dbname=%s sslmode=disable search_path=%s",
-		user, password, host, port, dbName, schema)
-
-	orm.RegisterDataBase("default", driverName, dataSource)
+// fatal пишет ошибку в лог и завершает процесс.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
 
-	fmt.Println(driverName)
+// configErrors — ошибки Load по одной в элементе, чтобы в JSON они не склеивались через \n.
+func configErrors(err error) []string {
+	return strings.Split(err.Error(), "\n")
 }
 
+// ./main [-ключ=значение ...] [config print | migrate up|down|status]
 func main() {
+	// До загрузки конфигурации уровень ещё неизвестен.
+	logging.Setup(os.Stderr, slog.LevelInfo)
+
+	cfg, err := config.Load(appConfigValue, os.LookupEnv, os.Args[1:])
+	if cfg == nil {
+		fatal("Invalid command line", "error", err)
+	}
+	args := cfg.Args
+
+	// config print выводит настройки даже с ошибками, чтобы было видно, что исправить.
+	if len(args) > 0 && args[0] == "config" {
+		if len(args) != 2 || args[1] != "print" {
+			fatal("usage: config print")
+		}
+		cfg.Print(os.Stdout)
+		if err != nil {
+			fatal("Invalid configuration", "errors", configErrors(err))
+		}
+		return
+	}
+	if err != nil {
+		fatal("Invalid configuration", "errors", configErrors(err))
+	}
+	config.Set(cfg.Config)
+	middleware.ConfigureAuth(cfg.Auth)
+	level, _ := logging.ParseLevel(cfg.Log.Level)
+	logging.Setup(os.Stderr, level)
+	slog.Info("Configuration loaded", "config", cfg.Config.String())
+
+	if err := dialect.Configure(cfg.DB.Driver); err != nil {
+		fatal("Failed to configure database", "error", err)
+	}
+	if cfg.DB.Driver == dialect.SQLite {
+		registerSQLite(cfg.DB)
+	} else {
+		registerPostgres(cfg.DB)
+	}
+
+	if err := cache.Configure(cfg.Cache.Backend, cfg.Cache.Addr, cfg.Cache.TTL); err != nil {
+		fatal("Failed to configure cache", "error", err)
+	}
+	if err := ratelimit.Configure(cfg.Limits.Backend, cfg.Limits.Addr, cfg.Limits.TrustProxy); err != nil {
+		fatal("Failed to configure rate limiting", "error", err)
+	}
+
+	// migrate up|down|status — управление схемой без запуска сервера.
+	if len(args) > 0 && args[0] == "migrate" {
+		db, err := orm.GetDB("default")
+		if err != nil {
+			fatal("Failed to get database", "error", err)
+		}
+		if err := migrations.Run(db, dialect.Current(), args[1:], os.Stdout); err != nil {
+			fatal("Migration failed", "error", err)
+		}
+		return
+	}
+	if len(args) > 0 {
+		fatal("Unknown command", "command", args[0])
+	}
+
 	web.BConfig.WebConfig.DirectoryIndex = true
 	web.BConfig.WebConfig.StaticDir["/swagger"] = "swagger"
 
 	beego.BConfig.RouterCaseSensitive = false
 	beego.SetStaticPath("/swagger", "swagger")
 
-	beego.InsertFilter("/api/*", beego.BeforeRouter, middleware.AuthMiddleware)
-	beego.InsertFilter("/user/universities/*", beego.BeforeRouter, middleware.AuthMiddleware)
-
+	// CORS идёт первым: ответы 401 и 429 из следующих фильтров тоже должны
+	// содержать Access-Control-Allow-Origin, иначе браузер их не покажет.
 	beego.InsertFilter("*", beego.BeforeRouter, cors.Allow(&cors.Options{
-		AllowOrigins: []string{"http://localhost:3000", "https://admin-course.testhub.kz",
-			"https://ent.testhub.kz", "https://console.ps.kz", "https://api-dev.testhub.kz",
-			"https://dev-front.testhub.kz"},
+		AllowOrigins:     cfg.CORS.AllowOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "lang"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Accept-Language", "Authorization", "lang", "If-None-Match", "If-Modified-Since", logging.RequestIDHeader, "traceparent", "tracestate"},
+		ExposeHeaders:    []string{"Content-Length", "Content-Language", "ETag", logging.RequestIDHeader, "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy", "Retry-After"},
 		AllowCredentials: true,
 	}))
+	beego.InsertFilter("/api/*", beego.BeforeRouter, middleware.AuthMiddleware)
+	beego.InsertFilter("/user/universities/*", beego.BeforeRouter, middleware.AuthMiddleware)
+	insertRateLimitFilters(cfg.Limits)
+	// После авторизации, чтобы учитывать язык из профиля пользователя.
+	beego.InsertFilter("*", beego.BeforeRouter, middleware.LocaleMiddleware)
+	beego.InsertFilter("/api/*", beego.FinishRouter, middleware.CacheInvalidationMiddleware, beego.WithReturnOnOutput(false))
+
+	routers.Register(repository.NewORM())
+
+	health.Register("database", models.PingDatabase)
+	health.Register("auth", middleware.PingAuth)
+	// Без бакета загрузка изображений не настроена, и проверять нечего.
+	if cfg.Storage.Bucket != "" {
+		health.Register("storage", models.PingStorage)
+	}
 
-	beego.Include(&controllers.SubjectController{})
-	beego.Include(&controllers.SpecialityController{})
-	beego.Include(&controllers.UniversityController{})
-	beego.Include(&controllers.CityController{})
-	beego.Include(&controllers.QuotaController{})
+	shutdownTracing, err := tracing.Setup(cfg.Tracing.Exporter, cfg.Tracing.Endpoint, os.Stdout)
+	if err != nil {
+		fatal("Failed to configure tracing", "error", err)
+	}
 
-	beego.Run()
+	runUntilShutdown(cfg.Server.ShutdownTimeout)
 
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Warn("Failed to flush traces", "error", err)
+	}
+}
+
+// insertRateLimitFilters ограничивает тяжёлые пользовательские маршруты и запись
+// в админке. Фильтры идут после авторизации, чтобы считать по user_id, а не по IP.
+func insertRateLimitFilters(limits config.RateLimit) {
+	search, _ := ratelimit.ParseLimit(limits.Search)
+	favorites, _ := ratelimit.ParseLimit(limits.Favorites)
+	adminWrites, _ := ratelimit.ParseLimit(limits.AdminWrites)
+
+	searchFilter := ratelimit.Filter(ratelimit.Group{Name: "search", Limit: search})
+	beego.InsertFilter("/user/:entity/search", beego.BeforeRouter, searchFilter)
+	beego.InsertFilter("/user/universities/map", beego.BeforeRouter, searchFilter)
+	beego.InsertFilter("/user/universities/favorites/*", beego.BeforeRouter,
+		ratelimit.Filter(ratelimit.Group{Name: "favorites", Limit: favorites}))
+	beego.InsertFilter("/api/*", beego.BeforeRouter, ratelimit.Filter(ratelimit.Group{
+		Name:    "admin_writes",
+		Limit:   adminWrites,
+		Methods: []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete},
+	}))
+}
+
+// runUntilShutdown запускает сервер и по SIGTERM или SIGINT перестаёт принимать
+// новые соединения, дожидаясь завершения начатых запросов не дольше timeout.
+func runUntilShutdown(timeout time.Duration) {
+	stopped := make(chan struct{})
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGTERM, os.Interrupt)
+		sig := <-signals
+		slog.Info("Shutting down", "signal", sig.String(), "timeout", timeout.String())
+		health.SetShuttingDown()
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if err := beego.BeeApp.Server.Shutdown(ctx); err != nil {
+			slog.Warn("Shutdown did not finish in time", "error", err)
+		}
+		close(stopped)
+	}()
+
+	beego.RunWithMiddleWares("",
+		tracing.HTTPMiddleware(beego.BeeApp.Handlers),
+		logging.Middleware,
+		metrics.HTTPMiddleware(beego.BeeApp.Handlers),
+	)
+
+	// Run возвращается сразу после Shutdown, пока запросы ещё выполняются.
+	if !health.ShuttingDown() {
+		fatal("Server stopped unexpectedly")
+	}
+	<-stopped
+	slog.Info("Server stopped")
 }
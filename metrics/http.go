@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	beego "github.com/beego/beego/v2/server/web"
+	beecontext "github.com/beego/beego/v2/server/web/context"
+)
+
+// unmatchedRoute — метка для запросов, не попавших ни в один маршрут (404,
+// статика, preflight), чтобы произвольные URL не раздували число рядов.
+const unmatchedRoute = "unmatched"
+
+// HTTPMiddleware считает запросы и их длительность по методу, шаблону маршрута
+// из routes (например /user/universities/:id) и коду ответа.
+func HTTPMiddleware(routes *beego.ControllerRegister) beego.MiddleWare {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			started := time.Now()
+			recorder := NewStatusRecorder(w)
+			next.ServeHTTP(recorder, r)
+
+			labels := []string{r.Method, RoutePattern(routes, r), strconv.Itoa(recorder.Status())}
+			httpRequests.WithLabelValues(labels...).Inc()
+			httpDuration.WithLabelValues(labels...).Observe(time.Since(started).Seconds())
+		})
+	}
+}
+
+// RoutePattern возвращает шаблон маршрута запроса или unmatched. Маршрут ищется
+// на отдельном контексте: контекст Beego возвращается в пул после ServeHTTP, и
+// читать его в middleware нельзя.
+func RoutePattern(routes *beego.ControllerRegister, r *http.Request) string {
+	ctx := beecontext.NewContext()
+	ctx.Reset(nil, r)
+	if info, ok := routes.FindRouter(ctx); ok {
+		return info.GetPattern()
+	}
+	return unmatchedRoute
+}
+
+// StatusRecorder запоминает код ответа; без WriteHeader это 200.
+type StatusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+// NewStatusRecorder оборачивает w.
+func NewStatusRecorder(w http.ResponseWriter) *StatusRecorder {
+	return &StatusRecorder{ResponseWriter: w}
+}
+
+func (w *StatusRecorder) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *StatusRecorder) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *StatusRecorder) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Status возвращает записанный код ответа.
+func (w *StatusRecorder) Status() int {
+	if w.code == 0 {
+		return http.StatusOK
+	}
+	return w.code
+}
@@ -0,0 +1,120 @@
+// Package metrics собирает метрики Prometheus: HTTP-запросы, запросы к базе по
+// функциям models, операции с хранилищем, проверки токенов и кэш. Метрики
+// рантайма Go и процесса отдаёт стандартный реестр. Всё публикуется на /metrics.
+package metrics
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "uni"
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route pattern and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route pattern and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	dbDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Database query latency by calling model function and operation (query or exec).",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"function", "operation"})
+
+	dbErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_query_errors_total",
+		Help:      "Failed database queries by calling model function.",
+	}, []string{"function"})
+
+	storageDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "storage_operation_duration_seconds",
+		Help:      "Blob storage operation latency by operation (upload or delete).",
+		Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"operation"})
+
+	storageFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "storage_operation_failures_total",
+		Help:      "Failed blob storage operations by operation.",
+	}, []string{"operation"})
+
+	authDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "auth_request_duration_seconds",
+		Help:      "Latency of token checks against the accounts service by result (ok, unauthorized, error).",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"result"})
+
+	cacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "cache_requests_total",
+		Help:      "Catalogue cache lookups by entity and result (hit or miss).",
+	}, []string{"entity", "result"})
+
+	rateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ratelimit_rejected_total",
+		Help:      "Requests rejected with 429 by route group.",
+	}, []string{"group"})
+)
+
+func init() {
+	prometheus.MustRegister(httpRequests, httpDuration, dbDuration, dbErrors,
+		storageDuration, storageFailures, authDuration, cacheRequests, rateLimited)
+}
+
+// Handler отдаёт метрики в формате Prometheus.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Результаты проверки токена для ObserveAuth.
+const (
+	AuthOK           = "ok"
+	AuthUnauthorized = "unauthorized"
+	AuthError        = "error"
+)
+
+// ObserveAuth учитывает запрос к сервису аккаунтов, начатый в started.
+func ObserveAuth(result string, started time.Time) {
+	authDuration.WithLabelValues(result).Observe(time.Since(started).Seconds())
+}
+
+// ObserveStorage учитывает операцию с хранилищем (upload, delete), начатую в started.
+func ObserveStorage(operation string, started time.Time, err error) {
+	storageDuration.WithLabelValues(operation).Observe(time.Since(started).Seconds())
+	if err != nil {
+		storageFailures.WithLabelValues(operation).Inc()
+	}
+}
+
+// CacheLookup учитывает обращение к кэшу по ключу key вида сущность:язык:...
+func CacheLookup(key string, hit bool) {
+	entity, _, _ := strings.Cut(key, ":")
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	cacheRequests.WithLabelValues(entity, result).Inc()
+}
+
+// RateLimited учитывает запрос, отклонённый ограничителем в группе group.
+func RateLimited(group string) {
+	rateLimited.WithLabelValues(group).Inc()
+}
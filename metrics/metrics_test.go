@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	beego "github.com/beego/beego/v2/server/web"
+	beecontext "github.com/beego/beego/v2/server/web/context"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func histogramCount(t *testing.T, observer prometheus.Observer) uint64 {
+	t.Helper()
+	var m dto.Metric
+	if err := observer.(prometheus.Metric).Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
+
+func counterValue(t *testing.T, counter prometheus.Counter) float64 {
+	t.Helper()
+	var m dto.Metric
+	if err := counter.Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetCounter().GetValue()
+}
+
+func TestHTTPMiddlewareLabelsByRoutePattern(t *testing.T) {
+	routes := beego.NewControllerRegister()
+	routes.Get("/user/universities/:id", func(ctx *beecontext.Context) {
+		ctx.Output.SetStatus(http.StatusNotFound)
+		ctx.Output.Body([]byte("not found"))
+	})
+	handler := HTTPMiddleware(routes)(routes)
+
+	for _, path := range []string{"/user/universities/1", "/user/universities/2", "/no/such/page"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if got := counterValue(t, httpRequests.WithLabelValues("GET", "/user/universities/:id", "404")); got != 2 {
+		t.Errorf("expected 2 requests for the route pattern, got %v", got)
+	}
+	if got := histogramCount(t, httpDuration.WithLabelValues("GET", "/user/universities/:id", "404")); got != 2 {
+		t.Errorf("expected 2 latency samples, got %d", got)
+	}
+	if got := counterValue(t, httpRequests.WithLabelValues("GET", unmatchedRoute, "404")); got != 1 {
+		t.Errorf("expected the unknown path under %q, got %v", unmatchedRoute, got)
+	}
+}
+
+func TestDriverAttributesQueriesToCaller(t *testing.T) {
+	// В тесте нет пакета models, поэтому вызывающей считается сама тестовая функция.
+	defer func(prefix string) { callerPrefix = prefix }(callerPrefix)
+	callerPrefix = "testhub-spec-uni/metrics.Test"
+
+	name, err := RegisterDriver("sqlite3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, err := RegisterDriver("sqlite3"); err != nil || again != name {
+		t.Fatalf("second registration returned %q, %v", again, err)
+	}
+	db, err := sql.Open(name, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec("CREATE TABLE city (id integer PRIMARY KEY, name text)"); err != nil {
+		t.Fatal(err)
+	}
+	stmt, err := db.Prepare("INSERT INTO city (name) VALUES (?)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stmt.Close()
+	if _, err := stmt.Exec("Алматы"); err != nil {
+		t.Fatal(err)
+	}
+	var count int
+	if err := db.QueryRow("SELECT count(*) FROM city").Scan(&count); err != nil || count != 1 {
+		t.Fatalf("count %d, err %v", count, err)
+	}
+	if _, err := db.Exec("SELECT * FROM missing"); err == nil || !strings.Contains(err.Error(), "no such table") {
+		t.Fatalf("expected missing table error, got %v", err)
+	}
+
+	function := "DriverAttributesQueriesToCaller"
+	if got := histogramCount(t, dbDuration.WithLabelValues(function, "exec")); got != 3 {
+		t.Errorf("expected 3 exec samples, got %d", got)
+	}
+	if got := histogramCount(t, dbDuration.WithLabelValues(function, "query")); got != 1 {
+		t.Errorf("expected 1 query sample, got %d", got)
+	}
+	if got := counterValue(t, dbErrors.WithLabelValues(function)); got != 1 {
+		t.Errorf("expected 1 error, got %v", got)
+	}
+}
+
+func TestCacheLookupUsesEntity(t *testing.T) {
+	CacheLookup("cities:ru", true)
+	CacheLookup("cities:kz:page=2", false)
+
+	if got := counterValue(t, cacheRequests.WithLabelValues("cities", "hit")); got != 1 {
+		t.Errorf("expected 1 hit, got %v", got)
+	}
+	if got := counterValue(t, cacheRequests.WithLabelValues("cities", "miss")); got != 1 {
+		t.Errorf("expected 1 miss, got %v", got)
+	}
+}
@@ -0,0 +1,279 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// callerPrefix — пакет, функции которого попадают в метку function запросов к базе.
+var callerPrefix = "testhub-spec-uni/models."
+
+// otherCaller — метка для запросов не из models: миграции, проверки готовности, ORM при старте.
+const otherCaller = "other"
+
+var registerMu sync.Mutex
+
+// QueryTracer начинает span запроса operation к базе system из функции function
+// и возвращает функцию, которая завершает его с ошибкой запроса.
+type QueryTracer func(ctx context.Context, system attribute.KeyValue, operation, function, text string) (context.Context, func(error))
+
+// queryTracer задаёт пакет tracing; без него запросы только замеряются.
+var queryTracer QueryTracer
+
+// SetQueryTracer подключает запись span для запросов через обёртку драйвера.
+// Вызывается при инициализации, до регистрации драйверов.
+func SetQueryTracer(tracer QueryTracer) {
+	queryTracer = tracer
+}
+
+// RegisterDriver регистрирует в database/sql обёртку над драйвером base,
+// которая замеряет каждый запрос и передаёт его в QueryTracer, и возвращает её
+// имя для orm.RegisterDataBase.
+func RegisterDriver(base string) (string, error) {
+	registerMu.Lock()
+	defer registerMu.Unlock()
+
+	name := base + "_metrics"
+	for _, registered := range sql.Drivers() {
+		if registered == name {
+			return name, nil
+		}
+	}
+	// sql.Open не подключается к базе, он только находит драйвер.
+	db, err := sql.Open(base, "")
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+	system := semconv.DBSystemPostgreSQL
+	if strings.HasPrefix(base, "sqlite") {
+		system = semconv.DBSystemSqlite
+	}
+	sql.Register(name, instrumentedDriver{db.Driver(), system})
+	return name, nil
+}
+
+// startQuery начинает запрос operation (query, exec) с текстом text. Возвращённая
+// функция учитывает его длительность и ошибку и завершает span, если он начат.
+func startQuery(ctx context.Context, system attribute.KeyValue, operation, text string) (context.Context, func(error)) {
+	function := caller()
+	started := time.Now()
+	finishSpan := func(error) {}
+	if queryTracer != nil {
+		ctx, finishSpan = queryTracer(ctx, system, operation, function, text)
+	}
+	return ctx, func(err error) {
+		// ErrSkip — не ошибка: database/sql повторит запрос через Prepare.
+		if err == driver.ErrSkip {
+			finishSpan(nil)
+			return
+		}
+		dbDuration.WithLabelValues(function, operation).Observe(time.Since(started).Seconds())
+		if err == driver.ErrBadConn {
+			err = nil
+		}
+		if err != nil {
+			dbErrors.WithLabelValues(function).Inc()
+		}
+		finishSpan(err)
+	}
+}
+
+// caller возвращает имя ближайшей функции из callerPrefix в стеке, без
+// суффиксов замыканий: GetUniversities.func1 → GetUniversities.
+func caller() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if name, ok := strings.CutPrefix(frame.Function, callerPrefix); ok {
+			if i := strings.Index(name, ".func"); i >= 0 {
+				name = name[:i]
+			}
+			return name
+		}
+		if !more {
+			return otherCaller
+		}
+	}
+}
+
+type instrumentedDriver struct {
+	driver.Driver
+	system attribute.KeyValue
+}
+
+func (d instrumentedDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedConn{Conn: conn, system: d.system}, nil
+}
+
+// instrumentedConn передаёт вызовы исходному соединению; если оно не
+// поддерживает интерфейс, возвращает driver.ErrSkip, и database/sql выбирает
+// другой путь, как для самого драйвера.
+type instrumentedConn struct {
+	driver.Conn
+	system attribute.KeyValue
+	// txCtx — контекст открытой транзакции. ORM вызывает методы без контекста,
+	// и database/sql передаёт драйверу context.Background(); запросам внутри
+	// BeginTx(ctx) вместо него достаётся ctx, и их span становятся дочерними.
+	txCtx context.Context
+}
+
+// queryContext возвращает контекст транзакции для запроса без контекста.
+func (c *instrumentedConn) queryContext(ctx context.Context) context.Context {
+	if ctx == context.Background() && c.txCtx != nil {
+		return c.txCtx
+	}
+	return ctx
+}
+
+func (c *instrumentedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, finish := startQuery(c.queryContext(ctx), c.system, "exec", query)
+	result, err := execer.ExecContext(ctx, query, args)
+	finish(err)
+	return result, err
+}
+
+func (c *instrumentedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, finish := startQuery(c.queryContext(ctx), c.system, "query", query)
+	rows, err := queryer.QueryContext(ctx, query, args)
+	finish(err)
+	return rows, err
+}
+
+func (c *instrumentedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedStmt{stmt, c, query}, nil
+}
+
+func (c *instrumentedConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *instrumentedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	var tx driver.Tx
+	var err error
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		tx, err = beginner.BeginTx(ctx, opts)
+	} else {
+		tx, err = c.Conn.Begin()
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.txCtx = ctx
+	return &instrumentedTx{tx, c}, nil
+}
+
+func (c *instrumentedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *instrumentedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *instrumentedConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *instrumentedConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+// instrumentedTx забывает контекст транзакции у соединения при её завершении.
+type instrumentedTx struct {
+	driver.Tx
+	conn *instrumentedConn
+}
+
+func (t *instrumentedTx) Commit() error {
+	t.conn.txCtx = nil
+	return t.Tx.Commit()
+}
+
+func (t *instrumentedTx) Rollback() error {
+	t.conn.txCtx = nil
+	return t.Tx.Rollback()
+}
+
+type instrumentedStmt struct {
+	driver.Stmt
+	conn  *instrumentedConn
+	query string
+}
+
+func (s *instrumentedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	ctx, finish := startQuery(s.conn.queryContext(ctx), s.conn.system, "exec", s.query)
+	var result driver.Result
+	var err error
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		result, err = execer.ExecContext(ctx, args)
+	} else {
+		result, err = s.Stmt.Exec(values(args))
+	}
+	finish(err)
+	return result, err
+}
+
+func (s *instrumentedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	ctx, finish := startQuery(s.conn.queryContext(ctx), s.conn.system, "query", s.query)
+	var rows driver.Rows
+	var err error
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		rows, err = s.Stmt.Query(values(args))
+	}
+	finish(err)
+	return rows, err
+}
+
+func values(args []driver.NamedValue) []driver.Value {
+	result := make([]driver.Value, len(args))
+	for i, arg := range args {
+		result[i] = arg.Value
+	}
+	return result
+}
@@ -6,10 +6,16 @@ import (
 	"fmt"
 	"github.com/astaxie/beego/orm"
 	"github.com/beego/beego/v2/server/web/context"
-	"log"
+	"go.opentelemetry.io/otel/attribute"
+	"log/slog"
 	"net/http"
 	"strings"
+	"testhub-spec-uni/config"
+	"testhub-spec-uni/dialect"
+	"testhub-spec-uni/logging"
+	"testhub-spec-uni/metrics"
 	"testhub-spec-uni/models"
+	"testhub-spec-uni/tracing"
 	"time"
 )
 
@@ -32,37 +38,40 @@ func AuthMiddleware(ctx *context.Context) {
 		token = "Bearer " + token
 	}
 
-	authURL := "https://api-dev.testhub.kz/accounts/api/v1/me"
-	req, err := http.NewRequest("GET", authURL, nil)
+	authCtx, span := tracing.Start(ctx.Request.Context(), "auth GET /me",
+		attribute.String("http.request.method", http.MethodGet), attribute.String("url.full", auth.url))
+	req, err := http.NewRequestWithContext(authCtx, http.MethodGet, auth.url, nil)
 	if err != nil {
+		tracing.End(span, err)
 		ctx.Output.SetStatus(http.StatusInternalServerError)
 		ctx.Output.JSON(map[string]string{"error": "Failed to create request"}, true, true)
 		return
 	}
 
 	req.Header.Set("Authorization", token)
+	tracing.Inject(authCtx, req.Header)
 
-	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-	}
-	client := &http.Client{
-		Transport: transport,
-		Timeout:   time.Second * 10,
-	}
-
-	resp, err := client.Do(req)
+	started := time.Now()
+	resp, err := auth.client.Do(req)
 	if err != nil {
+		tracing.End(span, err)
+		metrics.ObserveAuth(metrics.AuthError, started)
+		slog.ErrorContext(ctx.Request.Context(), "Auth request failed", "error", err)
 		ctx.Output.SetStatus(http.StatusInternalServerError)
 		ctx.Output.JSON(map[string]string{"error": fmt.Sprintf("Failed to perform request: %v", err)}, true, true)
 		return
 	}
 	defer resp.Body.Close()
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	span.End()
 
 	if resp.StatusCode != http.StatusOK {
+		metrics.ObserveAuth(metrics.AuthUnauthorized, started)
 		ctx.Output.SetStatus(http.StatusUnauthorized)
 		ctx.Output.JSON(map[string]string{"error": "Unauthorized"}, true, true)
 		return
 	}
+	metrics.ObserveAuth(metrics.AuthOK, started)
 
 	var userInfo models.UserInfo
 	if err := json.NewDecoder(resp.Body).Decode(&userInfo); err != nil {
@@ -73,11 +82,18 @@ func AuthMiddleware(ctx *context.Context) {
 
 	// Сохранение user ID в контексте
 	ctx.Input.SetData("user_id", userInfo.ID)
+	logging.SetUserID(ctx.Request.Context(), userInfo.ID)
+	ctx.Input.SetData(UserLocaleDataKey, userInfo.Language)
+
+	// Ответ зависит от пользователя, поэтому общие кэши не должны его хранить.
+	ctx.Output.Header("Cache-Control", "private, no-cache")
+	appendVary(ctx, "Authorization")
 
 	// Проверка, нужно ли проверять права администратора для текущего маршрута
 	if strings.HasPrefix(path, "/api") && path != "/api/cities" && path != "/api/subjects/" {
 		isSuperUser, err := IsSuperUser(userInfo.ID)
 		if err != nil {
+			slog.ErrorContext(ctx.Request.Context(), "Failed to check superuser status", "error", err)
 			ctx.Output.SetStatus(http.StatusInternalServerError)
 			ctx.Output.JSON(map[string]string{"error": "Failed to check superuser status"}, true, true)
 			return
@@ -91,12 +107,35 @@ func AuthMiddleware(ctx *context.Context) {
 	}
 }
 
+// authService — адрес сервиса аккаунтов и клиент для него. Клиент создаётся один
+// раз, чтобы запросы переиспользовали соединения его пула.
+type authService struct {
+	url    string
+	client *http.Client
+}
+
+var auth = newAuthService(config.Get().Auth)
+
+// ConfigureAuth задаёт сервис аккаунтов по настройкам authConfig. Вызывается после
+// config.Set, до приёма запросов.
+func ConfigureAuth(authConfig config.Auth) {
+	auth = newAuthService(authConfig)
+}
+
+func newAuthService(authConfig config.Auth) authService {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: authConfig.InsecureSkipVerify}
+	return authService{
+		url:    authConfig.URL,
+		client: &http.Client{Transport: transport, Timeout: authConfig.Timeout},
+	}
+}
+
 func IsSuperUser(userId int) (bool, error) {
 	o := orm.NewOrm()
 	var isSuperUser bool
-	err := o.Raw("SELECT is_superuser FROM accounts.user WHERE id = ?", userId).QueryRow(&isSuperUser)
+	err := o.Raw("SELECT is_superuser FROM "+dialect.Current().Table("accounts", "user")+" WHERE id = ?", userId).QueryRow(&isSuperUser)
 	if err != nil {
-		log.Printf("Error querying user superuser status: %v", err)
 		return false, err
 	}
 	return isSuperUser, nil
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"testhub-spec-uni/cache"
+	"testhub-spec-uni/models"
+
+	"github.com/beego/beego/v2/server/web/context"
+)
+
+// adminCacheTags — какие теги кэша сбрасывает изменение в разделе админки /api/<раздел>.
+// Для раздела, которого нет в списке, сбрасывается весь кэш.
+var adminCacheTags = map[string][]string{
+	"subjects":         {models.CacheTagSubject},
+	"subjectpairs":     {models.CacheTagSubjectPair},
+	"specialities":     {models.CacheTagSpeciality, models.CacheTagPointStat}, // и статистика баллов: addpointstat, updatepointstat, deletepointstat
+	"universities":     {models.CacheTagUniversity},
+	"cities":           {models.CacheTagCity, models.CacheTagService, models.CacheTagUniversity},
+	"regions":          {models.CacheTagRegion},
+	"campuses":         {models.CacheTagCampus},
+	"quotas":           {models.CacheTagQuota},
+	"quotaeligibility": {models.CacheTagQuota},
+	"quotaallocations": {models.CacheTagQuota},
+	"reviews":          {models.CacheTagReview},
+	"faq":              {models.CacheTagFaq},
+	"translations":     {models.CacheTagTranslation},
+	"services":         {models.CacheTagService},
+	"unispecdetails":   {models.CacheTagSpecialityUniversity},
+	"events":           {models.CacheTagEvent},
+	// Вопросы и ответы не кэшируются.
+	"qa": {},
+}
+
+// CacheInvalidationMiddleware сбрасывает кэш после успешного изменяющего запроса
+// к админке. Подключается на FinishRouter с WithReturnOnOutput(false), чтобы
+// выполняться после ответа контроллера.
+func CacheInvalidationMiddleware(ctx *context.Context) {
+	switch ctx.Input.Method() {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return
+	}
+	if status := ctx.ResponseWriter.Status; status >= http.StatusBadRequest {
+		return
+	}
+
+	section := strings.Split(strings.TrimPrefix(ctx.Input.URL(), "/api/"), "/")[0]
+	tags, known := adminCacheTags[strings.ToLower(section)]
+	if !known {
+		cache.InvalidateAll()
+		return
+	}
+	cache.Invalidate(tags...)
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"testhub-spec-uni/models"
+
+	"github.com/beego/beego/v2/server/web/context"
+)
+
+// LocaleDataKey — ключ, под которым язык запроса хранится в контексте.
+const LocaleDataKey = "locale"
+
+// UserLocaleDataKey — язык из профиля пользователя; заполняется AuthMiddleware.
+const UserLocaleDataKey = "user_locale"
+
+// LocaleMiddleware определяет язык запроса, сохраняет его в контексте и возвращает
+// в заголовке Content-Language. Порядок: query-параметр lang, заголовок lang,
+// Accept-Language, язык из профиля, язык по умолчанию.
+func LocaleMiddleware(ctx *context.Context) {
+	locale := ResolveLocale(ctx)
+	ctx.Input.SetData(LocaleDataKey, locale)
+	ctx.Output.Header("Content-Language", locale)
+	appendVary(ctx, "Accept-Language", "lang")
+}
+
+// ResolveLocale возвращает язык запроса; неподдерживаемые значения пропускаются.
+func ResolveLocale(ctx *context.Context) string {
+	if locale, ok := supportedLocale(ctx.Input.Query("lang")); ok {
+		return locale
+	}
+	if locale, ok := supportedLocale(ctx.Input.Header("lang")); ok {
+		return locale
+	}
+	if locale, ok := negotiateAcceptLanguage(ctx.Input.Header("Accept-Language")); ok {
+		return locale
+	}
+	if profileLocale, _ := ctx.Input.GetData(UserLocaleDataKey).(string); profileLocale != "" {
+		if locale, ok := supportedLocale(profileLocale); ok {
+			return locale
+		}
+	}
+	return models.DefaultLocale()
+}
+
+func supportedLocale(value string) (string, bool) {
+	locale := models.NormalizeLocale(value)
+	return locale, locale != "" && models.IsSupportedLocale(locale)
+}
+
+// negotiateAcceptLanguage выбирает поддерживаемый язык с наибольшим весом q.
+// Для "kk-KZ" или "ru-RU" проверяется и базовый язык.
+func negotiateAcceptLanguage(header string) (string, bool) {
+	type candidate struct {
+		locale string
+		q      float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+
+		locale, ok := supportedLocale(tag)
+		if !ok {
+			base, _, _ := strings.Cut(tag, "-")
+			if locale, ok = supportedLocale(base); !ok {
+				continue
+			}
+		}
+		candidates = append(candidates, candidate{locale, q})
+	}
+
+	if len(candidates) == 0 {
+		return "", false
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].locale, true
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testhub-spec-uni/models"
+	"testing"
+
+	"github.com/beego/beego/v2/server/web/context"
+)
+
+func localeContext(target string, header http.Header, profileLocale string) *context.Context {
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	for name, values := range header {
+		r.Header[name] = values
+	}
+	ctx := context.NewContext()
+	ctx.Reset(httptest.NewRecorder(), r)
+	if profileLocale != "" {
+		ctx.Input.SetData(UserLocaleDataKey, profileLocale)
+	}
+	return ctx
+}
+
+// Порядок: query-параметр lang, заголовок lang, Accept-Language, профиль, значение по умолчанию.
+func TestResolveLocaleOrder(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  string
+		header  http.Header
+		profile string
+		want    string
+	}{
+		{"query wins", "/?lang=en", http.Header{"Lang": {"ru"}, "Accept-Language": {"kk"}}, "ru", models.LocaleEn},
+		{"header before accept-language", "/", http.Header{"Lang": {"ru"}, "Accept-Language": {"en"}}, "kz", models.LocaleRu},
+		{"accept-language before profile", "/", http.Header{"Accept-Language": {"kk-KZ, ru;q=0.8"}}, "ru", models.LocaleKz},
+		{"profile", "/", nil, "en", models.LocaleEn},
+		{"unsupported values are skipped", "/?lang=de", http.Header{"Lang": {"fr"}, "Accept-Language": {"de-DE"}}, "ru", models.LocaleRu},
+		{"default", "/", nil, "", models.DefaultLocale()},
+	}
+	for _, tt := range tests {
+		if got := ResolveLocale(localeContext(tt.target, tt.header, tt.profile)); got != tt.want {
+			t.Errorf("%s: ResolveLocale = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNegotiateAcceptLanguage(t *testing.T) {
+	for header, want := range map[string]string{
+		"ru-RU,ru;q=0.9,en;q=0.8": models.LocaleRu,
+		"en;q=0.5, kk;q=0.9":      models.LocaleKz,
+		"de, en;q=0.1":            models.LocaleEn,
+		"kk;q=0, ru;q=0.2":        models.LocaleRu,
+	} {
+		if got, _ := negotiateAcceptLanguage(header); got != want {
+			t.Errorf("negotiateAcceptLanguage(%q) = %q, want %q", header, got, want)
+		}
+	}
+	if _, ok := negotiateAcceptLanguage("*, de"); ok {
+		t.Error("unsupported languages were negotiated")
+	}
+}
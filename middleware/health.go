@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// PingAuth проверяет, что сервис аккаунтов отвечает. Запрос без токена должен
+// получить 401; недоступен сервис, только если ответа нет или он 5xx.
+func PingAuth(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, auth.url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := auth.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("auth service responded %s", resp.Status)
+	}
+	return nil
+}
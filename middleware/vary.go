@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/beego/beego/v2/server/web/context"
+)
+
+// appendVary добавляет заголовки в Vary, не затирая уже добавленные другими фильтрами.
+func appendVary(ctx *context.Context, headers ...string) {
+	header := ctx.ResponseWriter.Header()
+	existing := header.Values("Vary")
+	for _, h := range headers {
+		found := false
+		for _, value := range existing {
+			for _, v := range strings.Split(value, ",") {
+				if strings.EqualFold(strings.TrimSpace(v), h) {
+					found = true
+				}
+			}
+		}
+		if !found {
+			existing = append(existing, h)
+		}
+	}
+	header.Set("Vary", strings.Join(existing, ", "))
+}
@@ -0,0 +1,60 @@
+package migrations
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"testhub-spec-uni/dialect"
+	"time"
+)
+
+const usage = "usage: migrate up|down|status"
+
+// Run выполняет подкоманду migrate: up применяет все новые миграции, down
+// откатывает последнюю, status печатает состояние каждой версии.
+func Run(db *sql.DB, d dialect.Dialect, args []string, out io.Writer) error {
+	if len(args) != 1 {
+		return errors.New(usage)
+	}
+
+	switch args[0] {
+	case "up":
+		done, err := Up(db, d)
+		for _, m := range done {
+			fmt.Fprintf(out, "applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(done) == 0 {
+			fmt.Fprintln(out, "schema is up to date")
+		}
+		return nil
+	case "down":
+		m, err := Down(db, d)
+		if err != nil {
+			return err
+		}
+		if m == nil {
+			fmt.Fprintln(out, "no applied migrations")
+			return nil
+		}
+		fmt.Fprintf(out, "reverted %04d_%s\n", m.Version, m.Name)
+		return nil
+	case "status":
+		statuses, err := Status(db, d)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.AppliedAt != nil {
+				state = "applied " + s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(out, "%04d_%s\t%s\n", s.Version, s.Name, state)
+		}
+		return nil
+	}
+	return errors.New(usage)
+}
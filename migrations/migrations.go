@@ -0,0 +1,237 @@
+// Package migrations применяет версионированные SQL-миграции схемы.
+//
+// Миграции лежат в sql/<диалект>/ парами файлов <версия>_<имя>.up.sql и
+// <версия>_<имя>.down.sql и встраиваются в бинарник. У каждого диалекта свой
+// набор файлов с одинаковыми версиями. Применённые версии записываются в
+// таблицу schema_migrations.
+package migrations
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"testhub-spec-uni/dialect"
+	"time"
+)
+
+//go:embed sql/*/*.sql
+var files embed.FS
+
+const versionTable = "schema_migrations"
+
+// Migration — одна версия схемы.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus — миграция и время её применения; AppliedAt == nil, если
+// миграция ещё не применена.
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Load читает встроенные миграции диалекта d, отсортированные по версии.
+func Load(d dialect.Dialect) ([]Migration, error) {
+	return load(files, path.Join("sql", d.Name()))
+}
+
+func load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		name := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(name, "."+direction+".sql")
+		versionPart, title, found := strings.Cut(base, "_")
+		version, err := strconv.Atoi(versionPart)
+		if !found || err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration file %q: expected <version>_<name>.%s.sql", name, direction)
+		}
+
+		body, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: title}
+			byVersion[version] = m
+		} else if m.Name != title {
+			return nil, fmt.Errorf("migration %d has different names: %q and %q", version, m.Name, title)
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+func ensureVersionTable(db *sql.DB, d dialect.Dialect) error {
+	timestampType := "timestamp with time zone"
+	if d.Name() == dialect.SQLite {
+		// Драйвер SQLite отдаёт time.Time только для колонок типа datetime.
+		timestampType = "datetime"
+	}
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS "` + versionTable + `" (
+    "version" integer NOT NULL PRIMARY KEY,
+    "name" varchar(128) NOT NULL,
+    "applied_at" ` + timestampType + ` NOT NULL
+)`)
+	return err
+}
+
+func appliedVersions(db *sql.DB) (map[int]time.Time, error) {
+	rows, err := db.Query(`SELECT "version", "applied_at" FROM "` + versionTable + `"`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+// Status возвращает все известные миграции с отметкой о применении.
+func Status(db *sql.DB, d dialect.Dialect) ([]MigrationStatus, error) {
+	migrations, err := Load(d)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureVersionTable(db, d); err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		status := MigrationStatus{Migration: m}
+		if appliedAt, ok := applied[m.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+		result = append(result, status)
+	}
+	return result, nil
+}
+
+// Up применяет все непримененные миграции по порядку, каждую в своей транзакции,
+// и возвращает применённые.
+func Up(db *sql.DB, d dialect.Dialect) ([]Migration, error) {
+	statuses, err := Status(db, d)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, status := range statuses {
+		if status.AppliedAt != nil {
+			continue
+		}
+		m := status.Migration
+		err := inTransaction(db, d, m, func(tx *sql.Tx) error {
+			if _, err := tx.Exec(m.Up); err != nil {
+				return err
+			}
+			_, err := tx.Exec(`INSERT INTO "`+versionTable+`" ("version", "name", "applied_at") VALUES (`+
+				d.Placeholder(1)+`, `+d.Placeholder(2)+`, `+d.Placeholder(3)+`)`,
+				m.Version, m.Name, time.Now())
+			return err
+		})
+		if err != nil {
+			return done, err
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// Down откатывает последнюю применённую миграцию. Возвращает nil, если откатывать нечего.
+func Down(db *sql.DB, d dialect.Dialect) (*Migration, error) {
+	statuses, err := Status(db, d)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(statuses) - 1; i >= 0; i-- {
+		if statuses[i].AppliedAt == nil {
+			continue
+		}
+		m := statuses[i].Migration
+		err := inTransaction(db, d, m, func(tx *sql.Tx) error {
+			if _, err := tx.Exec(m.Down); err != nil {
+				return err
+			}
+			_, err := tx.Exec(`DELETE FROM "`+versionTable+`" WHERE "version" = `+d.Placeholder(1), m.Version)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &m, nil
+	}
+	return nil, nil
+}
+
+// inTransaction выполняет шаг миграции в транзакции. Таблица версий блокируется,
+// чтобы два одновременно запущенных migrate не применили одну версию дважды.
+func inTransaction(db *sql.DB, d dialect.Dialect, m Migration, step func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if lock := d.LockTable(versionTable); lock != "" {
+		if _, err := tx.Exec(lock); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err := step(tx); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("migration %d_%s: %w", m.Version, m.Name, err)
+	}
+	return tx.Commit()
+}
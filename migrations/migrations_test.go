@@ -0,0 +1,148 @@
+package migrations
+
+import (
+	"bytes"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testhub-spec-uni/dialect"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestLoadEmbedded(t *testing.T) {
+	postgres, err := Load(dialectFor(t, dialect.Postgres))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(postgres) == 0 || postgres[0].Version != 1 || postgres[0].Name != "initial" {
+		t.Fatalf("unexpected migrations: %+v", postgres)
+	}
+	for i, m := range postgres {
+		if m.Version != i+1 {
+			t.Fatalf("migration versions must be consecutive, got %d at position %d", m.Version, i)
+		}
+	}
+
+	sqlite, err := Load(dialectFor(t, dialect.SQLite))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sqlite) != len(postgres) {
+		t.Fatalf("dialects have different migrations: %d postgres, %d sqlite", len(postgres), len(sqlite))
+	}
+	for i := range postgres {
+		if postgres[i].Version != sqlite[i].Version || postgres[i].Name != sqlite[i].Name {
+			t.Fatalf("migration %d differs: postgres %d_%s, sqlite %d_%s", i,
+				postgres[i].Version, postgres[i].Name, sqlite[i].Version, sqlite[i].Name)
+		}
+	}
+
+	for _, index := range []string{
+		`"favorite_university" ("user_id", "university_id")`,
+		`"point_stat" ("university_id", "speciality_id", "year")`,
+	} {
+		for _, m := range []Migration{postgres[1], sqlite[1]} {
+			if !strings.Contains(m.Up, "CREATE UNIQUE INDEX IF NOT EXISTS") || !strings.Contains(m.Up, index) {
+				t.Fatalf("migration %d_%s has no unique index on %s", m.Version, m.Name, index)
+			}
+		}
+	}
+}
+
+func TestUpDownOnSQLite(t *testing.T) {
+	d := dialectFor(t, dialect.SQLite)
+	db, err := sql.Open(dialect.SQLiteDriver, dialect.SQLiteDataSource(filepath.Join(t.TempDir(), "uni.db")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var out bytes.Buffer
+	if err := Run(db, d, []string{"up"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "applied 0001_initial") {
+		t.Fatalf("unexpected output: %q", out.String())
+	}
+
+	if _, err := db.Exec(`INSERT INTO "favorite_university" ("user_id", "university_id", "created_at") VALUES (1, 1, ?), (1, 1, ?)`,
+		time.Now(), time.Now()); err == nil {
+		t.Fatal("duplicate favorite was inserted")
+	}
+
+	statuses, err := Status(db, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range statuses {
+		if s.AppliedAt == nil {
+			t.Fatalf("migration %d is not applied", s.Version)
+		}
+	}
+
+	done, err := Up(db, d)
+	if err != nil || len(done) != 0 {
+		t.Fatalf("second up applied %v, err %v", done, err)
+	}
+
+	for range statuses {
+		if _, err := Down(db, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if m, err := Down(db, d); m != nil || err != nil {
+		t.Fatalf("down on an empty schema reverted %v, err %v", m, err)
+	}
+	// Исходная схема переживает откат, таблицы из следующих миграций — нет.
+	for table, want := range map[string]int{"university": 1, "review": 0} {
+		var tables int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&tables); err != nil || tables != want {
+			t.Fatalf("%s: %d tables after down, want %d, err %v", table, tables, want, err)
+		}
+	}
+	var columns int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('university') WHERE name = 'rating_score'`).Scan(&columns); err != nil || columns != 0 {
+		t.Fatalf("rating_score left after down: %d, err %v", columns, err)
+	}
+}
+
+func dialectFor(t *testing.T, driver string) dialect.Dialect {
+	t.Helper()
+	d, err := dialect.ForDriver(driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestLoadOrdersAndValidates(t *testing.T) {
+	migrations, err := load(fstest.MapFS{
+		"sql/0010_later.up.sql":   {Data: []byte("up 10")},
+		"sql/0010_later.down.sql": {Data: []byte("down 10")},
+		"sql/0002_first.up.sql":   {Data: []byte("up 2")},
+		"sql/0002_first.down.sql": {Data: []byte("down 2")},
+		"sql/README.md":           {Data: []byte("ignored")},
+	}, "sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 2 || migrations[0].Version != 2 || migrations[1].Down != "down 10" {
+		t.Fatalf("unexpected migrations: %+v", migrations)
+	}
+
+	broken := map[string]fstest.MapFS{
+		"missing down": {"sql/0001_a.up.sql": {Data: []byte("up")}},
+		"bad version":  {"sql/x_a.up.sql": {Data: []byte("up")}, "sql/x_a.down.sql": {Data: []byte("down")}},
+		"name mismatch": {
+			"sql/0001_a.up.sql":   {Data: []byte("up")},
+			"sql/0001_b.down.sql": {Data: []byte("down")},
+		},
+	}
+	for name, fsys := range broken {
+		if _, err := load(fsys, "sql"); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,4 @@
+-- Исходная схема существовала до миграций, поэтому откат её не удаляет:
+-- иначе down на рабочей базе стёр бы каталог вместе с данными.
+
+SELECT 1;
@@ -0,0 +1,244 @@
+-- Столбцы и таблицы, появившиеся после исходной схемы, а также ограничения
+-- и индексы. IF NOT EXISTS позволяет применить миграцию к базе, где часть
+-- изменений уже сделана через orm syncdb.
+
+ALTER TABLE "city" ADD COLUMN IF NOT EXISTS "region_id" integer;
+ALTER TABLE "city" ADD COLUMN IF NOT EXISTS "latitude" double precision NOT NULL DEFAULT 0;
+ALTER TABLE "city" ADD COLUMN IF NOT EXISTS "longitude" double precision NOT NULL DEFAULT 0;
+ALTER TABLE "service" ADD COLUMN IF NOT EXISTS "created_at" timestamp with time zone NOT NULL DEFAULT now();
+ALTER TABLE "service" ADD COLUMN IF NOT EXISTS "updated_at" timestamp with time zone NOT NULL DEFAULT now();
+ALTER TABLE "subject" ADD COLUMN IF NOT EXISTS "max_score" integer NOT NULL DEFAULT 50;
+ALTER TABLE "subject" ADD COLUMN IF NOT EXISTS "threshold" integer NOT NULL DEFAULT 5;
+ALTER TABLE "university" ADD COLUMN IF NOT EXISTS "max_entry_score" integer NOT NULL DEFAULT 0;
+ALTER TABLE "university" ADD COLUMN IF NOT EXISTS "median_entry_score" integer NOT NULL DEFAULT 0;
+ALTER TABLE "university" ADD COLUMN IF NOT EXISTS "min_fee" integer NOT NULL DEFAULT 0;
+ALTER TABLE "university" ADD COLUMN IF NOT EXISTS "max_fee" integer NOT NULL DEFAULT 0;
+ALTER TABLE "university" ADD COLUMN IF NOT EXISTS "median_fee" integer NOT NULL DEFAULT 0;
+ALTER TABLE "university" ADD COLUMN IF NOT EXISTS "stats_year" integer NOT NULL DEFAULT 0;
+ALTER TABLE "university" ADD COLUMN IF NOT EXISTS "latitude" double precision NOT NULL DEFAULT 0;
+ALTER TABLE "university" ADD COLUMN IF NOT EXISTS "longitude" double precision NOT NULL DEFAULT 0;
+ALTER TABLE "university" ADD COLUMN IF NOT EXISTS "rating_score" double precision NOT NULL DEFAULT 0;
+ALTER TABLE "university" ADD COLUMN IF NOT EXISTS "review_count" integer NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS "admission_event" (
+    "id" serial NOT NULL PRIMARY KEY,
+    "university_id" integer NOT NULL,
+    "speciality_id" integer,
+    "event_type" varchar(64) NOT NULL DEFAULT '',
+    "title" varchar(256) NOT NULL DEFAULT '',
+    "title_ru" varchar(256) NOT NULL DEFAULT '',
+    "title_kz" varchar(256) NOT NULL DEFAULT '',
+    "description" text NOT NULL,
+    "description_ru" text NOT NULL,
+    "description_kz" text NOT NULL,
+    "start_date" timestamp with time zone NOT NULL,
+    "end_date" timestamp with time zone NOT NULL,
+    "all_day" bool NOT NULL DEFAULT FALSE,
+    "created_at" timestamp with time zone NOT NULL,
+    "updated_at" timestamp with time zone NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "calendar_feed" (
+    "id" serial NOT NULL PRIMARY KEY,
+    "user_id" integer NOT NULL DEFAULT 0 UNIQUE,
+    "token" varchar(64) NOT NULL DEFAULT '' UNIQUE,
+    "created_at" timestamp with time zone NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "campus" (
+    "id" serial NOT NULL PRIMARY KEY,
+    "university_id" integer NOT NULL,
+    "city_id" integer,
+    "name" varchar(128) NOT NULL DEFAULT '',
+    "name_ru" varchar(128) NOT NULL DEFAULT '',
+    "name_kz" varchar(128) NOT NULL DEFAULT '',
+    "address" varchar(256) NOT NULL DEFAULT '',
+    "latitude" double precision NOT NULL DEFAULT 0,
+    "longitude" double precision NOT NULL DEFAULT 0,
+    "created_at" timestamp with time zone NOT NULL,
+    "updated_at" timestamp with time zone NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "faq_entry" (
+    "id" serial NOT NULL PRIMARY KEY,
+    "university_id" integer NOT NULL,
+    "question_ru" text NOT NULL,
+    "question_kz" text NOT NULL,
+    "answer_ru" text NOT NULL,
+    "answer_kz" text NOT NULL,
+    "sort_order" integer NOT NULL DEFAULT 0,
+    "created_at" timestamp with time zone NOT NULL,
+    "updated_at" timestamp with time zone NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "quota_allocation" (
+    "id" serial NOT NULL PRIMARY KEY,
+    "quota_id" integer NOT NULL,
+    "university_id" integer NOT NULL,
+    "speciality_id" integer NOT NULL,
+    "year" integer NOT NULL DEFAULT 0,
+    "grant_count" integer NOT NULL DEFAULT 0,
+    "min_score" integer NOT NULL DEFAULT 0,
+    "max_score" integer NOT NULL DEFAULT 0,
+    "created_at" timestamp with time zone NOT NULL,
+    "updated_at" timestamp with time zone NOT NULL,
+    UNIQUE ("quota_id", "university_id", "speciality_id", "year")
+);
+
+CREATE TABLE IF NOT EXISTS "quota_criterion" (
+    "id" serial NOT NULL PRIMARY KEY,
+    "code" varchar(64) NOT NULL DEFAULT '' UNIQUE,
+    "question" varchar(512) NOT NULL DEFAULT '',
+    "question_ru" varchar(512) NOT NULL DEFAULT '',
+    "question_kz" varchar(512) NOT NULL DEFAULT '',
+    "created_at" timestamp with time zone NOT NULL,
+    "updated_at" timestamp with time zone NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "quota_rule" (
+    "id" serial NOT NULL PRIMARY KEY,
+    "quota_id" integer NOT NULL,
+    "criterion_code" varchar(64) NOT NULL DEFAULT '',
+    "rule_group" integer NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS "region" (
+    "id" serial NOT NULL PRIMARY KEY,
+    "name" varchar(128) NOT NULL DEFAULT '',
+    "name_ru" varchar(128) NOT NULL DEFAULT '',
+    "name_kz" varchar(128) NOT NULL DEFAULT '',
+    "created_at" timestamp with time zone NOT NULL,
+    "updated_at" timestamp with time zone NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "review" (
+    "id" serial NOT NULL PRIMARY KEY,
+    "university_id" integer NOT NULL,
+    "speciality_id" integer,
+    "user_id" integer NOT NULL DEFAULT 0,
+    "teaching_score" integer NOT NULL DEFAULT 0,
+    "infrastructure_score" integer NOT NULL DEFAULT 0,
+    "student_life_score" integer NOT NULL DEFAULT 0,
+    "career_score" integer NOT NULL DEFAULT 0,
+    "value_score" integer NOT NULL DEFAULT 0,
+    "overall" double precision NOT NULL DEFAULT 0,
+    "text" text NOT NULL,
+    "status" varchar(16) NOT NULL DEFAULT 'pending',
+    "flagged" bool NOT NULL DEFAULT FALSE,
+    "reject_reason" varchar(256) NOT NULL DEFAULT '',
+    "moderated_at" timestamp with time zone,
+    "created_at" timestamp with time zone NOT NULL,
+    "updated_at" timestamp with time zone NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "review_stop_word" (
+    "id" serial NOT NULL PRIMARY KEY,
+    "word" varchar(64) NOT NULL DEFAULT '' UNIQUE,
+    "created_at" timestamp with time zone NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "translation" (
+    "id" serial NOT NULL PRIMARY KEY,
+    "entity_type" varchar(32) NOT NULL DEFAULT '',
+    "entity_id" integer NOT NULL DEFAULT 0,
+    "field" varchar(64) NOT NULL DEFAULT '',
+    "locale" varchar(16) NOT NULL DEFAULT '',
+    "value" text NOT NULL,
+    "updated_at" timestamp with time zone NOT NULL,
+    UNIQUE ("entity_type", "entity_id", "field", "locale")
+);
+
+CREATE TABLE IF NOT EXISTS "university_question" (
+    "id" serial NOT NULL PRIMARY KEY,
+    "university_id" integer NOT NULL,
+    "user_id" integer NOT NULL DEFAULT 0,
+    "text" text NOT NULL,
+    "status" varchar(16) NOT NULL DEFAULT 'published',
+    "vote_count" integer NOT NULL DEFAULT 0,
+    "created_at" timestamp with time zone NOT NULL,
+    "updated_at" timestamp with time zone NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "university_answer" (
+    "id" serial NOT NULL PRIMARY KEY,
+    "question_id" integer NOT NULL,
+    "user_id" integer NOT NULL DEFAULT 0,
+    "author_role" varchar(16) NOT NULL DEFAULT '',
+    "text" text NOT NULL,
+    "status" varchar(16) NOT NULL DEFAULT 'published',
+    "vote_count" integer NOT NULL DEFAULT 0,
+    "created_at" timestamp with time zone NOT NULL,
+    "updated_at" timestamp with time zone NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "university_representative" (
+    "id" serial NOT NULL PRIMARY KEY,
+    "university_id" integer NOT NULL,
+    "user_id" integer NOT NULL DEFAULT 0,
+    "position" varchar(128) NOT NULL DEFAULT '',
+    "created_at" timestamp with time zone NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "qa_vote" (
+    "id" serial NOT NULL PRIMARY KEY,
+    "user_id" integer NOT NULL DEFAULT 0,
+    "question_id" integer,
+    "answer_id" integer
+);
+
+-- Повторы, которые мешают создать уникальные индексы. Для статистики баллов
+-- остаётся последняя добавленная запись, для связей — любая одна.
+DELETE FROM "favorite_university" a USING "favorite_university" b
+    WHERE a."id" < b."id" AND a."user_id" = b."user_id" AND a."university_id" = b."university_id";
+DELETE FROM "point_stat" a USING "point_stat" b
+    WHERE a."id" < b."id" AND a."university_id" = b."university_id"
+    AND a."speciality_id" = b."speciality_id" AND a."year" = b."year";
+DELETE FROM "speciality_university" a USING "speciality_university" b
+    WHERE a."id" < b."id" AND a."university_id" = b."university_id" AND a."speciality_id" = b."speciality_id";
+DELETE FROM "university_service" a USING "university_service" b
+    WHERE a."id" < b."id" AND a."university_id" = b."university_id" AND a."service_id" = b."service_id";
+DELETE FROM "quota_specialities" a USING "quota_specialities" b
+    WHERE a."id" < b."id" AND a."quota_id" = b."quota_id" AND a."speciality_id" = b."speciality_id";
+DELETE FROM "university_representative" a USING "university_representative" b
+    WHERE a."id" < b."id" AND a."user_id" = b."user_id" AND a."university_id" = b."university_id";
+DELETE FROM "qa_vote" a USING "qa_vote" b
+    WHERE a."id" < b."id" AND a."user_id" = b."user_id"
+    AND a."question_id" IS NOT DISTINCT FROM b."question_id" AND a."answer_id" IS NOT DISTINCT FROM b."answer_id";
+
+-- Уникальность, которую раньше проверял только код перед вставкой.
+CREATE UNIQUE INDEX IF NOT EXISTS "favorite_university_user_id_university_id_key" ON "favorite_university" ("user_id", "university_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "point_stat_university_id_speciality_id_year_key" ON "point_stat" ("university_id", "speciality_id", "year");
+CREATE UNIQUE INDEX IF NOT EXISTS "speciality_university_university_id_speciality_id_key" ON "speciality_university" ("university_id", "speciality_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "university_service_university_id_service_id_key" ON "university_service" ("university_id", "service_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "quota_specialities_quota_id_speciality_id_key" ON "quota_specialities" ("quota_id", "speciality_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "university_representative_user_id_university_id_key" ON "university_representative" ("user_id", "university_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "qa_vote_user_id_question_id_key" ON "qa_vote" ("user_id", "question_id") WHERE "question_id" IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS "qa_vote_user_id_answer_id_key" ON "qa_vote" ("user_id", "answer_id") WHERE "answer_id" IS NOT NULL;
+
+-- Индексы по внешним ключам и частым фильтрам. Столбцы, которые стоят первыми
+-- в уникальных индексах выше, отдельного индекса не требуют.
+CREATE INDEX IF NOT EXISTS "gallery_university_id_idx" ON "gallery" ("university_id");
+CREATE INDEX IF NOT EXISTS "admission_event_university_id_idx" ON "admission_event" ("university_id");
+CREATE INDEX IF NOT EXISTS "admission_event_speciality_id_idx" ON "admission_event" ("speciality_id");
+CREATE INDEX IF NOT EXISTS "admission_event_start_date_idx" ON "admission_event" ("start_date");
+CREATE INDEX IF NOT EXISTS "campus_university_id_idx" ON "campus" ("university_id");
+CREATE INDEX IF NOT EXISTS "campus_city_id_idx" ON "campus" ("city_id");
+CREATE INDEX IF NOT EXISTS "city_region_id_idx" ON "city" ("region_id");
+CREATE INDEX IF NOT EXISTS "faq_entry_university_id_idx" ON "faq_entry" ("university_id");
+CREATE INDEX IF NOT EXISTS "favorite_university_university_id_idx" ON "favorite_university" ("university_id");
+CREATE INDEX IF NOT EXISTS "point_stat_speciality_id_idx" ON "point_stat" ("speciality_id");
+CREATE INDEX IF NOT EXISTS "quota_allocation_university_id_idx" ON "quota_allocation" ("university_id");
+CREATE INDEX IF NOT EXISTS "quota_allocation_speciality_id_idx" ON "quota_allocation" ("speciality_id");
+CREATE INDEX IF NOT EXISTS "quota_rule_quota_id_idx" ON "quota_rule" ("quota_id");
+CREATE INDEX IF NOT EXISTS "quota_specialities_speciality_id_idx" ON "quota_specialities" ("speciality_id");
+CREATE INDEX IF NOT EXISTS "review_university_id_status_idx" ON "review" ("university_id", "status");
+CREATE INDEX IF NOT EXISTS "review_user_id_idx" ON "review" ("user_id");
+CREATE INDEX IF NOT EXISTS "speciality_subject_pair_id_idx" ON "speciality" ("subject_pair_id");
+CREATE INDEX IF NOT EXISTS "speciality_university_speciality_id_idx" ON "speciality_university" ("speciality_id");
+CREATE INDEX IF NOT EXISTS "subject_pair_subject1_id_idx" ON "subject_pair" ("subject1_id");
+CREATE INDEX IF NOT EXISTS "subject_pair_subject2_id_idx" ON "subject_pair" ("subject2_id");
+CREATE INDEX IF NOT EXISTS "university_city_id_idx" ON "university" ("city_id");
+CREATE INDEX IF NOT EXISTS "university_question_university_id_idx" ON "university_question" ("university_id");
+CREATE INDEX IF NOT EXISTS "university_answer_question_id_idx" ON "university_answer" ("question_id");
+CREATE INDEX IF NOT EXISTS "university_representative_university_id_idx" ON "university_representative" ("university_id");
+CREATE INDEX IF NOT EXISTS "university_service_service_id_idx" ON "university_service" ("service_id");
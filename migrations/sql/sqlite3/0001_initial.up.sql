@@ -0,0 +1,152 @@
+-- Исходная схема для SQLite: те же таблицы, что и в Postgres (orm sqlall для
+-- драйвера sqlite3).
+
+CREATE TABLE IF NOT EXISTS "gallery" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "university_id" integer NOT NULL,
+    "photo_url" varchar(256) NOT NULL DEFAULT '',
+    "created_at" datetime NOT NULL,
+    "updated_at" datetime NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "city" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "name" varchar(128) NOT NULL DEFAULT '',
+    "name_ru" varchar(128) NOT NULL DEFAULT '',
+    "name_kz" varchar(128) NOT NULL DEFAULT '',
+    "created_at" datetime NOT NULL,
+    "updated_at" datetime NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "favorite_university" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "user_id" integer NOT NULL DEFAULT 0,
+    "university_id" integer NOT NULL,
+    "created_at" datetime NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "point_stat" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "grant_count" integer NOT NULL DEFAULT 0,
+    "min_score" integer NOT NULL DEFAULT 0,
+    "min_grant_score" integer NOT NULL DEFAULT 0,
+    "year" integer NOT NULL DEFAULT 0,
+    "avg_salary" integer NOT NULL DEFAULT 0,
+    "price" integer NOT NULL DEFAULT 0,
+    "speciality_id" integer NOT NULL,
+    "university_id" integer NOT NULL,
+    "created_at" datetime NOT NULL,
+    "updated_at" datetime NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "quota" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "quota_type" varchar(64) NOT NULL DEFAULT '',
+    "quota_type_ru" varchar(64) NOT NULL DEFAULT '',
+    "quota_type_kz" varchar(64) NOT NULL DEFAULT '',
+    "count" integer NOT NULL DEFAULT 0,
+    "min_score" integer NOT NULL DEFAULT 0,
+    "max_score" integer NOT NULL DEFAULT 0,
+    "created_at" datetime NOT NULL,
+    "updated_at" datetime NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "service" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "name" varchar(128) NOT NULL DEFAULT '',
+    "name_ru" varchar(128) NOT NULL DEFAULT '',
+    "name_kz" varchar(128) NOT NULL DEFAULT '',
+    "image_url" varchar(256) NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS "speciality" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "name" varchar(128) NOT NULL DEFAULT '',
+    "name_ru" varchar(128) NOT NULL DEFAULT '',
+    "name_kz" varchar(128) NOT NULL DEFAULT '',
+    "abbreviation_ru" varchar(255) NOT NULL DEFAULT '',
+    "abbreviation_kz" varchar(255) NOT NULL DEFAULT '',
+    "code" varchar(64) NOT NULL DEFAULT '',
+    "video_link" varchar(256) NOT NULL DEFAULT '',
+    "description" text NOT NULL,
+    "description_ru" text NOT NULL,
+    "description_kz" text NOT NULL,
+    "degree" varchar(128) NOT NULL DEFAULT '',
+    "scholarship" bool NOT NULL DEFAULT FALSE,
+    "subject_pair_id" integer,
+    "created_at" datetime NOT NULL,
+    "updated_at" datetime NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "speciality_university" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "university_id" integer NOT NULL,
+    "speciality_id" integer NOT NULL,
+    "term" integer NOT NULL DEFAULT 0,
+    "edu_lang" varchar(255) NOT NULL DEFAULT '',
+    "created_at" datetime NOT NULL,
+    "updated_at" datetime NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "subject" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "name" varchar(128) NOT NULL DEFAULT '',
+    "name_ru" varchar(128) NOT NULL DEFAULT '',
+    "name_kz" varchar(128) NOT NULL DEFAULT '',
+    "created_at" datetime NOT NULL,
+    "updated_at" datetime NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "subject_pair" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "subject1_id" integer NOT NULL,
+    "subject2_id" integer NOT NULL,
+    "created_at" datetime NOT NULL,
+    "updated_at" datetime NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "university" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "university_code" varchar(64) NOT NULL DEFAULT '',
+    "name" varchar(128) NOT NULL DEFAULT '',
+    "name_ru" varchar(128) NOT NULL DEFAULT '',
+    "name_kz" varchar(128) NOT NULL DEFAULT '',
+    "abbreviation" varchar(64) NOT NULL DEFAULT '',
+    "abbreviation_ru" varchar(64) NOT NULL DEFAULT '',
+    "abbreviation_kz" varchar(64) NOT NULL DEFAULT '',
+    "university_status" varchar(64) NOT NULL DEFAULT '',
+    "university_status_ru" varchar(64) NOT NULL DEFAULT '',
+    "university_status_kz" varchar(64) NOT NULL DEFAULT '',
+    "address" varchar(256) NOT NULL DEFAULT '',
+    "website" varchar(128) NOT NULL DEFAULT '',
+    "average_fee" integer NOT NULL DEFAULT 0,
+    "main_image_url" varchar(256) NOT NULL DEFAULT '',
+    "min_entry_score" integer NOT NULL DEFAULT 0,
+    "description" text NOT NULL,
+    "description_ru" text NOT NULL,
+    "description_kz" text NOT NULL,
+    "city_id" integer NOT NULL,
+    "created_at" datetime NOT NULL,
+    "updated_at" datetime NOT NULL,
+    "call_center_number" varchar(64) NOT NULL DEFAULT '',
+    "whats_app_number" varchar(64) NOT NULL DEFAULT '',
+    "study_format" varchar(64) NOT NULL DEFAULT '',
+    "study_format_ru" varchar(64) NOT NULL DEFAULT '',
+    "study_format_kz" varchar(64) NOT NULL DEFAULT '',
+    "address_link" varchar(256) NOT NULL DEFAULT '',
+    "email" varchar(64) NOT NULL DEFAULT '',
+    "rating" varchar(64) NOT NULL DEFAULT '',
+    "popular" bool NOT NULL DEFAULT FALSE
+);
+
+CREATE TABLE IF NOT EXISTS "university_service" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "university_id" integer NOT NULL,
+    "service_id" integer NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "quota_specialities" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "quota_id" integer NOT NULL,
+    "speciality_id" integer NOT NULL
+);
@@ -0,0 +1,53 @@
+-- Откат удаляет индексы и столбцы исходных таблиц, затем новые таблицы.
+
+DROP INDEX IF EXISTS "favorite_university_user_id_university_id_key";
+DROP INDEX IF EXISTS "point_stat_university_id_speciality_id_year_key";
+DROP INDEX IF EXISTS "speciality_university_university_id_speciality_id_key";
+DROP INDEX IF EXISTS "university_service_university_id_service_id_key";
+DROP INDEX IF EXISTS "quota_specialities_quota_id_speciality_id_key";
+DROP INDEX IF EXISTS "gallery_university_id_idx";
+DROP INDEX IF EXISTS "city_region_id_idx";
+DROP INDEX IF EXISTS "favorite_university_university_id_idx";
+DROP INDEX IF EXISTS "point_stat_speciality_id_idx";
+DROP INDEX IF EXISTS "quota_specialities_speciality_id_idx";
+DROP INDEX IF EXISTS "speciality_subject_pair_id_idx";
+DROP INDEX IF EXISTS "speciality_university_speciality_id_idx";
+DROP INDEX IF EXISTS "subject_pair_subject1_id_idx";
+DROP INDEX IF EXISTS "subject_pair_subject2_id_idx";
+DROP INDEX IF EXISTS "university_city_id_idx";
+DROP INDEX IF EXISTS "university_service_service_id_idx";
+
+ALTER TABLE "city" DROP COLUMN "region_id";
+ALTER TABLE "city" DROP COLUMN "latitude";
+ALTER TABLE "city" DROP COLUMN "longitude";
+ALTER TABLE "service" DROP COLUMN "created_at";
+ALTER TABLE "service" DROP COLUMN "updated_at";
+ALTER TABLE "subject" DROP COLUMN "max_score";
+ALTER TABLE "subject" DROP COLUMN "threshold";
+ALTER TABLE "university" DROP COLUMN "max_entry_score";
+ALTER TABLE "university" DROP COLUMN "median_entry_score";
+ALTER TABLE "university" DROP COLUMN "min_fee";
+ALTER TABLE "university" DROP COLUMN "max_fee";
+ALTER TABLE "university" DROP COLUMN "median_fee";
+ALTER TABLE "university" DROP COLUMN "stats_year";
+ALTER TABLE "university" DROP COLUMN "latitude";
+ALTER TABLE "university" DROP COLUMN "longitude";
+ALTER TABLE "university" DROP COLUMN "rating_score";
+ALTER TABLE "university" DROP COLUMN "review_count";
+
+DROP TABLE IF EXISTS "user";
+DROP TABLE IF EXISTS "qa_vote";
+DROP TABLE IF EXISTS "university_representative";
+DROP TABLE IF EXISTS "university_answer";
+DROP TABLE IF EXISTS "university_question";
+DROP TABLE IF EXISTS "translation";
+DROP TABLE IF EXISTS "review_stop_word";
+DROP TABLE IF EXISTS "review";
+DROP TABLE IF EXISTS "region";
+DROP TABLE IF EXISTS "quota_rule";
+DROP TABLE IF EXISTS "quota_criterion";
+DROP TABLE IF EXISTS "quota_allocation";
+DROP TABLE IF EXISTS "faq_entry";
+DROP TABLE IF EXISTS "campus";
+DROP TABLE IF EXISTS "calendar_feed";
+DROP TABLE IF EXISTS "admission_event";
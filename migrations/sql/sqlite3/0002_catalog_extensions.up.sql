@@ -0,0 +1,231 @@
+-- Новые столбцы, таблицы и индексы для SQLite. База SQLite всегда создаётся
+-- с нуля, поэтому ADD COLUMN без IF NOT EXISTS, которого SQLite не знает.
+
+ALTER TABLE "city" ADD COLUMN "region_id" integer;
+ALTER TABLE "city" ADD COLUMN "latitude" real NOT NULL DEFAULT 0;
+ALTER TABLE "city" ADD COLUMN "longitude" real NOT NULL DEFAULT 0;
+ALTER TABLE "service" ADD COLUMN "created_at" datetime NOT NULL DEFAULT '1970-01-01 00:00:00';
+ALTER TABLE "service" ADD COLUMN "updated_at" datetime NOT NULL DEFAULT '1970-01-01 00:00:00';
+ALTER TABLE "subject" ADD COLUMN "max_score" integer NOT NULL DEFAULT 50;
+ALTER TABLE "subject" ADD COLUMN "threshold" integer NOT NULL DEFAULT 5;
+ALTER TABLE "university" ADD COLUMN "max_entry_score" integer NOT NULL DEFAULT 0;
+ALTER TABLE "university" ADD COLUMN "median_entry_score" integer NOT NULL DEFAULT 0;
+ALTER TABLE "university" ADD COLUMN "min_fee" integer NOT NULL DEFAULT 0;
+ALTER TABLE "university" ADD COLUMN "max_fee" integer NOT NULL DEFAULT 0;
+ALTER TABLE "university" ADD COLUMN "median_fee" integer NOT NULL DEFAULT 0;
+ALTER TABLE "university" ADD COLUMN "stats_year" integer NOT NULL DEFAULT 0;
+ALTER TABLE "university" ADD COLUMN "latitude" real NOT NULL DEFAULT 0;
+ALTER TABLE "university" ADD COLUMN "longitude" real NOT NULL DEFAULT 0;
+ALTER TABLE "university" ADD COLUMN "rating_score" real NOT NULL DEFAULT 0;
+ALTER TABLE "university" ADD COLUMN "review_count" integer NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS "admission_event" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "university_id" integer NOT NULL,
+    "speciality_id" integer,
+    "event_type" varchar(64) NOT NULL DEFAULT '',
+    "title" varchar(256) NOT NULL DEFAULT '',
+    "title_ru" varchar(256) NOT NULL DEFAULT '',
+    "title_kz" varchar(256) NOT NULL DEFAULT '',
+    "description" text NOT NULL,
+    "description_ru" text NOT NULL,
+    "description_kz" text NOT NULL,
+    "start_date" datetime NOT NULL,
+    "end_date" datetime NOT NULL,
+    "all_day" bool NOT NULL DEFAULT FALSE,
+    "created_at" datetime NOT NULL,
+    "updated_at" datetime NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "calendar_feed" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "user_id" integer NOT NULL DEFAULT 0 UNIQUE,
+    "token" varchar(64) NOT NULL DEFAULT '' UNIQUE,
+    "created_at" datetime NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "campus" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "university_id" integer NOT NULL,
+    "city_id" integer,
+    "name" varchar(128) NOT NULL DEFAULT '',
+    "name_ru" varchar(128) NOT NULL DEFAULT '',
+    "name_kz" varchar(128) NOT NULL DEFAULT '',
+    "address" varchar(256) NOT NULL DEFAULT '',
+    "latitude" real NOT NULL DEFAULT 0,
+    "longitude" real NOT NULL DEFAULT 0,
+    "created_at" datetime NOT NULL,
+    "updated_at" datetime NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "faq_entry" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "university_id" integer NOT NULL,
+    "question_ru" text NOT NULL,
+    "question_kz" text NOT NULL,
+    "answer_ru" text NOT NULL,
+    "answer_kz" text NOT NULL,
+    "sort_order" integer NOT NULL DEFAULT 0,
+    "created_at" datetime NOT NULL,
+    "updated_at" datetime NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "quota_allocation" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "quota_id" integer NOT NULL,
+    "university_id" integer NOT NULL,
+    "speciality_id" integer NOT NULL,
+    "year" integer NOT NULL DEFAULT 0,
+    "grant_count" integer NOT NULL DEFAULT 0,
+    "min_score" integer NOT NULL DEFAULT 0,
+    "max_score" integer NOT NULL DEFAULT 0,
+    "created_at" datetime NOT NULL,
+    "updated_at" datetime NOT NULL,
+    UNIQUE ("quota_id", "university_id", "speciality_id", "year")
+);
+
+CREATE TABLE IF NOT EXISTS "quota_criterion" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "code" varchar(64) NOT NULL DEFAULT '' UNIQUE,
+    "question" varchar(512) NOT NULL DEFAULT '',
+    "question_ru" varchar(512) NOT NULL DEFAULT '',
+    "question_kz" varchar(512) NOT NULL DEFAULT '',
+    "created_at" datetime NOT NULL,
+    "updated_at" datetime NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "quota_rule" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "quota_id" integer NOT NULL,
+    "criterion_code" varchar(64) NOT NULL DEFAULT '',
+    "rule_group" integer NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS "region" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "name" varchar(128) NOT NULL DEFAULT '',
+    "name_ru" varchar(128) NOT NULL DEFAULT '',
+    "name_kz" varchar(128) NOT NULL DEFAULT '',
+    "created_at" datetime NOT NULL,
+    "updated_at" datetime NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "review" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "university_id" integer NOT NULL,
+    "speciality_id" integer,
+    "user_id" integer NOT NULL DEFAULT 0,
+    "teaching_score" integer NOT NULL DEFAULT 0,
+    "infrastructure_score" integer NOT NULL DEFAULT 0,
+    "student_life_score" integer NOT NULL DEFAULT 0,
+    "career_score" integer NOT NULL DEFAULT 0,
+    "value_score" integer NOT NULL DEFAULT 0,
+    "overall" real NOT NULL DEFAULT 0,
+    "text" text NOT NULL,
+    "status" varchar(16) NOT NULL DEFAULT 'pending',
+    "flagged" bool NOT NULL DEFAULT FALSE,
+    "reject_reason" varchar(256) NOT NULL DEFAULT '',
+    "moderated_at" datetime,
+    "created_at" datetime NOT NULL,
+    "updated_at" datetime NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "review_stop_word" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "word" varchar(64) NOT NULL DEFAULT '' UNIQUE,
+    "created_at" datetime NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "translation" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "entity_type" varchar(32) NOT NULL DEFAULT '',
+    "entity_id" integer NOT NULL DEFAULT 0,
+    "field" varchar(64) NOT NULL DEFAULT '',
+    "locale" varchar(16) NOT NULL DEFAULT '',
+    "value" text NOT NULL,
+    "updated_at" datetime NOT NULL,
+    UNIQUE ("entity_type", "entity_id", "field", "locale")
+);
+
+CREATE TABLE IF NOT EXISTS "university_question" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "university_id" integer NOT NULL,
+    "user_id" integer NOT NULL DEFAULT 0,
+    "text" text NOT NULL,
+    "status" varchar(16) NOT NULL DEFAULT 'published',
+    "vote_count" integer NOT NULL DEFAULT 0,
+    "created_at" datetime NOT NULL,
+    "updated_at" datetime NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "university_answer" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "question_id" integer NOT NULL,
+    "user_id" integer NOT NULL DEFAULT 0,
+    "author_role" varchar(16) NOT NULL DEFAULT '',
+    "text" text NOT NULL,
+    "status" varchar(16) NOT NULL DEFAULT 'published',
+    "vote_count" integer NOT NULL DEFAULT 0,
+    "created_at" datetime NOT NULL,
+    "updated_at" datetime NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "university_representative" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "university_id" integer NOT NULL,
+    "user_id" integer NOT NULL DEFAULT 0,
+    "position" varchar(128) NOT NULL DEFAULT '',
+    "created_at" datetime NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS "qa_vote" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "user_id" integer NOT NULL DEFAULT 0,
+    "question_id" integer,
+    "answer_id" integer
+);
+
+-- В Postgres пользователи лежат в схеме accounts сервиса аккаунтов. Локально
+-- достаточно заглушки с полями, которые читает этот сервис.
+CREATE TABLE IF NOT EXISTS "user" (
+    "id" integer NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "is_superuser" bool NOT NULL DEFAULT FALSE
+);
+
+-- Уникальность, которую раньше проверял только код перед вставкой.
+CREATE UNIQUE INDEX IF NOT EXISTS "favorite_university_user_id_university_id_key" ON "favorite_university" ("user_id", "university_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "point_stat_university_id_speciality_id_year_key" ON "point_stat" ("university_id", "speciality_id", "year");
+CREATE UNIQUE INDEX IF NOT EXISTS "speciality_university_university_id_speciality_id_key" ON "speciality_university" ("university_id", "speciality_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "university_service_university_id_service_id_key" ON "university_service" ("university_id", "service_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "quota_specialities_quota_id_speciality_id_key" ON "quota_specialities" ("quota_id", "speciality_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "university_representative_user_id_university_id_key" ON "university_representative" ("user_id", "university_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "qa_vote_user_id_question_id_key" ON "qa_vote" ("user_id", "question_id") WHERE "question_id" IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS "qa_vote_user_id_answer_id_key" ON "qa_vote" ("user_id", "answer_id") WHERE "answer_id" IS NOT NULL;
+
+-- Индексы по внешним ключам и частым фильтрам. Столбцы, которые стоят первыми
+-- в уникальных индексах выше, отдельного индекса не требуют.
+CREATE INDEX IF NOT EXISTS "gallery_university_id_idx" ON "gallery" ("university_id");
+CREATE INDEX IF NOT EXISTS "admission_event_university_id_idx" ON "admission_event" ("university_id");
+CREATE INDEX IF NOT EXISTS "admission_event_speciality_id_idx" ON "admission_event" ("speciality_id");
+CREATE INDEX IF NOT EXISTS "admission_event_start_date_idx" ON "admission_event" ("start_date");
+CREATE INDEX IF NOT EXISTS "campus_university_id_idx" ON "campus" ("university_id");
+CREATE INDEX IF NOT EXISTS "campus_city_id_idx" ON "campus" ("city_id");
+CREATE INDEX IF NOT EXISTS "city_region_id_idx" ON "city" ("region_id");
+CREATE INDEX IF NOT EXISTS "faq_entry_university_id_idx" ON "faq_entry" ("university_id");
+CREATE INDEX IF NOT EXISTS "favorite_university_university_id_idx" ON "favorite_university" ("university_id");
+CREATE INDEX IF NOT EXISTS "point_stat_speciality_id_idx" ON "point_stat" ("speciality_id");
+CREATE INDEX IF NOT EXISTS "quota_allocation_university_id_idx" ON "quota_allocation" ("university_id");
+CREATE INDEX IF NOT EXISTS "quota_allocation_speciality_id_idx" ON "quota_allocation" ("speciality_id");
+CREATE INDEX IF NOT EXISTS "quota_rule_quota_id_idx" ON "quota_rule" ("quota_id");
+CREATE INDEX IF NOT EXISTS "quota_specialities_speciality_id_idx" ON "quota_specialities" ("speciality_id");
+CREATE INDEX IF NOT EXISTS "review_university_id_status_idx" ON "review" ("university_id", "status");
+CREATE INDEX IF NOT EXISTS "review_user_id_idx" ON "review" ("user_id");
+CREATE INDEX IF NOT EXISTS "speciality_subject_pair_id_idx" ON "speciality" ("subject_pair_id");
+CREATE INDEX IF NOT EXISTS "speciality_university_speciality_id_idx" ON "speciality_university" ("speciality_id");
+CREATE INDEX IF NOT EXISTS "subject_pair_subject1_id_idx" ON "subject_pair" ("subject1_id");
+CREATE INDEX IF NOT EXISTS "subject_pair_subject2_id_idx" ON "subject_pair" ("subject2_id");
+CREATE INDEX IF NOT EXISTS "university_city_id_idx" ON "university" ("city_id");
+CREATE INDEX IF NOT EXISTS "university_question_university_id_idx" ON "university_question" ("university_id");
+CREATE INDEX IF NOT EXISTS "university_answer_question_id_idx" ON "university_answer" ("question_id");
+CREATE INDEX IF NOT EXISTS "university_representative_university_id_idx" ON "university_representative" ("university_id");
+CREATE INDEX IF NOT EXISTS "university_service_service_id_idx" ON "university_service" ("service_id");
@@ -0,0 +1,374 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/astaxie/beego/orm"
+)
+
+const (
+	EventTypeDocumentSubmission = "document_submission"
+	EventTypeGrantResults       = "grant_results"
+	EventTypeCreativeExam       = "creative_exam"
+	EventTypeOpenDay            = "open_day"
+)
+
+type AdmissionEvent struct {
+	Id            int         `orm:"auto"`
+	University    *University `orm:"rel(fk);on_delete(cascade)"`
+	Speciality    *Speciality `orm:"rel(fk);on_delete(set_null);null"`
+	EventType     string      `orm:"size(64)"`
+	Title         string      `orm:"size(256)"`
+	TitleRu       string      `orm:"size(256)"`
+	TitleKz       string      `orm:"size(256)"`
+	Description   string      `orm:"type(text)"`
+	DescriptionRu string      `orm:"type(text)"`
+	DescriptionKz string      `orm:"type(text)"`
+	StartDate     time.Time   `orm:"type(datetime)"`
+	EndDate       time.Time   `orm:"type(datetime)"`
+	AllDay        bool
+	CreatedAt     time.Time `orm:"auto_now_add;type(datetime)"`
+	UpdatedAt     time.Time `orm:"auto_now;type(datetime)"`
+}
+
+// CalendarFeed хранит секретный токен, по которому календарные приложения
+// получают .ics-ленту пользователя без заголовка Authorization.
+type CalendarFeed struct {
+	Id        int       `orm:"auto"`
+	UserId    int       `orm:"column(user_id);unique"`
+	Token     string    `orm:"size(64);unique"`
+	CreatedAt time.Time `orm:"auto_now_add;type(datetime)"`
+}
+
+type AdmissionEventResponse struct {
+	Id             int       `json:"Id"`
+	UniversityId   int       `json:"UniversityId"`
+	UniversityName string    `json:"UniversityName"`
+	SpecialityId   int       `json:"SpecialityId,omitempty"`
+	SpecialityName string    `json:"SpecialityName,omitempty"`
+	EventType      string    `json:"EventType"`
+	Title          string    `json:"Title"`
+	Description    string    `json:"Description"`
+	StartDate      time.Time `json:"StartDate"`
+	EndDate        time.Time `json:"EndDate"`
+	AllDay         bool      `json:"AllDay"`
+	UpdatedAt      time.Time `json:"UpdatedAt"`
+}
+
+type AddAdmissionEventResponse struct {
+	UniversityId  int    `form:"UniversityId" validate:"required"`
+	SpecialityId  int    `form:"SpecialityId"`
+	EventType     string `form:"EventType" validate:"required,oneof=document_submission grant_results creative_exam open_day"`
+	TitleRu       string `form:"TitleRu" validate:"required"`
+	TitleKz       string `form:"TitleKz" validate:"required"`
+	DescriptionRu string `form:"DescriptionRu"`
+	DescriptionKz string `form:"DescriptionKz"`
+	StartDate     string `form:"StartDate" validate:"required"`
+	EndDate       string `form:"EndDate"`
+}
+
+type UpdateAdmissionEventResponse struct {
+	SpecialityId  int    `form:"SpecialityId"`
+	EventType     string `form:"EventType" validate:"omitempty,oneof=document_submission grant_results creative_exam open_day"`
+	TitleRu       string `form:"TitleRu"`
+	TitleKz       string `form:"TitleKz"`
+	DescriptionRu string `form:"DescriptionRu"`
+	DescriptionKz string `form:"DescriptionKz"`
+	StartDate     string `form:"StartDate"`
+	EndDate       string `form:"EndDate"`
+}
+
+func init() {
+	orm.RegisterModel(new(AdmissionEvent), new(CalendarFeed))
+}
+
+func (e *AdmissionEvent) translationKey() (string, int) { return "admission_event", e.Id }
+
+func (e *AdmissionEvent) legacyTranslations() map[string]LocalizedText {
+	return map[string]LocalizedText{
+		"title":       {LocaleRu: e.TitleRu, LocaleKz: e.TitleKz},
+		"description": {LocaleRu: e.DescriptionRu, LocaleKz: e.DescriptionKz},
+	}
+}
+
+// ParseEventDate принимает дату в формате 2006-01-02 (событие на весь день)
+// или RFC3339 (событие с точным временем).
+func ParseEventDate(value string) (time.Time, bool, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid date %q: expected YYYY-MM-DD or RFC3339", value)
+	}
+	return t, false, nil
+}
+
+func AddAdmissionEvent(event *AdmissionEvent) (int64, error) {
+	o := orm.NewOrm()
+
+	university := &University{Id: event.University.Id}
+	if err := o.Read(university); err != nil {
+		return 0, fmt.Errorf("university not found: %v", err)
+	}
+	if event.Speciality != nil {
+		speciality := &Speciality{Id: event.Speciality.Id}
+		if err := o.Read(speciality); err != nil {
+			return 0, fmt.Errorf("speciality not found: %v", err)
+		}
+	}
+	if event.EndDate.IsZero() {
+		event.EndDate = event.StartDate
+	}
+	if event.EndDate.Before(event.StartDate) {
+		return 0, fmt.Errorf("end date must not be before start date")
+	}
+
+	id, err := o.Insert(event)
+	if err != nil {
+		return 0, err
+	}
+	event.Id = int(id)
+	return id, syncTranslations(o, event)
+}
+
+func GetAdmissionEventById(id int, language string) (*AdmissionEventResponse, error) {
+	o := orm.NewOrm()
+	event := &AdmissionEvent{Id: id}
+	if err := o.Read(event); err != nil {
+		return nil, err
+	}
+
+	responses, err := buildAdmissionEventResponses(o, []*AdmissionEvent{event}, language)
+	if err != nil {
+		return nil, err
+	}
+	return responses[0], nil
+}
+
+func GetAdmissionEventByIdForAdmin(id int) (*AdmissionEvent, error) {
+	o := orm.NewOrm()
+	event := &AdmissionEvent{Id: id}
+	if err := o.Read(event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func GetAdmissionEventsByUniversity(universityId int, language string) ([]*AdmissionEventResponse, error) {
+	o := orm.NewOrm()
+	var events []*AdmissionEvent
+	_, err := o.QueryTable("admission_event").
+		Filter("University__Id", universityId).
+		OrderBy("start_date").
+		All(&events)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildAdmissionEventResponses(o, events, language)
+}
+
+func UpdateAdmissionEvent(event *AdmissionEvent) error {
+	if event.EndDate.Before(event.StartDate) {
+		return fmt.Errorf("end date must not be before start date")
+	}
+	o := orm.NewOrm()
+	if _, err := o.Update(event); err != nil {
+		return err
+	}
+	return syncTranslations(o, event)
+}
+
+func DeleteAdmissionEvent(id int) error {
+	o := orm.NewOrm()
+	if _, err := o.Delete(&AdmissionEvent{Id: id}); err != nil {
+		return err
+	}
+	return deleteTranslations(o, "admission_event", id)
+}
+
+// GetUpcomingEventsForUser возвращает не закончившиеся к from события университетов
+// из избранного пользователя, отсортированные по дате начала. Нулевой until снимает
+// ограничение сверху.
+func GetUpcomingEventsForUser(userId int, language string, from, until time.Time, limit int) ([]*AdmissionEventResponse, error) {
+	o := orm.NewOrm()
+
+	var favorites []*FavoriteUniversity
+	_, err := o.QueryTable("favorite_university").Filter("user_id", userId).All(&favorites)
+	if err != nil {
+		return nil, err
+	}
+	if len(favorites) == 0 {
+		return []*AdmissionEventResponse{}, nil
+	}
+
+	universityIds := make([]int, 0, len(favorites))
+	for _, favorite := range favorites {
+		universityIds = append(universityIds, favorite.University.Id)
+	}
+
+	qs := o.QueryTable("admission_event").
+		Filter("University__Id__in", universityIds).
+		Filter("end_date__gte", from).
+		OrderBy("start_date")
+	if !until.IsZero() {
+		qs = qs.Filter("start_date__lte", until)
+	}
+	if limit > 0 {
+		qs = qs.Limit(limit)
+	}
+
+	var events []*AdmissionEvent
+	if _, err := qs.All(&events); err != nil {
+		return nil, err
+	}
+
+	return buildAdmissionEventResponses(o, events, language)
+}
+
+func buildAdmissionEventResponses(o orm.Ormer, events []*AdmissionEvent, language string) ([]*AdmissionEventResponse, error) {
+	responses := make([]*AdmissionEventResponse, 0, len(events))
+	if len(events) == 0 {
+		return responses, nil
+	}
+
+	universityIds := make([]int, 0, len(events))
+	specialityIds := make([]int, 0)
+	for _, event := range events {
+		universityIds = append(universityIds, event.University.Id)
+		if event.Speciality != nil {
+			specialityIds = append(specialityIds, event.Speciality.Id)
+		}
+	}
+
+	universityNames, err := universityNamesByIds(o, universityIds, language)
+	if err != nil {
+		return nil, err
+	}
+	specialityNames, err := specialityNamesByIds(o, specialityIds, language)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, event := range events {
+		title := translate(event, "title", language)
+		description := translate(event, "description", language)
+
+		response := &AdmissionEventResponse{
+			Id:             event.Id,
+			UniversityId:   event.University.Id,
+			UniversityName: universityNames[event.University.Id],
+			EventType:      event.EventType,
+			Title:          title,
+			Description:    description,
+			StartDate:      event.StartDate,
+			EndDate:        event.EndDate,
+			AllDay:         event.AllDay,
+			UpdatedAt:      event.UpdatedAt,
+		}
+		if event.Speciality != nil {
+			response.SpecialityId = event.Speciality.Id
+			response.SpecialityName = specialityNames[event.Speciality.Id]
+		}
+		responses = append(responses, response)
+	}
+
+	return responses, nil
+}
+
+// GetOrCreateCalendarFeedToken возвращает токен ленты пользователя, создавая его при первом обращении.
+func GetOrCreateCalendarFeedToken(userId int) (string, error) {
+	o := orm.NewOrm()
+	feed := &CalendarFeed{}
+	err := o.QueryTable("calendar_feed").Filter("user_id", userId).One(feed)
+	if err == nil {
+		return feed.Token, nil
+	}
+	if err != orm.ErrNoRows {
+		return "", err
+	}
+
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate feed token: %v", err)
+	}
+
+	feed = &CalendarFeed{UserId: userId, Token: hex.EncodeToString(buf)}
+	if _, err := o.Insert(feed); err != nil {
+		return "", err
+	}
+	return feed.Token, nil
+}
+
+func GetUserIdByCalendarFeedToken(token string) (int, error) {
+	o := orm.NewOrm()
+	feed := &CalendarFeed{}
+	if err := o.QueryTable("calendar_feed").Filter("token", token).One(feed); err != nil {
+		return 0, err
+	}
+	return feed.UserId, nil
+}
+
+// BuildICalendar собирает ленту в формате iCalendar (RFC 5545).
+func BuildICalendar(calendarName string, events []*AdmissionEventResponse) string {
+	var b strings.Builder
+	writeICalLine(&b, "BEGIN:VCALENDAR")
+	writeICalLine(&b, "VERSION:2.0")
+	writeICalLine(&b, "PRODID:-//testhub.kz//Admission calendar//RU")
+	writeICalLine(&b, "CALSCALE:GREGORIAN")
+	writeICalLine(&b, "METHOD:PUBLISH")
+	writeICalLine(&b, "X-WR-CALNAME:"+escapeICalText(calendarName))
+
+	for _, event := range events {
+		writeICalLine(&b, "BEGIN:VEVENT")
+		writeICalLine(&b, fmt.Sprintf("UID:admission-event-%d@testhub.kz", event.Id))
+		writeICalLine(&b, "DTSTAMP:"+event.UpdatedAt.UTC().Format("20060102T150405Z"))
+		if event.AllDay {
+			writeICalLine(&b, "DTSTART;VALUE=DATE:"+event.StartDate.Format("20060102"))
+			// DTEND для событий на весь день не включается в интервал
+			writeICalLine(&b, "DTEND;VALUE=DATE:"+event.EndDate.AddDate(0, 0, 1).Format("20060102"))
+		} else {
+			writeICalLine(&b, "DTSTART:"+event.StartDate.UTC().Format("20060102T150405Z"))
+			writeICalLine(&b, "DTEND:"+event.EndDate.UTC().Format("20060102T150405Z"))
+		}
+		summary := event.Title
+		if event.UniversityName != "" {
+			summary = event.UniversityName + ": " + event.Title
+		}
+		writeICalLine(&b, "SUMMARY:"+escapeICalText(summary))
+		if event.Description != "" {
+			writeICalLine(&b, "DESCRIPTION:"+escapeICalText(event.Description))
+		}
+		writeICalLine(&b, "CATEGORIES:"+strings.ToUpper(event.EventType))
+		writeICalLine(&b, "END:VEVENT")
+	}
+
+	writeICalLine(&b, "END:VCALENDAR")
+	return b.String()
+}
+
+func escapeICalText(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return replacer.Replace(s)
+}
+
+// writeICalLine переносит строки длиннее 75 байт, не разрывая UTF-8 символы.
+func writeICalLine(b *strings.Builder, line string) {
+	const limit = 75
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > limit {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestParseEventDate(t *testing.T) {
+	tests := []struct {
+		value  string
+		allDay bool
+		valid  bool
+	}{
+		{"2024-06-20", true, true},
+		{"2024-06-20T10:00:00+05:00", false, true},
+		{"2024-06-20T10:00:00Z", false, true},
+		{"20.06.2024", false, false},
+		{"2024-06-20 10:00", false, false},
+		{"", false, false},
+	}
+	for _, tt := range tests {
+		_, allDay, err := ParseEventDate(tt.value)
+		if (err == nil) != tt.valid || allDay != tt.allDay {
+			t.Errorf("ParseEventDate(%q) = allDay %v, err %v; want allDay %v, valid %v", tt.value, allDay, err, tt.allDay, tt.valid)
+		}
+	}
+}
+
+func TestEscapeICalText(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"Приём документов", "Приём документов"},
+		{"КазНУ; КБТУ, МУИТ", `КазНУ\; КБТУ\, МУИТ`},
+		{`C:\docs`, `C:\\docs`},
+		{"строка 1\nстрока 2\r\nстрока 3", `строка 1\nстрока 2\nстрока 3`},
+	}
+	for _, tt := range tests {
+		if got := escapeICalText(tt.in); got != tt.want {
+			t.Errorf("escapeICalText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWriteICalLineFolding(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"short", "SUMMARY:Open day"},
+		{"exactly 75 bytes", "SUMMARY:" + strings.Repeat("a", 67)},
+		{"long ascii", "DESCRIPTION:" + strings.Repeat("a", 200)},
+		{"long cyrillic", "DESCRIPTION:" + strings.Repeat("Қабылдау ", 30)},
+	}
+	for _, tt := range tests {
+		var b strings.Builder
+		writeICalLine(&b, tt.line)
+		out := b.String()
+		if !strings.HasSuffix(out, "\r\n") {
+			t.Errorf("%s: line is not terminated by CRLF: %q", tt.name, out)
+			continue
+		}
+		lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+		var unfolded strings.Builder
+		for i, l := range lines {
+			if len(l) > 75 {
+				t.Errorf("%s: line %d is %d bytes long", tt.name, i, len(l))
+			}
+			if !utf8.ValidString(l) {
+				t.Errorf("%s: line %d splits a UTF-8 character", tt.name, i)
+			}
+			if i > 0 {
+				if !strings.HasPrefix(l, " ") {
+					t.Errorf("%s: continuation line %d does not start with a space", tt.name, i)
+				}
+				l = l[1:]
+			}
+			unfolded.WriteString(l)
+		}
+		if unfolded.String() != tt.line {
+			t.Errorf("%s: unfolded line = %q, want %q", tt.name, unfolded.String(), tt.line)
+		}
+		if len(tt.line) <= 75 && len(lines) != 1 {
+			t.Errorf("%s: line of %d bytes was folded", tt.name, len(tt.line))
+		}
+	}
+}
+
+func TestBuildICalendar(t *testing.T) {
+	start := time.Date(2024, 6, 20, 0, 0, 0, 0, time.UTC)
+	updated := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	almaty := time.FixedZone("Asia/Almaty", 5*60*60)
+	events := []*AdmissionEventResponse{
+		{
+			Id: 1, UniversityName: "КазНУ", EventType: "document_submission", Title: "Приём документов",
+			StartDate: start, EndDate: start.AddDate(0, 0, 2), AllDay: true, UpdatedAt: updated,
+		},
+		{
+			Id: 2, EventType: "open_day", Title: "День открытых дверей", Description: "Корпус 1, аудитория 101",
+			StartDate: time.Date(2024, 7, 1, 10, 0, 0, 0, almaty), EndDate: time.Date(2024, 7, 1, 12, 0, 0, 0, almaty),
+			UpdatedAt: updated,
+		},
+	}
+	out := BuildICalendar("Мой календарь", events)
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"X-WR-CALNAME:Мой календарь\r\n",
+		"UID:admission-event-1@testhub.kz\r\n",
+		"DTSTAMP:20240501T093000Z\r\n",
+		"DTSTART;VALUE=DATE:20240620\r\n",
+		// DTEND события на весь день — следующий день после последнего.
+		"DTEND;VALUE=DATE:20240623\r\n",
+		"SUMMARY:КазНУ: Приём документов\r\n",
+		"CATEGORIES:DOCUMENT_SUBMISSION\r\n",
+		"DTSTART:20240701T050000Z\r\n",
+		"DTEND:20240701T070000Z\r\n",
+		"SUMMARY:День открытых дверей\r\n",
+		`DESCRIPTION:Корпус 1\, аудитория 101` + "\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("calendar does not contain %q:\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, "BEGIN:VEVENT"); n != len(events) {
+		t.Errorf("calendar has %d events, want %d", n, len(events))
+	}
+	if strings.Count(out, "DESCRIPTION:") != 1 {
+		t.Error("DESCRIPTION is written for an event without a description")
+	}
+}
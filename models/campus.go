@@ -0,0 +1,157 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/astaxie/beego/orm"
+)
+
+// Campus — корпус или филиал университета со своими координатами.
+// Основной адрес университета хранится в самом University.
+type Campus struct {
+	Id         int         `orm:"auto"`
+	University *University `orm:"rel(fk);on_delete(cascade)"`
+	City       *City       `orm:"rel(fk);null;on_delete(set_null)"`
+	Name       string      `orm:"size(128)"`
+	NameRu     string      `orm:"size(128)"`
+	NameKz     string      `orm:"size(128)"`
+	Address    string      `orm:"size(256)"`
+	Latitude   float64
+	Longitude  float64
+	CreatedAt  time.Time `orm:"auto_now_add;type(datetime)"`
+	UpdatedAt  time.Time `orm:"auto_now;type(datetime)"`
+}
+
+type CampusResponse struct {
+	Id           int     `json:"Id"`
+	UniversityId int     `json:"UniversityId"`
+	CityId       int     `json:"CityId,omitempty"`
+	Name         string  `json:"Name"`
+	Address      string  `json:"Address"`
+	Latitude     float64 `json:"Latitude"`
+	Longitude    float64 `json:"Longitude"`
+}
+
+type AddCampusResponse struct {
+	UniversityId int     `form:"UniversityId" validate:"required"`
+	CityId       int     `form:"CityId"`
+	NameRu       string  `form:"NameRu" validate:"required"`
+	NameKz       string  `form:"NameKz" validate:"required"`
+	Address      string  `form:"Address"`
+	Latitude     float64 `form:"Latitude" validate:"required,gte=-90,lte=90"`
+	Longitude    float64 `form:"Longitude" validate:"required,gte=-180,lte=180"`
+}
+
+type UpdateCampusResponse struct {
+	CityId    int     `form:"CityId"`
+	NameRu    string  `form:"NameRu"`
+	NameKz    string  `form:"NameKz"`
+	Address   string  `form:"Address"`
+	Latitude  float64 `form:"Latitude" validate:"gte=-90,lte=90"`
+	Longitude float64 `form:"Longitude" validate:"gte=-180,lte=180"`
+}
+
+func init() {
+	orm.RegisterModel(new(Campus))
+}
+
+func (c *Campus) translationKey() (string, int) { return "campus", c.Id }
+
+func (c *Campus) legacyTranslations() map[string]LocalizedText {
+	return map[string]LocalizedText{"name": {LocaleRu: c.NameRu, LocaleKz: c.NameKz}}
+}
+
+func AddCampus(form *AddCampusResponse) (int64, error) {
+	o := orm.NewOrm()
+	if err := o.Read(&University{Id: form.UniversityId}); err != nil {
+		return 0, fmt.Errorf("university %d not found", form.UniversityId)
+	}
+
+	campus := &Campus{
+		University: &University{Id: form.UniversityId},
+		NameRu:     form.NameRu,
+		NameKz:     form.NameKz,
+		Address:    form.Address,
+		Latitude:   form.Latitude,
+		Longitude:  form.Longitude,
+	}
+	if form.CityId != 0 {
+		if err := o.Read(&City{Id: form.CityId}); err != nil {
+			return 0, fmt.Errorf("city %d not found", form.CityId)
+		}
+		campus.City = &City{Id: form.CityId}
+	}
+	id, err := o.Insert(campus)
+	if err != nil {
+		return 0, err
+	}
+	campus.Id = int(id)
+	return id, syncTranslations(o, campus)
+}
+
+func GetCampusesByUniversity(universityId int, language string) ([]*CampusResponse, error) {
+	o := orm.NewOrm()
+	var campuses []*Campus
+	if _, err := o.QueryTable("campus").Filter("University__Id", universityId).OrderBy("Id").All(&campuses); err != nil {
+		return nil, err
+	}
+
+	responses := make([]*CampusResponse, 0, len(campuses))
+	for _, campus := range campuses {
+		name := translate(campus, "name", language)
+		response := &CampusResponse{
+			Id:           campus.Id,
+			UniversityId: campus.University.Id,
+			Name:         name,
+			Address:      campus.Address,
+			Latitude:     campus.Latitude,
+			Longitude:    campus.Longitude,
+		}
+		if campus.City != nil {
+			response.CityId = campus.City.Id
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+func UpdateCampus(id int, form *UpdateCampusResponse) error {
+	o := orm.NewOrm()
+	campus := &Campus{Id: id}
+	if err := o.Read(campus); err != nil {
+		return err
+	}
+
+	if form.CityId != 0 {
+		campus.City = &City{Id: form.CityId}
+	}
+	if form.NameRu != "" {
+		campus.NameRu = form.NameRu
+	}
+	if form.NameKz != "" {
+		campus.NameKz = form.NameKz
+	}
+	if form.Address != "" {
+		campus.Address = form.Address
+	}
+	if form.Latitude != 0 {
+		campus.Latitude = form.Latitude
+	}
+	if form.Longitude != 0 {
+		campus.Longitude = form.Longitude
+	}
+
+	if _, err := o.Update(campus); err != nil {
+		return err
+	}
+	return syncTranslations(o, campus)
+}
+
+func DeleteCampus(id int) error {
+	o := orm.NewOrm()
+	if _, err := o.Delete(&Campus{Id: id}); err != nil {
+		return err
+	}
+	return deleteTranslations(o, "campus", id)
+}
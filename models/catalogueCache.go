@@ -0,0 +1,37 @@
+package models
+
+// Теги кэша справочных данных. Тег совпадает с сущностью; изменение сущности через
+// админку сбрасывает все ключи с её тегом (см. middleware.CacheInvalidationMiddleware).
+const (
+	CacheTagCity                 = "city"
+	CacheTagRegion               = "region"
+	CacheTagSubject              = "subject"
+	CacheTagSubjectPair          = "subject_pair"
+	CacheTagUniversity           = "university"
+	CacheTagSpeciality           = "speciality"
+	CacheTagSpecialityUniversity = "speciality_university"
+	CacheTagPointStat            = "point_stat"
+	CacheTagService              = "service"
+	CacheTagCampus               = "campus"
+	CacheTagReview               = "review"
+	CacheTagFaq                  = "faq"
+	CacheTagQuota                = "quota"
+	CacheTagEvent                = "event"
+	CacheTagTranslation          = "translation"
+)
+
+var (
+	citiesCacheTags   = []string{CacheTagCity, CacheTagRegion, CacheTagTranslation}
+	subjectsCacheTags = []string{CacheTagSubject, CacheTagTranslation}
+	servicesCacheTags = []string{CacheTagService, CacheTagTranslation}
+
+	// Баллы и стоимость в карточке университета считаются по статистике баллов.
+	universityCacheTags = []string{
+		CacheTagUniversity, CacheTagService, CacheTagCampus, CacheTagReview, CacheTagFaq,
+		CacheTagPointStat, CacheTagTranslation,
+	}
+	universitySpecialitiesCacheTags = []string{
+		CacheTagUniversity, CacheTagSpeciality, CacheTagSpecialityUniversity, CacheTagPointStat,
+		CacheTagSubject, CacheTagSubjectPair, CacheTagTranslation,
+	}
+)
@@ -3,14 +3,18 @@ package models
 import (
 	"fmt"
 	"github.com/astaxie/beego/orm"
+	"testhub-spec-uni/cache"
 	"time"
 )
 
 type City struct {
-	Id           int           `orm:"auto"`
-	Name         string        `orm:"size(128)"`
-	NameRu       string        `orm:"size(128)"`
-	NameKz       string        `orm:"size(128)"`
+	Id           int     `orm:"auto"`
+	Name         string  `orm:"size(128)"`
+	NameRu       string  `orm:"size(128)"`
+	NameKz       string  `orm:"size(128)"`
+	Region       *Region `orm:"rel(fk);null;on_delete(set_null)"`
+	Latitude     float64
+	Longitude    float64
 	Universities []*University `orm:"reverse(many)"`
 	CreatedAt    time.Time     `orm:"auto_now_add;type(datetime)"`
 	UpdatedAt    time.Time     `orm:"auto_now;type(datetime)"`
@@ -19,6 +23,8 @@ type City struct {
 type CityResponse struct {
 	Id        int       `json:"Id"`
 	Name      string    `json:"Name"`
+	Latitude  float64   `json:"Latitude"`
+	Longitude float64   `json:"Longitude"`
 	CreatedAt time.Time `json:"CreatedAt"`
 	UpdatedAt time.Time `json:"UpdatedAt"`
 }
@@ -33,6 +39,16 @@ func init() {
 	orm.RegisterModel(new(City))
 }
 
+func (c *City) translationKey() (string, int) { return "city", c.Id }
+
+func (c *City) legacyTranslations() map[string]LocalizedText {
+	return map[string]LocalizedText{"name": {LocaleRu: c.NameRu, LocaleKz: c.NameKz}}
+}
+
+func (c *City) Localize(language string) {
+	c.Name = translate(c, "name", language)
+}
+
 func AddCity(city *City) (int64, error) {
 	o := orm.NewOrm()
 	id, err := o.Insert(city)
@@ -40,7 +56,7 @@ func AddCity(city *City) (int64, error) {
 		return 0, err
 	}
 	city.Id = int(id)
-	return id, nil
+	return id, syncTranslations(o, city)
 }
 
 func GetCityById(id int, language string) (*City, error) {
@@ -51,26 +67,25 @@ func GetCityById(id int, language string) (*City, error) {
 		return nil, err
 	}
 
-	switch language {
-	case "ru":
-		city.Name = city.NameRu
-	case "kz":
-		city.Name = city.NameKz
-	}
+	city.Localize(language)
 
 	return city, nil
 }
 
 func UpdateCity(city *City) error {
 	o := orm.NewOrm()
-	_, err := o.Update(city)
-	return err
+	if _, err := o.Update(city); err != nil {
+		return err
+	}
+	return syncTranslations(o, city)
 }
 
 func DeleteCity(id int) error {
 	o := orm.NewOrm()
-	_, err := o.Delete(&City{Id: id})
-	return err
+	if _, err := o.Delete(&City{Id: id}); err != nil {
+		return err
+	}
+	return deleteTranslations(o, "city", id)
 }
 
 func GetCityWithUniversities(id int, language string) (*City, error) {
@@ -83,17 +98,18 @@ func GetCityWithUniversities(id int, language string) (*City, error) {
 		return nil, err
 	}
 
-	switch language {
-	case "ru":
-		city.Name = city.NameRu
-	case "kz":
-		city.Name = city.NameKz
-	}
+	city.Localize(language)
 
 	return city, nil
 }
 
 func GetAllCitiesByLanguage(language string) ([]*City, error) {
+	return cache.Remember(cache.Key("cities", language), citiesCacheTags, func() ([]*City, error) {
+		return loadAllCitiesByLanguage(language)
+	})
+}
+
+func loadAllCitiesByLanguage(language string) ([]*City, error) {
 	o := orm.NewOrm()
 	var cities []*City
 	_, err := o.QueryTable("city").All(&cities)
@@ -102,12 +118,7 @@ func GetAllCitiesByLanguage(language string) ([]*City, error) {
 	}
 
 	for _, city := range cities {
-		switch language {
-		case "ru":
-			city.Name = city.NameRu
-		case "kz":
-			city.Name = city.NameKz
-		}
+		city.Localize(language)
 	}
 
 	return cities, nil
@@ -115,24 +126,56 @@ func GetAllCitiesByLanguage(language string) ([]*City, error) {
 
 func SearchCitiesByName(name, language string) ([]City, error) {
 	var results []City
-	var field string
-	switch language {
-	case "ru":
-		field = "name_ru"
-	case "kz":
-		field = "name_kz"
-	default:
-		return results, fmt.Errorf("unsupported language: %s", language)
-	}
 
 	o := orm.NewOrm()
-	query := fmt.Sprintf("SELECT * FROM city WHERE %s LIKE ?", field)
-	searchPattern := fmt.Sprintf("%s%%", name)
+	condition, args := localeSearch("city", "name", language, fmt.Sprintf("%s%%", name), likeColumn)
+	query := "SELECT * FROM city WHERE " + condition
 
-	_, err := o.Raw(query, searchPattern).QueryRows(&results)
+	_, err := o.Raw(query, args...).QueryRows(&results)
+	if err != nil {
+		return results, err
+	}
+	results, err = withKazakhLatinMatches(o, "city", name, true, func() (map[int]string, error) {
+		cities, err := GetAllCitiesByLanguage(LocaleKzLatn)
+		names := make(map[int]string, len(cities))
+		for _, city := range cities {
+			names[city.Id] = city.Name
+		}
+		return names, err
+	}, results)
 	if err != nil {
 		return results, err
 	}
 
+	for i := range results {
+		results[i].Localize(language)
+	}
 	return results, nil
 }
+
+func AssignRegionToCity(cityId, regionId int) error {
+	o := orm.NewOrm()
+	city := &City{Id: cityId}
+	if err := o.Read(city); err != nil {
+		return err
+	}
+	region := &Region{Id: regionId}
+	if err := o.Read(region); err != nil {
+		return err
+	}
+	city.Region = region
+	_, err := o.Update(city, "Region")
+	return err
+}
+
+func GetCitiesByRegion(regionId int, language string) ([]*City, error) {
+	o := orm.NewOrm()
+	var cities []*City
+	if _, err := o.QueryTable("city").Filter("Region__Id", regionId).All(&cities); err != nil {
+		return nil, err
+	}
+	for _, city := range cities {
+		city.Localize(language)
+	}
+	return cities, nil
+}
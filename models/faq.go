@@ -0,0 +1,143 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/astaxie/beego/orm"
+)
+
+// FaqEntry — часто задаваемый вопрос, который администратор закрепляет на странице университета.
+type FaqEntry struct {
+	Id         int         `orm:"auto"`
+	University *University `orm:"rel(fk);on_delete(cascade)"`
+	QuestionRu string      `orm:"type(text)"`
+	QuestionKz string      `orm:"type(text)"`
+	AnswerRu   string      `orm:"type(text)"`
+	AnswerKz   string      `orm:"type(text)"`
+	SortOrder  int         `orm:"default(0)"`
+	CreatedAt  time.Time   `orm:"auto_now_add;type(datetime)"`
+	UpdatedAt  time.Time   `orm:"auto_now;type(datetime)"`
+}
+
+type AddFaqEntryResponse struct {
+	UniversityId int    `form:"UniversityId" validate:"required"`
+	QuestionRu   string `form:"QuestionRu" validate:"required"`
+	QuestionKz   string `form:"QuestionKz" validate:"required"`
+	AnswerRu     string `form:"AnswerRu" validate:"required"`
+	AnswerKz     string `form:"AnswerKz" validate:"required"`
+	SortOrder    int    `form:"SortOrder"`
+}
+
+type UpdateFaqEntryResponse struct {
+	QuestionRu string `form:"QuestionRu"`
+	QuestionKz string `form:"QuestionKz"`
+	AnswerRu   string `form:"AnswerRu"`
+	AnswerKz   string `form:"AnswerKz"`
+	SortOrder  int    `form:"SortOrder"`
+}
+
+type FaqEntryResponse struct {
+	Id        int    `json:"Id"`
+	Question  string `json:"Question"`
+	Answer    string `json:"Answer"`
+	SortOrder int    `json:"SortOrder"`
+}
+
+func init() {
+	orm.RegisterModel(new(FaqEntry))
+}
+
+func (f *FaqEntry) translationKey() (string, int) { return "faq_entry", f.Id }
+
+func (f *FaqEntry) legacyTranslations() map[string]LocalizedText {
+	return map[string]LocalizedText{
+		"question": {LocaleRu: f.QuestionRu, LocaleKz: f.QuestionKz},
+		"answer":   {LocaleRu: f.AnswerRu, LocaleKz: f.AnswerKz},
+	}
+}
+
+func AddFaqEntry(form *AddFaqEntryResponse) (int64, error) {
+	o := orm.NewOrm()
+	if err := o.Read(&University{Id: form.UniversityId}); err != nil {
+		return 0, fmt.Errorf("university %d not found", form.UniversityId)
+	}
+	entry := &FaqEntry{
+		University: &University{Id: form.UniversityId},
+		QuestionRu: form.QuestionRu,
+		QuestionKz: form.QuestionKz,
+		AnswerRu:   form.AnswerRu,
+		AnswerKz:   form.AnswerKz,
+		SortOrder:  form.SortOrder,
+	}
+	id, err := o.Insert(entry)
+	if err != nil {
+		return 0, err
+	}
+	entry.Id = int(id)
+	return id, syncTranslations(o, entry)
+}
+
+// GetFaqEntriesForAdmin возвращает записи FAQ университета на обоих языках.
+func GetFaqEntriesForAdmin(universityId int) ([]*FaqEntry, error) {
+	o := orm.NewOrm()
+	var entries []*FaqEntry
+	_, err := o.QueryTable("faq_entry").Filter("University__Id", universityId).OrderBy("SortOrder", "Id").All(&entries)
+	return entries, err
+}
+
+// GetFaqEntries возвращает записи FAQ университета на выбранном языке.
+func GetFaqEntries(universityId int, language string) ([]*FaqEntryResponse, error) {
+	entries, err := GetFaqEntriesForAdmin(universityId)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*FaqEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		responses = append(responses, &FaqEntryResponse{
+			Id:        entry.Id,
+			Question:  translate(entry, "question", language),
+			Answer:    translate(entry, "answer", language),
+			SortOrder: entry.SortOrder,
+		})
+	}
+	return responses, nil
+}
+
+func UpdateFaqEntry(id int, form *UpdateFaqEntryResponse) error {
+	o := orm.NewOrm()
+	entry := &FaqEntry{Id: id}
+	if err := o.Read(entry); err != nil {
+		return err
+	}
+
+	if form.QuestionRu != "" {
+		entry.QuestionRu = form.QuestionRu
+	}
+	if form.QuestionKz != "" {
+		entry.QuestionKz = form.QuestionKz
+	}
+	if form.AnswerRu != "" {
+		entry.AnswerRu = form.AnswerRu
+	}
+	if form.AnswerKz != "" {
+		entry.AnswerKz = form.AnswerKz
+	}
+	if form.SortOrder != 0 {
+		entry.SortOrder = form.SortOrder
+	}
+
+	if _, err := o.Update(entry); err != nil {
+		return err
+	}
+	return syncTranslations(o, entry)
+}
+
+func DeleteFaqEntry(id int) error {
+	o := orm.NewOrm()
+	if _, err := o.Delete(&FaqEntry{Id: id}); err != nil {
+		return err
+	}
+	return deleteTranslations(o, "faq_entry", id)
+}
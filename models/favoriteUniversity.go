@@ -13,6 +13,7 @@ type UserInfo struct {
 	FirstName string `orm:"column(first_name)"`
 	LastName  string `orm:"column(last_name)"`
 	Balance   int    `orm:"column(balance)"`
+	Language  string `orm:"-" json:"language"`
 }
 
 type FavoriteUniversity struct {
@@ -22,6 +23,12 @@ type FavoriteUniversity struct {
 	CreatedAt  time.Time   `orm:"auto_now_add;type(datetime)"`
 }
 
+func (f *FavoriteUniversity) TableUnique() [][]string {
+	return [][]string{
+		{"UserId", "University"},
+	}
+}
+
 func init() {
 	orm.RegisterModel(new(FavoriteUniversity))
 }
@@ -34,11 +41,9 @@ func AddFavoriteUniversity(userId int, universityId int) error {
 		University: &University{Id: universityId},
 	}
 
-	_, err := o.Insert(favorite)
-	if err != nil {
-		return err
-	}
-	return nil
+	// Повторное добавление не ошибка: пара (user_id, university_id) уникальна.
+	_, _, err := o.ReadOrCreate(favorite, "UserId", "University")
+	return err
 }
 
 func RemoveFavoriteUniversity(userId int, universityId int) error {
@@ -0,0 +1,28 @@
+package models
+
+import "math"
+
+const earthRadiusKm = 6371.0
+
+// GeoPoint — координаты в градусах.
+type GeoPoint struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// IsZero сообщает, что координаты не заданы.
+func (p GeoPoint) IsZero() bool {
+	return p.Latitude == 0 && p.Longitude == 0
+}
+
+// HaversineKm возвращает расстояние между двумя точками по поверхности Земли в километрах.
+func HaversineKm(a, b GeoPoint) float64 {
+	lat1 := a.Latitude * math.Pi / 180
+	lat2 := b.Latitude * math.Pi / 180
+	dLat := (b.Latitude - a.Latitude) * math.Pi / 180
+	dLng := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
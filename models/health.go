@@ -0,0 +1,30 @@
+package models
+
+import (
+	"context"
+	"testhub-spec-uni/config"
+
+	"github.com/astaxie/beego/orm"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// PingDatabase проверяет соединение с базой.
+func PingDatabase(ctx context.Context) error {
+	db, err := orm.GetDB("default")
+	if err != nil {
+		return err
+	}
+	return db.PingContext(ctx)
+}
+
+// PingStorage проверяет, что бакет хранилища изображений доступен с настроенными ключами.
+func PingStorage(ctx context.Context) error {
+	storage := config.Get().Storage
+	sess, err := newStorageSession(storage)
+	if err != nil {
+		return err
+	}
+	_, err = s3.New(sess).HeadBucketWithContext(ctx, &s3.HeadBucketInput{Bucket: aws.String(storage.Bucket)})
+	return err
+}
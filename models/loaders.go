@@ -0,0 +1,81 @@
+package models
+
+import (
+	"github.com/astaxie/beego/orm"
+)
+
+// Пакетные загрузчики для списков: вместо запроса на каждую строку страницы
+// связанные данные загружаются одним запросом по всем ID и раскладываются по map.
+
+// uniqueIds убирает нули и повторы, сохраняя порядок.
+func uniqueIds(ids []int) []int {
+	seen := make(map[int]bool, len(ids))
+	result := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if id != 0 && !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// subjectsByIds загружает предметы одним запросом.
+func subjectsByIds(o orm.Ormer, ids []int) (map[int]*Subject, error) {
+	ids = uniqueIds(ids)
+	result := make(map[int]*Subject, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+	var subjects []*Subject
+	if _, err := o.QueryTable("subject").Filter("Id__in", ids).All(&subjects); err != nil {
+		return nil, err
+	}
+	for _, subject := range subjects {
+		result[subject.Id] = subject
+	}
+	return result, nil
+}
+
+// pointStatsBySpeciality загружает статистику баллов специальностей университета,
+// сгруппированную по ID специальности; внутри группы сначала последние годы.
+func pointStatsBySpeciality(o orm.Ormer, universityId int, specialityIds []int) (map[int][]*PointStat, error) {
+	specialityIds = uniqueIds(specialityIds)
+	result := make(map[int][]*PointStat, len(specialityIds))
+	if len(specialityIds) == 0 {
+		return result, nil
+	}
+	var pointStats []*PointStat
+	if _, err := o.QueryTable("point_stat").
+		Filter("University__Id", universityId).
+		Filter("Speciality__Id__in", specialityIds).
+		OrderBy("-Year").
+		Limit(-1).
+		All(&pointStats); err != nil {
+		return nil, err
+	}
+	for _, ps := range pointStats {
+		result[ps.Speciality.Id] = append(result[ps.Speciality.Id], ps)
+	}
+	return result, nil
+}
+
+// specialityCountsByUniversity считает привязанные специальности для каждого университета.
+func specialityCountsByUniversity(o orm.Ormer, universityIds []int) (map[int]int, error) {
+	universityIds = uniqueIds(universityIds)
+	result := make(map[int]int, len(universityIds))
+	if len(universityIds) == 0 {
+		return result, nil
+	}
+	var links []*SpecialityUniversity
+	if _, err := o.QueryTable("speciality_university").
+		Filter("University__Id__in", universityIds).
+		Limit(-1).
+		All(&links, "Id", "University"); err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		result[link.University.Id]++
+	}
+	return result, nil
+}
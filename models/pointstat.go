@@ -1,7 +1,9 @@
 package models
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/astaxie/beego/orm"
@@ -21,6 +23,12 @@ type PointStat struct {
 	UpdatedAt     time.Time   `orm:"auto_now;type(datetime)"`
 }
 
+func (p *PointStat) TableUnique() [][]string {
+	return [][]string{
+		{"University", "Speciality", "Year"},
+	}
+}
+
 type GetPointStatResponse struct {
 	Id            int `orm:"auto"`
 	GrantCount    int `json:"grant_count"`
@@ -34,8 +42,8 @@ type GetPointStatResponse struct {
 type AddPointStatResponse struct {
 	Id            int `form:"Id"`
 	AnnualGrants  int `form:"GrantCount" validate:"required"`
-	MinScore      int `form:"MinScore" validate:"required"`
-	MinGrantScore int `form:"MinGrantScore" validate:"required"`
+	MinScore      int `form:"MinScore" validate:"required,gte=0,lte=140"`
+	MinGrantScore int `form:"MinGrantScore" validate:"required,gte=0,lte=140"`
 	Year          int `form:"Year" validate:"required"`
 	AvgSalary     int `form:"AvgSalary" validate:"required"`
 	Price         int `form:"Price" validate:"required"`
@@ -44,8 +52,8 @@ type AddPointStatResponse struct {
 type UpdatePointStatResponse struct {
 	Id            int `form:"Id"`
 	AnnualGrants  int `form:"GrantCount"`
-	MinScore      int `form:"MinScore"`
-	MinGrantScore int `form:"MinGrantScore"`
+	MinScore      int `form:"MinScore" validate:"gte=0,lte=140"`
+	MinGrantScore int `form:"MinGrantScore" validate:"gte=0,lte=140"`
 	Year          int `form:"Year"`
 	AvgSalary     int `form:"AvgSalary"`
 	Price         int `form:"Price"`
@@ -55,6 +63,9 @@ func init() {
 	orm.RegisterModel(new(PointStat))
 }
 func AddPointStat(universityId, specialityId int, pointStat *PointStat) (int64, error) {
+	if err := ValidatePointStatScores(pointStat.MinScore, pointStat.MinGrantScore); err != nil {
+		return 0, err
+	}
 	o := orm.NewOrm()
 
 	exists := o.QueryTable("point_stat").Filter("University__Id", universityId).Filter("Speciality__Id", specialityId).Filter("Year", pointStat.Year).Exist()
@@ -62,11 +73,22 @@ func AddPointStat(universityId, specialityId int, pointStat *PointStat) (int64,
 		return 0, fmt.Errorf("PointStat with year %d already exists for the given university and speciality", pointStat.Year)
 	}
 
-	pointStat.University.Id = universityId
-	pointStat.Speciality.Id = specialityId
+	pointStat.University = &University{Id: universityId}
+	pointStat.Speciality = &Speciality{Id: specialityId}
 
+	if err := o.Begin(); err != nil {
+		return 0, err
+	}
 	id, err := o.Insert(pointStat)
 	if err != nil {
+		o.Rollback()
+		return 0, err
+	}
+	if err := refreshUniversityStats(o, universityId); err != nil {
+		o.Rollback()
+		return 0, err
+	}
+	if err := o.Commit(); err != nil {
 		return 0, err
 	}
 	return id, nil
@@ -101,10 +123,21 @@ func GetPointStatsByUniversityAndSpeciality(universityId, specialityId int) ([]*
 func DeletePointStat(id int) error {
 	o := orm.NewOrm()
 	pointStat := PointStat{Id: id}
+	if err := o.Read(&pointStat); err != nil {
+		return err
+	}
+	if err := o.Begin(); err != nil {
+		return err
+	}
 	if _, err := o.Delete(&pointStat); err != nil {
+		o.Rollback()
 		return err
 	}
-	return nil
+	if err := refreshUniversityStats(o, pointStat.University.Id); err != nil {
+		o.Rollback()
+		return err
+	}
+	return o.Commit()
 }
 
 func UpdatePointStatById(id int, form *UpdatePointStatResponse) error {
@@ -133,15 +166,28 @@ func UpdatePointStatById(id int, form *UpdatePointStatResponse) error {
 		pointStat.Price = form.Price
 	}
 
+	if err := ValidatePointStatScores(pointStat.MinScore, pointStat.MinGrantScore); err != nil {
+		return err
+	}
 	return UpdatePointStat(pointStat)
 }
 
 func UpdatePointStat(pointStat *PointStat) error {
 	o := orm.NewOrm()
+	if err := o.Begin(); err != nil {
+		return err
+	}
 	if _, err := o.Update(pointStat); err != nil {
+		o.Rollback()
 		return err
 	}
-	return nil
+	if pointStat.University != nil {
+		if err := refreshUniversityStats(o, pointStat.University.Id); err != nil {
+			o.Rollback()
+			return err
+		}
+	}
+	return o.Commit()
 }
 
 func GetPointStatById(id int) (*PointStat, error) {
@@ -152,3 +198,126 @@ func GetPointStatById(id int) (*PointStat, error) {
 	}
 	return &pointStat, nil
 }
+
+// RefreshUniversityStats пересчитывает MinEntryScore, AverageFee и связанные с ними
+// диапазоны университета по статистике баллов за последний доступный год.
+// Учитываются только специальности, которые сейчас привязаны к университету.
+func RefreshUniversityStats(universityId int) error {
+	return refreshUniversityStats(orm.NewOrm(), universityId)
+}
+
+// refreshUniversityStats пересчитывает показатели через переданный Ormer, чтобы
+// изменение статистики и пересчёт попадали в одну транзакцию.
+func refreshUniversityStats(o orm.Ormer, universityId int) error {
+	university := &University{Id: universityId}
+	if err := o.Read(university); err != nil {
+		return err
+	}
+
+	var specialityIds orm.ParamsList
+	if _, err := o.QueryTable("speciality_university").Filter("university_id", universityId).ValuesFlat(&specialityIds, "Speciality"); err != nil {
+		return err
+	}
+
+	var pointStats []*PointStat
+	if len(specialityIds) > 0 {
+		if _, err := o.QueryTable("point_stat").
+			Filter("University__Id", universityId).
+			Filter("Speciality__Id__in", specialityIds...).
+			All(&pointStats); err != nil {
+			return err
+		}
+	}
+	university.ApplyPointStats(pointStats)
+
+	_, err := o.Update(university, "MinEntryScore", "MaxEntryScore", "MedianEntryScore",
+		"MinFee", "MaxFee", "MedianFee", "AverageFee", "StatsYear")
+	return err
+}
+
+// ApplyPointStats заполняет MinEntryScore, AverageFee, диапазоны и StatsYear
+// университета по статистике привязанных специальностей за последний год.
+func (u *University) ApplyPointStats(pointStats []*PointStat) {
+	var scores, fees []int
+	latestYear := 0
+	for _, ps := range pointStats {
+		if ps.Year > latestYear {
+			latestYear = ps.Year
+		}
+	}
+	for _, ps := range pointStats {
+		if ps.Year != latestYear {
+			continue
+		}
+		if ps.MinScore > 0 {
+			scores = append(scores, ps.MinScore)
+		}
+		if ps.Price > 0 {
+			fees = append(fees, ps.Price)
+		}
+	}
+
+	scoreRange := computeValueRange(scores)
+	feeRange := computeValueRange(fees)
+
+	u.MinEntryScore = scoreRange.Min
+	u.MaxEntryScore = scoreRange.Max
+	u.MedianEntryScore = scoreRange.Median
+	u.MinFee = feeRange.Min
+	u.MaxFee = feeRange.Max
+	u.MedianFee = feeRange.Median
+	u.AverageFee = average(fees)
+	u.StatsYear = latestYear
+}
+
+// RefreshAllUniversityStats пересчитывает производные показатели для всех университетов
+// в одной транзакции: при ошибке ни один университет не остаётся пересчитанным наполовину.
+func RefreshAllUniversityStats(ctx context.Context) error {
+	o, err := newOrmWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := refreshAllUniversityStats(o); err != nil {
+		o.Rollback()
+		return err
+	}
+	return o.Commit()
+}
+
+func refreshAllUniversityStats(o orm.Ormer) error {
+	var universities []*University
+	if _, err := o.QueryTable("university").Limit(-1).All(&universities, "Id"); err != nil {
+		return err
+	}
+	for _, university := range universities {
+		if err := refreshUniversityStats(o, university.Id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func computeValueRange(values []int) ValueRange {
+	if len(values) == 0 {
+		return ValueRange{}
+	}
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	}
+	return ValueRange{Min: sorted[0], Max: sorted[len(sorted)-1], Median: median}
+}
+
+func average(values []int) int {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / len(values)
+}
@@ -0,0 +1,55 @@
+package models
+
+import "testing"
+
+func TestComputeValueRange(t *testing.T) {
+	tests := []struct {
+		values []int
+		want   ValueRange
+	}{
+		{nil, ValueRange{}},
+		{[]int{90}, ValueRange{Min: 90, Max: 90, Median: 90}},
+		{[]int{120, 70, 95}, ValueRange{Min: 70, Max: 120, Median: 95}},
+		{[]int{100, 60, 80, 90}, ValueRange{Min: 60, Max: 100, Median: 85}},
+	}
+	for _, tt := range tests {
+		if got := computeValueRange(tt.values); got != tt.want {
+			t.Errorf("computeValueRange(%v) = %+v, want %+v", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestComputeValueRangeKeepsInput(t *testing.T) {
+	values := []int{3, 1, 2}
+	computeValueRange(values)
+	if values[0] != 3 || values[1] != 1 || values[2] != 2 {
+		t.Fatalf("input was reordered: %v", values)
+	}
+}
+
+// Учитывается только последний год, а нулевые баллы и цены не сдвигают диапазоны.
+func TestApplyPointStats(t *testing.T) {
+	university := &University{}
+	university.ApplyPointStats([]*PointStat{
+		{Year: 2023, MinScore: 50, Price: 500000},
+		{Year: 2024, MinScore: 80, Price: 1000000},
+		{Year: 2024, MinScore: 100, Price: 0},
+		{Year: 2024, MinScore: 0, Price: 2000000},
+		{Year: 2024, MinScore: 90, Price: 1500000},
+	})
+
+	if university.StatsYear != 2024 {
+		t.Errorf("StatsYear = %d, want 2024", university.StatsYear)
+	}
+	if university.MinEntryScore != 80 || university.MaxEntryScore != 100 || university.MedianEntryScore != 90 {
+		t.Errorf("scores = %d/%d/%d, want 80/100/90", university.MinEntryScore, university.MaxEntryScore, university.MedianEntryScore)
+	}
+	if university.MinFee != 1000000 || university.MaxFee != 2000000 || university.MedianFee != 1500000 || university.AverageFee != 1500000 {
+		t.Errorf("fees = %d/%d/%d avg %d", university.MinFee, university.MaxFee, university.MedianFee, university.AverageFee)
+	}
+
+	university.ApplyPointStats(nil)
+	if university.StatsYear != 0 || university.MinEntryScore != 0 || university.AverageFee != 0 {
+		t.Errorf("stats were not cleared: %+v", university)
+	}
+}
@@ -0,0 +1,269 @@
+package models
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testhub-spec-uni/cache"
+	"testing"
+
+	"github.com/astaxie/beego/orm"
+)
+
+// countingDriver — драйвер database/sql, который отвечает заранее заданными строками
+// и считает выполненные запросы. Нужен, чтобы проверять число запросов без Postgres.
+type countingDriver struct {
+	mu       sync.Mutex
+	queries  []string
+	handlers []queryHandler
+}
+
+// queryHandler отвечает на запросы, содержащие match. Если columns не заданы, они
+// берутся из списка SELECT запроса.
+type queryHandler struct {
+	match   string
+	columns []string
+	rows    []map[string]driver.Value
+}
+
+var testDB = &countingDriver{}
+
+func init() {
+	sql.Register("counting", testDB)
+	orm.RegisterDriver("counting", orm.DRPostgres)
+	if err := orm.RegisterDataBase("default", "counting", ""); err != nil {
+		panic(err)
+	}
+	cache.SetBackend(nil)
+}
+
+// reset задаёт ответы и обнуляет счётчик. Кэш переводов загружается заранее, чтобы
+// его периодическая перезагрузка не попадала в подсчёт.
+func (d *countingDriver) reset(handlers ...queryHandler) {
+	d.mu.Lock()
+	d.handlers = handlers
+	d.mu.Unlock()
+	translations.reload()
+	d.mu.Lock()
+	d.queries = nil
+	d.mu.Unlock()
+}
+
+func (d *countingDriver) count() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.queries)
+}
+
+func (d *countingDriver) Open(string) (driver.Conn, error) { return &countingConn{d}, nil }
+
+type countingConn struct{ d *countingDriver }
+
+func (c *countingConn) Prepare(query string) (driver.Stmt, error) {
+	return &countingStmt{d: c.d, query: query}, nil
+}
+func (c *countingConn) Close() error              { return nil }
+func (c *countingConn) Begin() (driver.Tx, error) { return c, nil }
+func (c *countingConn) Commit() error             { return nil }
+func (c *countingConn) Rollback() error           { return nil }
+
+type countingStmt struct {
+	d     *countingDriver
+	query string
+}
+
+func (s *countingStmt) Close() error  { return nil }
+func (s *countingStmt) NumInput() int { return -1 }
+
+func (s *countingStmt) Exec([]driver.Value) (driver.Result, error) {
+	s.d.mu.Lock()
+	s.d.queries = append(s.d.queries, s.query)
+	s.d.mu.Unlock()
+	return driver.RowsAffected(0), nil
+}
+
+func (s *countingStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	s.d.queries = append(s.d.queries, s.query)
+	for _, h := range s.d.handlers {
+		if strings.Contains(s.query, h.match) {
+			columns := h.columns
+			if columns == nil {
+				columns = selectedColumns(s.query)
+			}
+			return &countingRows{columns: columns, rows: h.rows}, nil
+		}
+	}
+	return &countingRows{columns: selectedColumns(s.query)}, nil
+}
+
+// selectedColumns разбирает список колонок SELECT, который строит ORM:
+// SELECT T0."id", T0."name" FROM ...
+func selectedColumns(query string) []string {
+	start := strings.Index(query, "SELECT ")
+	end := strings.Index(query, " FROM ")
+	if start < 0 || end < start {
+		return nil
+	}
+	var columns []string
+	for _, column := range strings.Split(query[start+len("SELECT "):end], ",") {
+		column = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(column), "DISTINCT "))
+		if i := strings.LastIndex(column, "."); i >= 0 && !strings.Contains(column, "(") {
+			column = column[i+1:]
+		}
+		columns = append(columns, strings.Trim(column, `"`))
+	}
+	return columns
+}
+
+type countingRows struct {
+	columns []string
+	rows    []map[string]driver.Value
+	next    int
+}
+
+func (r *countingRows) Columns() []string { return r.columns }
+func (r *countingRows) Close() error      { return nil }
+
+func (r *countingRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	for i, column := range r.columns {
+		dest[i] = r.rows[r.next][column]
+	}
+	r.next++
+	return nil
+}
+
+func specialityPageHandlers(items int) []queryHandler {
+	rows := make([]map[string]driver.Value, 0, items)
+	stats := make([]map[string]driver.Value, 0, 2*items)
+	for i := 1; i <= items; i++ {
+		rows = append(rows, map[string]driver.Value{
+			"speciality_id": int64(i), "speciality_name": fmt.Sprintf("Специальность %d", i),
+			"code": fmt.Sprintf("6B%05d", i), "subject1_id": int64(i%4 + 1), "subject2_id": int64((i+1)%4 + 1),
+		})
+		for _, year := range []int64{2024, 2023} {
+			stats = append(stats, map[string]driver.Value{
+				"id": int64(len(stats) + 1), "speciality_id": int64(i), "university_id": int64(1),
+				"year": year, "grant_count": int64(10), "min_score": int64(70),
+			})
+		}
+	}
+	subjects := make([]map[string]driver.Value, 0, 4)
+	for i := int64(1); i <= 4; i++ {
+		subjects = append(subjects, map[string]driver.Value{"id": i, "name_ru": fmt.Sprintf("Предмет %d", i)})
+	}
+
+	return []queryHandler{
+		{match: "WITH speciality_data", columns: []string{"speciality_id", "speciality_name", "code", "subject1_id", "subject2_id"}, rows: rows},
+		{match: "count_query", columns: []string{"count"}, rows: []map[string]driver.Value{{"count": int64(items)}}},
+		{match: `FROM "subject"`, rows: subjects},
+		{match: `FROM "point_stat"`, rows: stats},
+	}
+}
+
+func universityPageHandlers(items int) []queryHandler {
+	universities := make([]map[string]driver.Value, 0, items)
+	links := make([]map[string]driver.Value, 0, 3*items)
+	for i := 1; i <= items; i++ {
+		universities = append(universities, map[string]driver.Value{"id": int64(i), "name_ru": fmt.Sprintf("Университет %d", i)})
+		for j := 0; j < 3; j++ {
+			links = append(links, map[string]driver.Value{"id": int64(len(links) + 1), "university_id": int64(i)})
+		}
+	}
+	return []queryHandler{
+		{match: `SELECT COUNT(*) FROM "university"`, rows: []map[string]driver.Value{{"COUNT(*)": int64(items)}}},
+		{match: `FROM "university"`, rows: universities},
+		{match: `FROM "speciality_university"`, rows: links},
+		{match: `FROM "favorite_university"`, rows: []map[string]driver.Value{{"id": int64(1), "user_id": int64(7), "university_id": int64(2)}}},
+	}
+}
+
+func subjectPairHandlers(items int) []queryHandler {
+	pairs := make([]map[string]driver.Value, 0, items)
+	for i := 1; i <= items; i++ {
+		pairs = append(pairs, map[string]driver.Value{"id": int64(i), "subject1_id": int64(i%4 + 1), "subject2_id": int64((i+1)%4 + 1)})
+	}
+	subjects := make([]map[string]driver.Value, 0, 4)
+	for i := int64(1); i <= 4; i++ {
+		subjects = append(subjects, map[string]driver.Value{"id": i, "name_ru": fmt.Sprintf("Предмет %d", i)})
+	}
+	return []queryHandler{
+		{match: `FROM "subject_pair"`, rows: pairs},
+		{match: `FROM "subject"`, rows: subjects},
+	}
+}
+
+// Бюджет запросов не зависит от размера страницы.
+func TestListingQueryBudget(t *testing.T) {
+	cases := []struct {
+		name     string
+		budget   int
+		handlers func(items int) []queryHandler
+		run      func(t *testing.T, items int)
+	}{
+		{
+			name:     "specialities in university",
+			budget:   4,
+			handlers: specialityPageHandlers,
+			run: func(t *testing.T, items int) {
+				results, total, err := loadSpecialitiesInUniversityForUser(1, LocaleRu, 1, items)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(results) != items || total != items {
+					t.Fatalf("got %d rows, total %d", len(results), total)
+				}
+				if len(results[0].SubjectNames) != 2 || len(results[0].AnnualPoints) != 2 || results[0].AnnualPoints[0].Year != 2024 {
+					t.Fatalf("related data not attached: %+v", results[0])
+				}
+			},
+		},
+		{
+			name:     "universities",
+			budget:   4,
+			handlers: universityPageHandlers,
+			run: func(t *testing.T, items int) {
+				responses, _, _, _, err := GetAllUniversities(7, LocaleRu, 1, items)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(responses) != items || responses[0].SpecialityCount != 3 || !responses[1].Favorite || responses[0].Favorite {
+					t.Fatalf("unexpected responses: %+v %+v", responses[0], responses[1])
+				}
+			},
+		},
+		{
+			name:     "subject pairs",
+			budget:   2,
+			handlers: subjectPairHandlers,
+			run: func(t *testing.T, items int) {
+				pairs, err := GetAllSubjectPairs()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(pairs) != items || pairs[0].Subject1.NameRu == "" {
+					t.Fatalf("subjects not attached: %+v", pairs[0].Subject1)
+				}
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, items := range []int{10, 100} {
+				testDB.reset(tc.handlers(items)...)
+				tc.run(t, items)
+				if got := testDB.count(); got > tc.budget {
+					t.Fatalf("%d items: %d queries, budget %d:\n%s", items, got, tc.budget, strings.Join(testDB.queries, "\n"))
+				}
+			}
+		})
+	}
+}
@@ -24,10 +24,27 @@ func init() {
 	orm.RegisterModel(new(Quota))
 }
 
+func (q *Quota) translationKey() (string, int) { return "quota", q.Id }
+
+func (q *Quota) legacyTranslations() map[string]LocalizedText {
+	return map[string]LocalizedText{"quota_type": {LocaleRu: q.QuotaTypeRu, LocaleKz: q.QuotaTypeKz}}
+}
+
+func (q *Quota) Localize(language string) {
+	q.QuotaType = translate(q, "quota_type", language)
+}
+
 func AddQuota(quota *Quota) (int64, error) {
+	if err := ValidateScoreRange(quota.MinScore, quota.MaxScore); err != nil {
+		return 0, err
+	}
 	o := orm.NewOrm()
 	id, err := o.Insert(quota)
-	return id, err
+	if err != nil {
+		return 0, err
+	}
+	quota.Id = int(id)
+	return id, syncTranslations(o, quota)
 }
 
 func GetQuotaById(id int, language string) (*Quota, error) {
@@ -38,12 +55,7 @@ func GetQuotaById(id int, language string) (*Quota, error) {
 		return nil, err
 	}
 
-	switch language {
-	case "ru":
-		quota.QuotaType = quota.QuotaTypeRu
-	case "kz":
-		quota.QuotaType = quota.QuotaTypeKz
-	}
+	quota.Localize(language)
 
 	return quota, err
 }
@@ -57,12 +69,7 @@ func GetAllQuotas(language string) ([]*Quota, error) {
 	}
 
 	for _, quota := range quotas {
-		switch language {
-		case "ru":
-			quota.QuotaType = quota.QuotaTypeRu
-		case "kz":
-			quota.QuotaType = quota.QuotaTypeKz
-		}
+		quota.Localize(language)
 	}
 
 	return quotas, err
@@ -70,17 +77,36 @@ func GetAllQuotas(language string) ([]*Quota, error) {
 
 func UpdateQuota(quota *Quota, fields ...string) error {
 	o := orm.NewOrm()
+	existing := &Quota{Id: quota.Id}
+	if err := o.Read(existing); err != nil {
+		return err
+	}
+	minScore, maxScore := existing.MinScore, existing.MaxScore
+	for _, field := range fields {
+		switch field {
+		case "MinScore":
+			minScore = quota.MinScore
+		case "MaxScore":
+			maxScore = quota.MaxScore
+		}
+	}
+	if err := ValidateScoreRange(minScore, maxScore); err != nil {
+		return err
+	}
+
 	_, err := o.Update(quota, fields...)
 	if err != nil {
 		return err
 	}
-	return nil
+	return syncTranslationsById(o, &Quota{Id: quota.Id})
 }
 
 func DeleteQuota(id int) error {
 	o := orm.NewOrm()
-	_, err := o.Delete(&Quota{Id: id})
-	return err
+	if _, err := o.Delete(&Quota{Id: id}); err != nil {
+		return err
+	}
+	return deleteTranslations(o, "quota", id)
 }
 
 func AddSpecialityToQuota(specialityId, quotaId int) error {
@@ -109,18 +135,17 @@ func AddSpecialityToQuota(specialityId, quotaId int) error {
 func GetAllQuotasWithSpecialities(language string) ([]*Quota, error) {
 	o := orm.NewOrm()
 	var quotas []*Quota
-	_, err := o.QueryTable("quota").RelatedSel("Specialities").All(&quotas)
+	_, err := o.QueryTable("quota").All(&quotas)
 	if err != nil {
 		return nil, err
 	}
 
+	// RelatedSel не загружает связи m2m, поэтому специальности читаются по каждой квоте.
 	for _, quota := range quotas {
-		switch language {
-		case "ru":
-			quota.QuotaType = quota.QuotaTypeRu
-		case "kz":
-			quota.QuotaType = quota.QuotaTypeKz
+		if _, err := o.LoadRelated(quota, "Specialities"); err != nil {
+			return nil, err
 		}
+		quota.Localize(language)
 	}
 
 	return quotas, err
@@ -140,12 +165,7 @@ func GetQuotaWithSpecialitiesById(id int, language string) (*Quota, error) {
 		return nil, err
 	}
 
-	switch language {
-	case "ru":
-		quota.QuotaType = quota.QuotaTypeRu
-	case "kz":
-		quota.QuotaType = quota.QuotaTypeKz
-	}
+	quota.Localize(language)
 
 	return quota, nil
 }
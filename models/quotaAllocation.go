@@ -0,0 +1,317 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/astaxie/beego/orm"
+)
+
+// QuotaAllocation — выделенные на год места по квоте (сельская, инвалидность,
+// многодетные семьи и т.д.) для конкретной программы университета.
+type QuotaAllocation struct {
+	Id         int         `orm:"auto"`
+	Quota      *Quota      `orm:"rel(fk);on_delete(cascade)"`
+	University *University `orm:"rel(fk);on_delete(cascade)"`
+	Speciality *Speciality `orm:"rel(fk);on_delete(cascade)"`
+	Year       int
+	GrantCount int
+	MinScore   int
+	MaxScore   int
+	CreatedAt  time.Time `orm:"auto_now_add;type(datetime)"`
+	UpdatedAt  time.Time `orm:"auto_now;type(datetime)"`
+}
+
+func (a *QuotaAllocation) TableUnique() [][]string {
+	return [][]string{
+		{"Quota", "University", "Speciality", "Year"},
+	}
+}
+
+type QuotaAllocationResponse struct {
+	Id           int    `json:"id"`
+	QuotaId      int    `json:"quota_id"`
+	QuotaType    string `json:"quota_type"`
+	UniversityId int    `json:"university_id"`
+	SpecialityId int    `json:"speciality_id"`
+	Year         int    `json:"year"`
+	GrantCount   int    `json:"grant_count"`
+	MinScore     int    `json:"min_score"`
+	MaxScore     int    `json:"max_score"`
+}
+
+type AddQuotaAllocationResponse struct {
+	QuotaId      int `form:"QuotaId" json:"quota_id" validate:"required"`
+	UniversityId int `form:"UniversityId" json:"university_id" validate:"required"`
+	SpecialityId int `form:"SpecialityId" json:"speciality_id" validate:"required"`
+	Year         int `form:"Year" json:"year" validate:"required"`
+	GrantCount   int `form:"GrantCount" json:"grant_count" validate:"gte=0"`
+	MinScore     int `form:"MinScore" json:"min_score" validate:"gte=0,lte=140"`
+	MaxScore     int `form:"MaxScore" json:"max_score" validate:"gte=0,lte=140"`
+}
+
+type UpdateQuotaAllocationResponse struct {
+	GrantCount int `form:"GrantCount"`
+	MinScore   int `form:"MinScore"`
+	MaxScore   int `form:"MaxScore"`
+}
+
+// QuotaAllocationImportResult — итог массового импорта распределения квот.
+type QuotaAllocationImportResult struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+}
+
+// PointStatWithQuotasResponse — статистика программы за год вместе с разбивкой по квотам.
+type PointStatWithQuotasResponse struct {
+	Year        int                        `json:"year"`
+	PointStat   *GetPointStatResponse      `json:"point_stat"`
+	QuotaGrants int                        `json:"quota_grants"`
+	Quotas      []*QuotaAllocationResponse `json:"quotas"`
+}
+
+func init() {
+	orm.RegisterModel(new(QuotaAllocation))
+}
+
+func AddQuotaAllocation(form *AddQuotaAllocationResponse) (int64, error) {
+	o := orm.NewOrm()
+
+	if err := checkQuotaAllocationRefs(o, form); err != nil {
+		return 0, err
+	}
+
+	exists := o.QueryTable("quota_allocation").
+		Filter("Quota__Id", form.QuotaId).
+		Filter("University__Id", form.UniversityId).
+		Filter("Speciality__Id", form.SpecialityId).
+		Filter("Year", form.Year).
+		Exist()
+	if exists {
+		return 0, validationErrorf("quota allocation for year %d already exists for the given quota, university and speciality", form.Year)
+	}
+
+	allocation := &QuotaAllocation{
+		Quota:      &Quota{Id: form.QuotaId},
+		University: &University{Id: form.UniversityId},
+		Speciality: &Speciality{Id: form.SpecialityId},
+		Year:       form.Year,
+		GrantCount: form.GrantCount,
+		MinScore:   form.MinScore,
+		MaxScore:   form.MaxScore,
+	}
+	return o.Insert(allocation)
+}
+
+func GetQuotaAllocationById(id int, language string) (*QuotaAllocationResponse, error) {
+	o := orm.NewOrm()
+	allocation := &QuotaAllocation{Id: id}
+	if err := o.Read(allocation); err != nil {
+		return nil, err
+	}
+
+	responses, err := buildQuotaAllocationResponses(o, []*QuotaAllocation{allocation}, language)
+	if err != nil {
+		return nil, err
+	}
+	return responses[0], nil
+}
+
+// GetQuotaAllocations возвращает распределения квот с необязательными фильтрами;
+// нулевое значение фильтра означает «без ограничения».
+func GetQuotaAllocations(quotaId, universityId, specialityId, year int, language string) ([]*QuotaAllocationResponse, error) {
+	o := orm.NewOrm()
+	qs := o.QueryTable("quota_allocation")
+	if quotaId != 0 {
+		qs = qs.Filter("Quota__Id", quotaId)
+	}
+	if universityId != 0 {
+		qs = qs.Filter("University__Id", universityId)
+	}
+	if specialityId != 0 {
+		qs = qs.Filter("Speciality__Id", specialityId)
+	}
+	if year != 0 {
+		qs = qs.Filter("Year", year)
+	}
+
+	var allocations []*QuotaAllocation
+	if _, err := qs.OrderBy("-Year", "Quota__Id").All(&allocations); err != nil {
+		return nil, err
+	}
+	return buildQuotaAllocationResponses(o, allocations, language)
+}
+
+func UpdateQuotaAllocation(id int, form *UpdateQuotaAllocationResponse) error {
+	o := orm.NewOrm()
+	allocation := &QuotaAllocation{Id: id}
+	if err := o.Read(allocation); err != nil {
+		return err
+	}
+
+	if form.GrantCount != 0 {
+		allocation.GrantCount = form.GrantCount
+	}
+	if form.MinScore != 0 {
+		allocation.MinScore = form.MinScore
+	}
+	if form.MaxScore != 0 {
+		allocation.MaxScore = form.MaxScore
+	}
+	if err := ValidateScoreRange(allocation.MinScore, allocation.MaxScore); err != nil {
+		return err
+	}
+
+	_, err := o.Update(allocation)
+	return err
+}
+
+func DeleteQuotaAllocation(id int) error {
+	o := orm.NewOrm()
+	_, err := o.Delete(&QuotaAllocation{Id: id})
+	return err
+}
+
+// ImportQuotaAllocations вставляет или обновляет распределения квот одной транзакцией.
+// Строка с уже существующим ключом (квота, университет, специальность, год) обновляет запись.
+func ImportQuotaAllocations(rows []*AddQuotaAllocationResponse) (*QuotaAllocationImportResult, error) {
+	o := orm.NewOrm()
+	if err := o.Begin(); err != nil {
+		return nil, err
+	}
+
+	result := &QuotaAllocationImportResult{}
+	for i, row := range rows {
+		if err := checkQuotaAllocationRefs(o, row); err != nil {
+			o.Rollback()
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
+
+		allocation := &QuotaAllocation{}
+		err := o.QueryTable("quota_allocation").
+			Filter("Quota__Id", row.QuotaId).
+			Filter("University__Id", row.UniversityId).
+			Filter("Speciality__Id", row.SpecialityId).
+			Filter("Year", row.Year).
+			One(allocation)
+
+		switch err {
+		case nil:
+			allocation.GrantCount = row.GrantCount
+			allocation.MinScore = row.MinScore
+			allocation.MaxScore = row.MaxScore
+			if _, err := o.Update(allocation, "GrantCount", "MinScore", "MaxScore", "UpdatedAt"); err != nil {
+				o.Rollback()
+				return nil, fmt.Errorf("row %d: %w", i+1, err)
+			}
+			result.Updated++
+		case orm.ErrNoRows:
+			allocation = &QuotaAllocation{
+				Quota:      &Quota{Id: row.QuotaId},
+				University: &University{Id: row.UniversityId},
+				Speciality: &Speciality{Id: row.SpecialityId},
+				Year:       row.Year,
+				GrantCount: row.GrantCount,
+				MinScore:   row.MinScore,
+				MaxScore:   row.MaxScore,
+			}
+			if _, err := o.Insert(allocation); err != nil {
+				o.Rollback()
+				return nil, fmt.Errorf("row %d: %w", i+1, err)
+			}
+			result.Created++
+		default:
+			o.Rollback()
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
+	}
+
+	if err := o.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetPointStatsWithQuotas возвращает статистику программы по годам вместе с распределением квот.
+// Годы, для которых есть только квоты или только PointStat, тоже попадают в ответ.
+func GetPointStatsWithQuotas(universityId, specialityId int, language string) ([]*PointStatWithQuotasResponse, error) {
+	pointStats, err := GetPointStatsByUniversityAndSpeciality(universityId, specialityId)
+	if err != nil {
+		return nil, err
+	}
+	allocations, err := GetQuotaAllocations(0, universityId, specialityId, 0, language)
+	if err != nil {
+		return nil, err
+	}
+
+	byYear := make(map[int]*PointStatWithQuotasResponse)
+	entry := func(year int) *PointStatWithQuotasResponse {
+		if byYear[year] == nil {
+			byYear[year] = &PointStatWithQuotasResponse{Year: year, Quotas: []*QuotaAllocationResponse{}}
+		}
+		return byYear[year]
+	}
+	for _, ps := range pointStats {
+		entry(ps.Year).PointStat = ps
+	}
+	for _, allocation := range allocations {
+		e := entry(allocation.Year)
+		e.Quotas = append(e.Quotas, allocation)
+		e.QuotaGrants += allocation.GrantCount
+	}
+
+	result := make([]*PointStatWithQuotasResponse, 0, len(byYear))
+	for _, e := range byYear {
+		result = append(result, e)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Year > result[j].Year })
+	return result, nil
+}
+
+func checkQuotaAllocationRefs(o orm.Ormer, form *AddQuotaAllocationResponse) error {
+	if err := o.Read(&Quota{Id: form.QuotaId}); err == orm.ErrNoRows {
+		return validationErrorf("quota %d not found", form.QuotaId)
+	} else if err != nil {
+		return err
+	}
+	if !o.QueryTable("speciality_university").Filter("university_id", form.UniversityId).Filter("speciality_id", form.SpecialityId).Exist() {
+		return validationErrorf("speciality %d is not offered by university %d", form.SpecialityId, form.UniversityId)
+	}
+	return ValidateScoreRange(form.MinScore, form.MaxScore)
+}
+
+func buildQuotaAllocationResponses(o orm.Ormer, allocations []*QuotaAllocation, language string) ([]*QuotaAllocationResponse, error) {
+	responses := make([]*QuotaAllocationResponse, 0, len(allocations))
+	if len(allocations) == 0 {
+		return responses, nil
+	}
+
+	quotaIds := make([]interface{}, 0, len(allocations))
+	for _, allocation := range allocations {
+		quotaIds = append(quotaIds, allocation.Quota.Id)
+	}
+	var quotas []*Quota
+	if _, err := o.QueryTable("quota").Filter("Id__in", quotaIds...).All(&quotas); err != nil {
+		return nil, err
+	}
+	quotaNames := make(map[int]string, len(quotas))
+	for _, quota := range quotas {
+		quotaNames[quota.Id] = translate(quota, "quota_type", language)
+	}
+
+	for _, allocation := range allocations {
+		responses = append(responses, &QuotaAllocationResponse{
+			Id:           allocation.Id,
+			QuotaId:      allocation.Quota.Id,
+			QuotaType:    quotaNames[allocation.Quota.Id],
+			UniversityId: allocation.University.Id,
+			SpecialityId: allocation.Speciality.Id,
+			Year:         allocation.Year,
+			GrantCount:   allocation.GrantCount,
+			MinScore:     allocation.MinScore,
+			MaxScore:     allocation.MaxScore,
+		})
+	}
+	return responses, nil
+}
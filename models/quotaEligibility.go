@@ -0,0 +1,377 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/astaxie/beego/orm"
+)
+
+// QuotaCriterion — вопрос анкеты для подбора квот (сельская местность, инвалидность,
+// сирота, многодетная семья, кандас и т.д.). Code используется в правилах QuotaRule.
+type QuotaCriterion struct {
+	Id         int       `orm:"auto"`
+	Code       string    `orm:"size(64);unique"`
+	Question   string    `orm:"size(512)"`
+	QuestionRu string    `orm:"size(512)"`
+	QuestionKz string    `orm:"size(512)"`
+	CreatedAt  time.Time `orm:"auto_now_add;type(datetime)"`
+	UpdatedAt  time.Time `orm:"auto_now;type(datetime)"`
+}
+
+// QuotaRule связывает квоту с критерием. Правила квоты с одинаковым Group
+// объединяются через «И», разные группы — через «ИЛИ»: студент подходит под квоту,
+// если хотя бы одна группа выполнена полностью. Квота без правил не подходит никому.
+type QuotaRule struct {
+	Id            int    `orm:"auto"`
+	Quota         *Quota `orm:"rel(fk);on_delete(cascade)"`
+	CriterionCode string `orm:"size(64)"`
+	Group         int    `orm:"column(rule_group)"`
+}
+
+type QuotaCriterionResponse struct {
+	Id       int    `json:"id"`
+	Code     string `json:"code"`
+	Question string `json:"question"`
+}
+
+type AddQuotaCriterionResponse struct {
+	Code       string `form:"Code" validate:"required,max=64"`
+	QuestionRu string `form:"QuestionRu" validate:"required"`
+	QuestionKz string `form:"QuestionKz" validate:"required"`
+}
+
+type UpdateQuotaCriterionResponse struct {
+	QuestionRu string `form:"QuestionRu"`
+	QuestionKz string `form:"QuestionKz"`
+}
+
+type AddQuotaRuleResponse struct {
+	QuotaId       int    `form:"QuotaId" validate:"required"`
+	CriterionCode string `form:"CriterionCode" validate:"required"`
+	Group         int    `form:"Group"`
+}
+
+type QuotaRuleResponse struct {
+	Id            int    `json:"id"`
+	QuotaId       int    `json:"quota_id"`
+	CriterionCode string `json:"criterion_code"`
+	Group         int    `json:"group"`
+}
+
+// QuotaEligibilityRequest — ответы студента на анкету и его балл ЕНТ. Если переданы
+// баллы по блокам (Unt), итог считается по ним и учитываются пороги предметов.
+type QuotaEligibilityRequest struct {
+	Answers map[string]bool `json:"answers"`
+	Score   int             `json:"score"`
+	Unt     *UntScore       `json:"unt,omitempty"`
+}
+
+type EligibleQuota struct {
+	Id        int    `json:"id"`
+	QuotaType string `json:"quota_type"`
+}
+
+// QuotaProgramOption — программа, где проходной балл по квоте ниже общего конкурса.
+type QuotaProgramOption struct {
+	UniversityId    int    `json:"university_id"`
+	UniversityName  string `json:"university_name"`
+	SpecialityId    int    `json:"speciality_id"`
+	SpecialityName  string `json:"speciality_name"`
+	QuotaId         int    `json:"quota_id"`
+	QuotaType       string `json:"quota_type"`
+	Year            int    `json:"year"`
+	QuotaGrants     int    `json:"quota_grants"`
+	QuotaMinScore   int    `json:"quota_min_score"`
+	GeneralMinScore int    `json:"general_min_score"`
+	Advantage       int    `json:"advantage"`
+	Passes          bool   `json:"passes"`
+}
+
+type QuotaEligibilityResult struct {
+	Unt      *UntEvaluation        `json:"unt,omitempty"`
+	Quotas   []*EligibleQuota      `json:"quotas"`
+	Programs []*QuotaProgramOption `json:"programs"`
+}
+
+func init() {
+	orm.RegisterModel(new(QuotaCriterion), new(QuotaRule))
+}
+
+func (c *QuotaCriterion) translationKey() (string, int) { return "quota_criterion", c.Id }
+
+func (c *QuotaCriterion) legacyTranslations() map[string]LocalizedText {
+	return map[string]LocalizedText{"question": {LocaleRu: c.QuestionRu, LocaleKz: c.QuestionKz}}
+}
+
+func AddQuotaCriterion(form *AddQuotaCriterionResponse) (int64, error) {
+	o := orm.NewOrm()
+	if o.QueryTable("quota_criterion").Filter("Code", form.Code).Exist() {
+		return 0, fmt.Errorf("criterion with code %q already exists", form.Code)
+	}
+	criterion := &QuotaCriterion{
+		Code:       form.Code,
+		QuestionRu: form.QuestionRu,
+		QuestionKz: form.QuestionKz,
+	}
+	id, err := o.Insert(criterion)
+	if err != nil {
+		return 0, err
+	}
+	criterion.Id = int(id)
+	return id, syncTranslations(o, criterion)
+}
+
+func GetAllQuotaCriteria(language string) ([]*QuotaCriterionResponse, error) {
+	o := orm.NewOrm()
+	var criteria []*QuotaCriterion
+	if _, err := o.QueryTable("quota_criterion").OrderBy("Id").All(&criteria); err != nil {
+		return nil, err
+	}
+
+	responses := make([]*QuotaCriterionResponse, 0, len(criteria))
+	for _, criterion := range criteria {
+		question := translate(criterion, "question", language)
+		responses = append(responses, &QuotaCriterionResponse{
+			Id:       criterion.Id,
+			Code:     criterion.Code,
+			Question: question,
+		})
+	}
+	return responses, nil
+}
+
+func GetAllQuotaCriteriaForAdmin() ([]*QuotaCriterion, error) {
+	o := orm.NewOrm()
+	var criteria []*QuotaCriterion
+	_, err := o.QueryTable("quota_criterion").OrderBy("Id").All(&criteria)
+	return criteria, err
+}
+
+func UpdateQuotaCriterion(id int, form *UpdateQuotaCriterionResponse) error {
+	o := orm.NewOrm()
+	criterion := &QuotaCriterion{Id: id}
+	if err := o.Read(criterion); err != nil {
+		return err
+	}
+	if form.QuestionRu != "" {
+		criterion.QuestionRu = form.QuestionRu
+	}
+	if form.QuestionKz != "" {
+		criterion.QuestionKz = form.QuestionKz
+	}
+	if _, err := o.Update(criterion); err != nil {
+		return err
+	}
+	return syncTranslations(o, criterion)
+}
+
+// DeleteQuotaCriterion удаляет критерий вместе с правилами, которые на него ссылаются.
+func DeleteQuotaCriterion(id int) error {
+	o := orm.NewOrm()
+	criterion := &QuotaCriterion{Id: id}
+	if err := o.Read(criterion); err != nil {
+		return err
+	}
+	if _, err := o.QueryTable("quota_rule").Filter("CriterionCode", criterion.Code).Delete(); err != nil {
+		return err
+	}
+	if _, err := o.Delete(criterion); err != nil {
+		return err
+	}
+	return deleteTranslations(o, "quota_criterion", id)
+}
+
+func AddQuotaRule(form *AddQuotaRuleResponse) (int64, error) {
+	o := orm.NewOrm()
+	if err := o.Read(&Quota{Id: form.QuotaId}); err != nil {
+		return 0, fmt.Errorf("quota %d not found", form.QuotaId)
+	}
+	if !o.QueryTable("quota_criterion").Filter("Code", form.CriterionCode).Exist() {
+		return 0, fmt.Errorf("criterion %q not found", form.CriterionCode)
+	}
+	return o.Insert(&QuotaRule{
+		Quota:         &Quota{Id: form.QuotaId},
+		CriterionCode: form.CriterionCode,
+		Group:         form.Group,
+	})
+}
+
+func GetQuotaRulesByQuota(quotaId int) ([]*QuotaRuleResponse, error) {
+	o := orm.NewOrm()
+	var rules []*QuotaRule
+	if _, err := o.QueryTable("quota_rule").Filter("Quota__Id", quotaId).OrderBy("Group", "Id").All(&rules); err != nil {
+		return nil, err
+	}
+
+	responses := make([]*QuotaRuleResponse, 0, len(rules))
+	for _, rule := range rules {
+		responses = append(responses, &QuotaRuleResponse{
+			Id:            rule.Id,
+			QuotaId:       rule.Quota.Id,
+			CriterionCode: rule.CriterionCode,
+			Group:         rule.Group,
+		})
+	}
+	return responses, nil
+}
+
+func DeleteQuotaRule(id int) error {
+	o := orm.NewOrm()
+	_, err := o.Delete(&QuotaRule{Id: id})
+	return err
+}
+
+// MatchQuotas возвращает ID квот, правила которых выполняются для данных ответов.
+func MatchQuotas(rules []*QuotaRule, answers map[string]bool) []int {
+	groups := make(map[int]map[int]bool)
+	for _, rule := range rules {
+		quotaId := rule.Quota.Id
+		if groups[quotaId] == nil {
+			groups[quotaId] = make(map[int]bool)
+		}
+		satisfied, seen := groups[quotaId][rule.Group]
+		if !seen {
+			satisfied = true
+		}
+		groups[quotaId][rule.Group] = satisfied && answers[rule.CriterionCode]
+	}
+
+	var matched []int
+	for quotaId, byGroup := range groups {
+		for _, satisfied := range byGroup {
+			if satisfied {
+				matched = append(matched, quotaId)
+				break
+			}
+		}
+	}
+	sort.Ints(matched)
+	return matched
+}
+
+// CheckQuotaEligibility определяет квоты, под которые подходит студент, и программы,
+// где проходной балл по квоте за последний год ниже общего проходного балла на грант.
+func CheckQuotaEligibility(request *QuotaEligibilityRequest, language string) (*QuotaEligibilityResult, error) {
+	o := orm.NewOrm()
+	result := &QuotaEligibilityResult{Quotas: []*EligibleQuota{}, Programs: []*QuotaProgramOption{}}
+
+	score, passedThresholds := request.Score, true
+	if request.Unt != nil {
+		evaluation, err := EvaluateUntScore(request.Unt)
+		if err != nil {
+			return nil, err
+		}
+		result.Unt = evaluation
+		score, passedThresholds = evaluation.Total, evaluation.PassedThresholds
+	} else if err := ValidateTotalScore("score", score); err != nil {
+		return nil, err
+	}
+
+	var rules []*QuotaRule
+	if _, err := o.QueryTable("quota_rule").All(&rules); err != nil {
+		return nil, err
+	}
+	quotaIds := MatchQuotas(rules, request.Answers)
+	if len(quotaIds) == 0 {
+		return result, nil
+	}
+
+	var quotas []*Quota
+	if _, err := o.QueryTable("quota").Filter("Id__in", quotaIds).OrderBy("Id").All(&quotas); err != nil {
+		return nil, err
+	}
+	quotaNames := make(map[int]string, len(quotas))
+	for _, quota := range quotas {
+		name := translate(quota, "quota_type", language)
+		quotaNames[quota.Id] = name
+		result.Quotas = append(result.Quotas, &EligibleQuota{Id: quota.Id, QuotaType: name})
+	}
+
+	var allocations []*QuotaAllocation
+	if _, err := o.QueryTable("quota_allocation").Filter("Quota__Id__in", quotaIds).Filter("MinScore__gt", 0).All(&allocations); err != nil {
+		return nil, err
+	}
+	if len(allocations) == 0 {
+		return result, nil
+	}
+
+	type programKey struct{ quota, university, speciality int }
+	latest := make(map[programKey]*QuotaAllocation)
+	universitySet := make(map[int]bool)
+	for _, allocation := range allocations {
+		key := programKey{allocation.Quota.Id, allocation.University.Id, allocation.Speciality.Id}
+		if current, ok := latest[key]; !ok || allocation.Year > current.Year {
+			latest[key] = allocation
+		}
+		universitySet[allocation.University.Id] = true
+	}
+
+	universityIds := make([]int, 0, len(universitySet))
+	for id := range universitySet {
+		universityIds = append(universityIds, id)
+	}
+	var pointStats []*PointStat
+	if _, err := o.QueryTable("point_stat").Filter("University__Id__in", universityIds).All(&pointStats); err != nil {
+		return nil, err
+	}
+	type statKey struct{ university, speciality, year int }
+	generalScores := make(map[statKey]int, len(pointStats))
+	for _, ps := range pointStats {
+		generalScores[statKey{ps.University.Id, ps.Speciality.Id, ps.Year}] = ps.MinGrantScore
+	}
+
+	specialitySet := make(map[int]bool)
+	for _, allocation := range latest {
+		general := generalScores[statKey{allocation.University.Id, allocation.Speciality.Id, allocation.Year}]
+		if general == 0 || allocation.MinScore >= general {
+			continue
+		}
+		specialitySet[allocation.Speciality.Id] = true
+		result.Programs = append(result.Programs, &QuotaProgramOption{
+			UniversityId:    allocation.University.Id,
+			SpecialityId:    allocation.Speciality.Id,
+			QuotaId:         allocation.Quota.Id,
+			QuotaType:       quotaNames[allocation.Quota.Id],
+			Year:            allocation.Year,
+			QuotaGrants:     allocation.GrantCount,
+			QuotaMinScore:   allocation.MinScore,
+			GeneralMinScore: general,
+			Advantage:       general - allocation.MinScore,
+			Passes:          passedThresholds && score > 0 && score >= allocation.MinScore,
+		})
+	}
+	if len(result.Programs) == 0 {
+		return result, nil
+	}
+
+	specialityIds := make([]int, 0, len(specialitySet))
+	for id := range specialitySet {
+		specialityIds = append(specialityIds, id)
+	}
+	universityNames, err := universityNamesByIds(o, universityIds, language)
+	if err != nil {
+		return nil, err
+	}
+	specialityNames, err := specialityNamesByIds(o, specialityIds, language)
+	if err != nil {
+		return nil, err
+	}
+	for _, program := range result.Programs {
+		program.UniversityName = universityNames[program.UniversityId]
+		program.SpecialityName = specialityNames[program.SpecialityId]
+	}
+
+	sort.Slice(result.Programs, func(i, j int) bool {
+		a, b := result.Programs[i], result.Programs[j]
+		if a.Passes != b.Passes {
+			return a.Passes
+		}
+		if a.Advantage != b.Advantage {
+			return a.Advantage > b.Advantage
+		}
+		return a.QuotaMinScore < b.QuotaMinScore
+	})
+	return result, nil
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchQuotas(t *testing.T) {
+	rule := func(quotaId, group int, code string) *QuotaRule {
+		return &QuotaRule{Quota: &Quota{Id: quotaId}, CriterionCode: code, Group: group}
+	}
+	// Квота 1: сельская местность. Квота 2: сирота И инвалидность. Квота 3: кандас
+	// ИЛИ многодетная семья.
+	rules := []*QuotaRule{
+		rule(1, 0, "rural"),
+		rule(2, 0, "orphan"),
+		rule(2, 0, "disability"),
+		rule(3, 0, "kandas"),
+		rule(3, 1, "large_family"),
+	}
+	tests := []struct {
+		name    string
+		answers map[string]bool
+		want    []int
+	}{
+		{"no answers", nil, nil},
+		{"all negative", map[string]bool{"rural": false, "orphan": false}, nil},
+		{"single rule", map[string]bool{"rural": true}, []int{1}},
+		{"AND group incomplete", map[string]bool{"orphan": true}, nil},
+		{"AND group complete", map[string]bool{"orphan": true, "disability": true}, []int{2}},
+		{"first OR group", map[string]bool{"kandas": true}, []int{3}},
+		{"second OR group", map[string]bool{"large_family": true}, []int{3}},
+		{"unknown criterion", map[string]bool{"veteran": true}, nil},
+		{
+			"several quotas sorted",
+			map[string]bool{"large_family": true, "rural": true, "orphan": true, "disability": true},
+			[]int{1, 2, 3},
+		},
+	}
+	for _, tt := range tests {
+		if got := MatchQuotas(rules, tt.answers); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: MatchQuotas = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMatchQuotasWithoutRules(t *testing.T) {
+	if got := MatchQuotas(nil, map[string]bool{"rural": true}); got != nil {
+		t.Errorf("MatchQuotas without rules = %v, want none", got)
+	}
+}
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"time"
+
+	"github.com/astaxie/beego/orm"
+)
+
+// Region — область (или город республиканского значения), в которую входят города.
+type Region struct {
+	Id        int       `orm:"auto"`
+	Name      string    `orm:"size(128)"`
+	NameRu    string    `orm:"size(128)"`
+	NameKz    string    `orm:"size(128)"`
+	Cities    []*City   `orm:"reverse(many)"`
+	CreatedAt time.Time `orm:"auto_now_add;type(datetime)"`
+	UpdatedAt time.Time `orm:"auto_now;type(datetime)"`
+}
+
+// UpdateRegionRequest — поля области для частичного обновления; пустые поля не меняются.
+type UpdateRegionRequest struct {
+	Name   string `json:"Name"`
+	NameRu string `json:"NameRu"`
+	NameKz string `json:"NameKz"`
+}
+
+// Apply переносит заданные поля запроса в область.
+func (r *UpdateRegionRequest) Apply(region *Region) {
+	if r.Name != "" {
+		region.Name = r.Name
+	}
+	if r.NameRu != "" {
+		region.NameRu = r.NameRu
+	}
+	if r.NameKz != "" {
+		region.NameKz = r.NameKz
+	}
+}
+
+type RegionResponse struct {
+	Id     int             `json:"Id"`
+	Name   string          `json:"Name"`
+	Cities []*CityResponse `json:"Cities,omitempty"`
+}
+
+func init() {
+	orm.RegisterModel(new(Region))
+}
+
+func (r *Region) translationKey() (string, int) { return "region", r.Id }
+
+func (r *Region) legacyTranslations() map[string]LocalizedText {
+	return map[string]LocalizedText{"name": {LocaleRu: r.NameRu, LocaleKz: r.NameKz}}
+}
+
+func (r *Region) Localize(language string) {
+	r.Name = translate(r, "name", language)
+}
+
+func AddRegion(region *Region) (int64, error) {
+	o := orm.NewOrm()
+	id, err := o.Insert(region)
+	if err != nil {
+		return 0, err
+	}
+	region.Id = int(id)
+	return id, syncTranslations(o, region)
+}
+
+func GetRegionById(id int, language string) (*Region, error) {
+	o := orm.NewOrm()
+	region := &Region{Id: id}
+	if err := o.Read(region); err != nil {
+		return nil, err
+	}
+	region.Localize(language)
+	return region, nil
+}
+
+func GetAllRegions(language string) ([]*Region, error) {
+	o := orm.NewOrm()
+	var regions []*Region
+	if _, err := o.QueryTable("region").OrderBy("Id").All(&regions); err != nil {
+		return nil, err
+	}
+	for _, region := range regions {
+		region.Localize(language)
+	}
+	return regions, nil
+}
+
+func GetRegionWithCities(id int, language string) (*RegionResponse, error) {
+	o := orm.NewOrm()
+	region := &Region{Id: id}
+	if err := o.Read(region); err != nil {
+		return nil, err
+	}
+	if _, err := o.LoadRelated(region, "Cities"); err != nil {
+		return nil, err
+	}
+	region.Localize(language)
+
+	response := &RegionResponse{Id: region.Id, Name: region.Name, Cities: []*CityResponse{}}
+	for _, city := range region.Cities {
+		city.Localize(language)
+		response.Cities = append(response.Cities, &CityResponse{
+			Id:        city.Id,
+			Name:      city.Name,
+			Latitude:  city.Latitude,
+			Longitude: city.Longitude,
+			CreatedAt: city.CreatedAt,
+			UpdatedAt: city.UpdatedAt,
+		})
+	}
+	return response, nil
+}
+
+func UpdateRegion(id int, form *UpdateRegionRequest) error {
+	o := orm.NewOrm()
+	region := &Region{Id: id}
+	if err := o.Read(region); err != nil {
+		return err
+	}
+
+	form.Apply(region)
+	if _, err := o.Update(region, "Name", "NameRu", "NameKz", "UpdatedAt"); err != nil {
+		return err
+	}
+	return syncTranslations(o, region)
+}
+
+func DeleteRegion(id int) error {
+	o := orm.NewOrm()
+	if _, err := o.Delete(&Region{Id: id}); err != nil {
+		return err
+	}
+	return deleteTranslations(o, "region", id)
+}
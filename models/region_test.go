@@ -0,0 +1,26 @@
+package models
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+// Частичное обновление не должно затирать непереданные названия и их переводы.
+func TestUpdateRegionKeepsMissingFields(t *testing.T) {
+	testDB.reset(
+		queryHandler{match: `FROM "region"`, rows: []map[string]driver.Value{{"id": int64(1), "name_ru": "Алматинская область", "name_kz": "Алматы облысы"}}},
+		queryHandler{match: `FROM "translation"`, rows: []map[string]driver.Value{{"id": int64(5), "entity_type": "region", "entity_id": int64(1), "field": "name", "value": "Алматы облысы"}}},
+	)
+	if err := UpdateRegion(1, &UpdateRegionRequest{NameKz: "Алматы облысы"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, query := range testDB.queries {
+		if strings.HasPrefix(query, `DELETE FROM "translation"`) {
+			t.Fatalf("translation was deleted:\n%s", strings.Join(testDB.queries, "\n"))
+		}
+		if strings.HasPrefix(query, `UPDATE "region"`) && strings.Contains(query, "created_at") {
+			t.Fatalf("created_at was overwritten: %s", query)
+		}
+	}
+}
@@ -0,0 +1,386 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/astaxie/beego/orm"
+)
+
+const (
+	ReviewStatusPending  = "pending"
+	ReviewStatusApproved = "approved"
+	ReviewStatusRejected = "rejected"
+)
+
+// Review — отзыв студента об университете (и, при желании, о специальности).
+// Оценки по критериям от 1 до 5; Overall — их среднее. Отзыв попадает в рейтинг
+// университета только после одобрения модератором.
+type Review struct {
+	Id                  int         `orm:"auto"`
+	University          *University `orm:"rel(fk);on_delete(cascade)"`
+	Speciality          *Speciality `orm:"rel(fk);null;on_delete(set_null)"`
+	UserId              int         `orm:"column(user_id)"`
+	TeachingScore       int
+	InfrastructureScore int
+	StudentLifeScore    int
+	CareerScore         int
+	ValueScore          int
+	Overall             float64
+	Text                string    `orm:"type(text)"`
+	Status              string    `orm:"size(16);default(pending)"`
+	Flagged             bool      // текст содержит слова из списка стоп-слов
+	RejectReason        string    `orm:"size(256)"`
+	ModeratedAt         time.Time `orm:"null;type(datetime)"`
+	CreatedAt           time.Time `orm:"auto_now_add;type(datetime)"`
+	UpdatedAt           time.Time `orm:"auto_now;type(datetime)"`
+}
+
+// ReviewStopWord — слово, при наличии которого отзыв помечается для модератора.
+type ReviewStopWord struct {
+	Id        int       `orm:"auto"`
+	Word      string    `orm:"size(64);unique"`
+	CreatedAt time.Time `orm:"auto_now_add;type(datetime)"`
+}
+
+type AddReviewResponse struct {
+	UniversityId        int    `form:"UniversityId" validate:"required"`
+	SpecialityId        int    `form:"SpecialityId"`
+	TeachingScore       int    `form:"TeachingScore" validate:"required,min=1,max=5"`
+	InfrastructureScore int    `form:"InfrastructureScore" validate:"required,min=1,max=5"`
+	StudentLifeScore    int    `form:"StudentLifeScore" validate:"required,min=1,max=5"`
+	CareerScore         int    `form:"CareerScore" validate:"required,min=1,max=5"`
+	ValueScore          int    `form:"ValueScore" validate:"required,min=1,max=5"`
+	Text                string `form:"Text" validate:"required,min=20,max=5000"`
+}
+
+type ReviewResponse struct {
+	Id                  int       `json:"Id"`
+	UniversityId        int       `json:"UniversityId"`
+	SpecialityId        int       `json:"SpecialityId,omitempty"`
+	TeachingScore       int       `json:"TeachingScore"`
+	InfrastructureScore int       `json:"InfrastructureScore"`
+	StudentLifeScore    int       `json:"StudentLifeScore"`
+	CareerScore         int       `json:"CareerScore"`
+	ValueScore          int       `json:"ValueScore"`
+	Overall             float64   `json:"Overall"`
+	Text                string    `json:"Text"`
+	Status              string    `json:"Status,omitempty"`
+	CreatedAt           time.Time `json:"CreatedAt"`
+}
+
+// ReviewForModeration — отзыв в очереди модерации.
+type ReviewForModeration struct {
+	ReviewResponse
+	UserId       int    `json:"UserId"`
+	Flagged      bool   `json:"Flagged"`
+	RejectReason string `json:"RejectReason,omitempty"`
+}
+
+// RatingSummary — агрегированные оценки университета по одобренным отзывам.
+type RatingSummary struct {
+	Overall        float64 `json:"Overall"`
+	ReviewCount    int     `json:"ReviewCount"`
+	Teaching       float64 `json:"Teaching"`
+	Infrastructure float64 `json:"Infrastructure"`
+	StudentLife    float64 `json:"StudentLife"`
+	Career         float64 `json:"Career"`
+	Value          float64 `json:"Value"`
+}
+
+type ReviewListResult struct {
+	Reviews    []*ReviewResponse `json:"reviews"`
+	Page       int               `json:"page"`
+	TotalPages int               `json:"total_pages"`
+	TotalCount int               `json:"total_count"`
+}
+
+func init() {
+	orm.RegisterModel(new(Review), new(ReviewStopWord))
+}
+
+func (r *Review) computeOverall() {
+	sum := r.TeachingScore + r.InfrastructureScore + r.StudentLifeScore + r.CareerScore + r.ValueScore
+	r.Overall = roundRating(float64(sum) / 5)
+}
+
+func (r *Review) toResponse() *ReviewResponse {
+	response := &ReviewResponse{
+		Id:                  r.Id,
+		UniversityId:        r.University.Id,
+		TeachingScore:       r.TeachingScore,
+		InfrastructureScore: r.InfrastructureScore,
+		StudentLifeScore:    r.StudentLifeScore,
+		CareerScore:         r.CareerScore,
+		ValueScore:          r.ValueScore,
+		Overall:             r.Overall,
+		Text:                r.Text,
+		CreatedAt:           r.CreatedAt,
+	}
+	if r.Speciality != nil {
+		response.SpecialityId = r.Speciality.Id
+	}
+	return response
+}
+
+func roundRating(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// AddReview сохраняет отзыв в статусе pending. У пользователя может быть только один
+// неотклонённый отзыв на пару университет/специальность.
+func AddReview(userId int, form *AddReviewResponse) (int64, error) {
+	o := orm.NewOrm()
+
+	if err := o.Read(&University{Id: form.UniversityId}); err != nil {
+		return 0, fmt.Errorf("university %d not found", form.UniversityId)
+	}
+
+	existing := o.QueryTable("review").
+		Filter("user_id", userId).
+		Filter("University__Id", form.UniversityId).
+		Exclude("Status", ReviewStatusRejected)
+	if form.SpecialityId != 0 {
+		if !o.QueryTable("speciality_university").Filter("university_id", form.UniversityId).Filter("speciality_id", form.SpecialityId).Exist() {
+			return 0, fmt.Errorf("speciality %d is not offered by university %d", form.SpecialityId, form.UniversityId)
+		}
+		existing = existing.Filter("Speciality__Id", form.SpecialityId)
+	} else {
+		existing = existing.Filter("Speciality__isnull", true)
+	}
+	if existing.Exist() {
+		return 0, fmt.Errorf("you have already reviewed this university")
+	}
+
+	flagged, err := containsStopWord(o, form.Text)
+	if err != nil {
+		return 0, err
+	}
+
+	review := &Review{
+		University:          &University{Id: form.UniversityId},
+		UserId:              userId,
+		TeachingScore:       form.TeachingScore,
+		InfrastructureScore: form.InfrastructureScore,
+		StudentLifeScore:    form.StudentLifeScore,
+		CareerScore:         form.CareerScore,
+		ValueScore:          form.ValueScore,
+		Text:                strings.TrimSpace(form.Text),
+		Status:              ReviewStatusPending,
+		Flagged:             flagged,
+	}
+	if form.SpecialityId != 0 {
+		review.Speciality = &Speciality{Id: form.SpecialityId}
+	}
+	review.computeOverall()
+
+	return o.Insert(review)
+}
+
+// GetApprovedReviewsByUniversity возвращает одобренные отзывы, новые первыми.
+func GetApprovedReviewsByUniversity(universityId, specialityId, page, perPage int) (*ReviewListResult, error) {
+	o := orm.NewOrm()
+	qs := o.QueryTable("review").Filter("University__Id", universityId).Filter("Status", ReviewStatusApproved)
+	if specialityId != 0 {
+		qs = qs.Filter("Speciality__Id", specialityId)
+	}
+
+	total, err := qs.Count()
+	if err != nil {
+		return nil, err
+	}
+
+	var reviews []*Review
+	if _, err := qs.OrderBy("-CreatedAt").Limit(perPage, (page-1)*perPage).All(&reviews); err != nil {
+		return nil, err
+	}
+
+	result := &ReviewListResult{
+		Reviews:    make([]*ReviewResponse, 0, len(reviews)),
+		Page:       page,
+		TotalPages: (int(total) + perPage - 1) / perPage,
+		TotalCount: int(total),
+	}
+	for _, review := range reviews {
+		result.Reviews = append(result.Reviews, review.toResponse())
+	}
+	return result, nil
+}
+
+// GetReviewsByUser возвращает отзывы пользователя вместе со статусом модерации.
+func GetReviewsByUser(userId int) ([]*ReviewResponse, error) {
+	o := orm.NewOrm()
+	var reviews []*Review
+	if _, err := o.QueryTable("review").Filter("user_id", userId).OrderBy("-CreatedAt").All(&reviews); err != nil {
+		return nil, err
+	}
+
+	responses := make([]*ReviewResponse, 0, len(reviews))
+	for _, review := range reviews {
+		response := review.toResponse()
+		response.Status = review.Status
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// GetModerationQueue возвращает отзывы, ожидающие модерации; помеченные стоп-словами — первыми.
+func GetModerationQueue() ([]*ReviewForModeration, error) {
+	o := orm.NewOrm()
+	var reviews []*Review
+	if _, err := o.QueryTable("review").Filter("Status", ReviewStatusPending).OrderBy("-Flagged", "CreatedAt").All(&reviews); err != nil {
+		return nil, err
+	}
+
+	queue := make([]*ReviewForModeration, 0, len(reviews))
+	for _, review := range reviews {
+		response := review.toResponse()
+		response.Status = review.Status
+		queue = append(queue, &ReviewForModeration{
+			ReviewResponse: *response,
+			UserId:         review.UserId,
+			Flagged:        review.Flagged,
+		})
+	}
+	return queue, nil
+}
+
+// ModerateReview одобряет или отклоняет отзыв и пересчитывает рейтинг университета.
+func ModerateReview(id int, approve bool, reason string) error {
+	o := orm.NewOrm()
+	review := &Review{Id: id}
+	if err := o.Read(review); err != nil {
+		return err
+	}
+
+	review.Status = ReviewStatusRejected
+	review.RejectReason = reason
+	if approve {
+		review.Status = ReviewStatusApproved
+		review.RejectReason = ""
+	}
+	review.ModeratedAt = time.Now()
+
+	if _, err := o.Update(review, "Status", "RejectReason", "ModeratedAt", "UpdatedAt"); err != nil {
+		return err
+	}
+	return RefreshUniversityRating(review.University.Id)
+}
+
+func DeleteReview(id int) error {
+	o := orm.NewOrm()
+	review := &Review{Id: id}
+	if err := o.Read(review); err != nil {
+		return err
+	}
+	if _, err := o.Delete(review); err != nil {
+		return err
+	}
+	return RefreshUniversityRating(review.University.Id)
+}
+
+// GetRatingSummary считает средние оценки университета по одобренным отзывам.
+func GetRatingSummary(universityId int) (*RatingSummary, error) {
+	o := orm.NewOrm()
+	var reviews []*Review
+	if _, err := o.QueryTable("review").
+		Filter("University__Id", universityId).
+		Filter("Status", ReviewStatusApproved).
+		All(&reviews, "Overall", "TeachingScore", "InfrastructureScore", "StudentLifeScore", "CareerScore", "ValueScore"); err != nil {
+		return nil, err
+	}
+
+	summary := &RatingSummary{ReviewCount: len(reviews)}
+	if len(reviews) == 0 {
+		return summary, nil
+	}
+	for _, review := range reviews {
+		summary.Overall += review.Overall
+		summary.Teaching += float64(review.TeachingScore)
+		summary.Infrastructure += float64(review.InfrastructureScore)
+		summary.StudentLife += float64(review.StudentLifeScore)
+		summary.Career += float64(review.CareerScore)
+		summary.Value += float64(review.ValueScore)
+	}
+	count := float64(len(reviews))
+	summary.Overall = roundRating(summary.Overall / count)
+	summary.Teaching = roundRating(summary.Teaching / count)
+	summary.Infrastructure = roundRating(summary.Infrastructure / count)
+	summary.StudentLife = roundRating(summary.StudentLife / count)
+	summary.Career = roundRating(summary.Career / count)
+	summary.Value = roundRating(summary.Value / count)
+	return summary, nil
+}
+
+// RefreshUniversityRating сохраняет агрегированную оценку в University, чтобы по ней
+// можно было сортировать результаты поиска без подсчёта отзывов на каждый запрос.
+func RefreshUniversityRating(universityId int) error {
+	summary, err := GetRatingSummary(universityId)
+	if err != nil {
+		return err
+	}
+
+	o := orm.NewOrm()
+	university := &University{Id: universityId, RatingScore: summary.Overall, ReviewCount: summary.ReviewCount}
+	_, err = o.Update(university, "RatingScore", "ReviewCount")
+	return err
+}
+
+func GetReviewStopWords() ([]*ReviewStopWord, error) {
+	o := orm.NewOrm()
+	var words []*ReviewStopWord
+	_, err := o.QueryTable("review_stop_word").OrderBy("Word").All(&words)
+	return words, err
+}
+
+func AddReviewStopWord(word string) (int64, error) {
+	word = strings.ToLower(strings.TrimSpace(word))
+	if word == "" {
+		return 0, fmt.Errorf("word must not be empty")
+	}
+	o := orm.NewOrm()
+	if o.QueryTable("review_stop_word").Filter("Word", word).Exist() {
+		return 0, fmt.Errorf("stop word %q already exists", word)
+	}
+	return o.Insert(&ReviewStopWord{Word: word})
+}
+
+func DeleteReviewStopWord(id int) error {
+	o := orm.NewOrm()
+	_, err := o.Delete(&ReviewStopWord{Id: id})
+	return err
+}
+
+func containsStopWord(o orm.Ormer, text string) (bool, error) {
+	var words []*ReviewStopWord
+	if _, err := o.QueryTable("review_stop_word").All(&words, "Word"); err != nil {
+		return false, err
+	}
+	stopWords := make([]string, 0, len(words))
+	for _, w := range words {
+		stopWords = append(stopWords, w.Word)
+	}
+	return MatchStopWords(text, stopWords), nil
+}
+
+// MatchStopWords проверяет, встречается ли в тексте одно из стоп-слов как отдельное слово
+// или как начало слова (чтобы ловить падежные формы), без учёта регистра.
+func MatchStopWords(text string, stopWords []string) bool {
+	if len(stopWords) == 0 {
+		return false
+	}
+	tokens := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, token := range tokens {
+		for _, word := range stopWords {
+			if word != "" && strings.HasPrefix(token, word) {
+				return true
+			}
+		}
+	}
+	return false
+}
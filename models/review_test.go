@@ -0,0 +1,64 @@
+package models
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/astaxie/beego/orm"
+)
+
+func TestMatchStopWords(t *testing.T) {
+	stopWords := []string{"дурак", "scam", "обман"}
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"Хороший университет, сильные преподаватели", false},
+		{"Это обман", true},
+		{"ОБМАН!", true},
+		{"Сплошной обманщик в деканате", true},
+		{"Преподаватели — дураки", true},
+		{"Total SCAM, avoid", true},
+		{"scam-университет", true},
+		// Стоп-слово внутри другого слова не считается.
+		{"Никакого самообмана", false},
+		{"Escambia", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := MatchStopWords(tt.text, stopWords); got != tt.want {
+			t.Errorf("MatchStopWords(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestMatchStopWordsWithoutWords(t *testing.T) {
+	for _, stopWords := range [][]string{nil, {""}} {
+		if MatchStopWords("любой текст", stopWords) {
+			t.Errorf("MatchStopWords with %q flagged the text", stopWords)
+		}
+	}
+}
+
+func TestModerationStatus(t *testing.T) {
+	stopWords := queryHandler{match: `FROM "review_stop_word"`, rows: []map[string]driver.Value{{"word": "обман"}}}
+	tests := []struct {
+		text     string
+		handlers []queryHandler
+		want     string
+	}{
+		{"Есть ли общежитие?", []queryHandler{stopWords}, QAStatusPublished},
+		{"Правда, что грант — обман?", []queryHandler{stopWords}, QAStatusPending},
+		{"Правда, что грант — обман?", nil, QAStatusPublished},
+	}
+	for _, tt := range tests {
+		testDB.reset(tt.handlers...)
+		got, err := moderationStatus(orm.NewOrm(), tt.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("moderationStatus(%q) with %d stop word handler(s) = %q, want %q", tt.text, len(tt.handlers), got, tt.want)
+		}
+	}
+}
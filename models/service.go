@@ -1,9 +1,13 @@
 package models
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/astaxie/beego/orm"
+	"log/slog"
+	"testhub-spec-uni/cache"
+	"time"
 )
 
 type Service struct {
@@ -13,6 +17,8 @@ type Service struct {
 	NameKz       string        `orm:"size(128)"`
 	ImageUrl     string        `orm:"size(256)"`
 	Universities []*University `orm:"reverse(many)"`
+	CreatedAt    time.Time     `orm:"auto_now_add;type(datetime)"`
+	UpdatedAt    time.Time     `orm:"auto_now;type(datetime)"`
 }
 
 type AddServiceForAdminResponse struct {
@@ -39,6 +45,12 @@ func init() {
 	orm.RegisterModel(new(Service))
 }
 
+func (s *Service) translationKey() (string, int) { return "service", s.Id }
+
+func (s *Service) legacyTranslations() map[string]LocalizedText {
+	return map[string]LocalizedText{"name": {LocaleRu: s.NameRu, LocaleKz: s.NameKz}}
+}
+
 func AddService(service *Service) (int64, error) {
 	o := orm.NewOrm()
 	id, err := o.Insert(service)
@@ -46,13 +58,15 @@ func AddService(service *Service) (int64, error) {
 		return 0, err
 	}
 	service.Id = int(id)
-	return id, nil
+	return id, syncTranslations(o, service)
 }
 
 func DeleteService(id int) error {
 	o := orm.NewOrm()
-	_, err := o.Delete(&Service{Id: id})
-	return err
+	if _, err := o.Delete(&Service{Id: id}); err != nil {
+		return err
+	}
+	return deleteTranslations(o, "service", id)
 }
 
 func UpdateService(service *Service, fields ...string) error {
@@ -61,7 +75,7 @@ func UpdateService(service *Service, fields ...string) error {
 	if err != nil {
 		return err
 	}
-	return nil
+	return syncTranslationsById(o, &Service{Id: service.Id})
 }
 
 func GetServiceById(id int, language string) (*ServiceResponseForUser, error) {
@@ -72,13 +86,7 @@ func GetServiceById(id int, language string) (*ServiceResponseForUser, error) {
 		return nil, err
 	}
 
-	name := service.Name
-	switch language {
-	case "ru":
-		name = service.NameRu
-	case "kz":
-		name = service.NameKz
-	}
+	name := translate(service, "name", language)
 
 	return &ServiceResponseForUser{
 		Id:       service.Id,
@@ -99,6 +107,12 @@ func GetServiceByID(serviceID int) (*Service, error) {
 }
 
 func GetAllServices(language string) ([]*ServiceResponseForUser, error) {
+	return cache.Remember(cache.Key("services", language), servicesCacheTags, func() ([]*ServiceResponseForUser, error) {
+		return loadAllServices(language)
+	})
+}
+
+func loadAllServices(language string) ([]*ServiceResponseForUser, error) {
 	o := orm.NewOrm()
 	var services []*Service
 	_, err := o.QueryTable("service").All(&services)
@@ -108,13 +122,7 @@ func GetAllServices(language string) ([]*ServiceResponseForUser, error) {
 
 	var serviceResponses []*ServiceResponseForUser
 	for _, service := range services {
-		name := service.Name
-		switch language {
-		case "ru":
-			name = service.NameRu
-		case "kz":
-			name = service.NameKz
-		}
+		name := translate(service, "name", language)
 		serviceResponses = append(serviceResponses, &ServiceResponseForUser{
 			Id:       service.Id,
 			Name:     name,
@@ -148,35 +156,30 @@ func GetAllServicesForAdmin() ([]*ServiceResponseForAdmin, error) {
 
 func SearchServicesByName(prefix, language string) ([]ServiceResponseForUser, error) {
 	var results []Service
-	var field string
-
-	switch language {
-	case "ru":
-		field = "name_ru"
-	case "kz":
-		field = "name_kz"
-	default:
-		field = "name"
-	}
 
 	o := orm.NewOrm()
-	query := fmt.Sprintf("SELECT * FROM service WHERE %s LIKE ?", field)
-	searchPattern := fmt.Sprintf("%s%%", prefix)
+	condition, args := localeSearch("service", "name", language, fmt.Sprintf("%s%%", prefix), likeColumn)
+	query := "SELECT * FROM service WHERE " + condition
 
-	_, err := o.Raw(query, searchPattern).QueryRows(&results)
+	_, err := o.Raw(query, args...).QueryRows(&results)
+	if err != nil {
+		return nil, err
+	}
+	results, err = withKazakhLatinMatches(o, "service", prefix, true, func() (map[int]string, error) {
+		services, err := GetAllServices(LocaleKzLatn)
+		names := make(map[int]string, len(services))
+		for _, service := range services {
+			names[service.Id] = service.Name
+		}
+		return names, err
+	}, results)
 	if err != nil {
 		return nil, err
 	}
 
 	var serviceResponses []ServiceResponseForUser
 	for _, service := range results {
-		name := service.Name
-		switch language {
-		case "ru":
-			name = service.NameRu
-		case "kz":
-			name = service.NameKz
-		}
+		name := translate(&service, "name", language)
 		serviceResponses = append(serviceResponses, ServiceResponseForUser{
 			Id:       service.Id,
 			Name:     name,
@@ -202,13 +205,7 @@ func GetServicesByUniversityId(universityId int, language string) ([]*ServiceRes
 
 	var serviceResponses []*ServiceResponseForUser
 	for _, service := range services {
-		name := service.Name
-		switch language {
-		case "ru":
-			name = service.NameRu
-		case "kz":
-			name = service.NameKz
-		}
+		name := translate(service, "name", language)
 		serviceResponses = append(serviceResponses, &ServiceResponseForUser{
 			Id:       service.Id,
 			Name:     name,
@@ -245,8 +242,24 @@ func GetServicesByUniversityIdForAdmin(universityId int) ([]*ServiceResponseForA
 	return serviceResponses, nil
 }
 
-func AddServiceToUniversity(serviceId, universityId int) error {
-	o := orm.NewOrm()
+func AddServiceToUniversity(ctx context.Context, serviceId, universityId int) error {
+	o, err := newOrmWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := addServiceToUniversity(o, serviceId, universityId); err != nil {
+		o.Rollback()
+		return err
+	}
+	if err := o.Commit(); err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "Service added to university", "university_id", universityId, "service_id", serviceId)
+	return nil
+}
+
+func addServiceToUniversity(o orm.Ormer, serviceId, universityId int) error {
 	service := &Service{Id: serviceId}
 	if err := o.Read(service); err != nil {
 		return err
@@ -263,12 +276,5 @@ func AddServiceToUniversity(serviceId, universityId int) error {
 	}
 
 	_, err := o.QueryM2M(university, "Services").Add(service)
-	if err != nil {
-		return err
-	}
-
-	o.LoadRelated(university, "Services")
-	fmt.Printf("Services for university %d: %v\n", universityId, university.Services)
-
-	return nil
+	return err
 }
@@ -1,9 +1,13 @@
 package models
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"strconv"
+	"testhub-spec-uni/cache"
+	"testhub-spec-uni/dialect"
 	"time"
 
 	"github.com/astaxie/beego/orm"
@@ -176,6 +180,21 @@ func init() {
 	orm.RegisterModel(new(Speciality))
 }
 
+func (s *Speciality) translationKey() (string, int) { return "speciality", s.Id }
+
+func (s *Speciality) legacyTranslations() map[string]LocalizedText {
+	return map[string]LocalizedText{
+		"name":         {LocaleRu: s.NameRu, LocaleKz: s.NameKz},
+		"abbreviation": {LocaleRu: s.AbbreviationRu, LocaleKz: s.AbbreviationKz},
+		"description":  {LocaleRu: s.DescriptionRu, LocaleKz: s.DescriptionKz},
+	}
+}
+
+func (s *Speciality) Localize(language string) {
+	s.Name = translate(s, "name", language)
+	s.Description = translate(s, "description", language)
+}
+
 func AddSpecialityFromFormData(data *AddSpecialityResponse) (int64, error) {
 	o := orm.NewOrm()
 
@@ -215,8 +234,9 @@ func AddSpecialityFromFormData(data *AddSpecialityResponse) (int64, error) {
 	if err != nil {
 		return 0, err
 	}
+	speciality.Id = int(id)
 
-	return id, nil
+	return id, syncTranslations(o, &speciality)
 }
 
 func GetSpecialityById(id int, language string) (*Speciality, error) {
@@ -228,14 +248,7 @@ func GetSpecialityById(id int, language string) (*Speciality, error) {
 		return nil, err
 	}
 
-	switch language {
-	case "ru":
-		speciality.Name = speciality.NameRu
-		speciality.Description = speciality.DescriptionRu
-	case "kz":
-		speciality.Name = speciality.NameKz
-		speciality.Description = speciality.DescriptionKz
-	}
+	speciality.Localize(language)
 
 	if speciality.SubjectPair != nil {
 		var subjectPair SubjectPair
@@ -272,14 +285,7 @@ func GetAllSpecialities(language string) ([]*Speciality, error) {
 			}
 		}
 
-		switch language {
-		case "ru":
-			speciality.Name = speciality.NameRu
-			speciality.Description = speciality.DescriptionRu
-		case "kz":
-			speciality.Name = speciality.NameKz
-			speciality.Description = speciality.DescriptionKz
-		}
+		speciality.Localize(language)
 	}
 
 	return specialities, nil
@@ -350,6 +356,10 @@ func UpdateSpecialityFromFormData(data *UpdateSpecialityResponse) error {
 		o.Rollback() // Rollback transaction on error
 		return fmt.Errorf("failed to update speciality: %v", err)
 	}
+	if err := syncTranslations(o, &speciality); err != nil {
+		o.Rollback()
+		return fmt.Errorf("failed to update translations: %v", err)
+	}
 
 	o.Commit() // Commit transaction
 	return nil
@@ -357,14 +367,21 @@ func UpdateSpecialityFromFormData(data *UpdateSpecialityResponse) error {
 
 func DeleteSpeciality(id int) error {
 	o := orm.NewOrm()
-	_, err := o.Delete(&Speciality{Id: id})
-	return err
+	if _, err := o.Delete(&Speciality{Id: id}); err != nil {
+		return err
+	}
+	return deleteTranslations(o, "speciality", id)
 }
 
-func SearchSpecialities(params map[string]interface{}, language string) (*SpecialitySearchResult, error) {
-	o := orm.NewOrm()
+func SearchSpecialities(ctx context.Context, params map[string]interface{}, language string) (*SpecialitySearchResult, error) {
+	// Транзакция только читает: она нужна, чтобы span запросов попали в трассу запроса.
+	o, err := newOrmWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
 	var specialities []*Speciality
-	_, err := o.QueryTable("speciality").All(&specialities)
+	_, err = o.QueryTable("speciality").All(&specialities)
+	o.Rollback()
 	if err != nil {
 		return nil, err
 	}
@@ -389,10 +406,25 @@ func SearchSpecialities(params map[string]interface{}, language string) (*Specia
 		return nil, err
 	}
 
-	fmt.Printf("SearchSpecialities: total specialities after filtering: %d\n", len(result.Specialities))
+	slog.DebugContext(ctx, "Speciality search finished", "found", len(specialities), "page_size", len(result.Specialities))
 	return result, nil
 }
 func GetSpecialitiesInUniversityForUser(universityId int, language string, page int, perPage int) ([]GetByUniResponseForUser, int, error) {
+	key := cache.Key("university_specialities", language, universityId, page, perPage)
+	result, err := cache.Remember(key, universitySpecialitiesCacheTags, func() (specialitiesPage, error) {
+		specialities, totalCount, err := loadSpecialitiesInUniversityForUser(universityId, language, page, perPage)
+		return specialitiesPage{Specialities: specialities, TotalCount: totalCount}, err
+	})
+	return result.Specialities, result.TotalCount, err
+}
+
+// specialitiesPage — страница специальностей университета в кэше.
+type specialitiesPage struct {
+	Specialities []GetByUniResponseForUser
+	TotalCount   int
+}
+
+func loadSpecialitiesInUniversityForUser(universityId int, language string, page int, perPage int) ([]GetByUniResponseForUser, int, error) {
 	o := orm.NewOrm()
 	var results []GetByUniResponseForUser
 
@@ -402,9 +434,9 @@ func GetSpecialitiesInUniversityForUser(universityId int, language string, page
         WITH speciality_data AS (
             SELECT DISTINCT 
                 s.id as speciality_id,
-                CASE WHEN ? = 'ru' THEN s.name_ru ELSE s.name_kz END as speciality_name,
-                CASE WHEN ? = 'ru' THEN u.name_ru ELSE u.name_kz END as university_name,
-                CASE WHEN ? = 'ru' THEN u.study_format_ru ELSE u.study_format_kz END as education_format,
+                s.%s as speciality_name,
+                u.%s as university_name,
+                u.%s as education_format,
                 s.code,
                 ps.price,
                 s.degree,
@@ -449,7 +481,8 @@ func GetSpecialitiesInUniversityForUser(universityId int, language string, page
         LIMIT ? OFFSET ?
     `
 
-	_, err := o.Raw(query, language, language, language, universityId, perPage, offset).QueryRows(&results)
+	query = fmt.Sprintf(query, localeColumn("name", language), localeColumn("name", language), localeColumn("study_format", language))
+	_, err := o.Raw(query, universityId, perPage, offset).QueryRows(&results)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -469,33 +502,32 @@ func GetSpecialitiesInUniversityForUser(universityId int, language string, page
 		return nil, 0, err
 	}
 
-	for i := range results {
-		var subjectNames []string
+	subjectIds := make([]int, 0, 2*len(results))
+	specialityIds := make([]int, 0, len(results))
+	for _, result := range results {
+		subjectIds = append(subjectIds, result.Subject1ID, result.Subject2ID)
+		specialityIds = append(specialityIds, result.SpecialityID)
+	}
+	subjects, err := subjectsByIds(o, subjectIds)
+	if err != nil {
+		return nil, 0, err
+	}
+	pointStats, err := pointStatsBySpeciality(o, universityId, specialityIds)
+	if err != nil {
+		return nil, 0, err
+	}
 
-		if results[i].Subject1ID != 0 {
-			var subject1 Subject
-			err := o.QueryTable("subject").Filter("id", results[i].Subject1ID).One(&subject1)
-			if err == nil {
-				if language == "ru" {
-					subjectNames = append(subjectNames, subject1.NameRu)
-				} else {
-					subjectNames = append(subjectNames, subject1.NameKz)
-				}
-			}
-		}
+	for i := range results {
+		results[i].SpecialityName = translateValue("speciality", results[i].SpecialityID, "name", language, results[i].SpecialityName)
+		results[i].UniversityName = translateValue("university", universityId, "name", language, results[i].UniversityName)
+		results[i].EducationFormat = translateValue("university", universityId, "study_format", language, results[i].EducationFormat)
 
-		if results[i].Subject2ID != 0 {
-			var subject2 Subject
-			err := o.QueryTable("subject").Filter("id", results[i].Subject2ID).One(&subject2)
-			if err == nil {
-				if language == "ru" {
-					subjectNames = append(subjectNames, subject2.NameRu)
-				} else {
-					subjectNames = append(subjectNames, subject2.NameKz)
-				}
+		var subjectNames []string
+		for _, subjectId := range []int{results[i].Subject1ID, results[i].Subject2ID} {
+			if subject, ok := subjects[subjectId]; ok {
+				subjectNames = append(subjectNames, translate(subject, "name", language))
 			}
 		}
-
 		results[i].SubjectNames = subjectNames
 
 		// Инициализация пустых слайсов для annualPoints и annualGrants
@@ -503,17 +535,8 @@ func GetSpecialitiesInUniversityForUser(universityId int, language string, page
 		annualGrants := []AnnualGrant{}
 		var latestGrantCount int
 
-		var pointStats []PointStat
-		_, err := o.QueryTable("point_stat").
-			Filter("speciality_id", results[i].SpecialityID).
-			Filter("university_id", universityId).
-			OrderBy("-year"). // Убедитесь, что сначала получаем последние данные
-			All(&pointStats)
-		if err != nil {
-			return nil, 0, err
-		}
-
-		for _, ps := range pointStats {
+		// Статистика отсортирована по году: сначала последние данные
+		for _, ps := range pointStats[results[i].SpecialityID] {
 			annualPoints = append(annualPoints, AnnualPoints{
 				Year:          ps.Year,
 				MinScore:      ps.MinScore,
@@ -545,6 +568,7 @@ func GetSpecialitiesInUniversityForUser(universityId int, language string, page
 func GetSpecialitiesInUniversityForAdmin(universityID int) ([]IUniverSpecialtyShortcut, error) {
 	var specialities []IUniverSpecialtyShortcut
 
+	d := dialect.Current()
 	query := `
 	SELECT
     s.id as speciality_id,
@@ -556,19 +580,19 @@ func GetSpecialitiesInUniversityForAdmin(universityID int) ([]IUniverSpecialtySh
     u.study_format_kz as education_format_kz,
     su.term,
     su.edu_lang,
-    COALESCE(json_agg(json_build_object(
-        'id', ls.id,
-        'grant_co', ls.grant_count,
-        'min_score', ls.min_score,
-        'year', ls.year,
-        'avg_salary', ls.avg_salary,
-        'price', ls.price
-    )) FILTER (WHERE ls.id IS NOT NULL AND ls.university_id = su.university_id), '[]') AS statistics
+    ` + d.JSONArrayAgg(d.JSONObject(
+		"'id', ls.id",
+		"'grant_co', ls.grant_count",
+		"'min_score', ls.min_score",
+		"'year', ls.year",
+		"'avg_salary', ls.avg_salary",
+		"'price', ls.price",
+	), "ls.id IS NOT NULL AND ls.university_id = su.university_id") + ` AS statistics
 FROM
-    uni_spec.speciality s
-    JOIN uni_spec.speciality_university su ON su.speciality_id = s.id
-    JOIN uni_spec.university u ON su.university_id = u.id
-    LEFT JOIN uni_spec.point_stat ls ON su.speciality_id = ls.speciality_id AND su.university_id = ls.university_id
+    speciality s
+    JOIN speciality_university su ON su.speciality_id = s.id
+    JOIN university u ON su.university_id = u.id
+    LEFT JOIN point_stat ls ON su.speciality_id = ls.speciality_id AND su.university_id = ls.university_id
 WHERE su.university_id = ?
 GROUP BY s.id, s.name_ru, s.name_kz, s.code, s.degree, u.study_format_ru, u.study_format_kz, su.term, su.edu_lang
 
@@ -684,14 +708,7 @@ func GetSpecialitiesBySubjectPair(subject1Id, subject2Id int, language string) (
 
 	// Переназначение полей в зависимости от языка
 	for _, speciality := range specialities {
-		switch language {
-		case "ru":
-			speciality.Name = speciality.NameRu
-			speciality.Description = speciality.DescriptionRu
-		case "kz":
-			speciality.Name = speciality.NameKz
-			speciality.Description = speciality.DescriptionKz
-		}
+		speciality.Localize(language)
 	}
 
 	return specialities, nil
@@ -716,7 +733,7 @@ func filterSpecialitiesInUniversity(params map[string]interface{}, specialities
 	// Получаем язык из параметров, если он существует, иначе используем язык по умолчанию
 	language, ok := params["language"].(string)
 	if !ok {
-		language = "ru" // Язык по умолчанию
+		language = DefaultLocale()
 	}
 
 	// Получаем параметры для пагинации
@@ -736,23 +753,19 @@ func filterSpecialitiesInUniversity(params map[string]interface{}, specialities
 		return nil, err
 	}
 
-	// Преобразуем specialityResponses в []*Speciality
+	// Оставляем специальности исходного списка, которые есть в университете: у них
+	// заполнены колонки *_ru и *_kz, поэтому название переводится через translate.
+	byId := make(map[int]*Speciality, len(specialities))
+	for _, speciality := range specialities {
+		byId[speciality.Id] = speciality
+	}
 	var filteredSpecialities []*Speciality
 	for _, sr := range specialityResponses {
-		speciality := &Speciality{
-			Id:          sr.SpecialityID,
-			Name:        sr.SpecialityName,
-			Degree:      sr.Degree,
-			Scholarship: sr.Scholarship,
-		}
-
-		// Динамическое обновление имени в зависимости от языка
-		if language == "ru" {
-			speciality.NameRu = sr.SpecialityName
-		} else if language == "kz" {
-			speciality.NameKz = sr.SpecialityName
+		speciality, ok := byId[sr.SpecialityID]
+		if !ok {
+			continue
 		}
-
+		speciality.Localize(language)
 		filteredSpecialities = append(filteredSpecialities, speciality)
 	}
 
@@ -767,9 +780,10 @@ func filterSpecialitiesByName(params map[string]interface{}, specialities []*Spe
 
 	var results []*Speciality
 	o := orm.NewOrm()
-	query := "SELECT * FROM speciality WHERE name LIKE ?"
-	searchPattern := fmt.Sprintf("%s%%", prefix)
-	_, err := o.Raw(query, searchPattern).QueryRows(&results)
+	// Колонка name при создании не заполняется, поэтому поиск идёт по колонке языка
+	// запроса и переводам, как у городов и предметов.
+	condition, args := localeSearch("speciality", "name", language, fmt.Sprintf("%s%%", prefix), likeColumn)
+	_, err := o.Raw("SELECT * FROM speciality WHERE "+condition, args...).QueryRows(&results)
 	if err != nil {
 		return nil, err
 	}
@@ -781,21 +795,14 @@ func filterSpecialitiesByName(params map[string]interface{}, specialities []*Spe
 
 	var filteredSpecialities []*Speciality
 	for _, speciality := range specialities {
-		if _, found := resultMap[speciality.Id]; found {
+		if _, found := resultMap[speciality.Id]; found || matchesKazakhLatin(prefix, speciality, true, "name") {
 			filteredSpecialities = append(filteredSpecialities, speciality)
 		}
 	}
 
 	// Переназначение полей в зависимости от языка
 	for _, speciality := range filteredSpecialities {
-		switch language {
-		case "ru":
-			speciality.Name = speciality.NameRu
-			speciality.Description = speciality.DescriptionRu
-		case "kz":
-			speciality.Name = speciality.NameKz
-			speciality.Description = speciality.DescriptionKz
-		}
+		speciality.Localize(language)
 	}
 
 	return filteredSpecialities, nil
@@ -829,14 +836,7 @@ func paginateSpecialities(specialities []*Speciality, params map[string]interfac
 
 	// Переназначение полей в зависимости от языка
 	for _, speciality := range specialities {
-		switch language {
-		case "ru":
-			speciality.Name = speciality.NameRu
-			speciality.Description = speciality.DescriptionRu
-		case "kz":
-			speciality.Name = speciality.NameKz
-			speciality.Description = speciality.DescriptionKz
-		}
+		speciality.Localize(language)
 	}
 
 	result := &SpecialitySearchResult{
@@ -854,31 +854,15 @@ func GetSpecialityNames(lang string) ([]GetSpecialityNameResponse, error) {
 	var specialities []Speciality
 	var responses []GetSpecialityNameResponse
 
-	qs := o.QueryTable(new(Speciality))
-
-	switch lang {
-	case "ru":
-		qs = qs.Filter("NameRu__isnull", false)
-	case "kz":
-		qs = qs.Filter("NameKz__isnull", false)
-	default:
-		qs = qs.Filter("Name__isnull", false)
-	}
-
-	_, err := qs.All(&specialities)
+	_, err := o.QueryTable(new(Speciality)).All(&specialities)
 	if err != nil {
 		return nil, err
 	}
 
 	for _, speciality := range specialities {
-		var specialityName string
-		switch lang {
-		case "ru":
-			specialityName = speciality.NameRu
-		case "kz":
-			specialityName = speciality.NameKz
-		default:
-			specialityName = speciality.Name
+		specialityName := translate(&speciality, "name", lang)
+		if specialityName == "" {
+			continue
 		}
 
 		response := GetSpecialityNameResponse{
@@ -890,3 +874,19 @@ func GetSpecialityNames(lang string) ([]GetSpecialityNameResponse, error) {
 
 	return responses, nil
 }
+
+// specialityNamesByIds загружает локализованные названия специальностей одним запросом.
+func specialityNamesByIds(o orm.Ormer, ids []int, language string) (map[int]string, error) {
+	names := make(map[int]string, len(ids))
+	if len(ids) == 0 {
+		return names, nil
+	}
+	var specialities []*Speciality
+	if _, err := o.QueryTable("speciality").Filter("id__in", ids).All(&specialities, "Id", "NameRu", "NameKz"); err != nil {
+		return nil, err
+	}
+	for _, spec := range specialities {
+		names[spec.Id] = translate(spec, "name", language)
+	}
+	return names, nil
+}
@@ -43,6 +43,8 @@ func GetByUniversityAndSpeciality(universityID, specialityID int) (*SpecialityUn
 
 func DeleteByUniversityAndSpeciality(universityID, specialityID int) error {
 	o := orm.NewOrm()
-	_, err := o.QueryTable("speciality_university").Filter("university_id", universityID).Filter("speciality_id", specialityID).Delete()
-	return err
+	if _, err := o.QueryTable("speciality_university").Filter("university_id", universityID).Filter("speciality_id", specialityID).Delete(); err != nil {
+		return err
+	}
+	return RefreshUniversityStats(universityID)
 }
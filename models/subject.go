@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"testhub-spec-uni/cache"
 	"time"
 
 	"github.com/astaxie/beego/orm"
@@ -12,6 +13,8 @@ type Subject struct {
 	Name      string    `orm:"size(128)"`
 	NameRu    string    `orm:"size(128)"`
 	NameKz    string    `orm:"size(128)"`
+	MaxScore  int       `orm:"default(50)"`
+	Threshold int       `orm:"default(5)"`
 	CreatedAt time.Time `orm:"auto_now_add;type(datetime)"`
 	UpdatedAt time.Time `orm:"auto_now;type(datetime)"`
 }
@@ -20,19 +23,61 @@ func init() {
 	orm.RegisterModel(new(Subject))
 }
 
+func (s *Subject) translationKey() (string, int) { return "subject", s.Id }
+
+func (s *Subject) legacyTranslations() map[string]LocalizedText {
+	return map[string]LocalizedText{"name": {LocaleRu: s.NameRu, LocaleKz: s.NameKz}}
+}
+
+func (s *Subject) Localize(language string) {
+	s.Name = translate(s, "name", language)
+}
+
+// UpdateSubjectRequest — поля предмета для частичного обновления. Баллы переданы
+// указателями: отсутствующее поле не меняется, а нулевой порог сохраняется как есть.
+type UpdateSubjectRequest struct {
+	Name      string `json:"Name"`
+	NameRu    string `json:"NameRu"`
+	NameKz    string `json:"NameKz"`
+	MaxScore  *int   `json:"MaxScore"`
+	Threshold *int   `json:"Threshold"`
+}
+
+// Apply переносит заданные поля запроса в предмет.
+func (r *UpdateSubjectRequest) Apply(subject *Subject) {
+	if r.Name != "" {
+		subject.Name = r.Name
+	}
+	if r.NameRu != "" {
+		subject.NameRu = r.NameRu
+	}
+	if r.NameKz != "" {
+		subject.NameKz = r.NameKz
+	}
+	if r.MaxScore != nil {
+		subject.MaxScore = *r.MaxScore
+	}
+	if r.Threshold != nil {
+		subject.Threshold = *r.Threshold
+	}
+}
+
 type SubjectResponse struct {
 	Id   int    `json:"Id"`
 	Name string `json:"Name"`
 }
 
 func AddSubject(subject *Subject) (int64, error) {
+	if err := ValidateSubjectLimits(subject); err != nil {
+		return 0, err
+	}
 	o := orm.NewOrm()
 	id, err := o.Insert(subject)
 	if err != nil {
 		return 0, err
 	}
 	subject.Id = int(id)
-	return id, nil
+	return id, syncTranslations(o, subject)
 }
 
 func GetSubjectById(id int, language string) (*SubjectResponse, error) {
@@ -43,13 +88,7 @@ func GetSubjectById(id int, language string) (*SubjectResponse, error) {
 		return nil, err
 	}
 
-	name := subject.Name
-	switch language {
-	case "ru":
-		name = subject.NameRu
-	case "kz":
-		name = subject.NameKz
-	}
+	name := translate(subject, "name", language)
 
 	return &SubjectResponse{
 		Id:   subject.Id,
@@ -58,6 +97,12 @@ func GetSubjectById(id int, language string) (*SubjectResponse, error) {
 }
 
 func GetAllSubjects(language string) ([]*SubjectResponse, error) {
+	return cache.Remember(cache.Key("subjects", language), subjectsCacheTags, func() ([]*SubjectResponse, error) {
+		return loadAllSubjects(language)
+	})
+}
+
+func loadAllSubjects(language string) ([]*SubjectResponse, error) {
 	o := orm.NewOrm()
 	var subjects []*Subject
 	_, err := o.QueryTable("subject").All(&subjects)
@@ -67,13 +112,7 @@ func GetAllSubjects(language string) ([]*SubjectResponse, error) {
 
 	var subjectResponses []*SubjectResponse
 	for _, subject := range subjects {
-		name := subject.Name
-		switch language {
-		case "ru":
-			name = subject.NameRu
-		case "kz":
-			name = subject.NameKz
-		}
+		name := translate(subject, "name", language)
 		subjectResponses = append(subjectResponses, &SubjectResponse{
 			Id:   subject.Id,
 			Name: name,
@@ -83,64 +122,58 @@ func GetAllSubjects(language string) ([]*SubjectResponse, error) {
 	return subjectResponses, nil
 }
 
-func UpdateSubject(subject *Subject) error {
+func UpdateSubject(id int, form *UpdateSubjectRequest) error {
 	o := orm.NewOrm()
-	existingSubject := Subject{Id: subject.Id}
+	existingSubject := Subject{Id: id}
 	if err := o.Read(&existingSubject); err != nil {
 		return err
 	}
 
-	if subject.Name != "" {
-		existingSubject.Name = subject.Name
-	}
-	if subject.NameRu != "" {
-		existingSubject.NameRu = subject.NameRu
-	}
-	if subject.NameKz != "" {
-		existingSubject.NameKz = subject.NameKz
+	form.Apply(&existingSubject)
+	if err := ValidateSubjectLimits(&existingSubject); err != nil {
+		return err
 	}
 
-	_, err := o.Update(&existingSubject)
-	return err
+	if _, err := o.Update(&existingSubject); err != nil {
+		return err
+	}
+	return syncTranslations(o, &existingSubject)
 }
 
 func DeleteSubject(id int) error {
 	o := orm.NewOrm()
-	_, err := o.Delete(&Subject{Id: id})
-	return err
+	if _, err := o.Delete(&Subject{Id: id}); err != nil {
+		return err
+	}
+	return deleteTranslations(o, "subject", id)
 }
 
 func SearchSubjectsByName(prefix, language string) ([]SubjectResponse, error) {
 	var results []Subject
-	var field string
-
-	switch language {
-	case "ru":
-		field = "name_ru"
-	case "kz":
-		field = "name_kz"
-	default:
-		field = "name"
-	}
 
 	o := orm.NewOrm()
-	query := fmt.Sprintf("SELECT * FROM subject WHERE %s LIKE ?", field)
-	searchPattern := fmt.Sprintf("%%%s%%", prefix)
+	condition, args := localeSearch("subject", "name", language, fmt.Sprintf("%%%s%%", prefix), likeColumn)
+	query := "SELECT * FROM subject WHERE " + condition
 
-	_, err := o.Raw(query, searchPattern).QueryRows(&results)
+	_, err := o.Raw(query, args...).QueryRows(&results)
+	if err != nil {
+		return nil, err
+	}
+	results, err = withKazakhLatinMatches(o, "subject", prefix, false, func() (map[int]string, error) {
+		subjects, err := GetAllSubjects(LocaleKzLatn)
+		names := make(map[int]string, len(subjects))
+		for _, subject := range subjects {
+			names[subject.Id] = subject.Name
+		}
+		return names, err
+	}, results)
 	if err != nil {
 		return nil, err
 	}
 
 	var subjectResponses []SubjectResponse
 	for _, subject := range results {
-		name := subject.Name
-		switch language {
-		case "ru":
-			name = subject.NameRu
-		case "kz":
-			name = subject.NameKz
-		}
+		name := translate(&subject, "name", language)
 		subjectResponses = append(subjectResponses, SubjectResponse{
 			Id:   subject.Id,
 			Name: name,
@@ -167,3 +200,14 @@ func GetAllowedSecondSubjects(subject1Id int) ([]*Subject, error) {
 
 	return subjects, nil
 }
+
+// ValidateSubjectLimits проверяет максимальный и пороговый балл профильного предмета.
+func ValidateSubjectLimits(subject *Subject) error {
+	if subject.MaxScore < 1 || subject.MaxScore > UntProfileMaxScore {
+		return validationErrorf("MaxScore must be between 1 and %d, got %d", UntProfileMaxScore, subject.MaxScore)
+	}
+	if subject.Threshold < 0 || subject.Threshold > subject.MaxScore {
+		return validationErrorf("Threshold must be between 0 and MaxScore (%d), got %d", subject.MaxScore, subject.Threshold)
+	}
+	return nil
+}
@@ -60,18 +60,26 @@ func GetAllSubjectPairs() ([]*SubjectPair, error) {
 		return nil, err
 	}
 
+	subjectIds := make([]int, 0, 2*len(subjectPairs))
 	for _, pair := range subjectPairs {
 		if pair.Subject1 != nil {
-			err := o.Read(pair.Subject1)
-			if err != nil && err != orm.ErrNoRows {
-				return nil, err
-			}
+			subjectIds = append(subjectIds, pair.Subject1.Id)
 		}
 		if pair.Subject2 != nil {
-			err := o.Read(pair.Subject2)
-			if err != nil && err != orm.ErrNoRows {
-				return nil, err
-			}
+			subjectIds = append(subjectIds, pair.Subject2.Id)
+		}
+	}
+	subjects, err := subjectsByIds(o, subjectIds)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pair := range subjectPairs {
+		if pair.Subject1 != nil && subjects[pair.Subject1.Id] != nil {
+			pair.Subject1 = subjects[pair.Subject1.Id]
+		}
+		if pair.Subject2 != nil && subjects[pair.Subject2.Id] != nil {
+			pair.Subject2 = subjects[pair.Subject2.Id]
 		}
 	}
 
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"context"
+
+	"github.com/astaxie/beego/orm"
+)
+
+// newOrmWithContext возвращает Ormer с открытой транзакцией, начатой с ctx. Сам ORM
+// контекст в database/sql не передаёт, но обёртка драйвера из metrics связывает
+// запросы транзакции с её контекстом, и их span становятся дочерними для span
+// HTTP-запроса. Вызывающий завершает транзакцию через Commit или Rollback.
+func newOrmWithContext(ctx context.Context) (orm.Ormer, error) {
+	o := orm.NewOrm()
+	if err := o.BeginTx(ctx, nil); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
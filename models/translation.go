@@ -0,0 +1,512 @@
+package models
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testhub-spec-uni/config"
+	"time"
+
+	"github.com/astaxie/beego/orm"
+)
+
+const (
+	LocaleRu     = "ru"
+	LocaleKz     = "kz"
+	LocaleEn     = "en"
+	LocaleKzLatn = "kz-latn"
+)
+
+// SupportedLocales — языки, на которых можно хранить переводы. Новый язык добавляется
+// здесь, без изменения таблиц сущностей.
+var SupportedLocales = []string{LocaleKz, LocaleRu, LocaleEn, LocaleKzLatn}
+
+// defaultFallbackChain используется, если в конфиге не задан i18n_fallback.
+var defaultFallbackChain = []string{LocaleKz, LocaleRu}
+
+// translationCacheTTL — как долго переводы живут в памяти до повторной загрузки.
+const translationCacheTTL = time.Minute
+
+// Translation — перевод одного поля сущности на один язык.
+type Translation struct {
+	Id         int       `orm:"auto"`
+	EntityType string    `orm:"size(32)"`
+	EntityId   int       `orm:"column(entity_id)"`
+	Field      string    `orm:"size(64)"`
+	Locale     string    `orm:"size(16)"`
+	Value      string    `orm:"type(text)"`
+	UpdatedAt  time.Time `orm:"auto_now;type(datetime)"`
+}
+
+func (t *Translation) TableUnique() [][]string {
+	return [][]string{{"EntityType", "EntityId", "Field", "Locale"}}
+}
+
+// LocalizedText — значения одного поля на разных языках.
+type LocalizedText map[string]string
+
+// translatable реализуют модели с переводимыми полями. legacyTranslations возвращает
+// значения из старых колонок *Ru/*Kz. Для ru и kz колонки — основное хранилище: они
+// копируются в таблицу translation при каждом сохранении сущности, а SetTranslation
+// на этих языках пишет и в колонку, и в таблицу.
+type translatable interface {
+	translationKey() (entityType string, id int)
+	legacyTranslations() map[string]LocalizedText
+}
+
+type SetTranslationRequest struct {
+	EntityType string `form:"EntityType" validate:"required"`
+	EntityId   int    `form:"EntityId" validate:"required"`
+	Field      string `form:"Field" validate:"required"`
+	Locale     string `form:"Locale" validate:"required"`
+	Value      string `form:"Value"`
+}
+
+// TranslationMigrationResult — итог переноса данных из колонок *Ru/*Kz.
+type TranslationMigrationResult struct {
+	Entities map[string]int `json:"entities"`
+	Written  int            `json:"written"`
+}
+
+func init() {
+	orm.RegisterModel(new(Translation))
+}
+
+// IsSupportedLocale проверяет, что язык есть в SupportedLocales.
+func IsSupportedLocale(locale string) bool {
+	for _, l := range SupportedLocales {
+		if l == locale {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeLocale приводит код языка к виду из SupportedLocales ("kk" и "kz-Latn" тоже принимаются).
+func NormalizeLocale(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(locale, "_", "-")))
+	switch locale {
+	case "kk":
+		return LocaleKz
+	case "kk-latn":
+		return LocaleKzLatn
+	}
+	return locale
+}
+
+// FallbackChain возвращает порядок языков для поиска перевода: сам язык, его базовый
+// язык (kz-latn → kz), затем цепочка из настройки i18n_fallback.
+func FallbackChain(locale string) []string {
+	chain := make([]string, 0, 4)
+	seen := make(map[string]bool)
+	add := func(l string) {
+		if l != "" && !seen[l] {
+			seen[l] = true
+			chain = append(chain, l)
+		}
+	}
+
+	locale = NormalizeLocale(locale)
+	add(locale)
+	if i := strings.Index(locale, "-"); i > 0 {
+		add(locale[:i])
+	}
+	for _, l := range configuredFallbackChain() {
+		add(l)
+	}
+	return chain
+}
+
+// DefaultLocale — первый язык цепочки fallback.
+func DefaultLocale() string {
+	return configuredFallbackChain()[0]
+}
+
+func configuredFallbackChain() []string {
+	var chain []string
+	for _, l := range strings.Split(config.Get().I18n.Fallback, ",") {
+		if l = NormalizeLocale(l); IsSupportedLocale(l) {
+			chain = append(chain, l)
+		}
+	}
+	if len(chain) == 0 {
+		return defaultFallbackChain
+	}
+	return chain
+}
+
+// legacyLocale возвращает ru или kz — язык старых колонок, ближайший к запрошенному
+// по цепочке fallback.
+func legacyLocale(locale string) string {
+	for _, l := range FallbackChain(locale) {
+		if l == LocaleRu || l == LocaleKz {
+			return l
+		}
+	}
+	return LocaleKz
+}
+
+// localeColumn возвращает колонку *_ru или *_kz для запросов по старым колонкам.
+func localeColumn(column, locale string) string {
+	return column + "_" + legacyLocale(locale)
+}
+
+// likeColumn — сравнение для localeSearch с учётом регистра.
+func likeColumn(column string) string {
+	return column + " LIKE ?"
+}
+
+// localeSearch строит условие поиска по переводимому полю: старая колонка ближайшего
+// языка или перевод из таблицы translation на любом языке цепочки fallback, чтобы
+// находились и переводы, которых нет в колонках (например, английские). like получает
+// имя колонки и возвращает сравнение с одним параметром — likeColumn или dialect ILike.
+func localeSearch(entityType, field, locale, pattern string, like func(column string) string) (string, []interface{}) {
+	chain := FallbackChain(locale)
+	condition := fmt.Sprintf(
+		"(%s OR id IN (SELECT entity_id FROM translation WHERE entity_type = ? AND field = ? AND locale IN (%s) AND %s))",
+		like(localeColumn(translatableEntities[entityType].column(field), locale)),
+		strings.TrimSuffix(strings.Repeat("?, ", len(chain)), ", "),
+		like("value"),
+	)
+
+	args := []interface{}{pattern, entityType, field}
+	for _, l := range chain {
+		args = append(args, l)
+	}
+	return condition, append(args, pattern)
+}
+
+type translationCacheKey struct {
+	entityType string
+	entityId   int
+	field      string
+	locale     string
+}
+
+// translationCache держит таблицу translation в памяти: она небольшая и читается
+// на каждый ответ с локализованными полями.
+type translationCache struct {
+	mu       sync.RWMutex
+	values   map[translationCacheKey]string
+	loadedAt time.Time
+}
+
+var translations = &translationCache{}
+
+func (c *translationCache) lookup(entityType string, entityId int, field, locale string) (string, bool) {
+	c.mu.RLock()
+	fresh := c.values != nil && time.Since(c.loadedAt) < translationCacheTTL
+	if fresh {
+		value, ok := c.values[translationCacheKey{entityType, entityId, field, locale}]
+		c.mu.RUnlock()
+		return value, ok
+	}
+	c.mu.RUnlock()
+
+	if err := c.reload(); err != nil {
+		slog.Warn("Translations reload failed, using legacy columns", "error", err)
+		return "", false
+	}
+	return c.lookup(entityType, entityId, field, locale)
+}
+
+func (c *translationCache) reload() error {
+	var rows []*Translation
+	if _, err := orm.NewOrm().QueryTable("translation").Limit(-1).All(&rows); err != nil {
+		c.mu.Lock()
+		// Не повторяем неудачную загрузку на каждый запрос.
+		if c.values == nil {
+			c.values = make(map[translationCacheKey]string)
+		}
+		c.loadedAt = time.Now()
+		c.mu.Unlock()
+		return err
+	}
+
+	values := make(map[translationCacheKey]string, len(rows))
+	for _, row := range rows {
+		values[translationCacheKey{row.EntityType, row.EntityId, row.Field, row.Locale}] = row.Value
+	}
+
+	c.mu.Lock()
+	c.values = values
+	c.loadedAt = time.Now()
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *translationCache) invalidate() {
+	c.mu.Lock()
+	c.values = nil
+	c.mu.Unlock()
+}
+
+// translate возвращает значение поля на запрошенном языке, проходя по цепочке fallback.
+// На каждом шаге сначала смотрится таблица translation, затем старые колонки.
+// Для kz-latn без ручного перевода казахский текст транслитерируется.
+func translate(e translatable, field, locale string) string {
+	entityType, id := e.translationKey()
+	legacy := e.legacyTranslations()[field]
+	for _, l := range FallbackChain(locale) {
+		if value, ok := translations.lookup(entityType, id, field, l); ok && value != "" {
+			return transliterateFallback(value, l, locale)
+		}
+		if value := legacy[l]; value != "" {
+			return transliterateFallback(value, l, locale)
+		}
+	}
+	return ""
+}
+
+// translateValue — вариант translate для строк, загруженных сырым SQL: fallback —
+// значение, уже выбранное из старой колонки.
+func translateValue(entityType string, id int, field, locale, fallback string) string {
+	legacy := legacyLocale(locale)
+	for _, l := range FallbackChain(locale) {
+		if value, ok := translations.lookup(entityType, id, field, l); ok && value != "" {
+			return transliterateFallback(value, l, locale)
+		}
+		if l == legacy && fallback != "" {
+			return transliterateFallback(fallback, l, locale)
+		}
+	}
+	return fallback
+}
+
+// transliterateFallback транслитерирует значение, найденное на казахском, если
+// запрошен kz-latn. Ручной перевод kz-latn хранится в таблице translation и
+// находится раньше по цепочке.
+func transliterateFallback(value, foundLocale, requestedLocale string) string {
+	if foundLocale == LocaleKz && NormalizeLocale(requestedLocale) == LocaleKzLatn {
+		return TransliterateKz(value)
+	}
+	return value
+}
+
+// syncTranslations записывает значения старых колонок в таблицу translation.
+// Вызывается после каждого добавления и обновления переводимой сущности. Переводы
+// на другие языки не затрагиваются.
+func syncTranslations(o orm.Ormer, e translatable) error {
+	written, err := writeLegacyTranslations(o, e)
+	if err != nil {
+		return err
+	}
+	if written > 0 {
+		translations.invalidate()
+	}
+	return nil
+}
+
+// syncTranslationsById перечитывает сущность и синхронизирует её переводы.
+func syncTranslationsById(o orm.Ormer, e translatable) error {
+	if err := o.Read(e); err != nil {
+		return err
+	}
+	return syncTranslations(o, e)
+}
+
+// deleteTranslations удаляет переводы удалённой сущности.
+func deleteTranslations(o orm.Ormer, entityType string, entityId int) error {
+	deleted, err := o.QueryTable("translation").Filter("EntityType", entityType).Filter("entity_id", entityId).Delete()
+	if err == nil && deleted > 0 {
+		translations.invalidate()
+	}
+	return err
+}
+
+func writeLegacyTranslations(o orm.Ormer, e translatable) (int, error) {
+	entityType, id := e.translationKey()
+	written := 0
+	for field, values := range e.legacyTranslations() {
+		for locale, value := range values {
+			changed, err := upsertTranslation(o, entityType, id, field, locale, value)
+			if err != nil {
+				return written, err
+			}
+			if changed {
+				written++
+			}
+		}
+	}
+	return written, nil
+}
+
+// upsertTranslation сохраняет перевод; пустое значение удаляет запись.
+func upsertTranslation(o orm.Ormer, entityType string, entityId int, field, locale, value string) (bool, error) {
+	existing := Translation{EntityType: entityType, EntityId: entityId, Field: field, Locale: locale}
+	err := o.Read(&existing, "EntityType", "EntityId", "Field", "Locale")
+	switch {
+	case err == orm.ErrNoRows:
+		if value == "" {
+			return false, nil
+		}
+		existing.Value = value
+		_, err = o.Insert(&existing)
+		return err == nil, err
+	case err != nil:
+		return false, err
+	case value == "":
+		_, err = o.Delete(&existing)
+		return err == nil, err
+	case existing.Value == value:
+		return false, nil
+	default:
+		existing.Value = value
+		_, err = o.Update(&existing, "Value", "UpdatedAt")
+		return err == nil, err
+	}
+}
+
+// translatableEntity описывает сущность с переводимыми полями: как загрузить все строки
+// и как получить одну строку по ID. Тип сущности совпадает с именем таблицы.
+type translatableEntity struct {
+	load func(o orm.Ormer) ([]translatable, error)
+	byId func(id int) translatable
+	// columns — префиксы старых колонок *_ru/*_kz, если они отличаются от имени поля.
+	columns map[string]string
+}
+
+// column возвращает префикс старых колонок поля.
+func (e translatableEntity) column(field string) string {
+	if column, ok := e.columns[field]; ok {
+		return column
+	}
+	return field
+}
+
+var translatableEntities = map[string]translatableEntity{
+	"city":       {load: loadTranslatable[City]("city"), byId: func(id int) translatable { return &City{Id: id} }},
+	"region":     {load: loadTranslatable[Region]("region"), byId: func(id int) translatable { return &Region{Id: id} }},
+	"service":    {load: loadTranslatable[Service]("service"), byId: func(id int) translatable { return &Service{Id: id} }},
+	"subject":    {load: loadTranslatable[Subject]("subject"), byId: func(id int) translatable { return &Subject{Id: id} }},
+	"quota":      {load: loadTranslatable[Quota]("quota"), byId: func(id int) translatable { return &Quota{Id: id} }},
+	"speciality": {load: loadTranslatable[Speciality]("speciality"), byId: func(id int) translatable { return &Speciality{Id: id} }},
+	"university": {
+		load:    loadTranslatable[University]("university"),
+		byId:    func(id int) translatable { return &University{Id: id} },
+		columns: map[string]string{"status": "university_status"},
+	},
+	"admission_event": {load: loadTranslatable[AdmissionEvent]("admission_event"), byId: func(id int) translatable { return &AdmissionEvent{Id: id} }},
+	"campus":          {load: loadTranslatable[Campus]("campus"), byId: func(id int) translatable { return &Campus{Id: id} }},
+	"faq_entry":       {load: loadTranslatable[FaqEntry]("faq_entry"), byId: func(id int) translatable { return &FaqEntry{Id: id} }},
+	"quota_criterion": {load: loadTranslatable[QuotaCriterion]("quota_criterion"), byId: func(id int) translatable { return &QuotaCriterion{Id: id} }},
+}
+
+func loadTranslatable[T any, PT interface {
+	*T
+	translatable
+}](table string) func(o orm.Ormer) ([]translatable, error) {
+	return func(o orm.Ormer) ([]translatable, error) {
+		var rows []*T
+		if _, err := o.QueryTable(table).Limit(-1).All(&rows); err != nil {
+			return nil, err
+		}
+		result := make([]translatable, 0, len(rows))
+		for _, row := range rows {
+			result = append(result, PT(row))
+		}
+		return result, nil
+	}
+}
+
+// MigrateLegacyTranslations переносит значения колонок *Ru/*Kz всех сущностей в таблицу
+// translation. Повторный запуск безопасен: неизменённые значения не перезаписываются.
+func MigrateLegacyTranslations() (*TranslationMigrationResult, error) {
+	o := orm.NewOrm()
+	result := &TranslationMigrationResult{Entities: make(map[string]int)}
+
+	if err := o.Begin(); err != nil {
+		return nil, err
+	}
+	for entityType, entity := range translatableEntities {
+		entities, err := entity.load(o)
+		if err != nil {
+			o.Rollback()
+			return nil, fmt.Errorf("load %s: %v", entityType, err)
+		}
+		for _, e := range entities {
+			written, err := writeLegacyTranslations(o, e)
+			if err != nil {
+				o.Rollback()
+				return nil, fmt.Errorf("migrate %s: %v", entityType, err)
+			}
+			result.Written += written
+		}
+		result.Entities[entityType] = len(entities)
+	}
+	if err := o.Commit(); err != nil {
+		return nil, err
+	}
+
+	translations.invalidate()
+	return result, nil
+}
+
+// SetTranslation сохраняет перевод одного поля, например на английский. Перевод на ru
+// или kz записывается и в старую колонку, иначе следующее сохранение сущности вернуло
+// бы в таблицу translation прежнее значение колонки.
+func SetTranslation(request *SetTranslationRequest) error {
+	locale := NormalizeLocale(request.Locale)
+	if !IsSupportedLocale(locale) {
+		return fmt.Errorf("unsupported locale: %s", request.Locale)
+	}
+	entity, ok := translatableEntities[request.EntityType]
+	if !ok {
+		return fmt.Errorf("unknown entity type: %s", request.EntityType)
+	}
+
+	o := orm.NewOrm()
+	e := entity.byId(request.EntityId)
+	if err := o.Read(e); err != nil {
+		return fmt.Errorf("%s %d not found", request.EntityType, request.EntityId)
+	}
+	if _, known := e.legacyTranslations()[request.Field]; !known {
+		return fmt.Errorf("field %s of %s is not translatable", request.Field, request.EntityType)
+	}
+
+	if err := o.Begin(); err != nil {
+		return err
+	}
+	if locale == LocaleRu || locale == LocaleKz {
+		column := entity.column(request.Field) + "_" + locale
+		if _, err := o.QueryTable(request.EntityType).Filter("Id", request.EntityId).
+			Update(orm.Params{column: request.Value, "UpdatedAt": time.Now()}); err != nil {
+			o.Rollback()
+			return err
+		}
+	}
+	if _, err := upsertTranslation(o, request.EntityType, request.EntityId, request.Field, locale, request.Value); err != nil {
+		o.Rollback()
+		return err
+	}
+	if err := o.Commit(); err != nil {
+		return err
+	}
+	translations.invalidate()
+	return nil
+}
+
+// GetTranslations возвращает все переводы сущности: поле → язык → значение.
+func GetTranslations(entityType string, entityId int) (map[string]LocalizedText, error) {
+	if _, ok := translatableEntities[entityType]; !ok {
+		return nil, fmt.Errorf("unknown entity type: %s", entityType)
+	}
+
+	var rows []*Translation
+	if _, err := orm.NewOrm().QueryTable("translation").
+		Filter("EntityType", entityType).
+		Filter("entity_id", entityId).
+		All(&rows); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]LocalizedText)
+	for _, row := range rows {
+		if result[row.Field] == nil {
+			result[row.Field] = LocalizedText{}
+		}
+		result[row.Field][row.Locale] = row.Value
+	}
+	return result, nil
+}
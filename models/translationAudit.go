@@ -0,0 +1,280 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/astaxie/beego/orm"
+)
+
+const (
+	TranslationIssueMissing        = "missing"
+	TranslationIssueIdentical      = "identical"
+	TranslationIssueLatinText      = "latin_text"
+	TranslationIssueLengthMismatch = "length_mismatch"
+)
+
+// DefaultLengthMismatchThreshold — допустимая относительная разница длин Ru и Kz текста.
+const DefaultLengthMismatchThreshold = 0.5
+
+// lengthMismatchMinLength — короче этого (в символах) тексты по длине не сравниваются:
+// у названий и аббревиатур разница в несколько букв нормальна.
+const lengthMismatchMinLength = 40
+
+// abbreviationMaxLength — слова длиннее этого не считаются аббревиатурами.
+const abbreviationMaxLength = 10
+
+// auditedEntityTypes — сущности, переводы которых проверяются аудитом.
+var auditedEntityTypes = []string{"university", "speciality", "city", "service", "subject", "quota"}
+
+// TranslationIssue — найденная проблема с переводом одного поля.
+type TranslationIssue struct {
+	EntityId int    `json:"entity_id"`
+	Label    string `json:"label,omitempty"`
+	Field    string `json:"field"`
+	Locale   string `json:"locale,omitempty"`
+	Issue    string `json:"issue"`
+	Details  string `json:"details,omitempty"`
+}
+
+// TranslationCompleteness — заполненность переводов одного поля или сущности целиком.
+type TranslationCompleteness struct {
+	Total    int     `json:"total"`
+	Filled   int     `json:"filled"`
+	Complete float64 `json:"complete"`
+}
+
+// TranslationAuditEntity — результат аудита одного типа сущности.
+type TranslationAuditEntity struct {
+	EntityType   string                              `json:"entity_type"`
+	Rows         int                                 `json:"rows"`
+	CompleteRows int                                 `json:"complete_rows"`
+	Completeness TranslationCompleteness             `json:"completeness"`
+	Fields       map[string]*TranslationCompleteness `json:"fields"`
+	IssueCounts  map[string]int                      `json:"issue_counts"`
+	Issues       []TranslationIssue                  `json:"issues"`
+}
+
+// TranslationAuditResult — итог аудита по всем сущностям.
+type TranslationAuditResult struct {
+	Threshold    float64                   `json:"threshold"`
+	Completeness TranslationCompleteness   `json:"completeness"`
+	Entities     []*TranslationAuditEntity `json:"entities"`
+}
+
+// AuditTranslations проверяет Ru и Kz переводы сущностей: пустые значения, одинаковый
+// текст на обоих языках (кроме аббревиатур и латинских брендов), латиницу
+// в кириллических полях и сильную разницу в длине.
+// Если entityType пуст, проверяются все сущности из auditedEntityTypes.
+func AuditTranslations(entityType string, threshold float64) (*TranslationAuditResult, error) {
+	if threshold <= 0 {
+		threshold = DefaultLengthMismatchThreshold
+	}
+
+	entityTypes := auditedEntityTypes
+	if entityType != "" {
+		if !isAuditedEntityType(entityType) {
+			return nil, fmt.Errorf("unknown entity type: %s", entityType)
+		}
+		entityTypes = []string{entityType}
+	}
+
+	o := orm.NewOrm()
+	result := &TranslationAuditResult{Threshold: threshold, Entities: make([]*TranslationAuditEntity, 0, len(entityTypes))}
+	for _, t := range entityTypes {
+		entities, err := translatableEntities[t].load(o)
+		if err != nil {
+			return nil, fmt.Errorf("load %s: %v", t, err)
+		}
+		audit := auditEntities(t, entities, threshold)
+		result.Completeness.Total += audit.Completeness.Total
+		result.Completeness.Filled += audit.Completeness.Filled
+		result.Entities = append(result.Entities, audit)
+	}
+	result.Completeness.Complete = completenessPercent(result.Completeness.Filled, result.Completeness.Total)
+	return result, nil
+}
+
+func isAuditedEntityType(entityType string) bool {
+	for _, t := range auditedEntityTypes {
+		if t == entityType {
+			return true
+		}
+	}
+	return false
+}
+
+func auditEntities(entityType string, entities []translatable, threshold float64) *TranslationAuditEntity {
+	audit := &TranslationAuditEntity{
+		EntityType:  entityType,
+		Rows:        len(entities),
+		Fields:      make(map[string]*TranslationCompleteness),
+		IssueCounts: make(map[string]int),
+		Issues:      []TranslationIssue{},
+	}
+
+	for _, e := range entities {
+		_, id := e.translationKey()
+		label := translationAuditLabel(e)
+		rowComplete := true
+
+		legacy := e.legacyTranslations()
+		fields := make([]string, 0, len(legacy))
+		for field := range legacy {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+
+		for _, field := range fields {
+			stats := audit.Fields[field]
+			if stats == nil {
+				stats = &TranslationCompleteness{}
+				audit.Fields[field] = stats
+			}
+
+			values := LocalizedText{}
+			for _, locale := range []string{LocaleRu, LocaleKz} {
+				values[locale] = storedTranslation(entityType, id, field, locale, legacy[field][locale])
+				stats.Total++
+				if values[locale] != "" {
+					stats.Filled++
+				}
+			}
+
+			issues := checkTranslation(values, threshold)
+			if len(issues) > 0 {
+				rowComplete = false
+			}
+			for _, issue := range issues {
+				issue.EntityId = id
+				issue.Label = label
+				issue.Field = field
+				audit.Issues = append(audit.Issues, issue)
+				audit.IssueCounts[issue.Issue]++
+			}
+		}
+		if rowComplete {
+			audit.CompleteRows++
+		}
+	}
+
+	for _, stats := range audit.Fields {
+		stats.Complete = completenessPercent(stats.Filled, stats.Total)
+		audit.Completeness.Total += stats.Total
+		audit.Completeness.Filled += stats.Filled
+	}
+	audit.Completeness.Complete = completenessPercent(audit.Completeness.Filled, audit.Completeness.Total)
+	return audit
+}
+
+// storedTranslation возвращает значение на конкретном языке без fallback: сначала из
+// таблицы translation, затем из старой колонки.
+func storedTranslation(entityType string, id int, field, locale, legacy string) string {
+	if value, ok := translations.lookup(entityType, id, field, locale); ok && value != "" {
+		return value
+	}
+	return legacy
+}
+
+// translationAuditLabel — название сущности для отчёта, чтобы не искать её по ID.
+func translationAuditLabel(e translatable) string {
+	for _, field := range []string{"name", "quota_type", "title", "question"} {
+		if _, ok := e.legacyTranslations()[field]; ok {
+			return translate(e, field, LocaleRu)
+		}
+	}
+	return ""
+}
+
+// checkTranslation проверяет пару Ru/Kz значений одного поля.
+func checkTranslation(values LocalizedText, threshold float64) []TranslationIssue {
+	ru, kz := values[LocaleRu], values[LocaleKz]
+	if ru == "" && kz == "" {
+		return []TranslationIssue{
+			{Locale: LocaleRu, Issue: TranslationIssueMissing},
+			{Locale: LocaleKz, Issue: TranslationIssueMissing},
+		}
+	}
+
+	// Латинское название, одинаковое на обоих языках, — бренд (Nazarbayev University),
+	// его не переводят.
+	brand := ru == kz && isMostlyLatin(ru)
+
+	var issues []TranslationIssue
+	for _, locale := range []string{LocaleRu, LocaleKz} {
+		if values[locale] == "" {
+			issues = append(issues, TranslationIssue{Locale: locale, Issue: TranslationIssueMissing})
+		} else if !brand && isMostlyLatin(values[locale]) {
+			issues = append(issues, TranslationIssue{Locale: locale, Issue: TranslationIssueLatinText})
+		}
+	}
+	if ru == "" || kz == "" {
+		return issues
+	}
+
+	if ru == kz && hasLetters(ru) && !brand && !isAbbreviation(ru) {
+		issues = append(issues, TranslationIssue{Issue: TranslationIssueIdentical})
+	}
+
+	ruLen, kzLen := utf8.RuneCountInString(ru), utf8.RuneCountInString(kz)
+	longest := math.Max(float64(ruLen), float64(kzLen))
+	if longest >= lengthMismatchMinLength {
+		if diff := math.Abs(float64(ruLen-kzLen)) / longest; diff > threshold {
+			issues = append(issues, TranslationIssue{
+				Issue:   TranslationIssueLengthMismatch,
+				Details: fmt.Sprintf("ru: %d, kz: %d", ruLen, kzLen),
+			})
+		}
+	}
+	return issues
+}
+
+// isMostlyLatin сообщает, что латинских букв в тексте больше, чем кириллических.
+// Отдельные латинские слова (IT, GPA) в кириллическом тексте допустимы.
+func isMostlyLatin(text string) bool {
+	latin, cyrillic := 0, 0
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		}
+	}
+	return latin > cyrillic
+}
+
+// isAbbreviation сообщает, что текст — аббревиатура вроде ЕНТ, КазНУ или IT: одно
+// слово, в котором не меньше двух заглавных букв. Аббревиатуры на ru и kz часто совпадают.
+func isAbbreviation(text string) bool {
+	if strings.ContainsAny(text, " \t") || utf8.RuneCountInString(text) > abbreviationMaxLength {
+		return false
+	}
+	upper := 0
+	for _, r := range text {
+		if unicode.IsUpper(r) {
+			upper++
+		}
+	}
+	return upper >= 2
+}
+
+func hasLetters(text string) bool {
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func completenessPercent(filled, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return math.Round(float64(filled)*10000/float64(total)) / 100
+}
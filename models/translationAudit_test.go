@@ -0,0 +1,68 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIsMostlyLatin(t *testing.T) {
+	for text, want := range map[string]bool{
+		"Nazarbayev University":        true,
+		"IT-технологии":                false,
+		"Информационные системы (GPA)": false,
+		"Almaty Management University": true,
+		"12345":                        false,
+		"":                             false,
+		"Kazakh-British ТУ":            true,
+	} {
+		if got := isMostlyLatin(text); got != want {
+			t.Errorf("isMostlyLatin(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
+
+func TestIsAbbreviation(t *testing.T) {
+	for text, want := range map[string]bool{
+		"ЕНТ":         true,
+		"КазНУ":       true,
+		"IT":          true,
+		"Алматы":      false,
+		"КБТУ им.":    false,
+		"ABCDEFGHIJK": false,
+	} {
+		if got := isAbbreviation(text); got != want {
+			t.Errorf("isAbbreviation(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
+
+func TestCheckTranslation(t *testing.T) {
+	long := strings.Repeat("а", 60)
+	tests := []struct {
+		name   string
+		ru, kz string
+		want   []string
+	}{
+		{"translated", "Информатика", "Информатика пәні", nil},
+		{"both missing", "", "", []string{TranslationIssueMissing, TranslationIssueMissing}},
+		{"kz missing", "Физика", "", []string{TranslationIssueMissing}},
+		{"identical", "Физика", "Физика", []string{TranslationIssueIdentical}},
+		{"abbreviation", "ЕНТ", "ЕНТ", nil},
+		{"latin abbreviation", "IT", "IT", nil},
+		{"brand", "Nazarbayev University", "Nazarbayev University", nil},
+		{"latin in kz", "Университет", "University", []string{TranslationIssueLatinText}},
+		{"digits only", "2024", "2024", nil},
+		{"length mismatch", long, "қысқа мәтін", []string{TranslationIssueLengthMismatch}},
+		{"short texts", "Общежитие", "Жатақхана мен асхана", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, issue := range checkTranslation(LocalizedText{LocaleRu: tt.ru, LocaleKz: tt.kz}, DefaultLengthMismatchThreshold) {
+			got = append(got, issue.Issue)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: issues %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
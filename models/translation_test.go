@@ -0,0 +1,124 @@
+package models
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testhub-spec-uni/config"
+	"testing"
+)
+
+// withFallback подменяет настройку i18n_fallback на время теста.
+func withFallback(t *testing.T, fallback string) {
+	t.Helper()
+	previous := config.Get()
+	c := *previous
+	c.I18n.Fallback = fallback
+	config.Set(&c)
+	t.Cleanup(func() { config.Set(previous) })
+}
+
+func TestNormalizeLocale(t *testing.T) {
+	for input, want := range map[string]string{
+		"ru":       LocaleRu,
+		" RU ":     LocaleRu,
+		"kk":       LocaleKz,
+		"kk-Latn":  LocaleKzLatn,
+		"kz_Latn":  LocaleKzLatn,
+		"en":       LocaleEn,
+		"de":       "de",
+		"":         "",
+		"Kz-LATN ": LocaleKzLatn,
+	} {
+		if got := NormalizeLocale(input); got != want {
+			t.Errorf("NormalizeLocale(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestFallbackChain(t *testing.T) {
+	tests := []struct {
+		fallback string
+		locale   string
+		want     []string
+	}{
+		{"", LocaleRu, []string{LocaleRu, LocaleKz}},
+		{"", LocaleEn, []string{LocaleEn, LocaleKz, LocaleRu}},
+		{"", "kk-Latn", []string{LocaleKzLatn, LocaleKz, LocaleRu}},
+		{"", "", []string{LocaleKz, LocaleRu}},
+		{"ru,kz", LocaleEn, []string{LocaleEn, LocaleRu, LocaleKz}},
+		{"ru", LocaleKz, []string{LocaleKz, LocaleRu}},
+		{"xx, kk", LocaleEn, []string{LocaleEn, LocaleKz}},
+		{"xx", LocaleEn, []string{LocaleEn, LocaleKz, LocaleRu}},
+	}
+	for _, tt := range tests {
+		withFallback(t, tt.fallback)
+		if got := FallbackChain(tt.locale); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("fallback %q: FallbackChain(%q) = %v, want %v", tt.fallback, tt.locale, got, tt.want)
+		}
+	}
+}
+
+func TestLegacyLocale(t *testing.T) {
+	withFallback(t, "ru,kz")
+	for locale, want := range map[string]string{LocaleEn: LocaleRu, LocaleKzLatn: LocaleKz, LocaleKz: LocaleKz} {
+		if got := legacyLocale(locale); got != want {
+			t.Errorf("legacyLocale(%q) = %q, want %q", locale, got, want)
+		}
+	}
+}
+
+func TestLocaleSearch(t *testing.T) {
+	withFallback(t, "")
+
+	condition, args := localeSearch("city", "name", LocaleEn, "Ast%", likeColumn)
+	if !strings.Contains(condition, "name_kz LIKE ?") || !strings.Contains(condition, "locale IN (?, ?, ?) AND value LIKE ?") {
+		t.Fatalf("unexpected condition: %s", condition)
+	}
+	want := []interface{}{"Ast%", "city", "name", LocaleEn, LocaleKz, LocaleRu, "Ast%"}
+	if !reflect.DeepEqual(args, want) {
+		t.Fatalf("args = %v, want %v", args, want)
+	}
+	if placeholders := strings.Count(condition, "?"); placeholders != len(args) {
+		t.Fatalf("%d placeholders for %d args", placeholders, len(args))
+	}
+
+	condition, _ = localeSearch("university", "status", LocaleRu, "%", likeColumn)
+	if !strings.Contains(condition, "university_status_ru LIKE ?") {
+		t.Fatalf("status search does not use the legacy column: %s", condition)
+	}
+}
+
+// Перевод на ru записывается и в старую колонку, иначе следующее сохранение города
+// вернуло бы в таблицу translation прежнее название.
+func TestSetTranslationWritesLegacyColumn(t *testing.T) {
+	for locale, wantUpdate := range map[string]bool{LocaleRu: true, LocaleEn: false} {
+		testDB.reset(
+			queryHandler{match: `FROM "city"`, rows: []map[string]driver.Value{{"id": int64(1), "name_ru": "Алматы", "name_kz": "Алматы"}}},
+			queryHandler{match: `INSERT INTO "translation"`, columns: []string{"id"}, rows: []map[string]driver.Value{{"id": int64(1)}}},
+		)
+		err := SetTranslation(&SetTranslationRequest{EntityType: "city", EntityId: 1, Field: "name", Locale: locale, Value: "Almaty"})
+		if err != nil {
+			t.Fatalf("%s: %v", locale, err)
+		}
+
+		var updated, inserted bool
+		for _, query := range testDB.queries {
+			updated = updated || strings.HasPrefix(query, `UPDATE "city" SET`) && strings.Contains(query, `"name_`+locale+`" = `)
+			inserted = inserted || strings.HasPrefix(query, `INSERT INTO "translation"`)
+		}
+		if updated != wantUpdate || !inserted {
+			t.Fatalf("%s: column updated %v, translation inserted %v:\n%s", locale, updated, inserted, strings.Join(testDB.queries, "\n"))
+		}
+	}
+}
+
+func TestSetTranslationRejectsUnknownFields(t *testing.T) {
+	testDB.reset(queryHandler{match: `FROM "campus"`, rows: []map[string]driver.Value{{"id": int64(3), "university_id": int64(1)}}})
+	if err := SetTranslation(&SetTranslationRequest{EntityType: "campus", EntityId: 3, Field: "address", Locale: LocaleEn, Value: "x"}); err == nil {
+		t.Fatal("address of a campus is not translatable")
+	}
+	if err := SetTranslation(&SetTranslationRequest{EntityType: "campus", EntityId: 3, Field: "name", Locale: "de", Value: "x"}); err == nil {
+		t.Fatal("unsupported locale was accepted")
+	}
+}
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/astaxie/beego/orm"
+)
+
+// kazakhLatin — соответствие кириллицы латинскому алфавиту казахского языка
+// (редакция 2021 года). Буквы, которых нет в казахском алфавите (ц, щ, ё, ю, я),
+// передаются буквосочетаниями.
+var kazakhLatin = map[rune]string{
+	'а': "a", 'ә': "ä", 'б': "b", 'в': "v", 'г': "g", 'ғ': "ğ", 'д': "d", 'е': "e",
+	'ё': "io", 'ж': "j", 'з': "z", 'и': "i", 'й': "i", 'к': "k", 'қ': "q", 'л': "l",
+	'м': "m", 'н': "n", 'ң': "ñ", 'о': "o", 'ө': "ö", 'п': "p", 'р': "r", 'с': "s",
+	'т': "t", 'у': "u", 'ұ': "ū", 'ү': "ü", 'ф': "f", 'х': "h", 'һ': "h", 'ц': "ts",
+	'ч': "ç", 'ш': "ş", 'щ': "şş", 'ъ': "", 'ы': "y", 'і': "ı", 'ь': "", 'э': "e",
+	'ю': "iu", 'я': "ia",
+}
+
+// kazakhLatinUpper — заглавные буквы, которые нельзя получить из строчных через
+// strings.ToUpper: и и й передаются как i, но заглавная у них İ, а I — заглавная ı (і).
+var kazakhLatinUpper = map[rune]string{'и': "İ", 'й': "İ"}
+
+// latinFolding убирает диакритику, чтобы запрос "Oskemen" находил "Öskemen".
+// ç и привычное "ch" сводятся к c: запросы "Chingiz" и "Cingiz" находят "Çingiz".
+var latinFolding = strings.NewReplacer(
+	"ä", "a", "ğ", "g", "ñ", "n", "ö", "o", "ū", "u", "ü", "u", "ş", "s", "ı", "i",
+	"ç", "c", "ch", "c",
+)
+
+// TransliterateKz переводит казахский текст с кириллицы на латиницу. Символы вне
+// таблицы (цифры, знаки, латиница) сохраняются без изменений.
+func TransliterateKz(text string) string {
+	runes := []rune(text)
+	var b strings.Builder
+	b.Grow(len(text))
+	for i, r := range runes {
+		latin, ok := kazakhLatin[unicode.ToLower(r)]
+		if !ok {
+			b.WriteRune(r)
+			continue
+		}
+		if !unicode.IsUpper(r) || latin == "" {
+			b.WriteString(latin)
+			continue
+		}
+		if upper, ok := kazakhLatinUpper[unicode.ToLower(r)]; ok {
+			b.WriteString(upper)
+			continue
+		}
+		// "ЦЕХ" → "TSEH", но "Цех" → "Tseh".
+		if isUpperAt(runes, i-1) || isUpperAt(runes, i+1) {
+			b.WriteString(strings.ToUpper(latin))
+		} else {
+			first := []rune(latin)
+			b.WriteString(strings.ToUpper(string(first[0])) + string(first[1:]))
+		}
+	}
+	return b.String()
+}
+
+func isUpperAt(runes []rune, i int) bool {
+	return i >= 0 && i < len(runes) && unicode.IsUpper(runes[i])
+}
+
+// foldLatin приводит латинский текст к нижнему регистру без диакритики для сравнения.
+func foldLatin(text string) string {
+	return latinFolding.Replace(strings.ToLower(strings.TrimSpace(text)))
+}
+
+// isLatinQuery сообщает, что поисковый запрос набран латиницей.
+func isLatinQuery(query string) bool {
+	return hasLetters(query) && isMostlyLatin(query)
+}
+
+// matchesKazakhLatin проверяет латинский запрос по казахским значениям полей в
+// латинице (с учётом ручных правок kz-latn). prefix задаёт поиск по началу строки,
+// иначе по вхождению.
+func matchesKazakhLatin(query string, e translatable, prefix bool, fields ...string) bool {
+	if !isLatinQuery(query) {
+		return false
+	}
+	for _, field := range fields {
+		if latinMatches(translate(e, field, LocaleKzLatn), query, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// latinMatches сравнивает значение с запросом без учёта регистра и диакритики.
+func latinMatches(value, query string, prefix bool) bool {
+	value, query = foldLatin(value), foldLatin(query)
+	if prefix {
+		return strings.HasPrefix(value, query)
+	}
+	return strings.Contains(value, query)
+}
+
+// LocalizedLegacy выбирает значение из пары колонок *Ru/*Kz; для kz-latn казахский
+// текст транслитерируется.
+func LocalizedLegacy(ru, kz, language string) string {
+	if legacyLocale(language) == LocaleRu {
+		return ru
+	}
+	if NormalizeLocale(language) == LocaleKzLatn {
+		return TransliterateKz(kz)
+	}
+	return kz
+}
+
+// withKazakhLatinMatches дополняет результаты SQL-поиска строками таблицы, чьё
+// казахское название в латинице совпадает с латинским запросом. Названия берутся
+// из кэшированного справочника на kz-latn (ID → название), из базы читаются только
+// совпавшие строки.
+func withKazakhLatinMatches[T any, PT interface {
+	*T
+	translatable
+}](o orm.Ormer, table, query string, prefix bool, catalogue func() (map[int]string, error), results []T) ([]T, error) {
+	if !isLatinQuery(query) {
+		return results, nil
+	}
+	names, err := catalogue()
+	if err != nil {
+		return results, err
+	}
+
+	found := make(map[int]bool, len(results))
+	for i := range results {
+		_, id := PT(&results[i]).translationKey()
+		found[id] = true
+	}
+	var ids []int
+	for id, name := range names {
+		if !found[id] && latinMatches(name, query, prefix) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return results, nil
+	}
+	sort.Ints(ids)
+
+	var rows []T
+	if _, err := o.QueryTable(table).Filter("id__in", ids).OrderBy("id").All(&rows); err != nil {
+		return results, err
+	}
+	return append(results, rows...), nil
+}
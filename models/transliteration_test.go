@@ -0,0 +1,55 @@
+package models
+
+import "testing"
+
+func TestTransliterateKz(t *testing.T) {
+	tests := []struct {
+		name, input, want string
+	}{
+		{"lower case", "алматы", "almaty"},
+		{"capitalized", "Өскемен", "Öskemen"},
+		{"all caps", "ҚАЗАҚСТАН", "QAZAQSTAN"},
+		{"dotless i", "білім", "bılım"},
+		{"capital dotless i", "Ілияс", "Iliias"},
+		{"capital и keeps the dot", "Ит", "İt"},
+		{"all caps и and і differ", "ИІ", "İI"},
+		{"capital й", "Йод", "İod"},
+		{"capital ч", "Чингиз", "Çingiz"},
+		{"ч in all caps", "ЧИНГИЗ", "ÇİNGİZ"},
+		{"digraph capitalized", "Цех", "Tseh"},
+		{"digraph in all caps", "ЦЕХ", "TSEH"},
+		{"digraph in abbreviation", "ЦА", "TSA"},
+		{"soft and hard signs", "съезд, ель", "sezd, el"},
+		{"iotated vowels", "Юрий Яков", "Iurii Iakov"},
+		{"mixed scripts", "КазНУ им. al-Farabi 2024", "KazNU im. al-Farabi 2024"},
+		{"latin unchanged", "IT Academy", "IT Academy"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		if got := TransliterateKz(tt.input); got != tt.want {
+			t.Errorf("%s: TransliterateKz(%q) = %q, want %q", tt.name, tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestLatinMatches(t *testing.T) {
+	tests := []struct {
+		value, query string
+		prefix, want bool
+	}{
+		{"Öskemen", "osk", true, true},
+		{"Öskemen", "kemen", true, false},
+		{"Öskemen", "kemen", false, true},
+		{"İT Akademiiasy", "it", true, true},
+		{"Şymkent", " SHYM", true, false},
+		{"Şymkent", "SYM", true, true},
+		{"Çingiz", "Chingiz", true, true},
+		{"Çingiz", "cing", true, true},
+		{"Çingiz", "Çin", false, true},
+	}
+	for _, tt := range tests {
+		if got := latinMatches(tt.value, tt.query, tt.prefix); got != tt.want {
+			t.Errorf("latinMatches(%q, %q, %v) = %v, want %v", tt.value, tt.query, tt.prefix, got, tt.want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"github.com/astaxie/beego/orm"
@@ -9,14 +10,20 @@ import (
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
-	beego "github.com/beego/beego/v2/server/web"
-	"github.com/beego/beego/v2/server/web/context"
 	"github.com/go-playground/validator/v10"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"log/slog"
+	"math"
 	"mime/multipart"
 	"net/http"
 	"sort"
-	"strconv"
 	"strings"
+	"testhub-spec-uni/cache"
+	"testhub-spec-uni/config"
+	"testhub-spec-uni/dialect"
+	"testhub-spec-uni/metrics"
+	"testhub-spec-uni/tracing"
 	"time"
 )
 
@@ -39,6 +46,12 @@ type University struct {
 	AverageFee         int
 	MainImageUrl       string `orm:"size(256)"`
 	MinEntryScore      int
+	MaxEntryScore      int
+	MedianEntryScore   int
+	MinFee             int
+	MaxFee             int
+	MedianFee          int
+	StatsYear          int
 	PhotosUrlList      []string                `orm:"-"`
 	Description        string                  `orm:"type(text)"`
 	DescriptionRu      string                  `orm:"type(text)" json:"-"`
@@ -55,9 +68,15 @@ type University struct {
 	StudyFormatRu      string                  `orm:"size(64)"`
 	StudyFormatKz      string                  `orm:"size(64)"`
 	AddressLink        string                  `orm:"size(256)"`
-	Email              string                  `orm:"size(64)"`
-	Rating             string                  `orm:"size(64)"`
-	Gallery            []*Gallery              `orm:"reverse(many);on_delete(cascade)"`
+	Latitude           float64
+	Longitude          float64
+	Campuses           []*Campus `orm:"reverse(many);on_delete(cascade)"`
+	DistanceKm         float64   `orm:"-" json:"-"`
+	Email              string    `orm:"size(64)"`
+	Rating             string    `orm:"size(64)"`
+	RatingScore        float64   // средняя оценка по одобренным отзывам
+	ReviewCount        int
+	Gallery            []*Gallery `orm:"reverse(many);on_delete(cascade)"`
 	Popular            bool
 }
 
@@ -68,17 +87,30 @@ type UniversitySearchResult struct {
 	TotalCount   int                         `json:"total_count"`
 }
 
+// ValueRange описывает разброс показателя по специальностям университета за последний год.
+type ValueRange struct {
+	Min    int `json:"Min"`
+	Max    int `json:"Max"`
+	Median int `json:"Median"`
+}
+
 type GetAllUniversityResponse struct {
-	Id               int    `json:"Id"`
-	Name             string `json:"Name"`
-	ImageUrl         string `json:"ImageUrl"`
-	Address          string `json:"Address"`
-	UniversityCode   string `json:"UniversityCode"`
-	SpecialityCount  int    `json:"SpecialityCount"`
-	UniversityStatus string `json:"UniversityStatus"`
-	MinScore         int    `json:"MinScore"`
-	Rating           string `json:"Rating"`
-	Favorite         bool   `json:"Favorite"`
+	Id               int        `json:"Id"`
+	Name             string     `json:"Name"`
+	ImageUrl         string     `json:"ImageUrl"`
+	Address          string     `json:"Address"`
+	UniversityCode   string     `json:"UniversityCode"`
+	SpecialityCount  int        `json:"SpecialityCount"`
+	UniversityStatus string     `json:"UniversityStatus"`
+	MinScore         int        `json:"MinScore"`
+	ScoreRange       ValueRange `json:"ScoreRange"`
+	AverageFee       int        `json:"AverageFee"`
+	FeeRange         ValueRange `json:"FeeRange"`
+	Rating           string     `json:"Rating"`
+	RatingScore      float64    `json:"RatingScore"`
+	ReviewCount      int        `json:"ReviewCount"`
+	Favorite         bool       `json:"Favorite"`
+	DistanceKm       *float64   `json:"DistanceKm,omitempty"`
 }
 type GetUniNamesResponse struct {
 	Id           int    `json:"Id"`
@@ -86,17 +118,21 @@ type GetUniNamesResponse struct {
 }
 
 type GetAllUniversityForAdminResponse struct {
-	Id                 int    `json:"Id"`
-	NameRu             string `json:"NameRu"`
-	NameKz             string `json:"NameKz"`
-	ImageUrl           string `json:"ImageUrl"`
-	Address            string `json:"Address"`
-	UniversityCode     string `json:"UniversityCode"`
-	SpecialityCount    int    `json:"SpecialityCount"`
-	UniversityStatusRu string `json:"UniversityStatusRu"`
-	UniversityStatusKz string `json:"UniversityStatusKz"`
-	MinScore           int    `json:"MinScore"`
-	Rating             string `json:"Rating"`
+	Id                 int        `json:"Id"`
+	NameRu             string     `json:"NameRu"`
+	NameKz             string     `json:"NameKz"`
+	ImageUrl           string     `json:"ImageUrl"`
+	Address            string     `json:"Address"`
+	UniversityCode     string     `json:"UniversityCode"`
+	SpecialityCount    int        `json:"SpecialityCount"`
+	UniversityStatusRu string     `json:"UniversityStatusRu"`
+	UniversityStatusKz string     `json:"UniversityStatusKz"`
+	MinScore           int        `json:"MinScore"`
+	ScoreRange         ValueRange `json:"ScoreRange"`
+	AverageFee         int        `json:"AverageFee"`
+	FeeRange           ValueRange `json:"FeeRange"`
+	StatsYear          int        `json:"StatsYear"`
+	Rating             string     `json:"Rating"`
 }
 type GetByIdUniversityResponseForAdmin struct {
 	Id                 int                `json:"Id"`
@@ -116,10 +152,16 @@ type GetByIdUniversityResponseForAdmin struct {
 	AbbreviationKz     string             `json:"AbbreviationKz" validate:"required"`
 	MainImageUrl       string             `json:"MainImageUrl" validate:"required,url"`
 	AddressLink        string             `json:"AddressLink" validate:"required"`
+	Latitude           float64            `json:"Latitude"`
+	Longitude          float64            `json:"Longitude"`
 	DescriptionRu      string             `json:"DescriptionRu" validate:"required"`
 	DescriptionKz      string             `json:"DescriptionKz" validate:"required"`
 	Rating             string             `json:"Rating" validate:"required"`
-	MinScore           int                `json:"MinScore" `
+	MinScore           int                `json:"MinScore"`
+	ScoreRange         ValueRange         `json:"ScoreRange"`
+	AverageFee         int                `json:"AverageFee"`
+	FeeRange           ValueRange         `json:"FeeRange"`
+	StatsYear          int                `json:"StatsYear"`
 	Gallery            []*GalleryResponse `json:"Gallery"`
 	Services           []*Service         `json:"Services"`
 	City               *City              `json:"City" validate:"required"`
@@ -136,7 +178,16 @@ type GetByIdUniversityResponseForUser struct {
 	WhatsAppNumber   string                    `json:"WhatsAppNumber"`
 	Address          string                    `json:"Address"`
 	AddressLink      string                    `json:"AddressLink"`
+	Latitude         float64                   `json:"Latitude"`
+	Longitude        float64                   `json:"Longitude"`
+	Campuses         []*CampusResponse         `json:"Campuses"`
 	Description      string                    `json:"Description"`
+	ScoreRange       ValueRange                `json:"ScoreRange"`
+	AverageFee       int                       `json:"AverageFee"`
+	FeeRange         ValueRange                `json:"FeeRange"`
+	StatsYear        int                       `json:"StatsYear"`
+	RatingSummary    *RatingSummary            `json:"RatingSummary"`
+	Faq              []*FaqEntryResponse       `json:"Faq"`
 	Services         []*ServiceResponseForUser `json:"Services"`
 	Gallery          []*GalleryResponse        `json:"Gallery"`
 }
@@ -158,10 +209,11 @@ type AddUUniversityResponse struct {
 	AbbreviationKz     string   `form:"AbbreviationKz" validate:"required"`
 	MainImageUrl       string   `form:"MainImageUrl"`
 	AddressLink        string   `form:"AddressLink" validate:"required"`
+	Latitude           float64  `form:"Latitude" validate:"gte=-90,lte=90"`
+	Longitude          float64  `form:"Longitude" validate:"gte=-180,lte=180"`
 	DescriptionRu      string   `form:"DescriptionRu" validate:"required"`
 	DescriptionKz      string   `form:"DescriptionKz" validate:"required"`
 	Rating             string   `form:"Rating" validate:"required"`
-	MinScore           int      `form:"MinScore" validate:"required"`
 	Gallery            []string `form:"Gallery"`
 	ServiceIds         []int    `form:"ServiceIds"`
 	CityId             int      `form:"CityId" validate:"required"`
@@ -184,10 +236,11 @@ type AddUniversityPartial struct {
 	AbbreviationKz     string   `form:"AbbreviationKz" validate:"required"`
 	MainImageUrl       string   `form:"MainImageUrl"`
 	AddressLink        string   `form:"AddressLink" validate:"required"`
+	Latitude           float64  `form:"Latitude" validate:"gte=-90,lte=90"`
+	Longitude          float64  `form:"Longitude" validate:"gte=-180,lte=180"`
 	DescriptionRu      string   `form:"DescriptionRu" validate:"required"`
 	DescriptionKz      string   `form:"DescriptionKz" validate:"required"`
 	Rating             string   `form:"Rating" validate:"required"`
-	MinScore           int      `form:"MinScore" validate:"required"`
 	Gallery            []string `form:"Gallery"`
 	CityId             int      `form:"CityId" validate:"required"`
 }
@@ -213,7 +266,6 @@ type UpdateUniversityResponse struct {
 	DescriptionRu      string   `form:"DescriptionRu"`
 	DescriptionKz      string   `form:"DescriptionKz"`
 	Rating             string   `form:"Rating"`
-	MinScore           int      `form:"MinScore"`
 	Gallery            []string `form:"Gallery"`
 	ServiceIds         []int    `form:"ServiceIds"`
 	CityId             int      `form:"CityId"`
@@ -237,20 +289,49 @@ type UpdateUniversityPartial struct {
 	MainImageUrl       string                  `form:"MainImageUrl"`
 	Email              string                  `form:"Email"`
 	AddressLink        string                  `form:"AddressLink"`
+	Latitude           float64                 `form:"Latitude" validate:"gte=-90,lte=90"`
+	Longitude          float64                 `form:"Longitude" validate:"gte=-180,lte=180"`
 	DescriptionRu      string                  `form:"DescriptionRu"`
 	DescriptionKz      string                  `form:"DescriptionKz"`
 	Rating             string                  `form:"Rating"`
-	MinScore           int                     `form:"MinScore"`
 	Gallery            []string                `form:"Gallery"`
 	SpecialityTerms    []*SpecialityUniversity `orm:"reverse(many)" json:"speciality_terms,omitempty"`
 	CityId             int                     `form:"CityId"`
 }
 
+func (u *University) ScoreRange() ValueRange {
+	return ValueRange{Min: u.MinEntryScore, Max: u.MaxEntryScore, Median: u.MedianEntryScore}
+}
+
+func (u *University) FeeRange() ValueRange {
+	return ValueRange{Min: u.MinFee, Max: u.MaxFee, Median: u.MedianFee}
+}
+
 type LocalizedFields struct {
 	Name   string
 	Status string
 }
 
+func (u *University) translationKey() (string, int) { return "university", u.Id }
+
+func (u *University) legacyTranslations() map[string]LocalizedText {
+	return map[string]LocalizedText{
+		"name":         {LocaleRu: u.NameRu, LocaleKz: u.NameKz},
+		"abbreviation": {LocaleRu: u.AbbreviationRu, LocaleKz: u.AbbreviationKz},
+		"status":       {LocaleRu: u.UniversityStatusRu, LocaleKz: u.UniversityStatusKz},
+		"description":  {LocaleRu: u.DescriptionRu, LocaleKz: u.DescriptionKz},
+		"study_format": {LocaleRu: u.StudyFormatRu, LocaleKz: u.StudyFormatKz},
+	}
+}
+
+func (u *University) Localize(language string) {
+	u.Name = translate(u, "name", language)
+	u.Abbreviation = translate(u, "abbreviation", language)
+	u.UniversityStatus = translate(u, "status", language)
+	u.Description = translate(u, "description", language)
+	u.StudyFormat = translate(u, "study_format", language)
+}
+
 func init() {
 	orm.RegisterModel(new(University))
 }
@@ -286,10 +367,11 @@ func AddUniversity(universityResponse *AddUUniversityResponse) (int64, error) {
 		AbbreviationKz:     universityResponse.AbbreviationKz,
 		MainImageUrl:       universityResponse.MainImageUrl,
 		AddressLink:        universityResponse.AddressLink,
+		Latitude:           universityResponse.Latitude,
+		Longitude:          universityResponse.Longitude,
 		DescriptionRu:      universityResponse.DescriptionRu,
 		DescriptionKz:      universityResponse.DescriptionKz,
 		Rating:             universityResponse.Rating,
-		MinEntryScore:      universityResponse.MinScore,
 		City:               &city,
 	}
 
@@ -298,6 +380,9 @@ func AddUniversity(universityResponse *AddUUniversityResponse) (int64, error) {
 		return 0, err
 	}
 	universityResponse.Id = int(id)
+	if err := syncTranslations(o, dbUniversity); err != nil {
+		return 0, err
+	}
 
 	for _, galleryURL := range universityResponse.Gallery {
 		gallery := &Gallery{
@@ -411,10 +496,16 @@ func GetUniversityByIdForAdmin(id int) (*GetByIdUniversityResponseForAdmin, erro
 		MainImageUrl:       university.MainImageUrl,
 		Gallery:            galleryResponses,
 		AddressLink:        university.AddressLink,
+		Latitude:           university.Latitude,
+		Longitude:          university.Longitude,
 		DescriptionRu:      university.DescriptionRu,
 		DescriptionKz:      university.DescriptionKz,
 		Rating:             university.Rating,
 		MinScore:           university.MinEntryScore,
+		ScoreRange:         university.ScoreRange(),
+		AverageFee:         university.AverageFee,
+		FeeRange:           university.FeeRange(),
+		StatsYear:          university.StatsYear,
 		Services:           university.Services,
 		City:               university.City,
 	}
@@ -423,6 +514,12 @@ func GetUniversityByIdForAdmin(id int) (*GetByIdUniversityResponseForAdmin, erro
 }
 
 func GetUniversityByIdForUser(id int, language string) (*GetByIdUniversityResponseForUser, error) {
+	return cache.Remember(cache.Key("university", language, id), universityCacheTags, func() (*GetByIdUniversityResponseForUser, error) {
+		return loadUniversityByIdForUser(id, language)
+	})
+}
+
+func loadUniversityByIdForUser(id int, language string) (*GetByIdUniversityResponseForUser, error) {
 	o := orm.NewOrm()
 	university := &University{Id: id}
 	err := o.Read(university)
@@ -437,6 +534,11 @@ func GetUniversityByIdForUser(id int, language string) (*GetByIdUniversityRespon
 		return nil, err
 	}
 
+	campuses, err := GetCampusesByUniversity(university.Id, language)
+	if err != nil {
+		return nil, err
+	}
+
 	var galleryResponses []*GalleryResponse
 	for _, gallery := range university.Gallery {
 		galleryResponses = append(galleryResponses, &GalleryResponse{
@@ -445,20 +547,16 @@ func GetUniversityByIdForUser(id int, language string) (*GetByIdUniversityRespon
 		})
 	}
 
-	var name, abbreviation, description string
-	switch language {
-	case "ru":
-		name = university.NameRu
-		description = university.DescriptionRu
-		abbreviation = university.AbbreviationRu
-	case "kz":
-		name = university.NameKz
-		description = university.DescriptionKz
-		abbreviation = university.AbbreviationKz
-	default:
-		name = university.NameKz
-		description = university.DescriptionKz
-		abbreviation = university.AbbreviationKz
+	university.Localize(language)
+
+	ratingSummary, err := GetRatingSummary(university.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	faq, err := GetFaqEntries(university.Id, language)
+	if err != nil {
+		return nil, err
 	}
 
 	serviceResponses := []*ServiceResponseForUser{}
@@ -472,16 +570,25 @@ func GetUniversityByIdForUser(id int, language string) (*GetByIdUniversityRespon
 
 	response := &GetByIdUniversityResponseForUser{
 		Id:               university.Id,
-		Name:             name,
+		Name:             university.Name,
 		Website:          university.Website,
 		Email:            university.Email,
 		CallCenterNumber: university.CallCenterNumber,
 		WhatsAppNumber:   university.WhatsAppNumber,
 		Address:          university.Address,
-		Abbreviation:     abbreviation,
+		Abbreviation:     university.Abbreviation,
 		MainImageUrl:     university.MainImageUrl,
 		AddressLink:      university.AddressLink,
-		Description:      description,
+		Latitude:         university.Latitude,
+		Longitude:        university.Longitude,
+		Campuses:         campuses,
+		Description:      university.Description,
+		ScoreRange:       university.ScoreRange(),
+		AverageFee:       university.AverageFee,
+		FeeRange:         university.FeeRange(),
+		StatsYear:        university.StatsYear,
+		RatingSummary:    ratingSummary,
+		Faq:              faq,
 		Gallery:          galleryResponses,
 		Services:         serviceResponses,
 	}
@@ -489,16 +596,11 @@ func GetUniversityByIdForUser(id int, language string) (*GetByIdUniversityRespon
 	return response, nil
 }
 
-func GetAllUniversities(ctx *context.Context, language string, page int, perPage int) ([]*GetAllUniversityResponse, int64, int, int, error) {
+// GetAllUniversities возвращает страницу университетов; Favorite отмечает избранные пользователем userId.
+func GetAllUniversities(userId int, language string, page int, perPage int) ([]*GetAllUniversityResponse, int64, int, int, error) {
 	o := orm.NewOrm()
 	var universities []*University
 
-	// Retrieve user_id from the context
-	userId, ok := ctx.Input.GetData("user_id").(int)
-	if !ok {
-		return nil, 0, 0, 0, errors.New("failed to retrieve user_id from context")
-	}
-
 	offset := (page - 1) * perPage
 
 	totalCount, err := o.QueryTable("university").Count()
@@ -513,36 +615,22 @@ func GetAllUniversities(ctx *context.Context, language string, page int, perPage
 		return nil, 0, 0, 0, err
 	}
 
-	var responses []*GetAllUniversityResponse
+	universityIds := make([]int, 0, len(universities))
 	for _, university := range universities {
-		switch language {
-		case "ru":
-			university.Name = university.NameRu
-			university.Description = university.DescriptionRu
-			university.UniversityStatus = university.UniversityStatusRu
-		case "kz":
-			university.Name = university.NameKz
-			university.Description = university.DescriptionKz
-			university.UniversityStatus = university.UniversityStatusKz
-		default:
-			university.Name = university.NameKz
-			university.Description = university.DescriptionKz
-		}
-
-		if _, err := o.LoadRelated(university, "Specialities"); err != nil {
-			return nil, 0, 0, 0, err
-		}
-
-		var favoriteUniversityCount int64
-
-		favoriteUniversityCount, err = o.QueryTable("favorite_university").
-			Filter("user_id", userId).
-			Filter("university_id", university.Id).
-			Count()
+		universityIds = append(universityIds, university.Id)
+	}
+	specialityCounts, err := specialityCountsByUniversity(o, universityIds)
+	if err != nil {
+		return nil, 0, 0, 0, err
+	}
+	favorites, err := favoriteUniversityIds(o, userId)
+	if err != nil {
+		return nil, 0, 0, 0, err
+	}
 
-		if err != nil {
-			return nil, 0, 0, 0, err
-		}
+	var responses []*GetAllUniversityResponse
+	for _, university := range universities {
+		university.Localize(language)
 
 		response := &GetAllUniversityResponse{
 			Id:               university.Id,
@@ -550,11 +638,16 @@ func GetAllUniversities(ctx *context.Context, language string, page int, perPage
 			ImageUrl:         university.MainImageUrl,
 			Address:          university.Address,
 			UniversityCode:   university.UniversityCode,
-			SpecialityCount:  len(university.Specialities),
+			SpecialityCount:  specialityCounts[university.Id],
 			UniversityStatus: university.UniversityStatus,
 			MinScore:         university.MinEntryScore,
+			ScoreRange:       university.ScoreRange(),
+			AverageFee:       university.AverageFee,
+			FeeRange:         university.FeeRange(),
 			Rating:           university.Rating,
-			Favorite:         favoriteUniversityCount > 0, // Set favorite field
+			RatingScore:      university.RatingScore,
+			ReviewCount:      university.ReviewCount,
+			Favorite:         favorites[university.Id],
 		}
 
 		responses = append(responses, response)
@@ -588,6 +681,10 @@ func GetAllUniversitiesForAdmin() ([]*GetAllUniversityForAdminResponse, error) {
 			UniversityStatusRu: university.UniversityStatusRu,
 			UniversityStatusKz: university.UniversityStatusKz,
 			MinScore:           university.MinEntryScore,
+			ScoreRange:         university.ScoreRange(),
+			AverageFee:         university.AverageFee,
+			FeeRange:           university.FeeRange(),
+			StatsYear:          university.StatsYear,
 			Rating:             university.Rating,
 		}
 
@@ -606,8 +703,11 @@ func GetUniversityByID(id int) (*University, error) {
 }
 
 func UpdateUniversity(university *University) error {
-	_, err := orm.NewOrm().Update(university)
-	return err
+	o := orm.NewOrm()
+	if _, err := o.Update(university); err != nil {
+		return err
+	}
+	return syncTranslations(o, university)
 }
 
 func UpdateUniversityGallery(universityID int, newGalleryURLs []string) error {
@@ -639,27 +739,28 @@ func UpdateUniversityGallery(universityID int, newGalleryURLs []string) error {
 	return nil
 }
 
-func DeleteUniversity(id int) error {
-	o := orm.NewOrm()
-	err := o.Begin()
+// DeleteUniversity удаляет университет вместе с галереей; главное изображение и фото
+// галереи удаляются из хранилища через deleteFile (DeleteFileFromCloud в сервисе).
+func DeleteUniversity(ctx context.Context, id int, deleteFile func(ctx context.Context, path string) error) error {
+	o, err := newOrmWithContext(ctx)
 	if err != nil {
-		fmt.Println("Failed to start transaction:", err)
+		slog.ErrorContext(ctx, "Failed to start transaction", "university_id", id, "error", err)
 		return err
 	}
 
 	university := University{Id: id}
 	if err := o.Read(&university); err != nil {
 		o.Rollback()
-		fmt.Println("Failed to read university:", err)
+		slog.ErrorContext(ctx, "Failed to read university", "university_id", id, "error", err)
 		return err
 	}
 
 	if university.MainImageUrl != "" {
-		fmt.Println("Deleting main image from cloud:", university.MainImageUrl)
-		err = deleteFileFromCloud(university.MainImageUrl)
+		slog.InfoContext(ctx, "Deleting university image from storage", "university_id", id, "path", university.MainImageUrl)
+		err = deleteFile(ctx, university.MainImageUrl)
 		if err != nil {
 			o.Rollback()
-			fmt.Println("Failed to delete main image from cloud:", err)
+			slog.ErrorContext(ctx, "Failed to delete university image from storage", "university_id", id, "error", err)
 			return err
 		}
 	}
@@ -668,17 +769,17 @@ func DeleteUniversity(id int) error {
 	_, err = o.QueryTable("gallery").Filter("university_id", id).All(&galleries)
 	if err != nil {
 		o.Rollback() // Rollback transaction on error
-		fmt.Println("Failed to fetch galleries:", err)
+		slog.ErrorContext(ctx, "Failed to fetch galleries", "university_id", id, "error", err)
 		return err
 	}
 
 	for _, gallery := range galleries {
 		if gallery.PhotoUrl != "" {
-			fmt.Println("Deleting gallery image from cloud:", gallery.PhotoUrl)
-			err = deleteFileFromCloud(gallery.PhotoUrl)
+			slog.InfoContext(ctx, "Deleting gallery image from storage", "university_id", id, "path", gallery.PhotoUrl)
+			err = deleteFile(ctx, gallery.PhotoUrl)
 			if err != nil {
 				o.Rollback() // Rollback transaction on error
-				fmt.Println("Failed to delete gallery image from cloud:", err)
+				slog.ErrorContext(ctx, "Failed to delete gallery image from storage", "university_id", id, "error", err)
 				return err
 			}
 		}
@@ -688,46 +789,73 @@ func DeleteUniversity(id int) error {
 	_, err = o.Delete(&university)
 	if err != nil {
 		o.Rollback() // Rollback transaction on error
-		fmt.Println("Failed to delete university:", err)
+		slog.ErrorContext(ctx, "Failed to delete university", "university_id", id, "error", err)
+		return err
+	}
+	if err := deleteTranslations(o, "university", university.Id); err != nil {
+		o.Rollback()
 		return err
 	}
 
 	err = o.Commit() // Commit transaction
 	if err != nil {
-		fmt.Println("Failed to commit transaction:", err)
+		slog.ErrorContext(ctx, "Failed to commit transaction", "university_id", id, "error", err)
 	}
 	return err
 }
 
-func deleteFileFromCloud(filePath string) error {
-	awsAccessKey, _ := beego.AppConfig.String("aws_access_key")
-	awsSecretKey, _ := beego.AppConfig.String("aws_secret_key")
-	bucket, _ := beego.AppConfig.String("bucket")
+// startStorageSpan начинает span вызова S3 method для объекта key в bucket.
+func startStorageSpan(ctx context.Context, method, bucket, key string) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "S3."+method,
+		attribute.String("rpc.system", "aws-api"),
+		attribute.String("rpc.service", "S3"),
+		attribute.String("rpc.method", method),
+		attribute.String("aws.s3.bucket", bucket),
+		attribute.String("aws.s3.key", key),
+	)
+}
 
-	sess, err := session.NewSession(&aws.Config{
-		Region:           aws.String("us-east-1"),
-		Credentials:      credentials.NewStaticCredentials(awsAccessKey, awsSecretKey, ""),
-		Endpoint:         aws.String("https://chi-sextans.object.pscloud.io"),
+func newStorageSession(storage config.Storage) (*session.Session, error) {
+	return session.NewSession(&aws.Config{
+		Region:           aws.String(storage.Region),
+		Credentials:      credentials.NewStaticCredentials(storage.AccessKey, storage.SecretKey, ""),
+		Endpoint:         aws.String(storage.Endpoint),
 		S3ForcePathStyle: aws.Bool(true),
 	})
+}
+
+// DeleteFileFromCloud удаляет файл filePath из S3-совместимого хранилища и ждёт,
+// пока он пропадёт.
+func DeleteFileFromCloud(ctx context.Context, filePath string) error {
+	storage := config.Get().Storage
+	bucket := storage.Bucket
+
+	sess, err := newStorageSession(storage)
 	if err != nil {
 		return fmt.Errorf("failed to create AWS session: %v", err)
 	}
 
 	svc := s3.New(sess)
 
-	_, err = svc.DeleteObject(&s3.DeleteObjectInput{
+	started := time.Now()
+	defer func() { metrics.ObserveStorage("delete", started, err) }()
+
+	spanCtx, span := startStorageSpan(ctx, "DeleteObject", bucket, filePath)
+	_, err = svc.DeleteObjectWithContext(spanCtx, &s3.DeleteObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(filePath),
 	})
+	tracing.End(span, err)
 	if err != nil {
 		return fmt.Errorf("failed to delete file: %v", err)
 	}
 
-	err = svc.WaitUntilObjectNotExists(&s3.HeadObjectInput{
+	spanCtx, span = startStorageSpan(ctx, "WaitUntilObjectNotExists", bucket, filePath)
+	err = svc.WaitUntilObjectNotExistsWithContext(spanCtx, &s3.HeadObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(filePath),
 	})
+	tracing.End(span, err)
 	if err != nil {
 		return fmt.Errorf("failed to wait for file deletion: %v", err)
 	}
@@ -764,9 +892,24 @@ func AssignCityToUniversity(universityId int, cityId int) error {
 
 	return nil
 }
-func AddSpecialityToUniversity(specialityId, universityId int) error {
-	o := orm.NewOrm()
+func AddSpecialityToUniversity(ctx context.Context, specialityId, universityId int) error {
+	o, err := newOrmWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := addSpecialityToUniversity(o, specialityId, universityId); err != nil {
+		o.Rollback()
+		return err
+	}
+	if err := o.Commit(); err != nil {
+		return err
+	}
 
+	slog.InfoContext(ctx, "Speciality added to university", "university_id", universityId, "speciality_id", specialityId)
+	return nil
+}
+
+func addSpecialityToUniversity(o orm.Ormer, specialityId, universityId int) error {
 	speciality := &Speciality{Id: specialityId}
 	if err := o.Read(speciality); err != nil {
 		return err
@@ -777,20 +920,47 @@ func AddSpecialityToUniversity(specialityId, universityId int) error {
 		return err
 	}
 
-	exist := o.QueryM2M(university, "Specialities").Exist(speciality)
+	// Specialities — обратная связь через speciality_university, а не m2m: запись связи
+	// создаётся явно, со сроком и языком обучения по умолчанию, как в AddSpecialitiesToUniversity.
+	exist := o.QueryTable("speciality_university").Filter("speciality_id", specialityId).Filter("university_id", universityId).Exist()
 	if exist {
 		return fmt.Errorf("speciality with ID %d is already assigned to university with ID %d", specialityId, universityId)
 	}
 
-	_, err := o.QueryM2M(university, "Specialities").Add(speciality)
-	if err != nil {
+	link := &SpecialityUniversity{Speciality: speciality, University: university, Term: 4, EduLang: "kz"}
+	if _, err := o.Insert(link); err != nil {
 		return err
 	}
+	return refreshUniversityStats(o, universityId)
+}
 
-	o.LoadRelated(university, "Specialities")
-	fmt.Printf("Specialities for university %d: %v\n", universityId, university.Specialities)
+// RemoveSpecialityFromUniversity отвязывает специальность от университета и
+// пересчитывает его статистику. Если связи нет, возвращается orm.ErrNoRows.
+func RemoveSpecialityFromUniversity(ctx context.Context, universityId, specialityId int) error {
+	o, err := newOrmWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := removeSpecialityFromUniversity(o, universityId, specialityId); err != nil {
+		o.Rollback()
+		return err
+	}
+	return o.Commit()
+}
 
-	return nil
+func removeSpecialityFromUniversity(o orm.Ormer, universityId, specialityId int) error {
+	res, err := o.Raw(`DELETE FROM speciality_university WHERE university_id = ? AND speciality_id = ?`, universityId, specialityId).Exec()
+	if err != nil {
+		return err
+	}
+	num, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if num == 0 {
+		return orm.ErrNoRows
+	}
+	return refreshUniversityStats(o, universityId)
 }
 
 func getSpecialityIDs(university *University) []int {
@@ -809,9 +979,24 @@ func getServiceIDs(university *University) []int {
 	return serviceIDs
 }
 
-func AddSpecialitiesToUniversity(specialityIds []int, universityId int) error {
-	o := orm.NewOrm()
+func AddSpecialitiesToUniversity(ctx context.Context, specialityIds []int, universityId int) error {
+	o, err := newOrmWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := addSpecialitiesToUniversity(o, specialityIds, universityId); err != nil {
+		o.Rollback()
+		return err
+	}
+	if err := o.Commit(); err != nil {
+		return err
+	}
 
+	slog.InfoContext(ctx, "Specialities added to university", "university_id", universityId, "speciality_ids", specialityIds)
+	return nil
+}
+
+func addSpecialitiesToUniversity(o orm.Ormer, specialityIds []int, universityId int) error {
 	university := &University{Id: universityId}
 	if err := o.Read(university); err != nil {
 		return err
@@ -837,16 +1022,27 @@ func AddSpecialitiesToUniversity(specialityIds []int, universityId int) error {
 		}
 	}
 
-	// Load the related Specialities
-	o.LoadRelated(university, "Specialities")
-	fmt.Printf("Specialities for university %d: %v\n", universityId, university.Specialities)
+	return refreshUniversityStats(o, universityId)
+}
 
+func AddServicesToUniversity(ctx context.Context, serviceIds []int, universityId int) error {
+	o, err := newOrmWithContext(ctx)
+	if err != nil {
+		return err
+	}
+	if err := addServicesToUniversity(o, serviceIds, universityId); err != nil {
+		o.Rollback()
+		return err
+	}
+	if err := o.Commit(); err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "Services added to university", "university_id", universityId, "service_ids", serviceIds)
 	return nil
 }
 
-func AddServicesToUniversity(serviceIds []int, universityId int) error {
-	o := orm.NewOrm()
-
+func addServicesToUniversity(o orm.Ormer, serviceIds []int, universityId int) error {
 	university := &University{Id: universityId}
 	if err := o.Read(university); err != nil {
 		return err
@@ -868,133 +1064,135 @@ func AddServicesToUniversity(serviceIds []int, universityId int) error {
 		}
 	}
 
-	o.LoadRelated(university, "Services")
-	fmt.Printf("Services for university %d: %v\n", universityId, university.Services)
-
 	return nil
 }
 
-func SearchUniversities(params map[string]interface{}, language string) (*UniversitySearchResult, error) {
-	o := orm.NewOrm()
-	var universities []*University
-
-	_, err := o.QueryTable("university").All(&universities)
+func SearchUniversities(ctx context.Context, params map[string]interface{}, language string) (*UniversitySearchResult, error) {
+	universities, err := filterUniversitiesByParams(ctx, params, language)
 	if err != nil {
 		return nil, err
 	}
 
+	var universityResponses []*GetAllUniversityResponse
 	for _, uni := range universities {
-		if _, err := o.LoadRelated(uni, "Specialities"); err != nil {
-			return nil, err
+		localized := selectLanguage(uni, language)
+		uniResponse := &GetAllUniversityResponse{
+			Id:               uni.Id,
+			Name:             localized.Name,
+			UniversityStatus: localized.Status,
+			ImageUrl:         uni.MainImageUrl,
+			Address:          uni.Address,
+			UniversityCode:   uni.UniversityCode,
+			SpecialityCount:  len(uni.Specialities),
+			MinScore:         uni.MinEntryScore,
+			ScoreRange:       uni.ScoreRange(),
+			AverageFee:       uni.AverageFee,
+			FeeRange:         uni.FeeRange(),
+			Rating:           uni.Rating,
+			RatingScore:      uni.RatingScore,
+			ReviewCount:      uni.ReviewCount,
 		}
-		if _, err := o.LoadRelated(uni, "Services"); err != nil {
-			return nil, err
+		if _, ok := searchOrigin(params); ok {
+			distance := math.Round(uni.DistanceKm*10) / 10
+			uniResponse.DistanceKm = &distance
 		}
+		universityResponses = append(universityResponses, uniResponse)
 	}
 
-	universities, err = filterByMinScore(params, universities)
-	if err != nil {
-		return nil, err
-	}
-
-	universities, err = filterByAvgFee(params, universities)
-	if err != nil {
-		return nil, err
-	}
-
-	universities, err = filterUniversitiesByName(params, universities, language)
-	if err != nil {
-		return nil, err
-	}
-
-	universities, err = filterBySubjects(params, universities)
+	result, err := paginateUniversities(universityResponses, params)
 	if err != nil {
 		return nil, err
 	}
 
-	universities, err = filterByCityID(params, universities)
-	if err != nil {
-		return nil, err
-	}
+	slog.DebugContext(ctx, "University search finished", "found", len(universityResponses), "page_size", len(result.Universities))
+	return result, nil
+}
 
-	universities, err = filterByStudyFormat(params, universities)
+// filterUniversitiesByParams загружает университеты и применяет фильтры и сортировку поиска.
+// Общая часть для SearchUniversities и карты университетов.
+func filterUniversitiesByParams(ctx context.Context, params map[string]interface{}, language string) ([]*University, error) {
+	// Транзакция только читает: она нужна, чтобы span запросов попали в трассу запроса.
+	o, err := newOrmWithContext(ctx)
 	if err != nil {
 		return nil, err
 	}
-
-	universities, err = filterBySpecialityIDs(params, universities)
+	universities, err := loadUniversitiesWithRelations(o)
+	o.Rollback()
 	if err != nil {
 		return nil, err
 	}
 
-	universities, err = filterByTerm(params, universities)
-	if err != nil {
-		return nil, err
+	localize := func(uni *University, field string) string { return translate(uni, field, language) }
+	// Сначала шаги, которые читают базу, затем фильтры по полям университета.
+	steps := []universityFilterStep{
+		{"name", func(u []*University) ([]*University, error) { return filterUniversitiesByName(params, u, language) }},
+		{"subjects", func(u []*University) ([]*University, error) { return filterBySubjects(params, u) }},
+		{"region_id", func(u []*University) ([]*University, error) { return filterByRegionID(params, u) }},
+		{"distance", func(u []*University) ([]*University, error) { return filterByDistance(params, u) }},
 	}
+	return applyUniversityFilters(ctx, append(steps, universityFieldFilters(params, localize)...), universities)
+}
 
-	universities, err = filterBySpecialityID(params, universities)
-	if err != nil {
+// loadUniversitiesWithRelations загружает все университеты с их специальностями и сервисами.
+func loadUniversitiesWithRelations(o orm.Ormer) ([]*University, error) {
+	var universities []*University
+	if _, err := o.QueryTable("university").All(&universities); err != nil {
 		return nil, err
 	}
-
-	universities, err = filterByStatus(params, universities, language)
-	if err != nil {
-		return nil, err
+	for _, uni := range universities {
+		if _, err := o.LoadRelated(uni, "Specialities"); err != nil {
+			return nil, err
+		}
+		if _, err := o.LoadRelated(uni, "Services"); err != nil {
+			return nil, err
+		}
 	}
+	return universities, nil
+}
 
-	universities, err = filterBySortOrder(params, universities, language)
-	if err != nil {
-		return nil, err
-	}
+type universityFilterStep struct {
+	name  string
+	apply func([]*University) ([]*University, error)
+}
 
-	universities, err = filterByServiceIDs(params, universities)
-	if err != nil {
-		return nil, err
+// universityFieldFilters — шаги поиска, которым хватает полей университета и загруженных
+// Specialities и Services; последним идёт сортировка.
+func universityFieldFilters(params map[string]interface{}, localize func(*University, string) string) []universityFilterStep {
+	return []universityFilterStep{
+		{"min_score", func(u []*University) ([]*University, error) { return filterByMinScore(params, u) }},
+		{"avg_fee", func(u []*University) ([]*University, error) { return filterByAvgFee(params, u) }},
+		{"city_id", func(u []*University) ([]*University, error) { return filterByCityID(params, u) }},
+		{"study_format", func(u []*University) ([]*University, error) { return filterByStudyFormat(params, u) }},
+		{"speciality_ids", func(u []*University) ([]*University, error) { return filterBySpecialityIDs(params, u) }},
+		{"term", func(u []*University) ([]*University, error) { return filterByTerm(params, u) }},
+		{"speciality_id", func(u []*University) ([]*University, error) { return filterBySpecialityID(params, u) }},
+		{"status", func(u []*University) ([]*University, error) { return filterByStatus(params, u, localize) }},
+		{"service_ids", func(u []*University) ([]*University, error) { return filterByServiceIDs(params, u) }},
+		{"sort", func(u []*University) ([]*University, error) { return filterBySortOrder(params, u, localize) }},
 	}
+}
 
-	var universityResponses []*GetAllUniversityResponse
-	for _, uni := range universities {
-		localized := selectLanguage(uni, language)
-		uniResponse := &GetAllUniversityResponse{
-			Id:               uni.Id,
-			Name:             localized.Name,
-			UniversityStatus: localized.Status,
-			ImageUrl:         uni.MainImageUrl,
-			Address:          uni.Address,
-			UniversityCode:   uni.UniversityCode,
-			SpecialityCount:  len(uni.Specialities),
-			MinScore:         uni.MinEntryScore,
-			Rating:           uni.Rating,
+// applyUniversityFilters применяет шаги по порядку; в отладочный лог попадают те,
+// что отбросили университеты.
+func applyUniversityFilters(ctx context.Context, steps []universityFilterStep, universities []*University) ([]*University, error) {
+	for _, step := range steps {
+		before := len(universities)
+		filtered, err := step.apply(universities)
+		if err != nil {
+			return nil, err
+		}
+		universities = filtered
+		if len(universities) != before {
+			slog.DebugContext(ctx, "University search filter applied", "filter", step.name, "before", before, "after", len(universities))
 		}
-		universityResponses = append(universityResponses, uniResponse)
-	}
-
-	result, err := paginateUniversities(universityResponses, params)
-	if err != nil {
-		return nil, err
 	}
-
-	fmt.Printf("SearchUniversities: total universities after filtering: %d\n", len(result.Universities))
-	return result, nil
+	return universities, nil
 }
 
 func selectLanguage(uni *University, language string) LocalizedFields {
-	switch language {
-	case "ru":
-		return LocalizedFields{
-			Name:   uni.NameRu,
-			Status: uni.UniversityStatusRu,
-		}
-	case "kz":
-		return LocalizedFields{
-			Name:   uni.NameKz,
-			Status: uni.UniversityStatusKz,
-		}
-	default:
-		return LocalizedFields{
-			Name:   uni.UniversityStatusKz,
-			Status: uni.UniversityStatusKz,
-		}
+	return LocalizedFields{
+		Name:   translate(uni, "name", language),
+		Status: translate(uni, "status", language),
 	}
 }
 func filterUniversitiesByName(params map[string]interface{}, universities []*University, lang string) ([]*University, error) {
@@ -1003,32 +1201,24 @@ func filterUniversitiesByName(params map[string]interface{}, universities []*Uni
 		return universities, nil
 	}
 
-	if lang != "ru" && lang != "kz" {
-		return universities, fmt.Errorf("invalid or missing lang parameter")
-	}
-
 	o := orm.NewOrm()
 	searchPattern := fmt.Sprintf("%%%s%%", prefix)
 
-	var nameField, abbreviationField string
-	if lang == "ru" {
-		nameField = "name_ru"
-		abbreviationField = "abbreviation_ru"
-	} else if lang == "kz" {
-		nameField = "name_kz"
-		abbreviationField = "abbreviation_kz"
-	}
+	d := dialect.Current()
+	nameCondition, args := localeSearch("university", "name", lang, searchPattern, d.ILike)
+	abbreviationCondition, abbreviationArgs := localeSearch("university", "abbreviation", lang, searchPattern, d.ILike)
+	args = append(append(args, abbreviationArgs...), searchPattern)
 
 	var matchingUniversities []*University
 	query := fmt.Sprintf(`
 		SELECT * 
 		FROM university 
-		WHERE %s ILIKE ? 
-		OR %s ILIKE ? 
-		OR university_code ILIKE ?
-	`, nameField, abbreviationField)
+		WHERE %s 
+		OR %s 
+		OR %s
+	`, nameCondition, abbreviationCondition, d.ILike("university_code"))
 
-	_, err := o.Raw(query, searchPattern, searchPattern, searchPattern).QueryRows(&matchingUniversities)
+	_, err := o.Raw(query, args...).QueryRows(&matchingUniversities)
 	if err != nil {
 		return universities, err
 	}
@@ -1040,7 +1230,7 @@ func filterUniversitiesByName(params map[string]interface{}, universities []*Uni
 
 	var filteredUniversities []*University
 	for _, uni := range universities {
-		if _, exists := matchingUniversityMap[uni.Id]; exists {
+		if _, exists := matchingUniversityMap[uni.Id]; exists || matchesKazakhLatin(prefix, uni, false, "name", "abbreviation") {
 			filteredUniversities = append(filteredUniversities, uni)
 		}
 	}
@@ -1048,33 +1238,42 @@ func filterUniversitiesByName(params map[string]interface{}, universities []*Uni
 	return filteredUniversities, nil
 }
 
-func filterBySortOrder(params map[string]interface{}, universities []*University, lang string) ([]*University, error) {
+func filterBySortOrder(params map[string]interface{}, universities []*University, localize func(*University, string) string) ([]*University, error) {
 	if sortOrder, ok := params["sort"].(string); ok {
 		switch sortOrder {
-		case "name_asc":
-			if lang == "ru" {
-				sort.Slice(universities, func(i, j int) bool {
-					return universities[i].NameRu < universities[j].NameRu
-				})
-			} else if lang == "kz" {
-				sort.Slice(universities, func(i, j int) bool {
-					return universities[i].NameKz < universities[j].NameKz
-				})
-			} else {
-				return universities, fmt.Errorf("unsupported language: %s", lang)
+		case "name_asc", "name_desc":
+			names := make(map[int]string, len(universities))
+			for _, uni := range universities {
+				names[uni.Id] = localize(uni, "name")
 			}
-		case "name_desc":
-			if lang == "ru" {
-				sort.Slice(universities, func(i, j int) bool {
-					return universities[i].NameRu > universities[j].NameRu
-				})
-			} else if lang == "kz" {
-				sort.Slice(universities, func(i, j int) bool {
-					return universities[i].NameKz > universities[j].NameKz
-				})
-			} else {
-				return universities, fmt.Errorf("unsupported language: %s", lang)
+			desc := sortOrder == "name_desc"
+			sort.Slice(universities, func(i, j int) bool {
+				if desc {
+					return names[universities[i].Id] > names[universities[j].Id]
+				}
+				return names[universities[i].Id] < names[universities[j].Id]
+			})
+		case "distance_asc":
+			if _, ok := searchOrigin(params); !ok {
+				return universities, fmt.Errorf("sort by distance requires lat and lng")
 			}
+			sort.SliceStable(universities, func(i, j int) bool {
+				return universities[i].DistanceKm < universities[j].DistanceKm
+			})
+		case "rating_desc":
+			sort.SliceStable(universities, func(i, j int) bool {
+				if universities[i].RatingScore != universities[j].RatingScore {
+					return universities[i].RatingScore > universities[j].RatingScore
+				}
+				return universities[i].ReviewCount > universities[j].ReviewCount
+			})
+		case "rating_asc":
+			sort.SliceStable(universities, func(i, j int) bool {
+				if universities[i].RatingScore != universities[j].RatingScore {
+					return universities[i].RatingScore < universities[j].RatingScore
+				}
+				return universities[i].ReviewCount > universities[j].ReviewCount
+			})
 		default:
 			return universities, fmt.Errorf("invalid sort order: %s", sortOrder)
 		}
@@ -1089,7 +1288,6 @@ func filterByMinScore(params map[string]interface{}, universities []*University)
 				filtered = append(filtered, uni)
 			}
 		}
-		fmt.Printf("filterByMinScore: filtered %d universities\n", len(universities)-len(filtered))
 		return filtered, nil
 	}
 	return universities, nil
@@ -1103,7 +1301,6 @@ func filterByAvgFee(params map[string]interface{}, universities []*University) (
 				filtered = append(filtered, uni)
 			}
 		}
-		fmt.Printf("filterByAvgFee: filtered %d universities\n", len(universities)-len(filtered))
 		return filtered, nil
 	}
 	return universities, nil
@@ -1113,15 +1310,10 @@ func filterByTerm(params map[string]interface{}, universities []*University) ([]
 	if term, ok := params["term"].(int); ok {
 		var filteredUniversities []*University
 
-		fmt.Printf("Filtering by Term: %d\n", term)
-
 		for _, uni := range universities {
-			fmt.Printf("Checking university: %s (ID: %d)\n", uni.Name, uni.Id)
-
 			matchingTermFound := false
 			for _, specUni := range uni.Specialities {
 				if specUni.Term == term {
-					fmt.Printf(" -> Match found for Term: %d in university: %s (ID: %d)\n", term, uni.Name, uni.Id)
 					matchingTermFound = true
 					break
 				}
@@ -1132,7 +1324,6 @@ func filterByTerm(params map[string]interface{}, universities []*University) ([]
 			}
 		}
 
-		fmt.Printf("Total universities after term filter: %d\n", len(filteredUniversities))
 		return filteredUniversities, nil
 	}
 
@@ -1140,7 +1331,7 @@ func filterByTerm(params map[string]interface{}, universities []*University) ([]
 	return universities, nil
 }
 
-func filterByStatus(params map[string]interface{}, universities []*University, language string) ([]*University, error) {
+func filterByStatus(params map[string]interface{}, universities []*University, localize func(*University, string) string) ([]*University, error) {
 	statusFilter, ok := params["status"].(string)
 	if !ok || statusFilter == "" {
 		return universities, nil
@@ -1148,15 +1339,7 @@ func filterByStatus(params map[string]interface{}, universities []*University, l
 	var filteredUniversities []*University
 	statusFilterLower := strings.ToLower(statusFilter)
 	for _, uni := range universities {
-		var universityStatus string
-		switch language {
-		case "ru":
-			universityStatus = uni.UniversityStatusRu
-		case "kz":
-			universityStatus = uni.UniversityStatusKz
-		default:
-			universityStatus = uni.UniversityStatusKz
-		}
+		universityStatus := localize(uni, "status")
 		if strings.ToLower(universityStatus) == statusFilterLower {
 			filteredUniversities = append(filteredUniversities, uni)
 		}
@@ -1173,21 +1356,119 @@ func filterByCityID(params map[string]interface{}, universities []*University) (
 				filtered = append(filtered, uni)
 			}
 		}
-		fmt.Printf("filterByCityID: filtered %d universities\n", len(universities)-len(filtered))
 		return filtered, nil
 	}
 	return universities, nil
 }
 
+// filterByRegionID оставляет университеты, у которых главный корпус или любой
+// из корпусов находится в городе указанной области.
+func filterByRegionID(params map[string]interface{}, universities []*University) ([]*University, error) {
+	regionID, ok := params["region_id"].(int)
+	if !ok {
+		return universities, nil
+	}
+
+	o := orm.NewOrm()
+	var cities []*City
+	if _, err := o.QueryTable("city").Filter("Region__Id", regionID).All(&cities, "Id"); err != nil {
+		return nil, err
+	}
+	inRegion := make(map[int]bool, len(cities))
+	for _, city := range cities {
+		inRegion[city.Id] = true
+	}
+
+	campusesByUniversity, err := loadCampusesByUniversity(o)
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []*University
+	for _, uni := range universities {
+		match := uni.City != nil && inRegion[uni.City.Id]
+		for _, campus := range campusesByUniversity[uni.Id] {
+			if campus.City != nil && inRegion[campus.City.Id] {
+				match = true
+			}
+		}
+		if match {
+			filtered = append(filtered, uni)
+		}
+	}
+	return filtered, nil
+}
+
+// searchOrigin возвращает точку, от которой считается расстояние, если она передана в параметрах.
+func searchOrigin(params map[string]interface{}) (GeoPoint, bool) {
+	lat, latOk := params["lat"].(float64)
+	lng, lngOk := params["lng"].(float64)
+	if !latOk || !lngOk {
+		return GeoPoint{}, false
+	}
+	return GeoPoint{Latitude: lat, Longitude: lng}, true
+}
+
+// filterByDistance считает для каждого университета расстояние до ближайшего корпуса
+// и, если задан radius_km, отбрасывает университеты дальше радиуса. Университеты без
+// координат при заданной точке исключаются, так как расстояние до них неизвестно.
+func filterByDistance(params map[string]interface{}, universities []*University) ([]*University, error) {
+	origin, ok := searchOrigin(params)
+	if !ok {
+		return universities, nil
+	}
+	radius, hasRadius := params["radius_km"].(float64)
+
+	campusesByUniversity, err := loadCampusesByUniversity(orm.NewOrm())
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []*University
+	for _, uni := range universities {
+		var points []GeoPoint
+		if main := (GeoPoint{Latitude: uni.Latitude, Longitude: uni.Longitude}); !main.IsZero() {
+			points = append(points, main)
+		}
+		for _, campus := range campusesByUniversity[uni.Id] {
+			if point := (GeoPoint{Latitude: campus.Latitude, Longitude: campus.Longitude}); !point.IsZero() {
+				points = append(points, point)
+			}
+		}
+		if len(points) == 0 {
+			continue
+		}
+
+		nearest := math.Inf(1)
+		for _, point := range points {
+			nearest = math.Min(nearest, HaversineKm(origin, point))
+		}
+		if hasRadius && nearest > radius {
+			continue
+		}
+		uni.DistanceKm = nearest
+		filtered = append(filtered, uni)
+	}
+	return filtered, nil
+}
+
+func loadCampusesByUniversity(o orm.Ormer) (map[int][]*Campus, error) {
+	var campuses []*Campus
+	if _, err := o.QueryTable("campus").All(&campuses); err != nil {
+		return nil, err
+	}
+	byUniversity := make(map[int][]*Campus)
+	for _, campus := range campuses {
+		byUniversity[campus.University.Id] = append(byUniversity[campus.University.Id], campus)
+	}
+	return byUniversity, nil
+}
+
 func filterBySpecialityIDs(params map[string]interface{}, universities []*University) ([]*University, error) {
 	if specialityIDs, ok := params["speciality_ids"].([]int); ok && len(specialityIDs) > 0 {
 		var filteredUniversities []*University
 
-		fmt.Printf("Filtering by Speciality IDs: %v\n", specialityIDs)
-
 		for _, uni := range universities {
-			fmt.Printf("Checking university: %s (ID: %d)\n", uni.Name, uni.Id)
-
 			specialityMap := make(map[int]bool)
 			for _, specUni := range uni.Specialities {
 				if containsInt(specialityIDs, specUni.Speciality.Id) {
@@ -1226,7 +1507,6 @@ func filterByServiceIDs(params map[string]interface{}, universities []*Universit
 	if serviceIDs, ok := params["service_ids"].([]int); ok {
 		var filtered []*University
 		for _, uni := range universities {
-			fmt.Println("ID: ", uni.Services)
 			matches := 0
 			for _, service := range uni.Services {
 				for _, id := range serviceIDs {
@@ -1240,7 +1520,6 @@ func filterByServiceIDs(params map[string]interface{}, universities []*Universit
 				filtered = append(filtered, uni)
 			}
 		}
-		fmt.Printf("filterByServiceIDs: filtered %d universities\n", len(universities)-len(filtered))
 		return filtered, nil
 	}
 	return universities, nil
@@ -1250,14 +1529,10 @@ func filterByStudyFormat(params map[string]interface{}, universities []*Universi
 	if studyFormat, ok := params["study_format"].(string); ok {
 		var filtered []*University
 		for _, uni := range universities {
-			fmt.Println("studyFormat", studyFormat)
-			fmt.Println("studyFormatRu", uni.StudyFormatRu)
-			fmt.Println("studyFormatKz", uni.StudyFormatKz)
 			if uni.StudyFormat == studyFormat || uni.StudyFormatRu == studyFormat || uni.StudyFormatKz == studyFormat {
 				filtered = append(filtered, uni)
 			}
 		}
-		fmt.Printf("filterByStudyFormat: filtered %d universities\n", len(universities)-len(filtered))
 		return filtered, nil
 	}
 	return universities, nil
@@ -1298,27 +1573,35 @@ func filterBySubjects(params map[string]interface{}, universities []*University)
         WHERE 1=1`
 
 	var args []interface{}
-	argCount := 1
 
 	if firstOk {
-		query += " AND (sp.subject1_id = $" + strconv.Itoa(argCount) + " OR $" + strconv.Itoa(argCount) + " IS NULL)"
+		query += " AND sp.subject1_id = ?"
 		args = append(args, firstSubjectId)
-		argCount++
 	}
 
 	if secondOk {
-		query += " AND (sp.subject2_id = $" + strconv.Itoa(argCount) + " OR $" + strconv.Itoa(argCount) + " IS NULL)"
+		query += " AND sp.subject2_id = ?"
 		args = append(args, secondSubjectId)
-		argCount++
 	}
 
-	var filtered []*University
-	_, err := o.Raw(query, args...).QueryRows(&filtered)
+	var matching []*University
+	_, err := o.Raw(query, args...).QueryRows(&matching)
 	if err != nil {
 		return nil, err
 	}
 
-	fmt.Printf("filterBySubjects: filtered %d universities\n", len(filtered))
+	// Оставляем университеты исходного списка: у них загружены связи, нужные
+	// следующим фильтрам, и посчитано расстояние.
+	matchingIds := make(map[int]bool, len(matching))
+	for _, uni := range matching {
+		matchingIds[uni.Id] = true
+	}
+	var filtered []*University
+	for _, uni := range universities {
+		if matchingIds[uni.Id] {
+			filtered = append(filtered, uni)
+		}
+	}
 	return filtered, nil
 }
 
@@ -1406,6 +1689,16 @@ func UpdateUniversityServices(universityID int, services []*Service) error {
 	return nil
 }
 
+// RemoveUniversityGalleryPhoto удаляет фото photoID из галереи университета.
+// Если университета нет, возвращается orm.ErrNoRows.
+func RemoveUniversityGalleryPhoto(universityId, photoID int) error {
+	university := &University{Id: universityId}
+	if err := orm.NewOrm().Read(university); err != nil {
+		return err
+	}
+	return university.RemoveGalleryPhoto(photoID)
+}
+
 func (u *University) RemoveGalleryPhoto(photoID int) error {
 	o := orm.NewOrm()
 
@@ -1425,17 +1718,11 @@ func (u *University) RemoveGalleryPhoto(photoID int) error {
 	return nil
 }
 
-func UploadFileToCloud(filePath string, file multipart.File) (string, error) {
-	awsAccessKey, _ := beego.AppConfig.String("aws_access_key")
-	awsSecretKey, _ := beego.AppConfig.String("aws_secret_key")
-	bucket, _ := beego.AppConfig.String("bucket")
+func UploadFileToCloud(ctx context.Context, filePath string, file multipart.File) (string, error) {
+	storage := config.Get().Storage
+	bucket := storage.Bucket
 
-	sess, err := session.NewSession(&aws.Config{
-		Region:           aws.String("us-east-1"),
-		Credentials:      credentials.NewStaticCredentials(awsAccessKey, awsSecretKey, ""),
-		Endpoint:         aws.String("https://chi-sextans.object.pscloud.io"),
-		S3ForcePathStyle: aws.Bool(true),
-	})
+	sess, err := newStorageSession(storage)
 	if err != nil {
 		return "", fmt.Errorf("failed to create AWS session: %v", err)
 	}
@@ -1461,18 +1748,22 @@ func UploadFileToCloud(filePath string, file multipart.File) (string, error) {
 		contentType = http.DetectContentType(buf.Bytes())
 	}
 
-	_, err = uploader.PutObject(&s3.PutObjectInput{
+	started := time.Now()
+	spanCtx, span := startStorageSpan(ctx, "PutObject", bucket, filePath)
+	_, err = uploader.PutObjectWithContext(spanCtx, &s3.PutObjectInput{
 		Bucket:      aws.String(bucket),
 		Key:         aws.String(filePath),
 		Body:        bytes.NewReader(buf.Bytes()),
 		ACL:         aws.String("public-read"),
 		ContentType: aws.String(contentType), // Устанавливаем MIME-тип
 	})
+	tracing.End(span, err)
+	metrics.ObserveStorage("upload", started, err)
 	if err != nil {
 		return "", fmt.Errorf("failed to upload file: %v", err)
 	}
 
-	fileURL := fmt.Sprintf("https://chi-sextans.object.pscloud.io/%s/%s", bucket, filePath)
+	fileURL := fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(storage.Endpoint, "/"), bucket, filePath)
 	return fileURL, nil
 }
 
@@ -1487,15 +1778,7 @@ func GetUniversityNames(lang string) ([]GetUniNamesResponse, error) {
 	}
 
 	for _, uni := range universities {
-		var name string
-		switch lang {
-		case "ru":
-			name = uni.AbbreviationRu
-		case "kz":
-			name = uni.AbbreviationKz
-		default:
-			name = uni.Name
-		}
+		name := translate(&uni, "abbreviation", lang)
 
 		response = append(response, GetUniNamesResponse{
 			Id:           uni.Id,
@@ -1505,3 +1788,19 @@ func GetUniversityNames(lang string) ([]GetUniNamesResponse, error) {
 
 	return response, nil
 }
+
+// universityNamesByIds загружает локализованные названия университетов одним запросом.
+func universityNamesByIds(o orm.Ormer, ids []int, language string) (map[int]string, error) {
+	names := make(map[int]string, len(ids))
+	if len(ids) == 0 {
+		return names, nil
+	}
+	var universities []*University
+	if _, err := o.QueryTable("university").Filter("id__in", ids).All(&universities, "Id", "NameRu", "NameKz"); err != nil {
+		return nil, err
+	}
+	for _, uni := range universities {
+		names[uni.Id] = selectLanguage(uni, language).Name
+	}
+	return names, nil
+}
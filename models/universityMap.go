@@ -0,0 +1,227 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/astaxie/beego/orm"
+)
+
+// MapClusterMaxZoom — до этого уровня масштаба (не включительно) точки группируются в кластеры.
+const MapClusterMaxZoom = 11
+
+// mapClusterCellsPerTile — на сколько ячеек по каждой оси делится тайл при кластеризации.
+const mapClusterCellsPerTile = 4
+
+type GeoJSONGeometry struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+type GeoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   GeoJSONGeometry        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type GeoJSONFeatureCollection struct {
+	Type     string            `json:"type"`
+	Features []*GeoJSONFeature `json:"features"`
+}
+
+// BoundingBox — прямоугольник карты в градусах (долгота, широта).
+type BoundingBox struct {
+	MinLng float64
+	MinLat float64
+	MaxLng float64
+	MaxLat float64
+}
+
+// ParseBoundingBox разбирает строку вида "minLng,minLat,maxLng,maxLat".
+func ParseBoundingBox(value string) (*BoundingBox, error) {
+	var box BoundingBox
+	if _, err := fmt.Sscanf(value, "%g,%g,%g,%g", &box.MinLng, &box.MinLat, &box.MaxLng, &box.MaxLat); err != nil {
+		return nil, fmt.Errorf("invalid bbox %q: expected minLng,minLat,maxLng,maxLat", value)
+	}
+	if box.MinLat > box.MaxLat || box.MinLat < -90 || box.MaxLat > 90 {
+		return nil, fmt.Errorf("invalid bbox %q: latitude out of range", value)
+	}
+	return &box, nil
+}
+
+// Contains учитывает bbox, пересекающий антимеридиан (MinLng > MaxLng).
+func (b *BoundingBox) Contains(p GeoPoint) bool {
+	if p.Latitude < b.MinLat || p.Latitude > b.MaxLat {
+		return false
+	}
+	if b.MinLng <= b.MaxLng {
+		return p.Longitude >= b.MinLng && p.Longitude <= b.MaxLng
+	}
+	return p.Longitude >= b.MinLng || p.Longitude <= b.MaxLng
+}
+
+type mapPoint struct {
+	point      GeoPoint
+	university *University
+	campus     *Campus
+}
+
+// GetUniversitiesMap возвращает университеты и их корпуса в виде GeoJSON FeatureCollection.
+// Фильтры те же, что у SearchUniversities. При zoom < MapClusterMaxZoom близкие точки
+// объединяются в кластеры по сетке; zoom < 0 отключает кластеризацию.
+func GetUniversitiesMap(ctx context.Context, params map[string]interface{}, language string, userId int, bbox *BoundingBox, zoom int) (*GeoJSONFeatureCollection, error) {
+	universities, err := filterUniversitiesByParams(ctx, params, language)
+	if err != nil {
+		return nil, err
+	}
+
+	o, err := newOrmWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	campusesByUniversity, err := loadCampusesByUniversity(o)
+	if err != nil {
+		o.Rollback()
+		return nil, err
+	}
+	favorites, err := favoriteUniversityIds(o, userId)
+	o.Rollback()
+	if err != nil {
+		return nil, err
+	}
+
+	var points []*mapPoint
+	for _, uni := range universities {
+		if main := (GeoPoint{Latitude: uni.Latitude, Longitude: uni.Longitude}); !main.IsZero() {
+			points = append(points, &mapPoint{point: main, university: uni})
+		}
+		for _, campus := range campusesByUniversity[uni.Id] {
+			if p := (GeoPoint{Latitude: campus.Latitude, Longitude: campus.Longitude}); !p.IsZero() {
+				points = append(points, &mapPoint{point: p, university: uni, campus: campus})
+			}
+		}
+	}
+
+	if bbox != nil {
+		visible := points[:0]
+		for _, p := range points {
+			if bbox.Contains(p.point) {
+				visible = append(visible, p)
+			}
+		}
+		points = visible
+	}
+
+	collection := &GeoJSONFeatureCollection{Type: "FeatureCollection", Features: []*GeoJSONFeature{}}
+	if zoom >= 0 && zoom < MapClusterMaxZoom {
+		for _, group := range clusterMapPoints(points, zoom) {
+			if len(group) == 1 {
+				collection.Features = append(collection.Features, mapPointFeature(group[0], language, favorites))
+			} else {
+				collection.Features = append(collection.Features, mapClusterFeature(group))
+			}
+		}
+		return collection, nil
+	}
+
+	for _, p := range points {
+		collection.Features = append(collection.Features, mapPointFeature(p, language, favorites))
+	}
+	return collection, nil
+}
+
+// clusterMapPoints группирует точки по ячейкам сетки, размер которой зависит от масштаба.
+func clusterMapPoints(points []*mapPoint, zoom int) [][]*mapPoint {
+	cellSize := 360 / (math.Pow(2, float64(zoom)) * mapClusterCellsPerTile)
+
+	type cell struct{ x, y int }
+	groups := make(map[cell][]*mapPoint)
+	var order []cell
+	for _, p := range points {
+		key := cell{int(math.Floor(p.point.Longitude / cellSize)), int(math.Floor(p.point.Latitude / cellSize))}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], p)
+	}
+
+	result := make([][]*mapPoint, 0, len(order))
+	for _, key := range order {
+		result = append(result, groups[key])
+	}
+	return result
+}
+
+func mapPointFeature(p *mapPoint, language string, favorites map[int]bool) *GeoJSONFeature {
+	localized := selectLanguage(p.university, language)
+	properties := map[string]interface{}{
+		"kind":          "university",
+		"university_id": p.university.Id,
+		"name":          localized.Name,
+		"status":        localized.Status,
+		"logo":          p.university.MainImageUrl,
+		"min_score":     p.university.MinEntryScore,
+		"favorite":      favorites[p.university.Id],
+	}
+	if p.campus != nil {
+		campusName := translate(p.campus, "name", language)
+		properties["kind"] = "campus"
+		properties["campus_id"] = p.campus.Id
+		properties["campus_name"] = campusName
+	}
+
+	return &GeoJSONFeature{
+		Type:       "Feature",
+		Geometry:   GeoJSONGeometry{Type: "Point", Coordinates: [2]float64{p.point.Longitude, p.point.Latitude}},
+		Properties: properties,
+	}
+}
+
+func mapClusterFeature(group []*mapPoint) *GeoJSONFeature {
+	var sumLat, sumLng float64
+	minScore := 0
+	seen := make(map[int]bool)
+	var universityIds []int
+	for _, p := range group {
+		sumLat += p.point.Latitude
+		sumLng += p.point.Longitude
+		if score := p.university.MinEntryScore; score > 0 && (minScore == 0 || score < minScore) {
+			minScore = score
+		}
+		if !seen[p.university.Id] {
+			seen[p.university.Id] = true
+			universityIds = append(universityIds, p.university.Id)
+		}
+	}
+	sort.Ints(universityIds)
+	count := float64(len(group))
+
+	return &GeoJSONFeature{
+		Type:     "Feature",
+		Geometry: GeoJSONGeometry{Type: "Point", Coordinates: [2]float64{sumLng / count, sumLat / count}},
+		Properties: map[string]interface{}{
+			"kind":           "cluster",
+			"cluster":        true,
+			"point_count":    len(group),
+			"university_ids": universityIds,
+			"min_score":      minScore,
+		},
+	}
+}
+
+func favoriteUniversityIds(o orm.Ormer, userId int) (map[int]bool, error) {
+	ids := make(map[int]bool)
+	if userId == 0 {
+		return ids, nil
+	}
+	var favorites []*FavoriteUniversity
+	if _, err := o.QueryTable("favorite_university").Filter("user_id", userId).All(&favorites); err != nil {
+		return nil, err
+	}
+	for _, favorite := range favorites {
+		ids[favorite.University.Id] = true
+	}
+	return ids, nil
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseBoundingBox(t *testing.T) {
+	tests := []struct {
+		value string
+		want  *BoundingBox
+	}{
+		{"46.5,40.5,87.3,55.4", &BoundingBox{MinLng: 46.5, MinLat: 40.5, MaxLng: 87.3, MaxLat: 55.4}},
+		{"-10,-5.5,10,5.5", &BoundingBox{MinLng: -10, MinLat: -5.5, MaxLng: 10, MaxLat: 5.5}},
+		// Через антимеридиан: MinLng > MaxLng допустим.
+		{"170,-20,-170,20", &BoundingBox{MinLng: 170, MinLat: -20, MaxLng: -170, MaxLat: 20}},
+		{"", nil},
+		{"46.5,40.5,87.3", nil},
+		{"a,b,c,d", nil},
+		{"46.5,55.4,87.3,40.5", nil},
+		{"0,-91,10,10", nil},
+		{"0,0,10,91", nil},
+	}
+	for _, tt := range tests {
+		got, err := ParseBoundingBox(tt.value)
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("ParseBoundingBox(%q) = %+v, want error", tt.value, got)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseBoundingBox(%q) = %+v, %v; want %+v", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestBoundingBoxContains(t *testing.T) {
+	kazakhstan := &BoundingBox{MinLng: 46.5, MinLat: 40.5, MaxLng: 87.3, MaxLat: 55.4}
+	pacific := &BoundingBox{MinLng: 170, MinLat: -20, MaxLng: -170, MaxLat: 20}
+	tests := []struct {
+		name string
+		box  *BoundingBox
+		p    GeoPoint
+		want bool
+	}{
+		{"inside", kazakhstan, GeoPoint{Latitude: 43.24, Longitude: 76.89}, true},
+		{"on the edge", kazakhstan, GeoPoint{Latitude: 40.5, Longitude: 46.5}, true},
+		{"west", kazakhstan, GeoPoint{Latitude: 43.24, Longitude: 37.6}, false},
+		{"north", kazakhstan, GeoPoint{Latitude: 55.8, Longitude: 76.89}, false},
+		{"antimeridian east side", pacific, GeoPoint{Latitude: 0, Longitude: 175}, true},
+		{"antimeridian west side", pacific, GeoPoint{Latitude: 0, Longitude: -175}, true},
+		{"antimeridian outside", pacific, GeoPoint{Latitude: 0, Longitude: 0}, false},
+		{"antimeridian latitude", pacific, GeoPoint{Latitude: 30, Longitude: 175}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.box.Contains(tt.p); got != tt.want {
+			t.Errorf("%s: Contains(%+v) = %v, want %v", tt.name, tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestClusterMapPoints(t *testing.T) {
+	point := func(id int, lat, lng float64) *mapPoint {
+		return &mapPoint{point: GeoPoint{Latitude: lat, Longitude: lng}, university: &University{Id: id}}
+	}
+	points := []*mapPoint{
+		point(1, 43.24, 76.89), // Алматы
+		point(2, 51.13, 71.43), // Астана
+		point(3, 43.26, 76.95), // Алматы, в нескольких километрах от первого
+		point(4, 42.30, 69.60), // Шымкент
+	}
+	tests := []struct {
+		zoom int
+		want [][]int
+	}{
+		{0, [][]int{{1, 2, 3, 4}}},
+		{4, [][]int{{1, 3}, {2}, {4}}},
+		{10, [][]int{{1}, {2}, {3}, {4}}},
+	}
+	for _, tt := range tests {
+		var got [][]int
+		for _, group := range clusterMapPoints(points, tt.zoom) {
+			var ids []int
+			for _, p := range group {
+				ids = append(ids, p.university.Id)
+			}
+			got = append(got, ids)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("zoom %d: clusterMapPoints = %v, want %v", tt.zoom, got, tt.want)
+		}
+	}
+}
+
+func TestClusterMapPointsSplitsAtZero(t *testing.T) {
+	points := []*mapPoint{
+		{point: GeoPoint{Latitude: 1, Longitude: -0.1}, university: &University{Id: 1}},
+		{point: GeoPoint{Latitude: 1, Longitude: 0.1}, university: &University{Id: 2}},
+	}
+	if groups := clusterMapPoints(points, 0); len(groups) != 2 {
+		t.Errorf("points on both sides of the prime meridian fell into %d cluster(s), want 2", len(groups))
+	}
+}
+
+func TestMapClusterFeature(t *testing.T) {
+	group := []*mapPoint{
+		{point: GeoPoint{Latitude: 43, Longitude: 76}, university: &University{Id: 5, MinEntryScore: 0}},
+		{point: GeoPoint{Latitude: 44, Longitude: 77}, university: &University{Id: 2, MinEntryScore: 90}},
+		{point: GeoPoint{Latitude: 45, Longitude: 78}, university: &University{Id: 5, MinEntryScore: 0}, campus: &Campus{Id: 1}},
+		{point: GeoPoint{Latitude: 44, Longitude: 77}, university: &University{Id: 3, MinEntryScore: 70}},
+	}
+	feature := mapClusterFeature(group)
+	if feature.Geometry.Coordinates != [2]float64{77, 44} {
+		t.Errorf("cluster center = %v, want [77 44]", feature.Geometry.Coordinates)
+	}
+	if got := feature.Properties["point_count"]; got != 4 {
+		t.Errorf("point_count = %v, want 4", got)
+	}
+	if got := feature.Properties["university_ids"]; !reflect.DeepEqual(got, []int{2, 3, 5}) {
+		t.Errorf("university_ids = %v, want [2 3 5]", got)
+	}
+	// Университеты без проходного балла не обнуляют минимум кластера.
+	if got := feature.Properties["min_score"]; got != 70 {
+		t.Errorf("min_score = %v, want 70", got)
+	}
+}
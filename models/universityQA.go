@@ -0,0 +1,409 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/astaxie/beego/orm"
+)
+
+const (
+	QAStatusPublished = "published"
+	QAStatusPending   = "pending"
+	QAStatusHidden    = "hidden"
+
+	AnswerAuthorRepresentative = "representative"
+	AnswerAuthorAdmin          = "admin"
+)
+
+// UniversityQuestion — вопрос студента на странице университета. Вопросы без
+// стоп-слов публикуются сразу, остальные ждут решения модератора.
+type UniversityQuestion struct {
+	Id         int                 `orm:"auto"`
+	University *University         `orm:"rel(fk);on_delete(cascade)"`
+	UserId     int                 `orm:"column(user_id)"`
+	Text       string              `orm:"type(text)"`
+	Status     string              `orm:"size(16);default(published)"`
+	VoteCount  int                 `orm:"default(0)"`
+	Answers    []*UniversityAnswer `orm:"reverse(many);on_delete(cascade)"`
+	CreatedAt  time.Time           `orm:"auto_now_add;type(datetime)"`
+	UpdatedAt  time.Time           `orm:"auto_now;type(datetime)"`
+}
+
+// UniversityAnswer — ответ представителя университета или администратора.
+type UniversityAnswer struct {
+	Id         int                 `orm:"auto"`
+	Question   *UniversityQuestion `orm:"rel(fk);on_delete(cascade)"`
+	UserId     int                 `orm:"column(user_id)"`
+	AuthorRole string              `orm:"size(16)"`
+	Text       string              `orm:"type(text)"`
+	Status     string              `orm:"size(16);default(published)"`
+	VoteCount  int                 `orm:"default(0)"`
+	CreatedAt  time.Time           `orm:"auto_now_add;type(datetime)"`
+	UpdatedAt  time.Time           `orm:"auto_now;type(datetime)"`
+}
+
+// UniversityRepresentative — пользователь, которому администратор разрешил отвечать
+// на вопросы от имени университета.
+type UniversityRepresentative struct {
+	Id         int         `orm:"auto"`
+	University *University `orm:"rel(fk);on_delete(cascade)"`
+	UserId     int         `orm:"column(user_id)"`
+	Position   string      `orm:"size(128)"`
+	CreatedAt  time.Time   `orm:"auto_now_add;type(datetime)"`
+}
+
+// QaVote — голос пользователя за вопрос или ответ. Заполняется ровно одна из ссылок.
+type QaVote struct {
+	Id       int                 `orm:"auto"`
+	UserId   int                 `orm:"column(user_id)"`
+	Question *UniversityQuestion `orm:"rel(fk);null;on_delete(cascade)"`
+	Answer   *UniversityAnswer   `orm:"rel(fk);null;on_delete(cascade)"`
+}
+
+type AddUniversityQuestionResponse struct {
+	UniversityId int    `form:"UniversityId" validate:"required"`
+	Text         string `form:"Text" validate:"required,min=10,max=2000"`
+}
+
+type AddUniversityAnswerResponse struct {
+	Text string `form:"Text" validate:"required,min=2,max=5000"`
+}
+
+type AddUniversityRepresentativeResponse struct {
+	UniversityId int    `form:"UniversityId" validate:"required"`
+	UserId       int    `form:"UserId" validate:"required"`
+	Position     string `form:"Position"`
+}
+
+type UniversityAnswerResponse struct {
+	Id         int       `json:"Id"`
+	QuestionId int       `json:"QuestionId"`
+	AuthorRole string    `json:"AuthorRole"`
+	Text       string    `json:"Text"`
+	Status     string    `json:"Status,omitempty"`
+	VoteCount  int       `json:"VoteCount"`
+	CreatedAt  time.Time `json:"CreatedAt"`
+}
+
+type UniversityQuestionResponse struct {
+	Id           int                         `json:"Id"`
+	UniversityId int                         `json:"UniversityId"`
+	Text         string                      `json:"Text"`
+	Status       string                      `json:"Status,omitempty"`
+	VoteCount    int                         `json:"VoteCount"`
+	Answers      []*UniversityAnswerResponse `json:"Answers"`
+	CreatedAt    time.Time                   `json:"CreatedAt"`
+}
+
+// QAModerationQueue — вопросы и ответы, ожидающие модерации.
+type QAModerationQueue struct {
+	Questions []*UniversityQuestionResponse `json:"Questions"`
+	Answers   []*UniversityAnswerResponse   `json:"Answers"`
+}
+
+func init() {
+	orm.RegisterModel(new(UniversityQuestion), new(UniversityAnswer), new(UniversityRepresentative), new(QaVote))
+}
+
+func (q *UniversityQuestion) toResponse() *UniversityQuestionResponse {
+	return &UniversityQuestionResponse{
+		Id:           q.Id,
+		UniversityId: q.University.Id,
+		Text:         q.Text,
+		VoteCount:    q.VoteCount,
+		Answers:      []*UniversityAnswerResponse{},
+		CreatedAt:    q.CreatedAt,
+	}
+}
+
+func (a *UniversityAnswer) toResponse() *UniversityAnswerResponse {
+	return &UniversityAnswerResponse{
+		Id:         a.Id,
+		QuestionId: a.Question.Id,
+		AuthorRole: a.AuthorRole,
+		Text:       a.Text,
+		VoteCount:  a.VoteCount,
+		CreatedAt:  a.CreatedAt,
+	}
+}
+
+func moderationStatus(o orm.Ormer, text string) (string, error) {
+	flagged, err := containsStopWord(o, text)
+	if err != nil {
+		return "", err
+	}
+	if flagged {
+		return QAStatusPending, nil
+	}
+	return QAStatusPublished, nil
+}
+
+func AddUniversityQuestion(userId int, form *AddUniversityQuestionResponse) (int64, error) {
+	o := orm.NewOrm()
+	if err := o.Read(&University{Id: form.UniversityId}); err != nil {
+		return 0, fmt.Errorf("university %d not found", form.UniversityId)
+	}
+
+	text := strings.TrimSpace(form.Text)
+	status, err := moderationStatus(o, text)
+	if err != nil {
+		return 0, err
+	}
+
+	return o.Insert(&UniversityQuestion{
+		University: &University{Id: form.UniversityId},
+		UserId:     userId,
+		Text:       text,
+		Status:     status,
+	})
+}
+
+// IsUniversityRepresentative проверяет, может ли пользователь отвечать от имени университета.
+func IsUniversityRepresentative(userId, universityId int) bool {
+	return orm.NewOrm().QueryTable("university_representative").
+		Filter("user_id", userId).
+		Filter("University__Id", universityId).
+		Exist()
+}
+
+// AddUniversityAnswer добавляет ответ на вопрос. Представитель может отвечать только
+// на вопросы своего университета; ответы администратора публикуются без проверки.
+func AddUniversityAnswer(questionId, userId int, role string, form *AddUniversityAnswerResponse) (int64, error) {
+	o := orm.NewOrm()
+	question := &UniversityQuestion{Id: questionId}
+	if err := o.Read(question); err != nil {
+		return 0, fmt.Errorf("question %d not found", questionId)
+	}
+
+	text := strings.TrimSpace(form.Text)
+	status := QAStatusPublished
+	if role == AnswerAuthorRepresentative {
+		if !IsUniversityRepresentative(userId, question.University.Id) {
+			return 0, fmt.Errorf("user is not a representative of university %d", question.University.Id)
+		}
+		var err error
+		if status, err = moderationStatus(o, text); err != nil {
+			return 0, err
+		}
+	}
+
+	return o.Insert(&UniversityAnswer{
+		Question:   question,
+		UserId:     userId,
+		AuthorRole: role,
+		Text:       text,
+		Status:     status,
+	})
+}
+
+// GetUniversityQuestions возвращает опубликованные вопросы с опубликованными ответами.
+// Вопросы и ответы упорядочены по числу голосов, затем по дате.
+func GetUniversityQuestions(universityId int) ([]*UniversityQuestionResponse, error) {
+	o := orm.NewOrm()
+	var questions []*UniversityQuestion
+	if _, err := o.QueryTable("university_question").
+		Filter("University__Id", universityId).
+		Filter("Status", QAStatusPublished).
+		OrderBy("-VoteCount", "-CreatedAt").
+		All(&questions); err != nil {
+		return nil, err
+	}
+	if len(questions) == 0 {
+		return []*UniversityQuestionResponse{}, nil
+	}
+
+	questionIds := make([]int, 0, len(questions))
+	responses := make([]*UniversityQuestionResponse, 0, len(questions))
+	byId := make(map[int]*UniversityQuestionResponse, len(questions))
+	for _, question := range questions {
+		response := question.toResponse()
+		questionIds = append(questionIds, question.Id)
+		responses = append(responses, response)
+		byId[question.Id] = response
+	}
+
+	var answers []*UniversityAnswer
+	if _, err := o.QueryTable("university_answer").
+		Filter("Question__Id__in", questionIds).
+		Filter("Status", QAStatusPublished).
+		OrderBy("-VoteCount", "CreatedAt").
+		All(&answers); err != nil {
+		return nil, err
+	}
+	for _, answer := range answers {
+		if question, ok := byId[answer.Question.Id]; ok {
+			question.Answers = append(question.Answers, answer.toResponse())
+		}
+	}
+
+	return responses, nil
+}
+
+// ToggleQuestionVote ставит или снимает голос пользователя за вопрос и возвращает новое число голосов.
+// Голосовать можно только за опубликованные вопросы.
+func ToggleQuestionVote(questionId, userId int) (int, error) {
+	o := orm.NewOrm()
+	question := &UniversityQuestion{}
+	if err := o.QueryTable("university_question").
+		Filter("Id", questionId).
+		Filter("Status", QAStatusPublished).
+		One(question); err == orm.ErrNoRows {
+		return 0, fmt.Errorf("question %d not found", questionId)
+	} else if err != nil {
+		return 0, err
+	}
+	return toggleVote(o, "Question__Id", questionId, userId, &QaVote{UserId: userId, Question: question}, "university_question")
+}
+
+// ToggleAnswerVote ставит или снимает голос пользователя за ответ и возвращает новое число голосов.
+// Ответ и его вопрос должны быть опубликованы.
+func ToggleAnswerVote(answerId, userId int) (int, error) {
+	o := orm.NewOrm()
+	answer := &UniversityAnswer{}
+	if err := o.QueryTable("university_answer").
+		Filter("Id", answerId).
+		Filter("Status", QAStatusPublished).
+		Filter("Question__Status", QAStatusPublished).
+		One(answer); err == orm.ErrNoRows {
+		return 0, fmt.Errorf("answer %d not found", answerId)
+	} else if err != nil {
+		return 0, err
+	}
+	return toggleVote(o, "Answer__Id", answerId, userId, &QaVote{UserId: userId, Answer: answer}, "university_answer")
+}
+
+func toggleVote(o orm.Ormer, field string, targetId, userId int, vote *QaVote, table string) (int, error) {
+	if err := o.Begin(); err != nil {
+		return 0, err
+	}
+
+	existing := o.QueryTable("qa_vote").Filter(field, targetId).Filter("user_id", userId)
+	delta := 1
+	if existing.Exist() {
+		if _, err := existing.Delete(); err != nil {
+			o.Rollback()
+			return 0, err
+		}
+		delta = -1
+	} else if _, err := o.Insert(vote); err != nil {
+		o.Rollback()
+		return 0, err
+	}
+
+	if _, err := o.QueryTable(table).Filter("id", targetId).Update(orm.Params{
+		"vote_count": orm.ColValue(orm.ColAdd, delta),
+	}); err != nil {
+		o.Rollback()
+		return 0, err
+	}
+
+	var count int
+	if err := o.Raw("SELECT vote_count FROM "+table+" WHERE id = ?", targetId).QueryRow(&count); err != nil {
+		o.Rollback()
+		return 0, err
+	}
+	return count, o.Commit()
+}
+
+// GetQAModerationQueue возвращает вопросы и ответы в статусе pending.
+func GetQAModerationQueue() (*QAModerationQueue, error) {
+	o := orm.NewOrm()
+	var questions []*UniversityQuestion
+	if _, err := o.QueryTable("university_question").Filter("Status", QAStatusPending).OrderBy("CreatedAt").All(&questions); err != nil {
+		return nil, err
+	}
+	var answers []*UniversityAnswer
+	if _, err := o.QueryTable("university_answer").Filter("Status", QAStatusPending).OrderBy("CreatedAt").All(&answers); err != nil {
+		return nil, err
+	}
+
+	queue := &QAModerationQueue{
+		Questions: make([]*UniversityQuestionResponse, 0, len(questions)),
+		Answers:   make([]*UniversityAnswerResponse, 0, len(answers)),
+	}
+	for _, question := range questions {
+		response := question.toResponse()
+		response.Status = question.Status
+		queue.Questions = append(queue.Questions, response)
+	}
+	for _, answer := range answers {
+		response := answer.toResponse()
+		response.Status = answer.Status
+		queue.Answers = append(queue.Answers, response)
+	}
+	return queue, nil
+}
+
+func validQAStatus(status string) bool {
+	return status == QAStatusPublished || status == QAStatusHidden || status == QAStatusPending
+}
+
+func SetQuestionStatus(id int, status string) error {
+	if !validQAStatus(status) {
+		return fmt.Errorf("invalid status: %s", status)
+	}
+	o := orm.NewOrm()
+	question := &UniversityQuestion{Id: id}
+	if err := o.Read(question); err != nil {
+		return err
+	}
+	question.Status = status
+	_, err := o.Update(question, "Status", "UpdatedAt")
+	return err
+}
+
+func SetAnswerStatus(id int, status string) error {
+	if !validQAStatus(status) {
+		return fmt.Errorf("invalid status: %s", status)
+	}
+	o := orm.NewOrm()
+	answer := &UniversityAnswer{Id: id}
+	if err := o.Read(answer); err != nil {
+		return err
+	}
+	answer.Status = status
+	_, err := o.Update(answer, "Status", "UpdatedAt")
+	return err
+}
+
+func DeleteUniversityQuestion(id int) error {
+	o := orm.NewOrm()
+	_, err := o.Delete(&UniversityQuestion{Id: id})
+	return err
+}
+
+func DeleteUniversityAnswer(id int) error {
+	o := orm.NewOrm()
+	_, err := o.Delete(&UniversityAnswer{Id: id})
+	return err
+}
+
+func AddUniversityRepresentative(form *AddUniversityRepresentativeResponse) (int64, error) {
+	o := orm.NewOrm()
+	if err := o.Read(&University{Id: form.UniversityId}); err != nil {
+		return 0, fmt.Errorf("university %d not found", form.UniversityId)
+	}
+	if IsUniversityRepresentative(form.UserId, form.UniversityId) {
+		return 0, fmt.Errorf("user %d is already a representative of university %d", form.UserId, form.UniversityId)
+	}
+	return o.Insert(&UniversityRepresentative{
+		University: &University{Id: form.UniversityId},
+		UserId:     form.UserId,
+		Position:   form.Position,
+	})
+}
+
+func GetUniversityRepresentatives(universityId int) ([]*UniversityRepresentative, error) {
+	o := orm.NewOrm()
+	var representatives []*UniversityRepresentative
+	_, err := o.QueryTable("university_representative").Filter("University__Id", universityId).OrderBy("Id").All(&representatives)
+	return representatives, err
+}
+
+func DeleteUniversityRepresentative(id int) error {
+	o := orm.NewOrm()
+	_, err := o.Delete(&UniversityRepresentative{Id: id})
+	return err
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+// За скрытые и ожидающие модерации вопросы и ответы голосовать нельзя.
+func TestToggleVoteRequiresPublishedTarget(t *testing.T) {
+	testDB.reset()
+	if _, err := ToggleQuestionVote(5, 1); err == nil {
+		t.Fatal("vote for an unpublished question was accepted")
+	}
+	if _, err := ToggleAnswerVote(7, 1); err == nil {
+		t.Fatal("vote for an unpublished answer was accepted")
+	}
+
+	var questionChecked, answerChecked bool
+	for _, query := range testDB.queries {
+		if strings.Contains(query, "INSERT") || strings.Contains(query, "UPDATE") {
+			t.Fatalf("vote was written: %s", query)
+		}
+		questionChecked = questionChecked || strings.Contains(query, `FROM "university_question"`) && strings.Contains(query, `"status" = $`)
+		answerChecked = answerChecked || strings.Contains(query, `FROM "university_answer"`) && strings.Count(query, `"status" = $`) == 2
+	}
+	if !questionChecked || !answerChecked {
+		t.Fatalf("status is not checked:\n%s", strings.Join(testDB.queries, "\n"))
+	}
+}
+
+func TestToggleVotePublishedQuestion(t *testing.T) {
+	testDB.reset(
+		queryHandler{match: `FROM "university_question"`, rows: []map[string]driver.Value{{"id": int64(5), "status": QAStatusPublished, "vote_count": int64(2)}}},
+		queryHandler{match: `INSERT INTO "qa_vote"`, columns: []string{"id"}, rows: []map[string]driver.Value{{"id": int64(1)}}},
+		queryHandler{match: `SELECT vote_count`, columns: []string{"vote_count"}, rows: []map[string]driver.Value{{"vote_count": int64(3)}}},
+	)
+	count, err := ToggleQuestionVote(5, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Fatalf("votes = %d, want 3", count)
+	}
+}
@@ -0,0 +1,149 @@
+package models
+
+import (
+	"fmt"
+
+	"github.com/astaxie/beego/orm"
+)
+
+// Структура ЕНТ: три обязательных блока и два профильных предмета из SubjectPair.
+// Максимальный суммарный балл — 140.
+const (
+	UntHistoryMaxScore        = 20
+	UntHistoryThreshold       = 5
+	UntReadingMaxScore        = 10
+	UntReadingThreshold       = 3
+	UntMathMaxScore           = 10
+	UntMathThreshold          = 3
+	UntProfileMaxScore        = 50
+	UntProfileThreshold       = 5
+	UntMaxScore               = UntHistoryMaxScore + UntReadingMaxScore + UntMathMaxScore + 2*UntProfileMaxScore
+	UntBlockHistory           = "history_of_kazakhstan"
+	UntBlockReadingLiteracy   = "reading_literacy"
+	UntBlockMathLiteracy      = "mathematical_literacy"
+	UntBlockProfileSubjectOne = "profile_subject_1"
+	UntBlockProfileSubjectTwo = "profile_subject_2"
+)
+
+// UntScore — баллы студента по блокам ЕНТ.
+type UntScore struct {
+	History       int `json:"history"`
+	Reading       int `json:"reading"`
+	Math          int `json:"math"`
+	SubjectPairId int `json:"subject_pair_id"`
+	Profile1      int `json:"profile1"`
+	Profile2      int `json:"profile2"`
+}
+
+// UntBlockResult — балл по одному блоку и его пороговое значение.
+type UntBlockResult struct {
+	Block     string `json:"block"`
+	SubjectId int    `json:"subject_id,omitempty"`
+	Score     int    `json:"score"`
+	MaxScore  int    `json:"max_score"`
+	Threshold int    `json:"threshold"`
+	Passed    bool   `json:"passed"`
+}
+
+// UntEvaluation — итог проверки баллов ЕНТ.
+type UntEvaluation struct {
+	Total            int               `json:"total"`
+	MaxScore         int               `json:"max_score"`
+	PassedThresholds bool              `json:"passed_thresholds"`
+	Blocks           []*UntBlockResult `json:"blocks"`
+}
+
+// Limits возвращает максимальный балл и порог предмета, если предмет сдаётся как профильный.
+// Нулевой максимальный балл означает стандартные 50; нулевой порог — допустимое значение.
+func (s *Subject) Limits() (maxScore, threshold int) {
+	maxScore, threshold = s.MaxScore, s.Threshold
+	if maxScore == 0 {
+		maxScore = UntProfileMaxScore
+	}
+	return maxScore, threshold
+}
+
+// ValidateTotalScore проверяет, что суммарный балл ЕНТ лежит в диапазоне 0–140.
+func ValidateTotalScore(field string, score int) error {
+	if score < 0 || score > UntMaxScore {
+		return validationErrorf("%s must be between 0 and %d, got %d", field, UntMaxScore, score)
+	}
+	return nil
+}
+
+// ValidatePointStatScores проверяет проходные баллы статистики: оба в диапазоне 0–140,
+// а балл на грант не ниже балла на платное обучение.
+func ValidatePointStatScores(minScore, minGrantScore int) error {
+	if err := ValidateTotalScore("MinScore", minScore); err != nil {
+		return err
+	}
+	if err := ValidateTotalScore("MinGrantScore", minGrantScore); err != nil {
+		return err
+	}
+	if minScore != 0 && minGrantScore != 0 && minGrantScore < minScore {
+		return validationErrorf("MinGrantScore (%d) must not be less than MinScore (%d)", minGrantScore, minScore)
+	}
+	return nil
+}
+
+// ValidateScoreRange проверяет пару минимального и максимального балла (квоты, распределения квот).
+func ValidateScoreRange(minScore, maxScore int) error {
+	if err := ValidateTotalScore("MinScore", minScore); err != nil {
+		return err
+	}
+	if err := ValidateTotalScore("MaxScore", maxScore); err != nil {
+		return err
+	}
+	if maxScore != 0 && maxScore < minScore {
+		return validationErrorf("MaxScore (%d) must not be less than MinScore (%d)", maxScore, minScore)
+	}
+	return nil
+}
+
+// EvaluateUntScore проверяет баллы по блокам и считает итог. Ошибка возвращается,
+// если балл блока выходит за допустимые пределы; непройденный порог ошибкой не считается.
+func EvaluateUntScore(score *UntScore) (*UntEvaluation, error) {
+	o := orm.NewOrm()
+
+	pair := &SubjectPair{Id: score.SubjectPairId}
+	if err := o.Read(pair); err != nil {
+		return nil, fmt.Errorf("subject pair %d not found", score.SubjectPairId)
+	}
+	subject1 := &Subject{Id: pair.Subject1.Id}
+	if err := o.Read(subject1); err != nil {
+		return nil, fmt.Errorf("subject %d not found", pair.Subject1.Id)
+	}
+	subject2 := &Subject{Id: pair.Subject2.Id}
+	if err := o.Read(subject2); err != nil {
+		return nil, fmt.Errorf("subject %d not found", pair.Subject2.Id)
+	}
+
+	return evaluateUntBlocks(score, subject1, subject2)
+}
+
+func evaluateUntBlocks(score *UntScore, subject1, subject2 *Subject) (*UntEvaluation, error) {
+	max1, threshold1 := subject1.Limits()
+	max2, threshold2 := subject2.Limits()
+
+	blocks := []*UntBlockResult{
+		{Block: UntBlockHistory, Score: score.History, MaxScore: UntHistoryMaxScore, Threshold: UntHistoryThreshold},
+		{Block: UntBlockReadingLiteracy, Score: score.Reading, MaxScore: UntReadingMaxScore, Threshold: UntReadingThreshold},
+		{Block: UntBlockMathLiteracy, Score: score.Math, MaxScore: UntMathMaxScore, Threshold: UntMathThreshold},
+		{Block: UntBlockProfileSubjectOne, SubjectId: subject1.Id, Score: score.Profile1, MaxScore: max1, Threshold: threshold1},
+		{Block: UntBlockProfileSubjectTwo, SubjectId: subject2.Id, Score: score.Profile2, MaxScore: max2, Threshold: threshold2},
+	}
+
+	evaluation := &UntEvaluation{PassedThresholds: true, Blocks: blocks}
+	for _, block := range blocks {
+		if block.Score < 0 || block.Score > block.MaxScore {
+			return nil, validationErrorf("%s score must be between 0 and %d, got %d", block.Block, block.MaxScore, block.Score)
+		}
+		block.Passed = block.Score >= block.Threshold
+		if !block.Passed {
+			evaluation.PassedThresholds = false
+		}
+		evaluation.Total += block.Score
+		evaluation.MaxScore += block.MaxScore
+	}
+	return evaluation, nil
+}
@@ -0,0 +1,102 @@
+package models
+
+import "testing"
+
+func TestValidateSubjectLimits(t *testing.T) {
+	tests := []struct {
+		maxScore, threshold int
+		valid               bool
+	}{
+		{50, 5, true},
+		{50, 0, true},
+		{40, 40, true},
+		{1, 0, true},
+		{0, 0, false},
+		{51, 5, false},
+		{50, -1, false},
+		{40, 41, false},
+	}
+	for _, tt := range tests {
+		err := ValidateSubjectLimits(&Subject{MaxScore: tt.maxScore, Threshold: tt.threshold})
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidateSubjectLimits(%d, %d) = %v, want valid %v", tt.maxScore, tt.threshold, err, tt.valid)
+		}
+	}
+}
+
+func TestUpdateSubjectRequestKeepsZeroThreshold(t *testing.T) {
+	subject := &Subject{NameRu: "Химия", MaxScore: 50, Threshold: 5}
+	zero := 0
+	(&UpdateSubjectRequest{Threshold: &zero}).Apply(subject)
+	if subject.Threshold != 0 || subject.MaxScore != 50 || subject.NameRu != "Химия" {
+		t.Fatalf("unexpected subject after update: %+v", subject)
+	}
+}
+
+func TestValidatePointStatScores(t *testing.T) {
+	tests := []struct {
+		minScore, minGrantScore int
+		valid                   bool
+	}{
+		{0, 0, true},
+		{70, 0, true},
+		{70, 110, true},
+		{UntMaxScore, UntMaxScore, true},
+		{110, 70, false},
+		{-1, 0, false},
+		{0, UntMaxScore + 1, false},
+	}
+	for _, tt := range tests {
+		err := ValidatePointStatScores(tt.minScore, tt.minGrantScore)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidatePointStatScores(%d, %d) = %v, want valid %v", tt.minScore, tt.minGrantScore, err, tt.valid)
+		}
+	}
+}
+
+func TestValidateScoreRange(t *testing.T) {
+	tests := []struct {
+		minScore, maxScore int
+		valid              bool
+	}{
+		{50, 0, true},
+		{50, 120, true},
+		{120, 50, false},
+		{0, UntMaxScore + 1, false},
+	}
+	for _, tt := range tests {
+		err := ValidateScoreRange(tt.minScore, tt.maxScore)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidateScoreRange(%d, %d) = %v, want valid %v", tt.minScore, tt.maxScore, err, tt.valid)
+		}
+	}
+}
+
+func TestEvaluateUntBlocks(t *testing.T) {
+	math := &Subject{Id: 1, MaxScore: 50, Threshold: 5}
+	physics := &Subject{Id: 2, MaxScore: 50, Threshold: 0}
+
+	evaluation, err := evaluateUntBlocks(&UntScore{History: 15, Reading: 8, Math: 2, Profile1: 40, Profile2: 0}, math, physics)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if evaluation.Total != 65 || evaluation.MaxScore != UntMaxScore {
+		t.Errorf("total %d of %d, want 65 of %d", evaluation.Total, evaluation.MaxScore, UntMaxScore)
+	}
+	if evaluation.PassedThresholds {
+		t.Error("math literacy is below its threshold")
+	}
+	for _, block := range evaluation.Blocks {
+		wantPassed := block.Block != UntBlockMathLiteracy
+		if block.Passed != wantPassed {
+			t.Errorf("%s passed = %v, want %v", block.Block, block.Passed, wantPassed)
+		}
+	}
+
+	if _, err := evaluateUntBlocks(&UntScore{History: 21}, math, physics); err == nil {
+		t.Error("history score above 20 was accepted")
+	}
+	if _, err := evaluateUntBlocks(&UntScore{Profile2: -1}, math, physics); err == nil {
+		t.Error("negative profile score was accepted")
+	}
+}
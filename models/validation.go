@@ -0,0 +1,15 @@
+package models
+
+import "fmt"
+
+// ValidationError — ошибка во входных данных запроса, а не в работе базы.
+// Контроллеры отвечают на неё 400.
+type ValidationError struct {
+	Message string
+}
+
+func (e *ValidationError) Error() string { return e.Message }
+
+func validationErrorf(format string, args ...interface{}) error {
+	return &ValidationError{Message: fmt.Sprintf(format, args...)}
+}
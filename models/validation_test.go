@@ -0,0 +1,48 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/astaxie/beego/orm"
+)
+
+func TestValidationErrors(t *testing.T) {
+	for name, err := range map[string]error{
+		"subject limits": ValidateSubjectLimits(&Subject{MaxScore: 0}),
+		"total score":    ValidateTotalScore("MinScore", UntMaxScore+1),
+		"point stat":     ValidatePointStatScores(100, 90),
+		"score range":    ValidateScoreRange(100, 90),
+	} {
+		var validation *ValidationError
+		if !errors.As(err, &validation) {
+			t.Errorf("%s: %v is not a ValidationError", name, err)
+		}
+	}
+}
+
+func TestQuotaAllocationRefsAreValidationErrors(t *testing.T) {
+	form := &AddQuotaAllocationResponse{QuotaId: 1, UniversityId: 2, SpecialityId: 3, Year: 2024}
+	tests := []struct {
+		name     string
+		handlers []queryHandler
+	}{
+		{"missing quota", nil},
+		{"speciality not offered", []queryHandler{{match: `FROM "quota"`, rows: []map[string]driver.Value{{"id": int64(1)}}}}},
+	}
+	for _, tt := range tests {
+		testDB.reset(tt.handlers...)
+		var validation *ValidationError
+		if err := checkQuotaAllocationRefs(orm.NewOrm(), form); !errors.As(err, &validation) {
+			t.Errorf("%s: %v is not a ValidationError", tt.name, err)
+		}
+	}
+}
+
+func TestUpdateMissingQuotaAllocation(t *testing.T) {
+	testDB.reset()
+	if err := UpdateQuotaAllocation(7, &UpdateQuotaAllocationResponse{GrantCount: 5}); err != orm.ErrNoRows {
+		t.Fatalf("UpdateQuotaAllocation of a missing row = %v, want orm.ErrNoRows", err)
+	}
+}
@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"testhub-spec-uni/metrics"
+	"time"
+
+	beego "github.com/beego/beego/v2/server/web"
+	"github.com/beego/beego/v2/server/web/context"
+)
+
+// Group — группа маршрутов с общим лимитом, например search или favorites.
+// Methods ограничивает группу перечисленными методами; пустой список — все.
+type Group struct {
+	Name    string
+	Limit   Limit
+	Methods []string
+}
+
+func (g Group) matches(method string) bool {
+	if method == http.MethodOptions {
+		return false
+	}
+	if len(g.Methods) == 0 {
+		return true
+	}
+	for _, m := range g.Methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter ограничивает запросы группы group. Ключ — user_id, если фильтр
+// авторизации уже отработал, иначе IP клиента. В ответ добавляются заголовки
+// RateLimit-*, а сверх лимита — 429 с Retry-After. Если хранилище недоступно,
+// запрос пропускается: лучше без ограничения, чем без сервиса.
+func Filter(group Group) beego.FilterFunc {
+	return func(ctx *context.Context) {
+		store, trustProxy := current()
+		if store == nil || !group.Limit.Enabled() || !group.matches(ctx.Input.Method()) {
+			return
+		}
+
+		key := group.Name + ":" + clientKey(ctx, trustProxy)
+		res, err := store.Take(key, group.Limit, time.Now())
+		if err != nil {
+			slog.WarnContext(ctx.Request.Context(), "Rate limit store failed", "group", group.Name, "error", err)
+			return
+		}
+
+		header := ctx.ResponseWriter.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		header.Set("RateLimit-Reset", seconds(res.Reset))
+		header.Set("RateLimit-Policy", strconv.Itoa(group.Limit.Requests)+";w="+seconds(group.Limit.Period))
+		if res.Allowed {
+			return
+		}
+
+		metrics.RateLimited(group.Name)
+		retryAfter := seconds(res.RetryAfter)
+		if retryAfter == "0" {
+			retryAfter = "1"
+		}
+		header.Set("Retry-After", retryAfter)
+		ctx.Output.SetStatus(http.StatusTooManyRequests)
+		ctx.Output.JSON(map[string]string{"error": "Too many requests"}, true, true)
+	}
+}
+
+// clientKey возвращает user:<id> для авторизованного запроса и ip:<адрес> иначе.
+func clientKey(ctx *context.Context, trustProxy bool) string {
+	if userID, ok := ctx.Input.GetData("user_id").(int); ok && userID != 0 {
+		return "user:" + strconv.Itoa(userID)
+	}
+	if trustProxy {
+		return "ip:" + ctx.Input.IP()
+	}
+	host, _, err := net.SplitHostPort(ctx.Request.RemoteAddr)
+	if err != nil {
+		host = ctx.Request.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// seconds округляет d вверх до целых секунд, как принято в заголовках RateLimit-*.
+func seconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
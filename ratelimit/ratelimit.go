@@ -0,0 +1,134 @@
+// Package ratelimit ограничивает частоту запросов по алгоритму token bucket.
+//
+// У каждого ключа (пользователь или IP-адрес) в каждой группе маршрутов своё
+// ведро на Limit.Requests запросов, которое равномерно наполняется за
+// Limit.Period. Ведра хранятся в памяти процесса или, чтобы лимит был общим для
+// нескольких экземпляров сервиса, на сервере RESP (Redis).
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"testhub-spec-uni/cache"
+	"time"
+)
+
+const (
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+	BackendOff    = "off"
+)
+
+// Limit — не больше Requests запросов за Period. Нулевой Limit отключает ограничение.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+var periods = map[string]time.Duration{"s": time.Second, "m": time.Minute, "h": time.Hour}
+
+// ParseLimit разбирает лимит вида "60/m" (s, m или h). "off" и пустая строка — без ограничения.
+func ParseLimit(raw string) (Limit, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || raw == BackendOff {
+		return Limit{}, nil
+	}
+	count, unit, ok := strings.Cut(raw, "/")
+	requests, err := strconv.Atoi(count)
+	period, known := periods[unit]
+	if !ok || err != nil || requests <= 0 || !known {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, expected N/s, N/m, N/h or off", raw)
+	}
+	return Limit{Requests: requests, Period: period}, nil
+}
+
+// Enabled сообщает, задан ли лимит.
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0
+}
+
+// interval — время, за которое в ведро возвращается один запрос.
+func (l Limit) interval() time.Duration {
+	return l.Period / time.Duration(l.Requests)
+}
+
+// Result — итог попытки взять запрос из ведра.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset — через сколько ведро снова наполнится целиком.
+	Reset time.Duration
+	// RetryAfter — через сколько появится следующий запрос, если Allowed false.
+	RetryAfter time.Duration
+}
+
+// Store атомарно берёт один запрос из ведра key.
+type Store interface {
+	Take(key string, limit Limit, now time.Time) (Result, error)
+}
+
+// result собирает Result по числу запросов tokens в ведре после попытки.
+func result(allowed bool, tokens float64, limit Limit) Result {
+	interval := limit.interval()
+	r := Result{
+		Allowed:   allowed,
+		Limit:     limit.Requests,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration((float64(limit.Requests) - tokens) * float64(interval)),
+	}
+	if !allowed {
+		r.RetryAfter = time.Duration((1 - tokens) * float64(interval))
+	}
+	return r
+}
+
+var (
+	mu    sync.RWMutex
+	store Store = NewMemoryStore()
+	// trustProxy — брать адрес клиента из X-Forwarded-For/X-Real-IP, а не из
+	// соединения. Включать только за своим балансировщиком, иначе клиент
+	// выберет себе ключ сам.
+	trustProxy bool
+)
+
+// Configure выбирает хранилище: memory (по умолчанию), redis — сервер RESP по
+// адресу addr, off — ограничение выключено. trustProxyHeaders включает адрес
+// клиента из заголовков прокси.
+func Configure(kind, addr string, trustProxyHeaders bool) error {
+	var s Store
+	switch kind {
+	case "", BackendMemory:
+		s = NewMemoryStore()
+	case BackendRedis:
+		if addr == "" {
+			return fmt.Errorf("ratelimit: redis backend requires an address")
+		}
+		s = NewRESPStore(cache.NewRESPBackend(addr))
+	case BackendOff:
+		s = nil
+	default:
+		return fmt.Errorf("ratelimit: unknown backend %q", kind)
+	}
+	SetStore(s)
+	mu.Lock()
+	trustProxy = trustProxyHeaders
+	mu.Unlock()
+	return nil
+}
+
+// SetStore подменяет хранилище, например в тестах; nil выключает ограничение.
+func SetStore(s Store) {
+	mu.Lock()
+	store = s
+	mu.Unlock()
+}
+
+func current() (Store, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	return store, trustProxy
+}
@@ -0,0 +1,127 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	beego "github.com/beego/beego/v2/server/web"
+	"github.com/beego/beego/v2/server/web/context"
+)
+
+func TestParseLimit(t *testing.T) {
+	for raw, want := range map[string]Limit{
+		"60/m": {Requests: 60, Period: time.Minute},
+		"5/s":  {Requests: 5, Period: time.Second},
+		"off":  {},
+		"":     {},
+	} {
+		got, err := ParseLimit(raw)
+		if err != nil || got != want {
+			t.Errorf("ParseLimit(%q) = %+v, %v; want %+v", raw, got, err, want)
+		}
+	}
+	for _, raw := range []string{"60", "0/m", "-1/m", "60/day", "x/m"} {
+		if _, err := ParseLimit(raw); err == nil {
+			t.Errorf("ParseLimit(%q) accepted an invalid limit", raw)
+		}
+	}
+}
+
+func TestMemoryStoreRefills(t *testing.T) {
+	store := NewMemoryStore()
+	limit := Limit{Requests: 2, Period: 2 * time.Second}
+	now := time.Unix(1000, 0)
+
+	for i, remaining := range []int{1, 0} {
+		res, _ := store.Take("k", limit, now)
+		if !res.Allowed || res.Remaining != remaining {
+			t.Fatalf("request %d: %+v", i, res)
+		}
+	}
+	res, _ := store.Take("k", limit, now)
+	if res.Allowed || res.RetryAfter != time.Second || res.Reset != 2*time.Second {
+		t.Fatalf("expected a rejection with a one second retry, got %+v", res)
+	}
+	if other, _ := store.Take("other", limit, now); !other.Allowed {
+		t.Fatal("keys must not share a bucket")
+	}
+
+	res, _ = store.Take("k", limit, now.Add(time.Second))
+	if !res.Allowed || res.Remaining != 0 {
+		t.Fatalf("expected one request back after a second, got %+v", res)
+	}
+}
+
+type fakeDoer struct {
+	args  []string
+	reply interface{}
+}
+
+func (d *fakeDoer) Do(args ...string) (interface{}, error) {
+	d.args = args
+	return d.reply, nil
+}
+
+func TestRESPStoreParsesScriptReply(t *testing.T) {
+	server := &fakeDoer{reply: []interface{}{int64(0), []byte("0.25")}}
+	res, err := NewRESPStore(server).Take("search:ip:1.2.3.4", Limit{Requests: 60, Period: time.Minute}, time.UnixMilli(5000))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if server.args[0] != "EVAL" || server.args[3] != respKeyPrefix+"search:ip:1.2.3.4" ||
+		server.args[4] != "60" || server.args[5] != "1000" || server.args[6] != "5000" {
+		t.Errorf("unexpected command %q", server.args[2:])
+	}
+	if res.Allowed || res.Remaining != 0 || res.RetryAfter != 750*time.Millisecond {
+		t.Errorf("unexpected result %+v", res)
+	}
+}
+
+func TestFilterLimitsByUserAndIP(t *testing.T) {
+	SetStore(NewMemoryStore())
+	defer SetStore(NewMemoryStore())
+
+	routes := beego.NewControllerRegister()
+	routes.InsertFilter("/user/:entity/search", beego.BeforeRouter, func(ctx *context.Context) {
+		if ctx.Input.Header("Authorization") != "" {
+			ctx.Input.SetData("user_id", 7)
+		}
+	})
+	routes.InsertFilter("/user/:entity/search", beego.BeforeRouter,
+		Filter(Group{Name: "search", Limit: Limit{Requests: 1, Period: time.Minute}}))
+	routes.Get("/user/:entity/search", func(ctx *context.Context) {
+		ctx.Output.Body([]byte("ok"))
+	})
+
+	serve := func(remoteAddr, token string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/user/universities/search", nil)
+		r.RemoteAddr = remoteAddr
+		if token != "" {
+			r.Header.Set("Authorization", token)
+		}
+		w := httptest.NewRecorder()
+		routes.ServeHTTP(w, r)
+		return w
+	}
+
+	first := serve("10.0.0.1:1234", "")
+	if first.Code != http.StatusOK || first.Header().Get("RateLimit-Remaining") != "0" ||
+		first.Header().Get("RateLimit-Policy") != "1;w=60" {
+		t.Fatalf("first request: %d %v", first.Code, first.Header())
+	}
+	second := serve("10.0.0.1:5678", "")
+	if second.Code != http.StatusTooManyRequests || second.Header().Get("Retry-After") != "60" {
+		t.Fatalf("second request from the same IP: %d %v", second.Code, second.Header())
+	}
+	if w := serve("10.0.0.2:1234", ""); w.Code != http.StatusOK {
+		t.Errorf("another IP was limited: %d", w.Code)
+	}
+	if w := serve("10.0.0.1:1234", "Bearer token"); w.Code != http.StatusOK {
+		t.Errorf("an authenticated user was limited by the IP bucket: %d", w.Code)
+	}
+	if w := serve("10.0.0.3:1234", "Bearer token"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("the user bucket was not shared across IPs: %d", w.Code)
+	}
+}
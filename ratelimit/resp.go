@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const respKeyPrefix = "uni:ratelimit:"
+
+// takeScript — тот же token bucket, что и в MemoryStore, выполненный на сервере
+// атомарно. Ведро — хеш с полями tokens и ts (мс); оно истекает, когда
+// наполнилось бы целиком. Возвращает {1|0, остаток запросов строкой}.
+const takeScript = `
+local capacity = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+  tokens = capacity
+  ts = now
+end
+if now > ts then
+  tokens = math.min(capacity, tokens + (now - ts) / interval)
+  ts = now
+end
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(ts))
+redis.call('PEXPIRE', KEYS[1], math.ceil((capacity - tokens) * interval) + 1000)
+return {allowed, tostring(tokens)}
+`
+
+// Doer выполняет команду RESP; его реализует cache.RESPBackend.
+type Doer interface {
+	Do(args ...string) (interface{}, error)
+}
+
+// RESPStore хранит ведра на сервере RESP, общем для всех экземпляров сервиса.
+type RESPStore struct {
+	server Doer
+}
+
+func NewRESPStore(server Doer) *RESPStore {
+	return &RESPStore{server: server}
+}
+
+func (s *RESPStore) Take(key string, limit Limit, now time.Time) (Result, error) {
+	interval := float64(limit.interval()) / float64(time.Millisecond)
+	reply, err := s.server.Do("EVAL", takeScript, "1", respKeyPrefix+key,
+		strconv.Itoa(limit.Requests),
+		strconv.FormatFloat(interval, 'f', -1, 64),
+		strconv.FormatInt(now.UnixMilli(), 10),
+	)
+	if err != nil {
+		return Result{}, err
+	}
+
+	items, ok := reply.([]interface{})
+	if !ok || len(items) != 2 {
+		return Result{}, fmt.Errorf("ratelimit: unexpected reply %v", reply)
+	}
+	allowed, _ := items[0].(int64)
+	raw, _ := items[1].([]byte)
+	tokens, err := strconv.ParseFloat(string(raw), 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("ratelimit: unexpected token count %q", raw)
+	}
+	return result(allowed == 1, tokens, limit), nil
+}
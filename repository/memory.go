@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"strings"
+	"sync"
+)
+
+// MemoryStorage хранит загруженные файлы в памяти процесса и отдаёт адрес
+// memory://path. Заменяет облачное хранилище в тестах.
+type MemoryStorage struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{files: map[string][]byte{}}
+}
+
+func (s *MemoryStorage) Upload(ctx context.Context, path string, file multipart.File) (string, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[path] = data
+	return "memory://" + path, nil
+}
+
+func (s *MemoryStorage) Delete(ctx context.Context, path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.files, strings.TrimPrefix(path, "memory://"))
+	return nil
+}
+
+// File возвращает содержимое файла, загруженного по пути path.
+func (s *MemoryStorage) File(path string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.files[path]
+	return data, ok
+}
@@ -0,0 +1,421 @@
+package repository
+
+import (
+	"context"
+	"mime/multipart"
+	"testhub-spec-uni/models"
+
+	"github.com/astaxie/beego/orm"
+)
+
+// NewORM возвращает репозитории поверх функций models и базы по умолчанию;
+// файлы хранятся в S3-совместимом хранилище из конфигурации.
+func NewORM() *Repositories {
+	return NewORMWithStorage(cloudStorage{})
+}
+
+// NewORMWithStorage — то же, что NewORM, но файлы загружаются и удаляются через storage.
+func NewORMWithStorage(storage Storage) *Repositories {
+	return &Repositories{
+		Universities: ormUniversities{storage},
+		Specialities: ormSpecialities{},
+		PointStats:   ormPointStats{},
+		Quotas:       ormQuotas{},
+		Services:     ormServices{},
+		Cities:       ormCities{},
+		Subjects:     ormSubjects{},
+		Favorites:    ormFavorites{},
+		Storage:      storage,
+	}
+}
+
+// notFound заменяет orm.ErrNoRows на ErrNotFound, чтобы контроллеры не зависели от ORM.
+func notFound(err error) error {
+	if err == orm.ErrNoRows {
+		return ErrNotFound
+	}
+	return err
+}
+
+type ormUniversities struct{ storage Storage }
+
+func (ormUniversities) Create(data *models.AddUUniversityResponse) (int64, error) {
+	id, err := models.AddUniversity(data)
+	return id, notFound(err)
+}
+
+func (ormUniversities) SetMainImage(id int64, url string) error {
+	return models.UpdateUniversityImageURL(id, url)
+}
+
+func (ormUniversities) AddGallery(id int64, urls []string) error {
+	return models.AddGalleryImages(id, urls)
+}
+
+func (ormUniversities) MergeGallery(id int, urls []string) error {
+	return models.UpdateUniversityGallery(id, urls)
+}
+
+func (ormUniversities) RemoveGalleryPhoto(id, photoId int) error {
+	return notFound(models.RemoveUniversityGalleryPhoto(id, photoId))
+}
+
+func (ormUniversities) Get(id int) (*models.University, error) {
+	university, err := models.GetUniversityByID(id)
+	return university, notFound(err)
+}
+
+func (ormUniversities) GetForAdmin(id int) (*models.GetByIdUniversityResponseForAdmin, error) {
+	university, err := models.GetUniversityByIdForAdmin(id)
+	return university, notFound(err)
+}
+
+func (ormUniversities) GetForUser(id int, language string) (*models.GetByIdUniversityResponseForUser, error) {
+	university, err := models.GetUniversityByIdForUser(id, language)
+	return university, notFound(err)
+}
+
+func (ormUniversities) List(userId int, language string, page, perPage int) ([]*models.GetAllUniversityResponse, int64, int, int, error) {
+	return models.GetAllUniversities(userId, language, page, perPage)
+}
+
+func (ormUniversities) ListForAdmin() ([]*models.GetAllUniversityForAdminResponse, error) {
+	return models.GetAllUniversitiesForAdmin()
+}
+
+func (ormUniversities) Names(language string) ([]models.GetUniNamesResponse, error) {
+	return models.GetUniversityNames(language)
+}
+
+func (ormUniversities) Search(ctx context.Context, params map[string]interface{}, language string) (*models.UniversitySearchResult, error) {
+	return models.SearchUniversities(ctx, params, language)
+}
+
+func (ormUniversities) Map(ctx context.Context, params map[string]interface{}, language string, userId int, bbox *models.BoundingBox, zoom int) (*models.GeoJSONFeatureCollection, error) {
+	return models.GetUniversitiesMap(ctx, params, language, userId, bbox, zoom)
+}
+
+func (ormUniversities) Update(university *models.University) error {
+	return models.UpdateUniversity(university)
+}
+
+func (u ormUniversities) Delete(ctx context.Context, id int) error {
+	return notFound(models.DeleteUniversity(ctx, id, u.storage.Delete))
+}
+
+func (ormUniversities) AssignCity(universityId, cityId int) error {
+	return notFound(models.AssignCityToUniversity(universityId, cityId))
+}
+
+func (ormUniversities) AddSpeciality(ctx context.Context, universityId, specialityId int) error {
+	return notFound(models.AddSpecialityToUniversity(ctx, specialityId, universityId))
+}
+
+func (ormUniversities) AddSpecialities(ctx context.Context, universityId int, specialityIds []int) error {
+	return notFound(models.AddSpecialitiesToUniversity(ctx, specialityIds, universityId))
+}
+
+func (ormUniversities) RemoveSpeciality(ctx context.Context, universityId, specialityId int) error {
+	return notFound(models.RemoveSpecialityFromUniversity(ctx, universityId, specialityId))
+}
+
+func (ormUniversities) AddServices(ctx context.Context, universityId int, serviceIds []int) error {
+	return notFound(models.AddServicesToUniversity(ctx, serviceIds, universityId))
+}
+
+func (ormUniversities) ReplaceServices(universityId int, services []*models.Service) error {
+	return models.UpdateUniversityServices(universityId, services)
+}
+
+func (ormUniversities) RefreshAllStats(ctx context.Context) error {
+	return models.RefreshAllUniversityStats(ctx)
+}
+
+type ormSpecialities struct{}
+
+func (ormSpecialities) Create(data *models.AddSpecialityResponse) (int64, error) {
+	return models.AddSpecialityFromFormData(data)
+}
+
+func (ormSpecialities) Get(id int, language string) (*models.Speciality, error) {
+	speciality, err := models.GetSpecialityById(id, language)
+	return speciality, notFound(err)
+}
+
+func (ormSpecialities) List(language string) ([]*models.Speciality, error) {
+	return models.GetAllSpecialities(language)
+}
+
+func (ormSpecialities) Update(data *models.UpdateSpecialityResponse) error {
+	return models.UpdateSpecialityFromFormData(data)
+}
+
+func (ormSpecialities) Delete(id int) error {
+	return models.DeleteSpeciality(id)
+}
+
+func (ormSpecialities) ListByUniversity(universityId int, language string, page, perPage int) ([]models.GetByUniResponseForUser, int, error) {
+	return models.GetSpecialitiesInUniversityForUser(universityId, language, page, perPage)
+}
+
+func (ormSpecialities) ListByUniversityForAdmin(universityId int) ([]models.IUniverSpecialtyShortcut, error) {
+	return models.GetSpecialitiesInUniversityForAdmin(universityId)
+}
+
+func (ormSpecialities) SetSubjectPair(specialityId, subjectPairId int) error {
+	return models.AssociateSpecialityWithSubjectPair(specialityId, subjectPairId)
+}
+
+func (ormSpecialities) SubjectPairs(specialityId int, language string) ([]*models.SubjectPair, error) {
+	return models.GetSubjectPairsBySpecialityId(specialityId, language)
+}
+
+func (ormSpecialities) ListBySubjects(subject1Id, subject2Id int, language string) ([]*models.Speciality, error) {
+	return models.GetSpecialitiesBySubjectPair(subject1Id, subject2Id, language)
+}
+
+func (ormSpecialities) Search(ctx context.Context, params map[string]interface{}, language string) (*models.SpecialitySearchResult, error) {
+	return models.SearchSpecialities(ctx, params, language)
+}
+
+func (ormSpecialities) Names(language string) ([]models.GetSpecialityNameResponse, error) {
+	return models.GetSpecialityNames(language)
+}
+
+type ormPointStats struct{}
+
+func (ormPointStats) Create(universityId, specialityId int, pointStat *models.PointStat) (int64, error) {
+	return models.AddPointStat(universityId, specialityId, pointStat)
+}
+
+func (ormPointStats) List(universityId, specialityId int) ([]*models.GetPointStatResponse, error) {
+	return models.GetPointStatsByUniversityAndSpeciality(universityId, specialityId)
+}
+
+func (ormPointStats) Get(id int) (*models.PointStat, error) {
+	pointStat, err := models.GetPointStatById(id)
+	return pointStat, notFound(err)
+}
+
+func (ormPointStats) Update(id int, form *models.UpdatePointStatResponse) error {
+	return notFound(models.UpdatePointStatById(id, form))
+}
+
+func (ormPointStats) Delete(id int) error {
+	return notFound(models.DeletePointStat(id))
+}
+
+type ormQuotas struct{}
+
+func (ormQuotas) Create(quota *models.Quota) (int64, error) {
+	return models.AddQuota(quota)
+}
+
+func (ormQuotas) Get(id int, language string) (*models.Quota, error) {
+	quota, err := models.GetQuotaById(id, language)
+	return quota, notFound(err)
+}
+
+func (ormQuotas) List(language string) ([]*models.Quota, error) {
+	return models.GetAllQuotas(language)
+}
+
+func (ormQuotas) GetWithSpecialities(id int, language string) (*models.Quota, error) {
+	quota, err := models.GetQuotaWithSpecialitiesById(id, language)
+	return quota, notFound(err)
+}
+
+func (ormQuotas) ListWithSpecialities(language string) ([]*models.Quota, error) {
+	return models.GetAllQuotasWithSpecialities(language)
+}
+
+func (ormQuotas) Update(quota *models.Quota, fields ...string) error {
+	return notFound(models.UpdateQuota(quota, fields...))
+}
+
+func (ormQuotas) Delete(id int) error {
+	return models.DeleteQuota(id)
+}
+
+func (ormQuotas) AddSpeciality(quotaId, specialityId int) error {
+	return notFound(models.AddSpecialityToQuota(specialityId, quotaId))
+}
+
+type ormServices struct{}
+
+func (ormServices) Create(service *models.Service) (int64, error) {
+	return models.AddService(service)
+}
+
+func (ormServices) Get(id int, language string) (*models.ServiceResponseForUser, error) {
+	service, err := models.GetServiceById(id, language)
+	return service, notFound(err)
+}
+
+func (ormServices) GetByID(id int) (*models.Service, error) {
+	return models.GetServiceByID(id)
+}
+
+func (ormServices) List(language string) ([]*models.ServiceResponseForUser, error) {
+	return models.GetAllServices(language)
+}
+
+func (ormServices) ListForAdmin() ([]*models.ServiceResponseForAdmin, error) {
+	return models.GetAllServicesForAdmin()
+}
+
+func (ormServices) Search(prefix, language string) ([]models.ServiceResponseForUser, error) {
+	return models.SearchServicesByName(prefix, language)
+}
+
+func (ormServices) ListByUniversity(universityId int, language string) ([]*models.ServiceResponseForUser, error) {
+	services, err := models.GetServicesByUniversityId(universityId, language)
+	return services, notFound(err)
+}
+
+func (ormServices) ListByUniversityForAdmin(universityId int) ([]*models.ServiceResponseForAdmin, error) {
+	services, err := models.GetServicesByUniversityIdForAdmin(universityId)
+	return services, notFound(err)
+}
+
+func (ormServices) AddToUniversity(ctx context.Context, serviceId, universityId int) error {
+	return notFound(models.AddServiceToUniversity(ctx, serviceId, universityId))
+}
+
+func (ormServices) Update(service *models.Service, fields ...string) error {
+	return models.UpdateService(service, fields...)
+}
+
+func (ormServices) Delete(id int) error {
+	return models.DeleteService(id)
+}
+
+type ormCities struct{}
+
+func (ormCities) Create(city *models.City) (int64, error) {
+	return models.AddCity(city)
+}
+
+func (ormCities) Get(id int, language string) (*models.City, error) {
+	city, err := models.GetCityById(id, language)
+	return city, notFound(err)
+}
+
+func (ormCities) GetWithUniversities(id int, language string) (*models.City, error) {
+	city, err := models.GetCityWithUniversities(id, language)
+	return city, notFound(err)
+}
+
+func (ormCities) List(language string) ([]*models.City, error) {
+	return models.GetAllCitiesByLanguage(language)
+}
+
+func (ormCities) Search(name, language string) ([]models.City, error) {
+	return models.SearchCitiesByName(name, language)
+}
+
+func (ormCities) Update(city *models.City) error {
+	return models.UpdateCity(city)
+}
+
+func (ormCities) Delete(id int) error {
+	return models.DeleteCity(id)
+}
+
+func (ormCities) AssignRegion(cityId, regionId int) error {
+	return notFound(models.AssignRegionToCity(cityId, regionId))
+}
+
+type ormSubjects struct{}
+
+func (ormSubjects) Create(subject *models.Subject) (int64, error) {
+	return models.AddSubject(subject)
+}
+
+func (ormSubjects) Get(id int, language string) (*models.SubjectResponse, error) {
+	subject, err := models.GetSubjectById(id, language)
+	return subject, notFound(err)
+}
+
+func (ormSubjects) List(language string) ([]*models.SubjectResponse, error) {
+	return models.GetAllSubjects(language)
+}
+
+func (ormSubjects) Search(name, language string) ([]models.SubjectResponse, error) {
+	return models.SearchSubjectsByName(name, language)
+}
+
+func (ormSubjects) AllowedSecond(firstSubjectId int, language string) ([]*models.SubjectResponse, error) {
+	subjects, err := models.GetAllowedSecondSubjects(firstSubjectId)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []*models.SubjectResponse
+	for _, subject := range subjects {
+		subject.Localize(language)
+		responses = append(responses, &models.SubjectResponse{
+			Id:   subject.Id,
+			Name: subject.Name,
+		})
+	}
+	return responses, nil
+}
+
+func (ormSubjects) Update(id int, form *models.UpdateSubjectRequest) error {
+	return notFound(models.UpdateSubject(id, form))
+}
+
+func (ormSubjects) Delete(id int) error {
+	return models.DeleteSubject(id)
+}
+
+type ormFavorites struct{}
+
+func (ormFavorites) Add(userId, universityId int) error {
+	return models.AddFavoriteUniversity(userId, universityId)
+}
+
+func (ormFavorites) Remove(userId, universityId int) error {
+	return models.RemoveFavoriteUniversity(userId, universityId)
+}
+
+func (ormFavorites) List(userId int, language string) ([]*models.GetAllUniversityResponse, error) {
+	universities, err := models.ListFavoriteUniversities(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []*models.GetAllUniversityResponse
+	for _, university := range universities {
+		university.Localize(language)
+
+		responses = append(responses, &models.GetAllUniversityResponse{
+			Id:               university.Id,
+			Name:             university.Name,
+			ImageUrl:         university.MainImageUrl,
+			Address:          university.Address,
+			UniversityCode:   university.UniversityCode,
+			SpecialityCount:  len(university.Specialities),
+			UniversityStatus: university.UniversityStatus,
+			MinScore:         university.MinEntryScore,
+			ScoreRange:       university.ScoreRange(),
+			AverageFee:       university.AverageFee,
+			FeeRange:         university.FeeRange(),
+			Rating:           university.Rating,
+			Favorite:         true,
+		})
+	}
+	return responses, nil
+}
+
+// cloudStorage загружает файлы в S3-совместимое хранилище из конфигурации.
+type cloudStorage struct{}
+
+func (cloudStorage) Upload(ctx context.Context, path string, file multipart.File) (string, error) {
+	return models.UploadFileToCloud(ctx, path, file)
+}
+
+func (cloudStorage) Delete(ctx context.Context, path string) error {
+	return models.DeleteFileFromCloud(ctx, path)
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testhub-spec-uni/dbtest"
+	"testhub-spec-uni/models"
+	"testing"
+)
+
+// TestMain поднимает для ORM-репозиториев временную базу SQLite со всеми миграциями.
+func TestMain(m *testing.M) {
+	os.Exit(dbtest.Run(m, nil))
+}
+
+func TestMissingRecords(t *testing.T) {
+	repos := NewORM()
+	if _, err := repos.Cities.Get(1<<20, models.LocaleRu); err != ErrNotFound {
+		t.Errorf("city: %v, want ErrNotFound", err)
+	}
+	if _, err := repos.Subjects.Get(1<<20, models.LocaleRu); err != ErrNotFound {
+		t.Errorf("subject: %v, want ErrNotFound", err)
+	}
+	if _, err := repos.Universities.Get(1 << 20); err != ErrNotFound {
+		t.Errorf("university: %v, want ErrNotFound", err)
+	}
+	if err := repos.Subjects.Update(1<<20, &models.UpdateSubjectRequest{NameRu: "x"}); err != ErrNotFound {
+		t.Errorf("subject update: %v, want ErrNotFound", err)
+	}
+}
+
+func TestSubjectUpdate(t *testing.T) {
+	repos := NewORM()
+	id, err := repos.Subjects.Create(&models.Subject{NameRu: "Химия", NameKz: "Химия", MaxScore: 50, Threshold: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	zero, tooHigh := 0, 60
+	if err := repos.Subjects.Update(int(id), &models.UpdateSubjectRequest{NameRu: "Химия и биология", Threshold: &zero}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Subjects.Update(int(id), &models.UpdateSubjectRequest{Threshold: &tooHigh}); err == nil {
+		t.Error("threshold above MaxScore was accepted")
+	}
+	subject, err := repos.Subjects.Get(int(id), models.LocaleRu)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if subject.Name != "Химия и биология" {
+		t.Errorf("name %q", subject.Name)
+	}
+}
+
+func TestUniversitySearch(t *testing.T) {
+	ctx := context.Background()
+	repos := NewORM()
+	cityId, err := repos.Cities.Create(&models.City{NameRu: "Шымкент", NameKz: "Шымкент"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	serviceId, err := repos.Services.Create(&models.Service{NameRu: "Бассейн", NameKz: "Бассейн"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := map[string]int{}
+	// Координаты есть только у первого университета, остальных на карте нет.
+	points := map[string]models.GeoPoint{"Альфа": {Latitude: 42.3, Longitude: 69.6}}
+	for _, code := range []string{"Альфа", "Бета", "Гамма"} {
+		id, err := repos.Universities.Create(&models.AddUUniversityResponse{
+			NameRu: "Тестовый университет " + code, NameKz: "Тест университеті " + code,
+			UniversityStatusRu: "Частный", UniversityStatusKz: "Жеке",
+			Website: "https://example.kz", CallCenterNumber: "+77000000000", WhatsAppNumber: "+77000000000",
+			Address: "ул. Тестовая, 1", UniversityCode: "T-" + code,
+			StudyFormatRu: "Очная", StudyFormatKz: "Күндізгі",
+			AbbreviationRu: "ТУ", AbbreviationKz: "ТУ",
+			AddressLink: "https://2gis.kz", DescriptionRu: "Описание", DescriptionKz: "Сипаттама",
+			Rating: "1", CityId: int(cityId), Latitude: points[code].Latitude, Longitude: points[code].Longitude,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[code] = int(id)
+	}
+	if err := repos.Universities.AddServices(ctx, ids["Бета"], []int{int(serviceId)}); err != nil {
+		t.Fatal(err)
+	}
+
+	search := func(params map[string]interface{}) []int {
+		t.Helper()
+		params["name"] = "Тестовый университет"
+		result, err := repos.Universities.Search(ctx, params, models.LocaleRu)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var found []int
+		for _, university := range result.Universities {
+			found = append(found, university.Id)
+		}
+		return found
+	}
+
+	if found := search(map[string]interface{}{"sort": "name_desc"}); fmt.Sprint(found) != fmt.Sprint([]int{ids["Гамма"], ids["Бета"], ids["Альфа"]}) {
+		t.Errorf("sorted by name: %v", found)
+	}
+	if found := search(map[string]interface{}{"service_ids": []int{int(serviceId)}}); fmt.Sprint(found) != fmt.Sprint([]int{ids["Бета"]}) {
+		t.Errorf("by service: %v", found)
+	}
+	if found := search(map[string]interface{}{"status": "частный", "city_id": int(cityId)}); len(found) != 3 {
+		t.Errorf("by status and city: %v", found)
+	}
+	if found := search(map[string]interface{}{"min_score": 1}); len(found) != 0 {
+		t.Errorf("universities without stats passed min_score: %v", found)
+	}
+
+	collection, err := repos.Universities.Map(ctx, map[string]interface{}{"name": "Тестовый университет"}, models.LocaleRu, 0, nil, 18)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(collection.Features) != 1 || collection.Features[0].Properties["university_id"] != ids["Альфа"] ||
+		collection.Features[0].Properties["name"] != "Тестовый университет Альфа" {
+		t.Errorf("map features: %+v", collection.Features)
+	}
+	if _, err := repos.Universities.Search(ctx, map[string]interface{}{"sort": "size"}, models.LocaleRu); err == nil {
+		t.Error("unknown sort order was accepted")
+	}
+}
@@ -0,0 +1,148 @@
+// Package repository отделяет контроллеры от ORM.
+//
+// Для каждого агрегата (университеты, специальности, статистика баллов, квоты,
+// сервисы, города, предметы, избранное) есть интерфейс. NewORM возвращает
+// реализацию поверх функций models и глобального orm.NewOrm(). Правила проверки и
+// расчёта живут только в models; тесты запускают ту же реализацию на SQLite
+// (пакет dbtest), а файлы хранят в MemoryStorage.
+package repository
+
+import (
+	"context"
+	"errors"
+	"mime/multipart"
+	"testhub-spec-uni/models"
+)
+
+// ErrNotFound возвращается, когда запрошенной записи нет.
+var ErrNotFound = errors.New("record not found")
+
+type Universities interface {
+	Create(data *models.AddUUniversityResponse) (int64, error)
+	SetMainImage(id int64, url string) error
+	AddGallery(id int64, urls []string) error
+	// MergeGallery добавляет в галерею фото, которых в ней ещё нет.
+	MergeGallery(id int, urls []string) error
+	RemoveGalleryPhoto(id, photoId int) error
+	Get(id int) (*models.University, error)
+	GetForAdmin(id int) (*models.GetByIdUniversityResponseForAdmin, error)
+	GetForUser(id int, language string) (*models.GetByIdUniversityResponseForUser, error)
+	// List возвращает страницу университетов, общее число, число страниц и номер
+	// страницы; Favorite отмечает избранные пользователем userId.
+	List(userId int, language string, page, perPage int) ([]*models.GetAllUniversityResponse, int64, int, int, error)
+	ListForAdmin() ([]*models.GetAllUniversityForAdminResponse, error)
+	Names(language string) ([]models.GetUniNamesResponse, error)
+	Search(ctx context.Context, params map[string]interface{}, language string) (*models.UniversitySearchResult, error)
+	Map(ctx context.Context, params map[string]interface{}, language string, userId int, bbox *models.BoundingBox, zoom int) (*models.GeoJSONFeatureCollection, error)
+	Update(university *models.University) error
+	Delete(ctx context.Context, id int) error
+	AssignCity(universityId, cityId int) error
+	AddSpeciality(ctx context.Context, universityId, specialityId int) error
+	AddSpecialities(ctx context.Context, universityId int, specialityIds []int) error
+	// RemoveSpeciality возвращает ErrNotFound, если специальность не привязана к университету.
+	RemoveSpeciality(ctx context.Context, universityId, specialityId int) error
+	AddServices(ctx context.Context, universityId int, serviceIds []int) error
+	ReplaceServices(universityId int, services []*models.Service) error
+	RefreshAllStats(ctx context.Context) error
+}
+
+type Specialities interface {
+	Create(data *models.AddSpecialityResponse) (int64, error)
+	Get(id int, language string) (*models.Speciality, error)
+	List(language string) ([]*models.Speciality, error)
+	Update(data *models.UpdateSpecialityResponse) error
+	Delete(id int) error
+	// ListByUniversity возвращает страницу специальностей университета и их общее число.
+	ListByUniversity(universityId int, language string, page, perPage int) ([]models.GetByUniResponseForUser, int, error)
+	ListByUniversityForAdmin(universityId int) ([]models.IUniverSpecialtyShortcut, error)
+	SetSubjectPair(specialityId, subjectPairId int) error
+	SubjectPairs(specialityId int, language string) ([]*models.SubjectPair, error)
+	ListBySubjects(subject1Id, subject2Id int, language string) ([]*models.Speciality, error)
+	Search(ctx context.Context, params map[string]interface{}, language string) (*models.SpecialitySearchResult, error)
+	Names(language string) ([]models.GetSpecialityNameResponse, error)
+}
+
+type PointStats interface {
+	Create(universityId, specialityId int, pointStat *models.PointStat) (int64, error)
+	List(universityId, specialityId int) ([]*models.GetPointStatResponse, error)
+	Get(id int) (*models.PointStat, error)
+	Update(id int, form *models.UpdatePointStatResponse) error
+	Delete(id int) error
+}
+
+type Quotas interface {
+	Create(quota *models.Quota) (int64, error)
+	Get(id int, language string) (*models.Quota, error)
+	List(language string) ([]*models.Quota, error)
+	GetWithSpecialities(id int, language string) (*models.Quota, error)
+	ListWithSpecialities(language string) ([]*models.Quota, error)
+	// Update сохраняет только поля fields; без них — все поля.
+	Update(quota *models.Quota, fields ...string) error
+	Delete(id int) error
+	AddSpeciality(quotaId, specialityId int) error
+}
+
+type Services interface {
+	Create(service *models.Service) (int64, error)
+	Get(id int, language string) (*models.ServiceResponseForUser, error)
+	GetByID(id int) (*models.Service, error)
+	List(language string) ([]*models.ServiceResponseForUser, error)
+	ListForAdmin() ([]*models.ServiceResponseForAdmin, error)
+	Search(prefix, language string) ([]models.ServiceResponseForUser, error)
+	ListByUniversity(universityId int, language string) ([]*models.ServiceResponseForUser, error)
+	ListByUniversityForAdmin(universityId int) ([]*models.ServiceResponseForAdmin, error)
+	AddToUniversity(ctx context.Context, serviceId, universityId int) error
+	// Update сохраняет только поля fields; без них — все поля.
+	Update(service *models.Service, fields ...string) error
+	Delete(id int) error
+}
+
+type Cities interface {
+	Create(city *models.City) (int64, error)
+	Get(id int, language string) (*models.City, error)
+	GetWithUniversities(id int, language string) (*models.City, error)
+	List(language string) ([]*models.City, error)
+	Search(name, language string) ([]models.City, error)
+	Update(city *models.City) error
+	Delete(id int) error
+	AssignRegion(cityId, regionId int) error
+}
+
+type Subjects interface {
+	Create(subject *models.Subject) (int64, error)
+	Get(id int, language string) (*models.SubjectResponse, error)
+	List(language string) ([]*models.SubjectResponse, error)
+	Search(name, language string) ([]models.SubjectResponse, error)
+	// AllowedSecond возвращает предметы, которые образуют пару с первым предметом.
+	AllowedSecond(firstSubjectId int, language string) ([]*models.SubjectResponse, error)
+	Update(id int, form *models.UpdateSubjectRequest) error
+	Delete(id int) error
+}
+
+// Favorites — избранные университеты пользователя.
+type Favorites interface {
+	// Add не считает ошибкой повторное добавление.
+	Add(userId, universityId int) error
+	Remove(userId, universityId int) error
+	List(userId int, language string) ([]*models.GetAllUniversityResponse, error)
+}
+
+// Storage загружает файлы (изображения университетов и сервисов) и возвращает их URL.
+type Storage interface {
+	Upload(ctx context.Context, path string, file multipart.File) (string, error)
+	// Delete удаляет файл по пути или URL, который вернул Upload.
+	Delete(ctx context.Context, path string) error
+}
+
+// Repositories — набор репозиториев, который получают контроллеры.
+type Repositories struct {
+	Universities Universities
+	Specialities Specialities
+	PointStats   PointStats
+	Quotas       Quotas
+	Services     Services
+	Cities       Cities
+	Subjects     Subjects
+	Favorites    Favorites
+	Storage      Storage
+}
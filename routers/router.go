@@ -7,21 +7,36 @@ package routers
 
 import (
 	"testhub-spec-uni/controllers"
+	"testhub-spec-uni/metrics"
+	"testhub-spec-uni/repository"
 
 	beego "github.com/beego/beego/v2/server/web"
 )
 
-func init() {
+// Register регистрирует маршруты API; контроллеры каталога получают репозитории repos.
+func Register(repos *repository.Repositories) {
+	subject := &controllers.SubjectController{Subjects: repos.Subjects}
+	speciality := &controllers.SpecialityController{Specialities: repos.Specialities, PointStats: repos.PointStats}
+	university := &controllers.UniversityController{
+		Universities: repos.Universities,
+		Services:     repos.Services,
+		Favorites:    repos.Favorites,
+		Storage:      repos.Storage,
+	}
+	city := &controllers.CityController{Cities: repos.Cities}
+	quota := &controllers.QuotaController{Quotas: repos.Quotas}
+	service := &controllers.ServiceController{Services: repos.Services, Storage: repos.Storage}
+
 	adminNS := beego.NewNamespace("/api",
 		beego.NSNamespace("/subjects",
-			beego.NSInclude(&controllers.SubjectController{}),
-			beego.NSRouter("/", &controllers.SubjectController{}, "post:Create"),
-			beego.NSRouter("/:id", &controllers.SubjectController{}, "get:Get"),
-			beego.NSRouter("/", &controllers.SubjectController{}, "get:GetAll"),
-			beego.NSRouter("/:id", &controllers.SubjectController{}, "put:Update"),
-			beego.NSRouter("/:id", &controllers.SubjectController{}, "delete:Delete"),
-			beego.NSRouter("/secubjects/:firstSubjectId", &controllers.SubjectController{}, "get:GetAllowedSecondSubjects"),
-			beego.NSRouter("/search", &controllers.SubjectController{}, "get:SearchSubjectsByName"),
+			beego.NSInclude(subject),
+			beego.NSRouter("/", subject, "post:Create"),
+			beego.NSRouter("/:id", subject, "get:Get"),
+			beego.NSRouter("/", subject, "get:GetAll"),
+			beego.NSRouter("/:id", subject, "put:Update"),
+			beego.NSRouter("/:id", subject, "delete:Delete"),
+			beego.NSRouter("/secubjects/:firstSubjectId", subject, "get:GetAllowedSecondSubjects"),
+			beego.NSRouter("/search", subject, "get:SearchSubjectsByName"),
 		),
 		beego.NSNamespace("/subjectpairs",
 			beego.NSInclude(&controllers.SubjectPairController{}),
@@ -36,74 +51,154 @@ func init() {
 		),
 
 		beego.NSNamespace("/specialities",
-			beego.NSInclude(&controllers.SpecialityController{}),
-			beego.NSRouter("/", &controllers.SpecialityController{}, "post:Create"),
-			beego.NSRouter("/:id", &controllers.SpecialityController{}, "get:Get"),
-			beego.NSRouter("/", &controllers.SpecialityController{}, "get:GetAll"),
-			beego.NSRouter("/:id", &controllers.SpecialityController{}, "put:Update"),
-			beego.NSRouter("/:id", &controllers.SpecialityController{}, "delete:Delete"),
-			beego.NSRouter("/search", &controllers.SpecialityController{}, "get:SearchSpecialities"),
-			beego.NSRouter("/byuni/:universityId", &controllers.SpecialityController{}, "get:GetByUniversityForAdmin"),
-			beego.NSRouter("/bysubjects/:subject1_id/:subject2_id", &controllers.SpecialityController{}, "get:GetSpecialitiesBySubjectPair"),
-			beego.NSRouter("/associatepair/:speciality_id/:subject_pair_id", &controllers.SpecialityController{}, "put:AssociateSpecialityWithSubjectPair"),
-			beego.NSRouter("/byspec/:speciality_id", &controllers.SpecialityController{}, "get:GetSubjectPairsBySpecialityId"),
-			beego.NSRouter("/addpointstat/:universityId/:specialityId", &controllers.SpecialityController{}, "post:AddPointStat"),
-			beego.NSRouter("/pointstatsbyparams/:universityId/:specialityId", &controllers.SpecialityController{}, "get:GetPointStatsByUniversityAndSpeciality"),
-			beego.NSRouter("/updatepointstat/:id", &controllers.SpecialityController{}, "put:UpdatePointStat"),
-			beego.NSRouter("/getstat/:pointStatId/", &controllers.SpecialityController{}, "get:GetPointStatById"),
-			beego.NSRouter("/deletepointstat/:pointStatId/", &controllers.SpecialityController{}, "delete:DeletePointStat"),
-
-			//beego.NSRouter("/subject_combinations/:id", &controllers.SpecialityController{}, "get:GetSubjectsCombinationForSpeciality"),
-			//beego.NSRouter("/:specialityId/subjects/:subjectId", &controllers.SpecialityController{}, "post:AddSubject"),
+			beego.NSInclude(speciality),
+			beego.NSRouter("/", speciality, "post:Create"),
+			beego.NSRouter("/:id", speciality, "get:Get"),
+			beego.NSRouter("/", speciality, "get:GetAll"),
+			beego.NSRouter("/:id", speciality, "put:Update"),
+			beego.NSRouter("/:id", speciality, "delete:Delete"),
+			beego.NSRouter("/search", speciality, "get:SearchSpecialities"),
+			beego.NSRouter("/byuni/:universityId", speciality, "get:GetByUniversityForAdmin"),
+			beego.NSRouter("/bysubjects/:subject1_id/:subject2_id", speciality, "get:GetSpecialitiesBySubjectPair"),
+			beego.NSRouter("/associatepair/:speciality_id/:subject_pair_id", speciality, "put:AssociateSpecialityWithSubjectPair"),
+			beego.NSRouter("/byspec/:speciality_id", speciality, "get:GetSubjectPairsBySpecialityId"),
+			beego.NSRouter("/addpointstat/:universityId/:specialityId", speciality, "post:AddPointStat"),
+			beego.NSRouter("/pointstatsbyparams/:universityId/:specialityId", speciality, "get:GetPointStatsByUniversityAndSpeciality"),
+			beego.NSRouter("/updatepointstat/:id", speciality, "put:UpdatePointStat"),
+			beego.NSRouter("/getstat/:pointStatId/", speciality, "get:GetPointStatById"),
+			beego.NSRouter("/deletepointstat/:pointStatId/", speciality, "delete:DeletePointStat"),
+
+			//beego.NSRouter("/subject_combinations/:id", speciality, "get:GetSubjectsCombinationForSpeciality"),
+			//beego.NSRouter("/:specialityId/subjects/:subjectId", speciality, "post:AddSubject"),
 		),
 		beego.NSNamespace("/universities",
-			beego.NSInclude(&controllers.UniversityController{}),
-			beego.NSRouter("/", &controllers.UniversityController{}, "post:Create"),
-			beego.NSRouter("/:id", &controllers.UniversityController{}, "get:GetForAdmin"),
-			beego.NSRouter("/", &controllers.UniversityController{}, "get:GetAllForAdmin"),
-			beego.NSRouter("/:id", &controllers.UniversityController{}, "put:Update"),
-			beego.NSRouter("/:id", &controllers.UniversityController{}, "delete:Delete"),
-			beego.NSRouter("/assigncity/:universityId/:cityId", &controllers.UniversityController{}, "put:AssignCityToUniversity"),
-			beego.NSRouter("/assignspec/:universityId/:specialityId", &controllers.UniversityController{}, "post:AddSpecialityToUniversity"),
-			beego.NSRouter("/assignspecialities/:universityId", &controllers.UniversityController{}, "post:AddSpecialitiesToUniversity"),
-			beego.NSRouter("/assignserv/:universityId", &controllers.UniversityController{}, "post:AddServicesToUniversity"),
-			beego.NSRouter("/search", &controllers.UniversityController{}, "get:SearchUniversities"),
-			beego.NSRouter("/deletespec/:university_id/:speciality_id", &controllers.UniversityController{}, "delete:DeleteSpecialityFromUniversity"),
-			beego.NSRouter("/:uniId/delete-gallery/:photoId", &controllers.UniversityController{}, "delete:DeleteGalleryPhoto"),
+			beego.NSInclude(university),
+			beego.NSRouter("/", university, "post:Create"),
+			beego.NSRouter("/:id", university, "get:GetForAdmin"),
+			beego.NSRouter("/", university, "get:GetAllForAdmin"),
+			beego.NSRouter("/:id", university, "put:Update"),
+			beego.NSRouter("/:id", university, "delete:Delete"),
+			beego.NSRouter("/assigncity/:universityId/:cityId", university, "put:AssignCityToUniversity"),
+			beego.NSRouter("/assignspec/:universityId/:specialityId", university, "post:AddSpecialityToUniversity"),
+			beego.NSRouter("/assignspecialities/:universityId", university, "post:AddSpecialitiesToUniversity"),
+			beego.NSRouter("/assignserv/:universityId", university, "post:AddServicesToUniversity"),
+			beego.NSRouter("/search", university, "get:SearchUniversities"),
+			beego.NSRouter("/refreshstats", university, "put:RefreshStats"),
+			beego.NSRouter("/deletespec/:university_id/:speciality_id", university, "delete:DeleteSpecialityFromUniversity"),
+			beego.NSRouter("/:uniId/delete-gallery/:photoId", university, "delete:DeleteGalleryPhoto"),
 		),
 
 		beego.NSNamespace("/cities",
-			beego.NSInclude(&controllers.CityController{}),
-			beego.NSRouter("/", &controllers.CityController{}, "post:Create"),
-			beego.NSRouter("/:id", &controllers.CityController{}, "get:Get"),
-			beego.NSRouter("/", &controllers.CityController{}, "get:GetAll"),
-			beego.NSRouter("/:id", &controllers.CityController{}, "put:Update"),
-			beego.NSRouter("/:id", &controllers.CityController{}, "delete:Delete"),
-			beego.NSRouter("/info/:id", &controllers.CityController{}, "get:GetWithUniversities"),
-			beego.NSRouter("/search", &controllers.CityController{}, "get:SearchCities"),
+			beego.NSInclude(city),
+			beego.NSRouter("/", city, "post:Create"),
+			beego.NSRouter("/:id", city, "get:Get"),
+			beego.NSRouter("/", city, "get:GetAll"),
+			beego.NSRouter("/:id", city, "put:Update"),
+			beego.NSRouter("/:id", city, "delete:Delete"),
+			beego.NSRouter("/info/:id", city, "get:GetWithUniversities"),
+			beego.NSRouter("/search", city, "get:SearchCities"),
+			beego.NSRouter("/assignregion/:cityId/:regionId", city, "put:AssignRegion"),
+		),
+
+		beego.NSNamespace("/regions",
+			beego.NSInclude(&controllers.RegionController{}),
+			beego.NSRouter("/", &controllers.RegionController{}, "post:Create"),
+			beego.NSRouter("/", &controllers.RegionController{}, "get:GetAll"),
+			beego.NSRouter("/:id", &controllers.RegionController{}, "get:Get"),
+			beego.NSRouter("/:id", &controllers.RegionController{}, "put:Update"),
+			beego.NSRouter("/:id", &controllers.RegionController{}, "delete:Delete"),
+		),
+
+		beego.NSNamespace("/campuses",
+			beego.NSInclude(&controllers.CampusController{}),
+			beego.NSRouter("/", &controllers.CampusController{}, "post:Create"),
+			beego.NSRouter("/byuni/:universityId", &controllers.CampusController{}, "get:GetByUniversity"),
+			beego.NSRouter("/:id", &controllers.CampusController{}, "put:Update"),
+			beego.NSRouter("/:id", &controllers.CampusController{}, "delete:Delete"),
 		),
 
 		beego.NSNamespace("/quotas",
-			beego.NSInclude(&controllers.QuotaController{}),
-			beego.NSRouter("/", &controllers.QuotaController{}, "post:Create"),
-			beego.NSRouter("/:id", &controllers.QuotaController{}, "get:Get"),
-			beego.NSRouter("/", &controllers.QuotaController{}, "get:GetAll"),
-			beego.NSRouter("/:id", &controllers.QuotaController{}, "put:Update"),
-			beego.NSRouter("/:id", &controllers.QuotaController{}, "delete:Delete"),
-			beego.NSRouter("/all/:id", &controllers.QuotaController{}, "get:GetQuotaWithSpecialities"),
-			beego.NSRouter("/:quota_id/specialities/:speciality_id", &controllers.QuotaController{}, "post:AddSpecialityToQuota"),
+			beego.NSInclude(quota),
+			beego.NSRouter("/", quota, "post:Create"),
+			beego.NSRouter("/:id", quota, "get:Get"),
+			beego.NSRouter("/", quota, "get:GetAll"),
+			beego.NSRouter("/:id", quota, "put:Update"),
+			beego.NSRouter("/:id", quota, "delete:Delete"),
+			beego.NSRouter("/all/:id", quota, "get:GetQuotaWithSpecialities"),
+			beego.NSRouter("/:quota_id/specialities/:speciality_id", quota, "post:AddSpecialityToQuota"),
+		),
+
+		beego.NSNamespace("/quotaeligibility",
+			beego.NSInclude(&controllers.QuotaEligibilityController{}),
+			beego.NSRouter("/criteria", &controllers.QuotaEligibilityController{}, "post:CreateCriterion"),
+			beego.NSRouter("/criteria", &controllers.QuotaEligibilityController{}, "get:GetAllCriteriaForAdmin"),
+			beego.NSRouter("/criteria/:id", &controllers.QuotaEligibilityController{}, "put:UpdateCriterion"),
+			beego.NSRouter("/criteria/:id", &controllers.QuotaEligibilityController{}, "delete:DeleteCriterion"),
+			beego.NSRouter("/rules", &controllers.QuotaEligibilityController{}, "post:CreateRule"),
+			beego.NSRouter("/rules/byquota/:quotaId", &controllers.QuotaEligibilityController{}, "get:GetRulesByQuota"),
+			beego.NSRouter("/rules/:id", &controllers.QuotaEligibilityController{}, "delete:DeleteRule"),
+		),
+
+		beego.NSNamespace("/reviews",
+			beego.NSInclude(&controllers.ReviewController{}),
+			beego.NSRouter("/queue", &controllers.ReviewController{}, "get:GetQueue"),
+			beego.NSRouter("/stopwords", &controllers.ReviewController{}, "get:GetStopWords"),
+			beego.NSRouter("/stopwords", &controllers.ReviewController{}, "post:AddStopWord"),
+			beego.NSRouter("/stopwords/:id", &controllers.ReviewController{}, "delete:DeleteStopWord"),
+			beego.NSRouter("/:id/approve", &controllers.ReviewController{}, "put:Approve"),
+			beego.NSRouter("/:id/reject", &controllers.ReviewController{}, "put:Reject"),
+			beego.NSRouter("/:id", &controllers.ReviewController{}, "delete:Delete"),
+		),
+
+		beego.NSNamespace("/qa",
+			beego.NSInclude(&controllers.UniversityQAController{}),
+			beego.NSRouter("/queue", &controllers.UniversityQAController{}, "get:GetQueue"),
+			beego.NSRouter("/questions/:id/answers", &controllers.UniversityQAController{}, "post:AnswerAsAdmin"),
+			beego.NSRouter("/questions/:id/status/:status", &controllers.UniversityQAController{}, "put:SetQuestionStatus"),
+			beego.NSRouter("/questions/:id", &controllers.UniversityQAController{}, "delete:DeleteQuestion"),
+			beego.NSRouter("/answers/:id/status/:status", &controllers.UniversityQAController{}, "put:SetAnswerStatus"),
+			beego.NSRouter("/answers/:id", &controllers.UniversityQAController{}, "delete:DeleteAnswer"),
+			beego.NSRouter("/representatives", &controllers.UniversityQAController{}, "post:AddRepresentative"),
+			beego.NSRouter("/representatives/byuni/:universityId", &controllers.UniversityQAController{}, "get:GetRepresentatives"),
+			beego.NSRouter("/representatives/:id", &controllers.UniversityQAController{}, "delete:DeleteRepresentative"),
+		),
+
+		beego.NSNamespace("/faq",
+			beego.NSInclude(&controllers.FaqController{}),
+			beego.NSRouter("/", &controllers.FaqController{}, "post:Create"),
+			beego.NSRouter("/byuni/:universityId", &controllers.FaqController{}, "get:GetByUniversityForAdmin"),
+			beego.NSRouter("/:id", &controllers.FaqController{}, "put:Update"),
+			beego.NSRouter("/:id", &controllers.FaqController{}, "delete:Delete"),
+		),
+
+		beego.NSNamespace("/translations",
+			beego.NSInclude(&controllers.TranslationController{}),
+			beego.NSRouter("/", &controllers.TranslationController{}, "put:Set"),
+			beego.NSRouter("/migrate", &controllers.TranslationController{}, "post:Migrate"),
+			beego.NSRouter("/audit", &controllers.TranslationController{}, "get:Audit"),
+			beego.NSRouter("/:entityType/:entityId", &controllers.TranslationController{}, "get:Get"),
+		),
+
+		beego.NSNamespace("/quotaallocations",
+			beego.NSInclude(&controllers.QuotaAllocationController{}),
+			beego.NSRouter("/", &controllers.QuotaAllocationController{}, "post:Create"),
+			beego.NSRouter("/", &controllers.QuotaAllocationController{}, "get:GetAll"),
+			beego.NSRouter("/import", &controllers.QuotaAllocationController{}, "post:Import"),
+			beego.NSRouter("/:id", &controllers.QuotaAllocationController{}, "get:Get"),
+			beego.NSRouter("/:id", &controllers.QuotaAllocationController{}, "put:Update"),
+			beego.NSRouter("/:id", &controllers.QuotaAllocationController{}, "delete:Delete"),
 		),
 
 		beego.NSNamespace("/services",
-			beego.NSInclude(&controllers.ServiceController{}),
-			beego.NSRouter("/search", &controllers.ServiceController{}, "get:SearchServices"),
-			beego.NSRouter("/", &controllers.ServiceController{}, "post:AddService"),
-			beego.NSRouter("/", &controllers.ServiceController{}, "get:GetAllServicesForAdmin"),
-			beego.NSRouter("/:id", &controllers.ServiceController{}, "get:GetServiceById"),
-			beego.NSRouter("/:id", &controllers.ServiceController{}, "delete:DeleteService"),
-			beego.NSRouter("/:id", &controllers.ServiceController{}, "put:UpdateService"),
-			beego.NSRouter("/bind/:serviceId/:universityId", &controllers.ServiceController{}, "post:AddServiceToUniversity"),
-			beego.NSRouter("/getbyuni/:id", &controllers.ServiceController{}, "get:GetServicesByUniversityIdForAdmin"),
+			beego.NSInclude(service),
+			beego.NSRouter("/search", service, "get:SearchServices"),
+			beego.NSRouter("/", service, "post:AddService"),
+			beego.NSRouter("/", service, "get:GetAllServicesForAdmin"),
+			beego.NSRouter("/:id", service, "get:GetServiceById"),
+			beego.NSRouter("/:id", service, "delete:DeleteService"),
+			beego.NSRouter("/:id", service, "put:UpdateService"),
+			beego.NSRouter("/bind/:serviceId/:universityId", service, "post:AddServiceToUniversity"),
+			beego.NSRouter("/getbyuni/:id", service, "get:GetServicesByUniversityIdForAdmin"),
 		),
 
 		beego.NSNamespace("/unispecdetails",
@@ -114,6 +209,15 @@ func init() {
 			beego.NSRouter("/delete/:uid/:sid", &controllers.SpecialityUniversityController{}, "delete:DeleteUniversitySpecialityDetail"),
 		),
 
+		beego.NSNamespace("/events",
+			beego.NSInclude(&controllers.AdmissionEventController{}),
+			beego.NSRouter("/", &controllers.AdmissionEventController{}, "post:Create"),
+			beego.NSRouter("/:id", &controllers.AdmissionEventController{}, "get:Get"),
+			beego.NSRouter("/:id", &controllers.AdmissionEventController{}, "put:Update"),
+			beego.NSRouter("/:id", &controllers.AdmissionEventController{}, "delete:Delete"),
+			beego.NSRouter("/byuni/:universityId", &controllers.AdmissionEventController{}, "get:GetByUniversity"),
+		),
+
 		/**
 		beego.NSNamespace("/users",
 			beego.NSRouter("/", &controllers.UserController{}),
@@ -128,11 +232,11 @@ func init() {
 
 	userNS := beego.NewNamespace("/user",
 		beego.NSNamespace("/subjects",
-			beego.NSInclude(&controllers.SubjectController{}),
-			beego.NSRouter("/:id", &controllers.SubjectController{}, "get:Get"),
-			beego.NSRouter("/", &controllers.SubjectController{}, "get:GetAll"),
-			beego.NSRouter("/secubjects/:firstSubjectId", &controllers.SubjectController{}, "get:GetAllowedSecondSubjects"),
-			beego.NSRouter("/search", &controllers.SubjectController{}, "get:SearchSubjectsByName"),
+			beego.NSInclude(subject),
+			beego.NSRouter("/:id", subject, "get:Get"),
+			beego.NSRouter("/", subject, "get:GetAll"),
+			beego.NSRouter("/secubjects/:firstSubjectId", subject, "get:GetAllowedSecondSubjects"),
+			beego.NSRouter("/search", subject, "get:SearchSubjectsByName"),
 		),
 		beego.NSNamespace("/subjectpairs",
 			beego.NSInclude(&controllers.SubjectPairController{}),
@@ -142,52 +246,110 @@ func init() {
 		),
 
 		beego.NSNamespace("/specialities",
-			beego.NSInclude(&controllers.SpecialityController{}),
-			beego.NSRouter("/:id", &controllers.SpecialityController{}, "get:Get"),
-			beego.NSRouter("/", &controllers.SpecialityController{}, "get:GetAll"),
-			beego.NSRouter("/search", &controllers.SpecialityController{}, "get:SearchSpecialities"),
-			beego.NSRouter("/byuni/:universityId", &controllers.SpecialityController{}, "get:GetByUniversity"),
-			beego.NSRouter("/specialitynames", &controllers.SpecialityController{}, "get:GetSpecialityNames"),
-			beego.NSRouter("/bysubjects/:subject1_id/:subject2_id", &controllers.SpecialityController{}, "get:GetSpecialitiesBySubjectPair"),
-			beego.NSRouter("/byspec/:speciality_id", &controllers.SpecialityController{}, "get:GetSubjectPairsBySpecialityId"),
-			beego.NSRouter("/pointstatsbyparams/:universityId/:specialityId", &controllers.SpecialityController{}, "get:GetPointStatsByUniversityAndSpeciality"),
+			beego.NSInclude(speciality),
+			beego.NSRouter("/:id", speciality, "get:Get"),
+			beego.NSRouter("/", speciality, "get:GetAll"),
+			beego.NSRouter("/search", speciality, "get:SearchSpecialities"),
+			beego.NSRouter("/byuni/:universityId", speciality, "get:GetByUniversity"),
+			beego.NSRouter("/specialitynames", speciality, "get:GetSpecialityNames"),
+			beego.NSRouter("/bysubjects/:subject1_id/:subject2_id", speciality, "get:GetSpecialitiesBySubjectPair"),
+			beego.NSRouter("/byspec/:speciality_id", speciality, "get:GetSubjectPairsBySpecialityId"),
+			beego.NSRouter("/pointstatsbyparams/:universityId/:specialityId", speciality, "get:GetPointStatsByUniversityAndSpeciality"),
 		),
 		beego.NSNamespace("/universities",
-			beego.NSInclude(&controllers.UniversityController{}),
-			beego.NSRouter("/:id", &controllers.UniversityController{}, "get:GetForUser"),
-			beego.NSRouter("/", &controllers.UniversityController{}, "get:GetAll"),
-			beego.NSRouter("/uninames", &controllers.UniversityController{}, "get:GetUniNames"),
-			beego.NSRouter("/search", &controllers.UniversityController{}, "get:SearchUniversities"),
-			beego.NSRouter("/favorites/add/:universityId", &controllers.UniversityController{}, "post:AddFavoriteUniversity"),
-			beego.NSRouter("/favorites/remove/:universityId", &controllers.UniversityController{}, "post:RemoveFavoriteUniversity"),
-			beego.NSRouter("/favorites/list", &controllers.UniversityController{}, "get:ListFavoriteUniversities"),
+			beego.NSInclude(university),
+			beego.NSRouter("/:id", university, "get:GetForUser"),
+			beego.NSRouter("/", university, "get:GetAll"),
+			beego.NSRouter("/uninames", university, "get:GetUniNames"),
+			beego.NSRouter("/search", university, "get:SearchUniversities"),
+			beego.NSRouter("/map", university, "get:Map"),
+			beego.NSRouter("/favorites/add/:universityId", university, "post:AddFavoriteUniversity"),
+			beego.NSRouter("/favorites/remove/:universityId", university, "post:RemoveFavoriteUniversity"),
+			beego.NSRouter("/favorites/list", university, "get:ListFavoriteUniversities"),
+			beego.NSRouter("/events/upcoming", &controllers.AdmissionEventController{}, "get:GetUpcoming"),
+			beego.NSRouter("/events/feed", &controllers.AdmissionEventController{}, "get:GetCalendarFeed"),
+			beego.NSRouter("/reviews", &controllers.ReviewController{}, "post:Create"),
+			beego.NSRouter("/reviews/mine", &controllers.ReviewController{}, "get:GetMine"),
+			beego.NSRouter("/qa/questions", &controllers.UniversityQAController{}, "post:AskQuestion"),
+			beego.NSRouter("/qa/questions/:id/answers", &controllers.UniversityQAController{}, "post:AnswerAsRepresentative"),
+			beego.NSRouter("/qa/questions/:id/vote", &controllers.UniversityQAController{}, "put:VoteQuestion"),
+			beego.NSRouter("/qa/answers/:id/vote", &controllers.UniversityQAController{}, "put:VoteAnswer"),
 		),
 
 		beego.NSNamespace("/cities",
-			beego.NSInclude(&controllers.CityController{}),
-			beego.NSRouter("/:id", &controllers.CityController{}, "get:Get"),
-			beego.NSRouter("/", &controllers.CityController{}, "get:GetAll"),
-			beego.NSRouter("/info/:id", &controllers.CityController{}, "get:GetWithUniversities"),
-			beego.NSRouter("/search", &controllers.CityController{}, "get:SearchCities"),
+			beego.NSInclude(city),
+			beego.NSRouter("/:id", city, "get:Get"),
+			beego.NSRouter("/", city, "get:GetAll"),
+			beego.NSRouter("/info/:id", city, "get:GetWithUniversities"),
+			beego.NSRouter("/search", city, "get:SearchCities"),
+		),
+
+		beego.NSNamespace("/regions",
+			beego.NSInclude(&controllers.RegionController{}),
+			beego.NSRouter("/", &controllers.RegionController{}, "get:GetAll"),
+			beego.NSRouter("/:id", &controllers.RegionController{}, "get:Get"),
+		),
+
+		beego.NSNamespace("/campuses",
+			beego.NSInclude(&controllers.CampusController{}),
+			beego.NSRouter("/byuni/:universityId", &controllers.CampusController{}, "get:GetByUniversity"),
 		),
 
 		beego.NSNamespace("/quotas",
-			beego.NSInclude(&controllers.QuotaController{}),
-			beego.NSRouter("/:id", &controllers.QuotaController{}, "get:Get"),
-			beego.NSRouter("/", &controllers.QuotaController{}, "get:GetAll"),
-			beego.NSRouter("/all/:id", &controllers.QuotaController{}, "get:GetQuotaWithSpecialities"),
-			beego.NSRouter("/:quota_id/specialities/:speciality_id", &controllers.QuotaController{}, "post:AddSpecialityToQuota"),
+			beego.NSInclude(quota),
+			beego.NSRouter("/:id", quota, "get:Get"),
+			beego.NSRouter("/", quota, "get:GetAll"),
+			beego.NSRouter("/all/:id", quota, "get:GetQuotaWithSpecialities"),
+			beego.NSRouter("/:quota_id/specialities/:speciality_id", quota, "post:AddSpecialityToQuota"),
+		),
+		beego.NSNamespace("/quotaallocations",
+			beego.NSInclude(&controllers.QuotaAllocationController{}),
+			beego.NSRouter("/", &controllers.QuotaAllocationController{}, "get:GetAll"),
+			beego.NSRouter("/breakdown/:universityId/:specialityId", &controllers.QuotaAllocationController{}, "get:GetBreakdown"),
+		),
+		beego.NSNamespace("/unt",
+			beego.NSInclude(&controllers.UntController{}),
+			beego.NSRouter("/evaluate", &controllers.UntController{}, "post:Evaluate"),
+		),
+		beego.NSNamespace("/quotaeligibility",
+			beego.NSInclude(&controllers.QuotaEligibilityController{}),
+			beego.NSRouter("/questions", &controllers.QuotaEligibilityController{}, "get:GetQuestionnaire"),
+			beego.NSRouter("/check", &controllers.QuotaEligibilityController{}, "post:Check"),
+		),
+		beego.NSNamespace("/reviews",
+			beego.NSInclude(&controllers.ReviewController{}),
+			beego.NSRouter("/byuni/:universityId", &controllers.ReviewController{}, "get:GetByUniversity"),
+		),
+		beego.NSNamespace("/qa",
+			beego.NSInclude(&controllers.UniversityQAController{}),
+			beego.NSRouter("/byuni/:universityId", &controllers.UniversityQAController{}, "get:GetByUniversity"),
 		),
 		beego.NSNamespace("/services",
-			beego.NSInclude(&controllers.ServiceController{}),
-			beego.NSRouter("/search", &controllers.ServiceController{}, "get:SearchServices"),
-			beego.NSRouter("/", &controllers.ServiceController{}, "post:AddService"),
-			beego.NSRouter("/", &controllers.ServiceController{}, "get:GetAllServices"),
-			beego.NSRouter("/:id", &controllers.ServiceController{}, "get:GetServiceById"),
-			beego.NSRouter("/getbyuni/:universityId", &controllers.ServiceController{}, "get:GetServicesByUniversityId"),
+			beego.NSInclude(service),
+			beego.NSRouter("/search", service, "get:SearchServices"),
+			beego.NSRouter("/", service, "post:AddService"),
+			beego.NSRouter("/", service, "get:GetAllServices"),
+			beego.NSRouter("/:id", service, "get:GetServiceById"),
+			beego.NSRouter("/getbyuni/:universityId", service, "get:GetServicesByUniversityId"),
+		),
+		beego.NSNamespace("/events",
+			beego.NSInclude(&controllers.AdmissionEventController{}),
+			beego.NSRouter("/:id", &controllers.AdmissionEventController{}, "get:Get"),
+			beego.NSRouter("/byuni/:universityId", &controllers.AdmissionEventController{}, "get:GetByUniversity"),
+			beego.NSRouter("/calendar/:token", &controllers.AdmissionEventController{}, "get:ServeCalendar"),
 		),
 	)
 
 	beego.AddNamespace(adminNS)
 	beego.AddNamespace(userNS)
+
+	beego.Include(subject)
+	beego.Include(speciality)
+	beego.Include(university)
+	beego.Include(city)
+	beego.Include(quota)
+
+	beego.Router("/healthz", &controllers.HealthController{}, "get:Healthz")
+	beego.Router("/readyz", &controllers.HealthController{}, "get:Readyz")
+	beego.Handler("/metrics", metrics.Handler())
 }
@@ -0,0 +1,664 @@
+package routers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testhub-spec-uni/controllers"
+	"testhub-spec-uni/dbtest"
+	"testhub-spec-uni/models"
+	"testhub-spec-uni/repository"
+	"testing"
+	"time"
+
+	beego "github.com/beego/beego/v2/server/web"
+	beecontext "github.com/beego/beego/v2/server/web/context"
+)
+
+const testUserId = 7
+
+// Записи, которые TestMain кладёт в базу до запуска тестов.
+var (
+	storage                               *repository.MemoryStorage
+	regionId, cityId                      int
+	mathId, physicsId, informaticsId      int
+	specialityId, serviceId, universityId int
+	quotaId                               int
+)
+
+// TestMain запускает тесты на ORM-репозиториях поверх временной базы SQLite;
+// файлы загружаются в память.
+func TestMain(m *testing.M) {
+	storage = repository.NewMemoryStorage()
+	repos := repository.NewORMWithStorage(storage)
+
+	beego.BConfig.CopyRequestBody = true
+	// В приложении user_id кладёт middleware авторизации; здесь любой токен — пользователь testUserId.
+	beego.InsertFilter("/user/*", beego.BeforeRouter, func(ctx *beecontext.Context) {
+		if ctx.Input.Header("Authorization") != "" {
+			ctx.Input.SetData("user_id", testUserId)
+		}
+	})
+	Register(repos)
+
+	os.Exit(dbtest.Run(m, func() error { return seed(repos) }))
+}
+
+func seed(repos *repository.Repositories) error {
+	ctx := context.Background()
+	id, err := models.AddRegion(&models.Region{NameRu: "Алматинская область", NameKz: "Алматы облысы"})
+	if err != nil {
+		return err
+	}
+	regionId = int(id)
+
+	city := &models.City{NameRu: "Алматы", NameKz: "Алматы", Latitude: 43.24, Longitude: 76.89}
+	id, err = repos.Cities.Create(city)
+	if err != nil {
+		return err
+	}
+	cityId = int(id)
+
+	for _, subject := range []struct {
+		id     *int
+		ru, kz string
+	}{
+		{&mathId, "Математика", "Математика"},
+		{&physicsId, "Физика", "Физика"},
+		{&informaticsId, "Информатика", "Информатика"},
+	} {
+		id, err := repos.Subjects.Create(&models.Subject{NameRu: subject.ru, NameKz: subject.kz, MaxScore: 50, Threshold: 5})
+		if err != nil {
+			return err
+		}
+		*subject.id = int(id)
+	}
+
+	id, err = repos.Specialities.Create(&models.AddSpecialityResponse{
+		NameRu: "Информационные системы", NameKz: "Ақпараттық жүйелер",
+		AbbreviationRu: "ИС", AbbreviationKz: "АЖ",
+		Subject1: mathId, Subject2: informaticsId,
+		Degree: "Бакалавр", Code: "6B06101",
+	})
+	if err != nil {
+		return err
+	}
+	specialityId = int(id)
+
+	id, err = repos.Services.Create(&models.Service{NameRu: "Общежитие", NameKz: "Жатақхана", ImageUrl: "memory://dorm.png"})
+	if err != nil {
+		return err
+	}
+	serviceId = int(id)
+
+	id, err = repos.Universities.Create(&models.AddUUniversityResponse{
+		NameRu: "Казахский национальный университет", NameKz: "Қазақ ұлттық университеті",
+		UniversityStatusRu: "Национальный", UniversityStatusKz: "Ұлттық",
+		Website: "https://www.kaznu.kz", CallCenterNumber: "+77272211000", WhatsAppNumber: "+77000000000",
+		Address: "пр. аль-Фараби, 71", UniversityCode: "001",
+		StudyFormatRu: "Очная", StudyFormatKz: "Күндізгі",
+		AbbreviationRu: "КазНУ", AbbreviationKz: "ҚазҰУ",
+		AddressLink: "https://2gis.kz/almaty", Latitude: 43.22, Longitude: 76.92,
+		DescriptionRu: "Старейший университет", DescriptionKz: "Ең көне университет",
+		Rating: "1", Gallery: []string{"memory://kaznu/1.jpg"}, CityId: cityId,
+	})
+	if err != nil {
+		return err
+	}
+	universityId = int(id)
+	// Популярность задаётся только в базе, а /uninames отдаёт лишь популярные университеты.
+	university, err := repos.Universities.Get(universityId)
+	if err != nil {
+		return err
+	}
+	university.Popular = true
+	if err := repos.Universities.Update(university); err != nil {
+		return err
+	}
+	if err := repos.Universities.AddSpeciality(ctx, universityId, specialityId); err != nil {
+		return err
+	}
+	if _, err := repos.PointStats.Create(universityId, specialityId, &models.PointStat{
+		GrantCount: 120, MinScore: 70, MinGrantScore: 95, Year: 2024, AvgSalary: 350000, Price: 1500000,
+	}); err != nil {
+		return err
+	}
+
+	id, err = repos.Quotas.Create(&models.Quota{QuotaType: "rural", QuotaTypeRu: "Сельская квота", QuotaTypeKz: "Ауыл квотасы", Count: 30, MinScore: 50, MaxScore: 140})
+	if err != nil {
+		return err
+	}
+	quotaId = int(id)
+	return nil
+}
+
+type request struct {
+	method, path string
+	body         io.Reader
+	header       http.Header
+}
+
+func serve(t *testing.T, r request) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(r.method, r.path, r.body)
+	for key, values := range r.header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if req.Header.Get("lang") == "" {
+		req.Header.Set("lang", "ru")
+	}
+	rec := httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(rec, req)
+	return rec
+}
+
+// call выполняет запрос, проверяет статус и декодирует ответ в out, если он задан.
+func call(t *testing.T, r request, status int, out interface{}) {
+	t.Helper()
+	rec := serve(t, r)
+	if rec.Code != status {
+		t.Fatalf("%s %s: status %d, want %d: %s", r.method, r.path, rec.Code, status, rec.Body.String())
+	}
+	if out != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: decode %q: %v", r.method, r.path, rec.Body.String(), err)
+		}
+	}
+}
+
+func get(path string) request { return request{method: http.MethodGet, path: path} }
+
+func jsonRequest(method, path string, body interface{}) request {
+	data, _ := json.Marshal(body)
+	return request{method: method, path: path, body: bytes.NewReader(data), header: http.Header{"Content-Type": {"application/json"}}}
+}
+
+func formRequest(method, path string, values url.Values) request {
+	return request{
+		method: method, path: path,
+		body:   strings.NewReader(values.Encode()),
+		header: http.Header{"Content-Type": {"application/x-www-form-urlencoded"}},
+	}
+}
+
+// multipartRequest собирает multipart/form-data; files сопоставляет полю имена файлов.
+func multipartRequest(method, path string, values url.Values, files map[string][]string) request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for key, list := range values {
+		for _, value := range list {
+			writer.WriteField(key, value)
+		}
+	}
+	for field, names := range files {
+		for _, name := range names {
+			part, _ := writer.CreateFormFile(field, name)
+			part.Write([]byte("image " + name))
+		}
+	}
+	writer.Close()
+	return request{method: method, path: path, body: &body, header: http.Header{"Content-Type": {writer.FormDataContentType()}}}
+}
+
+func authorized(r request) request {
+	if r.header == nil {
+		r.header = http.Header{}
+	}
+	r.header.Set("Authorization", "Bearer test")
+	return r
+}
+
+type created struct {
+	Id int `json:"id"`
+}
+
+func TestSubjectEndpoints(t *testing.T) {
+	// Без MaxScore и Threshold предмет получает стандартные 50 и 5.
+	var subject created
+	call(t, jsonRequest(http.MethodPost, "/api/subjects", map[string]interface{}{
+		"NameRu": "Химия", "NameKz": "Химия",
+	}), http.StatusOK, &subject)
+	if subject.Id == 0 {
+		t.Fatal("subject without scores was not created")
+	}
+	path := fmt.Sprintf("/api/subjects/%d", subject.Id)
+
+	var got models.SubjectResponse
+	call(t, get(fmt.Sprintf("/user/subjects/%d", subject.Id)), http.StatusOK, &got)
+	if got.Name != "Химия" {
+		t.Errorf("subject name %q", got.Name)
+	}
+
+	var all []models.SubjectResponse
+	call(t, get("/user/subjects"), http.StatusOK, &all)
+	if len(all) != 4 {
+		t.Errorf("listed %d subjects, want 4", len(all))
+	}
+
+	var found []models.SubjectResponse
+	call(t, get("/user/subjects/search?name="+url.QueryEscape("Физ")), http.StatusOK, &found)
+	if len(found) != 1 || found[0].Id != physicsId {
+		t.Errorf("search found %+v", found)
+	}
+	call(t, get("/user/subjects/search"), http.StatusBadRequest, nil)
+
+	var second []models.SubjectResponse
+	call(t, get(fmt.Sprintf("/user/subjects/secubjects/%d", mathId)), http.StatusOK, &second)
+	if len(second) != 1 || second[0].Id != informaticsId {
+		t.Errorf("allowed second subjects %+v", second)
+	}
+
+	call(t, jsonRequest(http.MethodPut, path, map[string]string{"NameRu": "Химия и биология"}), http.StatusOK, nil)
+	call(t, get(path), http.StatusOK, &got)
+	if got.Name != "Химия и биология" {
+		t.Errorf("updated name %q", got.Name)
+	}
+	call(t, jsonRequest(http.MethodPut, path, map[string]int{"Threshold": 60}), http.StatusBadRequest, nil)
+	call(t, jsonRequest(http.MethodPut, path, map[string]int{"MaxScore": 0}), http.StatusBadRequest, nil)
+	call(t, jsonRequest(http.MethodPut, path, map[string]int{"Threshold": 0}), http.StatusOK, nil)
+	call(t, jsonRequest(http.MethodPut, "/api/subjects/999999", map[string]int{"Threshold": 0}), http.StatusNotFound, nil)
+
+	call(t, request{method: http.MethodDelete, path: path}, http.StatusOK, nil)
+	call(t, get(path), http.StatusNotFound, nil)
+	call(t, get("/api/subjects/abc"), http.StatusBadRequest, nil)
+}
+
+func TestCityEndpoints(t *testing.T) {
+	var city created
+	call(t, jsonRequest(http.MethodPost, "/api/cities", map[string]interface{}{
+		"NameRu": "Шымкент", "NameKz": "Шымкент", "Latitude": 42.3, "Longitude": 69.6,
+	}), http.StatusOK, &city)
+	path := fmt.Sprintf("/api/cities/%d", city.Id)
+
+	var got controllers.CityResponse
+	call(t, get(path), http.StatusOK, &got)
+	if got.Name != "Шымкент" || got.RegionId != 0 {
+		t.Errorf("city %+v", got)
+	}
+
+	call(t, jsonRequest(http.MethodPut, path, map[string]interface{}{"NameRu": "Шымкент", "NameKz": "Шымкент қаласы", "Latitude": 42.32}), http.StatusOK, nil)
+	call(t, request{method: http.MethodPut, path: fmt.Sprintf("/api/cities/assignregion/%d/%d", city.Id, regionId)}, http.StatusOK, nil)
+	call(t, request{method: http.MethodPut, path: fmt.Sprintf("/api/cities/assignregion/abc/%d", regionId)}, http.StatusBadRequest, nil)
+	call(t, request{method: http.MethodPut, path: fmt.Sprintf("/api/cities/assignregion/999999/%d", regionId)}, http.StatusNotFound, nil)
+	call(t, request{method: http.MethodPut, path: fmt.Sprintf("/api/cities/assignregion/%d/999999", city.Id)}, http.StatusNotFound, nil)
+	call(t, request{method: http.MethodGet, path: path, header: http.Header{"lang": {"kz"}}}, http.StatusOK, &got)
+	if got.Name != "Шымкент қаласы" || got.Latitude != 42.32 || got.RegionId != regionId {
+		t.Errorf("updated city %+v", got)
+	}
+
+	var all []controllers.CityResponse
+	call(t, get("/user/cities"), http.StatusOK, &all)
+	if len(all) != 2 {
+		t.Errorf("listed %d cities, want 2", len(all))
+	}
+
+	var found []controllers.CityResponse
+	call(t, get("/user/cities/search?name="+url.QueryEscape("Алм")), http.StatusOK, &found)
+	if len(found) != 1 || found[0].Id != cityId {
+		t.Errorf("search found %+v", found)
+	}
+
+	call(t, get(fmt.Sprintf("/user/cities/info/%d", cityId)), http.StatusOK, &got)
+	if got.Id != cityId {
+		t.Errorf("city info %+v", got)
+	}
+
+	call(t, request{method: http.MethodDelete, path: path}, http.StatusOK, nil)
+	var missing string
+	call(t, get(path), http.StatusOK, &missing)
+	if missing != repository.ErrNotFound.Error() {
+		t.Errorf("deleted city: %q", missing)
+	}
+}
+
+func TestQuotaEndpoints(t *testing.T) {
+	var quota created
+	call(t, jsonRequest(http.MethodPost, "/api/quotas", map[string]interface{}{
+		"QuotaType": "disability", "QuotaTypeRu": "Квота для лиц с инвалидностью", "QuotaTypeKz": "Мүгедектігі бар адамдар квотасы",
+		"Count": 10, "MinScore": 50, "MaxScore": 140,
+	}), http.StatusOK, &quota)
+	path := fmt.Sprintf("/api/quotas/%d", quota.Id)
+
+	call(t, jsonRequest(http.MethodPut, path, map[string]int{"Count": 15}), http.StatusOK, nil)
+	call(t, jsonRequest(http.MethodPut, path, map[string]int{"MinScore": 120, "MaxScore": 100}), http.StatusBadRequest, nil)
+	call(t, jsonRequest(http.MethodPut, "/api/quotas/999999", map[string]int{"Count": 1}), http.StatusNotFound, nil)
+	call(t, request{method: http.MethodPost, path: fmt.Sprintf("/user/quotas/%d/specialities/%d", quota.Id, specialityId)}, http.StatusOK, nil)
+
+	var got controllers.QuotaResponse
+	call(t, get(fmt.Sprintf("/user/quotas/%d", quota.Id)), http.StatusOK, &got)
+	if got.Count != 15 || got.MinScore != 50 {
+		t.Errorf("quota %+v", got)
+	}
+
+	var all []controllers.QuotaResponse
+	call(t, get("/user/quotas"), http.StatusOK, &all)
+	if len(all) != 2 {
+		t.Errorf("listed %d quotas, want 2", len(all))
+	}
+
+	call(t, get(fmt.Sprintf("/user/quotas/all/%d", quota.Id)), http.StatusOK, &all)
+	for _, q := range all {
+		if q.Id == quota.Id && (len(q.Specialities) != 1 || q.Specialities[0] != specialityId) {
+			t.Errorf("quota specialities %v", q.Specialities)
+		}
+	}
+
+	call(t, request{method: http.MethodDelete, path: path}, http.StatusOK, nil)
+	call(t, get("/user/quotas"), http.StatusOK, &all)
+	if len(all) != 1 || all[0].Id != quotaId {
+		t.Errorf("quotas after delete %+v", all)
+	}
+}
+
+func TestServiceEndpoints(t *testing.T) {
+	var service models.AddServiceForAdminResponse
+	call(t, multipartRequest(http.MethodPost, "/api/services", url.Values{"NameRu": {"Спортзал"}, "NameKz": {"Спортзал"}},
+		map[string][]string{"ImageUrl": {"gym.png"}}), http.StatusOK, &service)
+	if service.ImageUrl != "memory://Services/0/gym.png" {
+		t.Errorf("service image %q", service.ImageUrl)
+	}
+	path := fmt.Sprintf("/api/services/%d", service.Id)
+
+	call(t, jsonRequest(http.MethodPut, path, map[string]string{"NameKz": "Спорт залы"}), http.StatusOK, nil)
+
+	var got models.ServiceResponseForUser
+	call(t, request{method: http.MethodGet, path: fmt.Sprintf("/user/services/%d", service.Id), header: http.Header{"lang": {"kz"}}}, http.StatusOK, &got)
+	if got.Name != "Спорт залы" {
+		t.Errorf("service %+v", got)
+	}
+
+	var all []models.ServiceResponseForUser
+	call(t, get("/user/services"), http.StatusOK, &all)
+	if len(all) != 2 {
+		t.Errorf("listed %d services, want 2", len(all))
+	}
+	var admin []models.ServiceResponseForAdmin
+	call(t, get("/api/services"), http.StatusOK, &admin)
+	if len(admin) != 2 {
+		t.Errorf("listed %d services for admin, want 2", len(admin))
+	}
+
+	var found []models.ServiceResponseForUser
+	call(t, get("/user/services/search?prefix="+url.QueryEscape("Общ")), http.StatusOK, &found)
+	if len(found) != 1 || found[0].Id != serviceId {
+		t.Errorf("search found %+v", found)
+	}
+
+	call(t, request{method: http.MethodPost, path: fmt.Sprintf("/api/services/bind/%d/%d", service.Id, universityId)}, http.StatusOK, nil)
+	call(t, get(fmt.Sprintf("/user/services/getbyuni/%d", universityId)), http.StatusOK, &all)
+	call(t, get(fmt.Sprintf("/api/services/getbyuni/%d", universityId)), http.StatusOK, &admin)
+	if len(all) != 1 || len(admin) != 1 || admin[0].Id != service.Id {
+		t.Errorf("university services %+v, %+v", all, admin)
+	}
+
+	call(t, request{method: http.MethodDelete, path: path}, http.StatusOK, nil)
+	call(t, get(fmt.Sprintf("/api/services/getbyuni/%d", universityId)), http.StatusOK, &admin)
+	if len(admin) != 0 {
+		t.Errorf("deleted service still bound: %+v", admin)
+	}
+	call(t, request{method: http.MethodDelete, path: "/api/services/abc"}, http.StatusBadRequest, nil)
+}
+
+func TestSpecialityEndpoints(t *testing.T) {
+	var speciality created
+	call(t, formRequest(http.MethodPost, "/api/specialities", url.Values{
+		"NameRu": {"Физика"}, "NameKz": {"Физика"}, "AbbreviationRu": {"Ф"}, "AbbreviationKz": {"Ф"},
+		"Subject_1": {fmt.Sprint(mathId)}, "Subject_2": {fmt.Sprint(physicsId)},
+		"Degree": {"Бакалавр"}, "Code": {"6B05301"},
+	}), http.StatusOK, &speciality)
+	path := fmt.Sprintf("/api/specialities/%d", speciality.Id)
+
+	var invalid map[string]string
+	call(t, formRequest(http.MethodPost, "/api/specialities", url.Values{"NameRu": {"Без кода"}}), http.StatusOK, &invalid)
+	if invalid["Code"] == "" {
+		t.Errorf("missing Code accepted: %v", invalid)
+	}
+
+	call(t, formRequest(http.MethodPut, path, url.Values{"NameRu": {"Прикладная физика"}}), http.StatusOK, nil)
+	var got models.Speciality
+	call(t, get(fmt.Sprintf("/user/specialities/%d", speciality.Id)), http.StatusOK, &got)
+	if got.Name != "Прикладная физика" || got.Code != "6B05301" {
+		t.Errorf("speciality %+v", got)
+	}
+
+	var all []models.Speciality
+	call(t, get("/user/specialities"), http.StatusOK, &all)
+	if len(all) != 2 {
+		t.Errorf("listed %d specialities, want 2", len(all))
+	}
+
+	var bySubjects []models.Speciality
+	call(t, get(fmt.Sprintf("/user/specialities/bysubjects/%d/%d", mathId, physicsId)), http.StatusOK, &bySubjects)
+	if len(bySubjects) != 1 || bySubjects[0].Id != speciality.Id {
+		t.Errorf("by subjects %+v", bySubjects)
+	}
+
+	var pairs []models.SubjectPair
+	call(t, get(fmt.Sprintf("/user/specialities/byspec/%d", specialityId)), http.StatusOK, &pairs)
+	if len(pairs) != 1 {
+		t.Fatalf("subject pairs %+v", pairs)
+	}
+	call(t, request{method: http.MethodPut, path: fmt.Sprintf("/api/specialities/associatepair/%d/%d", speciality.Id, pairs[0].Id)}, http.StatusOK, nil)
+	call(t, get(fmt.Sprintf("/user/specialities/bysubjects/%d/%d", mathId, informaticsId)), http.StatusOK, &bySubjects)
+	if len(bySubjects) != 2 {
+		t.Errorf("after associating the pair got %d specialities, want 2", len(bySubjects))
+	}
+
+	var search models.SpecialitySearchResult
+	call(t, get("/user/specialities/search?name="+url.QueryEscape("Инф")), http.StatusOK, &search)
+	if search.TotalCount != 1 || search.Specialities[0].Id != specialityId {
+		t.Errorf("search %+v", search)
+	}
+
+	var names []models.GetSpecialityNameResponse
+	call(t, get("/user/specialities/specialitynames"), http.StatusOK, &names)
+	if len(names) != 2 {
+		t.Errorf("speciality names %+v", names)
+	}
+
+	var byUni struct {
+		Specialities []models.GetByUniResponseForUser `json:"specialities"`
+		TotalCount   int                              `json:"total_count"`
+	}
+	call(t, get(fmt.Sprintf("/user/specialities/byuni/%d", universityId)), http.StatusOK, &byUni)
+	if byUni.TotalCount != 1 || byUni.Specialities[0].SpecialityID != specialityId || byUni.Specialities[0].MinScore != 70 {
+		t.Errorf("university specialities %+v", byUni)
+	}
+	var byUniAdmin []models.IUniverSpecialtyShortcut
+	call(t, get(fmt.Sprintf("/api/specialities/byuni/%d", universityId)), http.StatusOK, &byUniAdmin)
+	if len(byUniAdmin) != 1 || byUniAdmin[0].SpecialityNameKz != "Ақпараттық жүйелер" {
+		t.Errorf("university specialities for admin %+v", byUniAdmin)
+	}
+
+	call(t, request{method: http.MethodDelete, path: path}, http.StatusOK, nil)
+	call(t, get("/user/specialities"), http.StatusOK, &all)
+	if len(all) != 1 {
+		t.Errorf("listed %d specialities after delete, want 1", len(all))
+	}
+}
+
+func TestPointStatEndpoints(t *testing.T) {
+	statsPath := fmt.Sprintf("/specialities/pointstatsbyparams/%d/%d", universityId, specialityId)
+
+	var stat created
+	call(t, formRequest(http.MethodPost, fmt.Sprintf("/api/specialities/addpointstat/%d/%d", universityId, specialityId), url.Values{
+		"GrantCount": {"100"}, "MinScore": {"65"}, "MinGrantScore": {"90"}, "Year": {"2023"}, "AvgSalary": {"300000"}, "Price": {"1400000"},
+	}), http.StatusOK, &stat)
+
+	var stats []models.GetPointStatResponse
+	call(t, get("/user"+statsPath), http.StatusOK, &stats)
+	if len(stats) != 2 {
+		t.Fatalf("listed %d point stats, want 2", len(stats))
+	}
+
+	call(t, formRequest(http.MethodPut, fmt.Sprintf("/api/specialities/updatepointstat/%d", stat.Id), url.Values{"MinScore": {"68"}}), http.StatusOK, nil)
+	call(t, formRequest(http.MethodPut, fmt.Sprintf("/api/specialities/updatepointstat/%d", stat.Id), url.Values{"MinScore": {"200"}}), http.StatusBadRequest, nil)
+	call(t, formRequest(http.MethodPut, fmt.Sprintf("/api/specialities/updatepointstat/%d", stat.Id), url.Values{"MinGrantScore": {"60"}}), http.StatusBadRequest, nil)
+	call(t, formRequest(http.MethodPut, "/api/specialities/updatepointstat/999999", url.Values{"MinScore": {"68"}}), http.StatusNotFound, nil)
+
+	var got map[string]int
+	call(t, get(fmt.Sprintf("/api/specialities/getstat/%d/", stat.Id)), http.StatusOK, &got)
+	if got["min_score"] != 68 || got["year"] != 2023 {
+		t.Errorf("point stat %v", got)
+	}
+
+	call(t, request{method: http.MethodDelete, path: fmt.Sprintf("/api/specialities/deletepointstat/%d/", stat.Id)}, http.StatusOK, nil)
+	call(t, get("/api"+statsPath), http.StatusOK, &stats)
+	if len(stats) != 1 || stats[0].Year != 2024 {
+		t.Errorf("point stats after delete %+v", stats)
+	}
+}
+
+func universityForm(name string) url.Values {
+	return url.Values{
+		"NameRu": {name}, "NameKz": {name},
+		"UniversityStatusRu": {"Частный"}, "UniversityStatusKz": {"Жеке"},
+		"Website": {"https://example.kz"}, "CallCenterNumber": {"+77170000000"}, "WhatsAppNumber": {"+77010000000"},
+		"Address": {"ул. Сатпаева, 22"}, "UniversityCode": {"042"},
+		"StudyFormatRu": {"Очная"}, "StudyFormatKz": {"Күндізгі"},
+		"AbbreviationRu": {"УТ"}, "AbbreviationKz": {"УТ"},
+		"AddressLink": {"https://2gis.kz/almaty"}, "Latitude": {"43.23"}, "Longitude": {"76.93"},
+		"DescriptionRu": {"Описание"}, "DescriptionKz": {"Сипаттама"},
+		"Rating": {"5"}, "CityId": {fmt.Sprint(cityId)},
+		"ServiceIds": {fmt.Sprintf("[%d]", serviceId)},
+	}
+}
+
+func TestUniversityEndpoints(t *testing.T) {
+	var university created
+	call(t, multipartRequest(http.MethodPost, "/api/universities", universityForm("Университет Туран"),
+		map[string][]string{"MainImageUrl": {"main.jpg"}, "Gallery": {"1.jpg", "2.jpg"}}), http.StatusOK, &university)
+	path := fmt.Sprintf("/api/universities/%d", university.Id)
+	if data, ok := storage.File(fmt.Sprintf("Universities/%d/Gallery/2.jpg", university.Id)); !ok || string(data) != "image 2.jpg" {
+		t.Errorf("gallery upload %q, %v", data, ok)
+	}
+
+	invalid := universityForm("")
+	call(t, multipartRequest(http.MethodPost, "/api/universities", invalid, nil), http.StatusBadRequest, nil)
+
+	var admin models.GetByIdUniversityResponseForAdmin
+	call(t, get(path), http.StatusOK, &admin)
+	if admin.MainImageUrl != fmt.Sprintf("memory://Universities/%d/main.jpg", university.Id) ||
+		len(admin.Gallery) != 2 || len(admin.Services) != 1 || admin.City.Id != cityId {
+		t.Fatalf("university for admin %+v", admin)
+	}
+
+	call(t, multipartRequest(http.MethodPut, path, url.Values{"NameKz": {"Тұран университеті"}},
+		map[string][]string{"Gallery": {"3.jpg"}}), http.StatusOK, nil)
+	call(t, request{method: http.MethodDelete, path: fmt.Sprintf("/api/universities/%d/delete-gallery/%d", university.Id, admin.Gallery[0].Id)}, http.StatusOK, nil)
+	call(t, request{method: http.MethodDelete, path: fmt.Sprintf("/api/universities/%d/delete-gallery/%d", 9999, admin.Gallery[0].Id)}, http.StatusNotFound, nil)
+
+	var user models.GetByIdUniversityResponseForUser
+	call(t, request{method: http.MethodGet, path: fmt.Sprintf("/user/universities/%d", university.Id), header: http.Header{"lang": {"kz"}}}, http.StatusOK, &user)
+	if user.Name != "Тұран университеті" || len(user.Gallery) != 2 || len(user.Services) != 1 {
+		t.Errorf("university for user %+v", user)
+	}
+
+	call(t, request{method: http.MethodPost, path: fmt.Sprintf("/api/universities/assignspec/%d/%d", university.Id, specialityId)}, http.StatusOK, nil)
+	call(t, jsonRequest(http.MethodPost, fmt.Sprintf("/api/universities/assignspecialities/%d", university.Id), []int{specialityId}), http.StatusOK, nil)
+	call(t, jsonRequest(http.MethodPost, fmt.Sprintf("/api/universities/assignserv/%d", university.Id), []int{serviceId}), http.StatusOK, nil)
+	call(t, request{method: http.MethodPut, path: fmt.Sprintf("/api/universities/assigncity/%d/%d", university.Id, cityId)}, http.StatusOK, nil)
+	call(t, formRequest(http.MethodPost, fmt.Sprintf("/api/specialities/addpointstat/%d/%d", university.Id, specialityId), url.Values{
+		"GrantCount": {"20"}, "MinScore": {"90"}, "MinGrantScore": {"110"}, "Year": {"2024"}, "AvgSalary": {"280000"}, "Price": {"2000000"},
+	}), http.StatusOK, nil)
+	call(t, request{method: http.MethodPut, path: "/api/universities/refreshstats"}, http.StatusOK, nil)
+
+	var search models.UniversitySearchResult
+	call(t, get("/user/universities/search?min_score=80&sort=name_asc"), http.StatusOK, &search)
+	if search.TotalCount != 1 || search.Universities[0].Id != university.Id || search.Universities[0].MinScore != 90 {
+		t.Errorf("search %+v", search)
+	}
+	call(t, get("/api/universities/search?speciality_ids="+url.QueryEscape(fmt.Sprintf("[%d]", specialityId))), http.StatusOK, &search)
+	if search.TotalCount != 2 {
+		t.Errorf("search by speciality found %d universities, want 2", search.TotalCount)
+	}
+
+	var collection models.GeoJSONFeatureCollection
+	call(t, get("/user/universities/map?bbox=76,43,77,44"), http.StatusOK, &collection)
+	if collection.Type != "FeatureCollection" || len(collection.Features) != 2 {
+		t.Errorf("map %+v", collection)
+	}
+	call(t, get("/user/universities/map?zoom=30"), http.StatusBadRequest, nil)
+
+	var names []models.GetUniNamesResponse
+	call(t, get("/user/universities/uninames"), http.StatusOK, &names)
+	if len(names) != 1 || names[0].Id != universityId {
+		t.Errorf("university names %+v", names)
+	}
+	var forAdmin []models.GetAllUniversityForAdminResponse
+	call(t, get("/api/universities"), http.StatusOK, &forAdmin)
+	if len(forAdmin) != 2 {
+		t.Errorf("listed %d universities for admin, want 2", len(forAdmin))
+	}
+
+	call(t, request{method: http.MethodDelete, path: fmt.Sprintf("/api/universities/deletespec/%d/%d", university.Id, specialityId)}, http.StatusOK, nil)
+	call(t, request{method: http.MethodDelete, path: fmt.Sprintf("/api/universities/deletespec/%d/%d", university.Id, specialityId)}, http.StatusNotFound, nil)
+
+	call(t, request{method: http.MethodDelete, path: path}, http.StatusOK, nil)
+	call(t, get("/api/universities"), http.StatusOK, &forAdmin)
+	if len(forAdmin) != 1 || forAdmin[0].Id != universityId {
+		t.Errorf("universities after delete %+v", forAdmin)
+	}
+}
+
+func TestUniversityListAndFavorites(t *testing.T) {
+	var unauthorized string
+	call(t, get("/user/universities"), http.StatusOK, &unauthorized)
+	if unauthorized != "failed to retrieve user_id from context" {
+		t.Errorf("anonymous list: %q", unauthorized)
+	}
+
+	favorite := fmt.Sprintf("/user/universities/favorites/%%s/%d", universityId)
+	call(t, authorized(request{method: http.MethodPost, path: fmt.Sprintf(favorite, "add")}), http.StatusOK, nil)
+	call(t, request{method: http.MethodPost, path: fmt.Sprintf(favorite, "add")}, http.StatusBadRequest, nil)
+
+	var page struct {
+		Universities []models.GetAllUniversityResponse `json:"universities"`
+		TotalCount   int64                             `json:"total_count"`
+	}
+	call(t, authorized(get("/user/universities?page=1&per_page=5")), http.StatusOK, &page)
+	if page.TotalCount != 1 || !page.Universities[0].Favorite {
+		t.Errorf("university page %+v", page)
+	}
+
+	var favorites []models.GetAllUniversityResponse
+	call(t, authorized(get("/user/universities/favorites/list")), http.StatusOK, &favorites)
+	if len(favorites) != 1 || favorites[0].Id != universityId || favorites[0].Name != "Казахский национальный университет" {
+		t.Errorf("favorites %+v", favorites)
+	}
+
+	call(t, authorized(request{method: http.MethodPost, path: fmt.Sprintf(favorite, "remove")}), http.StatusOK, nil)
+	call(t, authorized(get("/user/universities/favorites/list")), http.StatusOK, &favorites)
+	if len(favorites) != 0 {
+		t.Errorf("favorites after remove %+v", favorites)
+	}
+}
+
+// 304 отдаётся только по ETag: Last-Modified не отправляется.
+func TestConditionalGet(t *testing.T) {
+	rec := serve(t, get("/user/subjects"))
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" || rec.Header().Get("Last-Modified") != "" {
+		t.Fatalf("status %d, ETag %q, Last-Modified %q", rec.Code, etag, rec.Header().Get("Last-Modified"))
+	}
+
+	if rec := serve(t, request{method: http.MethodGet, path: "/user/subjects", header: http.Header{"If-None-Match": {etag}}}); rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Fatalf("matching If-None-Match: status %d, body %q", rec.Code, rec.Body.String())
+	}
+	if rec := serve(t, request{method: http.MethodGet, path: "/user/subjects", header: http.Header{"If-None-Match": {`W/"other"`}}}); rec.Code != http.StatusOK {
+		t.Fatalf("stale If-None-Match: status %d", rec.Code)
+	}
+	if rec := serve(t, request{method: http.MethodGet, path: "/user/subjects", header: http.Header{"If-Modified-Since": {time.Now().UTC().Format(http.TimeFormat)}}}); rec.Code != http.StatusOK {
+		t.Fatalf("If-Modified-Since alone: status %d", rec.Code)
+	}
+}
@@ -0,0 +1,136 @@
+// Package tracing настраивает OpenTelemetry: экспортёр трасс, распространение
+// контекста W3C traceparent и span на каждый HTTP-запрос. Span для запросов к
+// базе пишутся через обёртку драйвера из metrics, для хранилища и сервиса
+// аккаунтов — кодом, который к ним обращается, через Start и End.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"testhub-spec-uni/metrics"
+
+	beego "github.com/beego/beego/v2/server/web"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Экспортёры трасс для trace_exporter.
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+const (
+	serviceName = "testhub-spec-uni"
+	tracerName  = "testhub-spec-uni"
+)
+
+func init() {
+	metrics.SetQueryTracer(startQuery)
+}
+
+// Setup устанавливает глобальный TracerProvider с экспортёром exporter. Для otlp
+// endpoint — адрес OTLP/HTTP-коллектора (http://localhost:4318); пустой адрес
+// берётся из OTEL_EXPORTER_OTLP_ENDPOINT. stdout пишет span в out. Возвращённая
+// функция отправляет накопленные span и должна вызываться при остановке.
+func Setup(exporter, endpoint string, out io.Writer) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var spanExporter sdktrace.SpanExporter
+	var err error
+	switch exporter {
+	case ExporterNone:
+		// Остаётся пустой TracerProvider: span не записываются, но входящий
+		// traceparent всё равно передаётся в исходящие запросы.
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		spanExporter, err = stdouttrace.New(stdouttrace.WithWriter(out))
+	case ExporterOTLP:
+		var options []otlptracehttp.Option
+		if endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(endpoint))
+		}
+		spanExporter, err = otlptracehttp.New(context.Background(), options...)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(spanExporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Start начинает дочерний span обращения name к внешнему сервису.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// End завершает span, отмечая его ошибкой, если err не nil.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// startQuery пишет span запроса к базе. Родитель — span из контекста, который
+// database/sql передаёт драйверу: у методов *Context это контекст вызывающего.
+// ORM контекст не передаёт, поэтому его запросы становятся дочерними, только если
+// выполняются в транзакции BeginTx(ctx) (models.newOrmWithContext); остальные — корневые.
+func startQuery(ctx context.Context, system attribute.KeyValue, operation, function, text string) (context.Context, func(error)) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "db."+operation+" "+function,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(system, semconv.DBQueryText(text), semconv.CodeFunction(function)),
+	)
+	return ctx, func(err error) { End(span, err) }
+}
+
+// Inject добавляет traceparent текущего span в заголовки исходящего запроса.
+func Inject(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// HTTPMiddleware продолжает трассу из входящего traceparent или начинает новую и
+// пишет span на запрос с именем «метод шаблон-маршрута».
+func HTTPMiddleware(routes *beego.ControllerRegister) beego.MiddleWare {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := otel.Tracer(tracerName).Start(ctx, r.Method,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(semconv.HTTPRequestMethodKey.String(r.Method), semconv.URLPath(r.URL.Path)),
+			)
+			defer span.End()
+
+			recorder := metrics.NewStatusRecorder(w)
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+
+			route := metrics.RoutePattern(routes, r)
+			status := recorder.Status()
+			span.SetName(r.Method + " " + route)
+			span.SetAttributes(semconv.HTTPRoute(route), semconv.HTTPResponseStatusCode(status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+		})
+	}
+}
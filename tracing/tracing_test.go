@@ -0,0 +1,175 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testhub-spec-uni/metrics"
+	"testing"
+
+	beego "github.com/beego/beego/v2/server/web"
+	beecontext "github.com/beego/beego/v2/server/web/context"
+	_ "github.com/mattn/go-sqlite3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	incomingTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	incomingSpanID  = "00f067aa0ba902b7"
+)
+
+func TestRequestSpansShareIncomingTrace(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	driverName, err := metrics.RegisterDriver("sqlite3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open(driverName, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var outgoing string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		outgoing = r.Header.Get("traceparent")
+	}))
+	defer upstream.Close()
+
+	routes := beego.NewControllerRegister()
+	routes.Get("/user/universities/:id", func(ctx *beecontext.Context) {
+		if _, err := db.ExecContext(ctx.Request.Context(), "SELECT 1"); err != nil {
+			t.Error(err)
+		}
+		// Как ORM: запрос к базе без контекста.
+		if rows, err := db.Query("SELECT 2"); err != nil {
+			t.Error(err)
+		} else {
+			rows.Close()
+		}
+
+		callCtx, span := Start(ctx.Request.Context(), "upstream")
+		req, _ := http.NewRequestWithContext(callCtx, http.MethodGet, upstream.URL, nil)
+		Inject(callCtx, req.Header)
+		resp, err := http.DefaultClient.Do(req)
+		End(span, err)
+		if err == nil {
+			resp.Body.Close()
+		}
+		ctx.Output.SetStatus(http.StatusInternalServerError)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/user/universities/7", nil)
+	request.Header.Set("traceparent", "00-"+incomingTraceID+"-"+incomingSpanID+"-01")
+	HTTPMiddleware(routes)(routes).ServeHTTP(httptest.NewRecorder(), request)
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+		if span.Name() == "db.query other" {
+			continue
+		}
+		if span.SpanContext().TraceID().String() != incomingTraceID {
+			t.Errorf("span %q is not in the incoming trace", span.Name())
+		}
+	}
+
+	server, ok := spans["GET /user/universities/:id"]
+	if !ok {
+		t.Fatalf("no server span named by the route pattern, got %v", spans)
+	}
+	if server.Parent().SpanID().String() != incomingSpanID || server.Status().Description != "Internal Server Error" {
+		t.Errorf("unexpected server span: parent %s, status %+v", server.Parent().SpanID(), server.Status())
+	}
+	for _, name := range []string{"upstream", "db.exec other"} {
+		span, ok := spans[name]
+		if !ok {
+			t.Errorf("no %q span, got %v", name, spans)
+			continue
+		}
+		if span.Parent().SpanID() != server.SpanContext().SpanID() {
+			t.Errorf("%q is not a child of the request span", name)
+		}
+	}
+	if orphan, ok := spans["db.query other"]; !ok || orphan.Parent().IsValid() {
+		t.Errorf("a query without context must produce a root span, got %v", spans)
+	}
+	if !strings.Contains(outgoing, incomingTraceID) || strings.Contains(outgoing, incomingSpanID) {
+		t.Errorf("outgoing traceparent %q does not continue the trace from the upstream span", outgoing)
+	}
+}
+
+func TestTransactionQueriesAttachToRequestSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	driverName, err := metrics.RegisterDriver("sqlite3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open(driverName, ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	ctx, request := otel.Tracer(tracerName).Start(context.Background(), "GET /user/universities")
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Как ORM в транзакции: запросы без контекста.
+	if _, err := tx.Exec("SELECT 1"); err != nil {
+		t.Fatal(err)
+	}
+	if rows, err := tx.Query("SELECT 2"); err != nil {
+		t.Fatal(err)
+	} else {
+		rows.Close()
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	// После транзакции то же соединение снова пишет корневые span.
+	if _, err := db.Exec("SELECT 3"); err != nil {
+		t.Fatal(err)
+	}
+	request.End()
+
+	var children, roots int
+	for _, span := range recorder.Ended() {
+		if !strings.HasPrefix(span.Name(), "db.") {
+			continue
+		}
+		switch span.Parent().SpanID() {
+		case request.SpanContext().SpanID():
+			children++
+		default:
+			if span.Parent().IsValid() {
+				t.Errorf("%q has an unexpected parent", span.Name())
+			}
+			roots++
+		}
+	}
+	if children != 2 || roots != 1 {
+		t.Errorf("expected 2 transaction spans under the request and 1 root span, got %d and %d", children, roots)
+	}
+}
+
+func TestSetupRejectsUnknownExporter(t *testing.T) {
+	if _, err := Setup("zipkin", "", nil); err == nil {
+		t.Fatal("expected an error")
+	}
+}